@@ -0,0 +1,86 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// Party.Open is only secure against semi-honest parties: a party that sends a wrong share makes the
+// reconstruction fail with ErrorIncompatibleShares, or silently gives a wrong secret, and nobody
+// learns who it was. If the secrets were dealt by ShareFeldman, or are linear combinations of such
+// secrets, see Commitments.Add, OpenVerified checks every received share against the commitments
+// and aborts with evidence against the parties that sent wrong ones.
+//
+// The evidence only convinces the party that received the shares, and only if the Network
+// authenticates the parties, for instance with TLS client certificates. The shares are not signed
+// by their senders, so anybody can make up evidence against any party, and a third party such as
+// an operator cannot tell made-up evidence from real evidence. Since shares are sent point to
+// point, a cheating party may also send wrong shares to some parties only, so different honest
+// parties may abort with evidence against different parties, or not abort at all.
+
+import (
+	"math/big"
+)
+
+// Evidence records a share that a party sent during OpenVerified and that does not match the
+// commitments of the secret. Index is the position of the secret in the opened vector. It is a
+// record for the receiving party only, and no proof for others that Party sent the share.
+type Evidence struct {
+	Party       int
+	Index       int
+	Share       Share
+	Commitments Commitments
+}
+
+// Verify checks that the share is not a valid share of Party for the commitments with the given
+// generator, either because it does not match them or because it has the X coordinate of another
+// party. It does not check that Party sent the share.
+func (e Evidence) Verify(generator GroupElement) bool {
+	return e.Share.X != e.Party || !e.Commitments.Verify(generator, e.Share)
+}
+
+// OpenVerified reveals the secrets shared by v to all parties, like Open, after checking the share
+// of every party against commitments[i] with the given generator for every secret i. If shares do
+// not match, it returns ErrorShareNotCommitted along with evidence against every party that sent
+// this party a wrong share.
+func (p *Party) OpenVerified(v ShareVector, generator GroupElement, commitments []Commitments) ([]*big.Int, []Evidence, error) {
+	if len(commitments) != len(v) {
+		return nil, nil, ErrorIncompatibleShares
+	}
+	outgoing := make([][]NestedShare, p.nParties)
+	for i := range outgoing {
+		outgoing[i] = nest(v)
+	}
+	received, err := p.exchange(outgoing)
+	if err != nil {
+		return nil, nil, err
+	}
+	vectors := make([]ShareVector, len(received))
+	var evidence []Evidence
+	for i := range received {
+		if len(received[i]) != len(v) {
+			return nil, nil, ErrorNetwork
+		}
+		vectors[i] = unnest(received[i])
+		for j, share := range vectors[i] {
+			e := Evidence{Party: i + 1, Index: j, Share: share, Commitments: commitments[j]}
+			if e.Verify(generator) {
+				evidence = append(evidence, e)
+			}
+		}
+	}
+	if evidence != nil {
+		return nil, evidence, ErrorShareNotCommitted
+	}
+	secrets, err := CombineVector(vectors)
+	return secrets, nil, err
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenVerified(t *testing.T) {
+	assert := assert.New(t)
+	g := testGroup.Generator()
+	a, commitmentsA := ShareFeldman(big.NewInt(12), g, 1, 4)
+	b, commitmentsB := ShareFeldman(big.NewInt(34), g, 1, 4)
+	sum, err := commitmentsA.Add(commitmentsB)
+	assert.NoError(err)
+	commitments := []Commitments{commitmentsA, commitmentsB, sum}
+
+	results := runParties(t, 4, 1, g.Order(), func(p *Party) ([]*big.Int, error) {
+		v := ShareVector{a[p.X()-1], b[p.X()-1]}
+		s, err := ShareVectorAdd([]ShareVector{{v[0]}, {v[1]}})
+		if err != nil {
+			return nil, err
+		}
+		secrets, evidence, err := p.OpenVerified(append(v, s[0]), g, commitments)
+		assert.Nil(evidence)
+		return secrets, err
+	})
+	for _, result := range results {
+		assert.Equal(bigInts(12, 34, 46), result)
+	}
+
+	// Party 3 sends a wrong share of the second secret, and every party aborts with evidence
+	var lock sync.Mutex
+	var evidence [][]Evidence
+	runParties(t, 4, 1, g.Order(), func(p *Party) ([]*big.Int, error) {
+		v := ShareVector{a[p.X()-1], b[p.X()-1]}
+		if p.X() == 3 {
+			v[1].Y = big.NewInt(0).Add(v[1].Y, big.NewInt(1))
+		}
+		secrets, e, err := p.OpenVerified(v, g, commitments[:2])
+		assert.Nil(secrets)
+		assert.Equal(ErrorShareNotCommitted, err)
+		lock.Lock()
+		defer lock.Unlock()
+		evidence = append(evidence, e)
+		return nil, nil
+	})
+	assert.Equal(4, len(evidence))
+	for _, e := range evidence {
+		if assert.Equal(1, len(e)) {
+			assert.Equal(3, e[0].Party)
+			assert.Equal(1, e[0].Index)
+			assert.True(e[0].Verify(g))
+		}
+	}
+
+	// A correct share is no evidence, but a correct share of another party is. Verify does not check
+	// who sent the share, so anyone can make up such evidence.
+	assert.False(Evidence{Party: 2, Share: a[1], Commitments: commitmentsA}.Verify(g))
+	assert.True(Evidence{Party: 2, Share: a[2], Commitments: commitmentsA}.Verify(g))
+}
+
+func TestOpenVerifiedWrongCommitments(t *testing.T) {
+	assert := assert.New(t)
+	g := testGroup.Generator()
+	p := NewParty(1, 1, g.Order(), 0, nil)
+	_, _, err := p.OpenVerified(ShareVector{{}}, g, nil)
+	assert.Equal(ErrorIncompatibleShares, err)
+}
@@ -0,0 +1,360 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrorInvalidPolicy = errors.New("Quorum policy is malformed")
+)
+
+// An AccessStructure describes which sets of parties can recover a secret, as a tree of threshold
+// gates: a leaf names a party, and any Threshold of the Children of an inner node must be satisfied
+// for the node to be satisfied. This generalizes the two levels of ShareGroups to any depth. Parse
+// an AccessStructure from a quorum policy with ParsePolicy, and deal and combine shares for it with
+// ShareAccessStructure and CombineAccessStructure.
+type AccessStructure struct {
+	Party     string
+	Threshold int
+	Children  []AccessStructure
+}
+
+// ParsePolicy parses a quorum policy, such as "2 of (A, B, C) and 1 of (D, E)", into an
+// AccessStructure, so that policies can be kept in configuration files. A policy is built from:
+//
+//	party                  a name of letters, digits and the characters _-.@:
+//	k of (p1, p2, ...)     any k of the policies p1, p2, ...
+//	p1 and p2 and ...      all of the policies, i.e. n of (p1, ..., pn)
+//	p1 or p2 or ...        any of the policies, i.e. 1 of (p1, ..., pn)
+//	(p)                    grouping
+//
+// The keywords are case-insensitive, and "and" binds more strongly than "or". ErrorInvalidPolicy is
+// returned for malformed policies and thresholds outside [1, n].
+func ParsePolicy(policy string) (AccessStructure, error) {
+	p := &policyParser{tokens: tokenizePolicy(policy)}
+	structure, err := p.parseOr()
+	if err != nil {
+		return AccessStructure{}, err
+	}
+	if p.position != len(p.tokens) {
+		return AccessStructure{}, ErrorInvalidPolicy
+	}
+	return structure, nil
+}
+
+// String returns the policy in the form "k of (p1, p2, ...)", which ParsePolicy accepts.
+func (a AccessStructure) String() string {
+	if a.Children == nil {
+		return a.Party
+	}
+	children := make([]string, len(a.Children))
+	for i, child := range a.Children {
+		children[i] = child.String()
+	}
+	return strconv.Itoa(a.Threshold) + " of (" + strings.Join(children, ", ") + ")"
+}
+
+// Parties returns the names of the parties in the access structure, in order of first appearance.
+func (a AccessStructure) Parties() []string {
+	var parties []string
+	seen := make(map[string]bool)
+	a.walk(func(leaf AccessStructure, _ []int) {
+		if !seen[leaf.Party] {
+			seen[leaf.Party] = true
+			parties = append(parties, leaf.Party)
+		}
+	}, nil)
+	return parties
+}
+
+// CanReconstruct reports whether the parties together satisfy the access structure.
+func (a AccessStructure) CanReconstruct(parties []string) bool {
+	present := make(map[string]bool, len(parties))
+	for _, party := range parties {
+		present[party] = true
+	}
+	return a.satisfied(present)
+}
+
+func (a AccessStructure) satisfied(present map[string]bool) bool {
+	if a.Children == nil {
+		return present[a.Party]
+	}
+	count := 0
+	for _, child := range a.Children {
+		if child.satisfied(present) {
+			count++
+		}
+	}
+	return count >= a.Threshold
+}
+
+// walk calls f for every leaf with the path of child indices from the root to the leaf.
+func (a AccessStructure) walk(f func(leaf AccessStructure, path []int), path []int) {
+	if a.Children == nil {
+		f(a, path)
+		return
+	}
+	for i, child := range a.Children {
+		child.walk(f, append(append([]int{}, path...), i))
+	}
+}
+
+// An AccessShare is a share dealt by ShareAccessStructure to Party, for the leaf of the access
+// structure at Path, the indices of the children on the way from the root. A party that appears in
+// several leaves receives a share for each of them.
+type AccessShare struct {
+	Party string
+	Path  []int
+	Share
+}
+
+// ShareAccessStructure shares a secret over a finite field of integers modulo fieldSize according
+// to an access structure: the value of every inner node is shared among its children with a
+// polynomial of degree Threshold-1, starting with the secret at the root. The field size must exceed
+// the number of children of every node. The shares of a party are those with its name as Party.
+func ShareAccessStructure(secret *big.Int, fieldSize *big.Int, structure AccessStructure) ([]AccessShare, error) {
+	if err := structure.validate(); err != nil {
+		return nil, err
+	}
+	root := structure
+	if root.Children == nil {
+		root = AccessStructure{Threshold: 1, Children: []AccessStructure{structure}}
+	}
+	var shares []AccessShare
+	var deal func(node AccessStructure, value *big.Int, path []int)
+	deal = func(node AccessStructure, value *big.Int, path []int) {
+		for i, share := range ShareFiniteField(value, fieldSize, node.Threshold-1, len(node.Children)) {
+			childPath := append(append([]int{}, path...), i)
+			if child := node.Children[i]; child.Children != nil {
+				deal(child, share.Y, childPath)
+			} else {
+				shares = append(shares, AccessShare{Party: child.Party, Path: childPath, Share: share})
+			}
+		}
+	}
+	deal(root, secret, nil)
+	return shares, nil
+}
+
+// CombineAccessStructure recovers a secret shared by ShareAccessStructure from the shares of a set
+// of parties that satisfies the access structure, and returns ErrorTooFewShares otherwise.
+func CombineAccessStructure(structure AccessStructure, shares []AccessShare) (*big.Int, error) {
+	if err := structure.validate(); err != nil {
+		return nil, err
+	}
+	if len(shares) == 0 {
+		return nil, ErrorNoShares
+	}
+	root := structure
+	if root.Children == nil {
+		root = AccessStructure{Threshold: 1, Children: []AccessStructure{structure}}
+	}
+	leaves := make(map[string]Share, len(shares))
+	for _, share := range shares {
+		leaves[pathKey(share.Path)] = share.Share
+	}
+	var combine func(node AccessStructure, path []int) (*big.Int, error)
+	combine = func(node AccessStructure, path []int) (*big.Int, error) {
+		var childShares []Share
+		for i, child := range node.Children {
+			childPath := append(append([]int{}, path...), i)
+			if child.Children == nil {
+				if share, ok := leaves[pathKey(childPath)]; ok {
+					childShares = append(childShares, share)
+				}
+				continue
+			}
+			y, err := combine(child, childPath)
+			if errors.Is(err, ErrorTooFewShares) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			childShares = append(childShares, Share{FieldSize: shares[0].FieldSize, Degree: node.Threshold - 1, X: i + 1, Y: y})
+		}
+		if len(childShares) == 0 {
+			return nil, ErrorTooFewShares
+		}
+		return ShareCombine(childShares)
+	}
+	return combine(root, nil)
+}
+
+// validate checks that the thresholds of the access structure lie in [1, n] for n children.
+func (a AccessStructure) validate() error {
+	if a.Children == nil {
+		if a.Party == "" {
+			return ErrorInvalidPolicy
+		}
+		return nil
+	}
+	if a.Threshold < 1 || a.Threshold > len(a.Children) {
+		return ErrorInvalidPolicy
+	}
+	for _, child := range a.Children {
+		if err := child.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pathKey encodes a path as a map key.
+func pathKey(path []int) string {
+	parts := make([]string, len(path))
+	for i, index := range path {
+		parts[i] = strconv.Itoa(index)
+	}
+	return strings.Join(parts, "/")
+}
+
+// policyParser is a recursive descent parser for quorum policies.
+type policyParser struct {
+	tokens   []string
+	position int
+}
+
+// tokenizePolicy splits a policy into parentheses, commas and words.
+func tokenizePolicy(policy string) []string {
+	var tokens []string
+	word := strings.Builder{}
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range policy {
+		switch {
+		case r == '(' || r == ')' || r == ',':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+func (p *policyParser) peek() string {
+	if p.position < len(p.tokens) {
+		return p.tokens[p.position]
+	}
+	return ""
+}
+
+func (p *policyParser) keyword(keyword string) bool {
+	if strings.EqualFold(p.peek(), keyword) {
+		p.position++
+		return true
+	}
+	return false
+}
+
+// parseOr parses p1 or p2 or ...
+func (p *policyParser) parseOr() (AccessStructure, error) {
+	return p.parseList("or", p.parseAnd, func(n int) int { return 1 })
+}
+
+// parseAnd parses p1 and p2 and ...
+func (p *policyParser) parseAnd() (AccessStructure, error) {
+	return p.parseList("and", p.parseTerm, func(n int) int { return n })
+}
+
+// parseList parses operands separated by the keyword, and combines two or more of them into a node
+// with the given threshold.
+func (p *policyParser) parseList(keyword string, operand func() (AccessStructure, error), threshold func(n int) int) (AccessStructure, error) {
+	first, err := operand()
+	if err != nil {
+		return AccessStructure{}, err
+	}
+	children := []AccessStructure{first}
+	for p.keyword(keyword) {
+		next, err := operand()
+		if err != nil {
+			return AccessStructure{}, err
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return AccessStructure{Threshold: threshold(len(children)), Children: children}, nil
+}
+
+// parseTerm parses a party, k of (p1, p2, ...), or a policy in parentheses.
+func (p *policyParser) parseTerm() (AccessStructure, error) {
+	if p.keyword("(") {
+		structure, err := p.parseOr()
+		if err != nil || !p.keyword(")") {
+			return AccessStructure{}, ErrorInvalidPolicy
+		}
+		return structure, nil
+	}
+	token := p.peek()
+	if !validPartyName(token) {
+		return AccessStructure{}, ErrorInvalidPolicy
+	}
+	p.position++
+	if threshold, err := strconv.Atoi(token); err == nil && p.keyword("of") {
+		if !p.keyword("(") {
+			return AccessStructure{}, ErrorInvalidPolicy
+		}
+		var children []AccessStructure
+		for {
+			child, err := p.parseOr()
+			if err != nil {
+				return AccessStructure{}, err
+			}
+			children = append(children, child)
+			if p.keyword(")") {
+				break
+			}
+			if !p.keyword(",") {
+				return AccessStructure{}, ErrorInvalidPolicy
+			}
+		}
+		structure := AccessStructure{Threshold: threshold, Children: children}
+		if err := structure.validate(); err != nil {
+			return AccessStructure{}, err
+		}
+		return structure, nil
+	}
+	return AccessStructure{Party: token}, nil
+}
+
+// validPartyName reports whether name is a valid party name, which excludes the keywords.
+func validPartyName(name string) bool {
+	if name == "" || strings.EqualFold(name, "and") || strings.EqualFold(name, "or") || strings.EqualFold(name, "of") {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_-.@:", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePolicy(t *testing.T) {
+	assert := assert.New(t)
+	structure, err := ParsePolicy("2 of (A,B,C) and 1 of (D, E)")
+	assert.NoError(err)
+	assert.Equal("2 of (2 of (A, B, C), 1 of (D, E))", structure.String())
+	assert.Equal([]string{"A", "B", "C", "D", "E"}, structure.Parties())
+
+	structure, err = ParsePolicy("alice@example.com OR bob and (carol or 2 of (dave, erin, frank))")
+	assert.NoError(err)
+	assert.Equal("1 of (alice@example.com, 2 of (bob, 1 of (carol, 2 of (dave, erin, frank))))", structure.String())
+	reparsed, err := ParsePolicy(structure.String())
+	assert.NoError(err)
+	assert.Equal(structure, reparsed)
+
+	structure, err = ParsePolicy("  (A) ")
+	assert.NoError(err)
+	assert.Equal(AccessStructure{Party: "A"}, structure)
+
+	for _, policy := range []string{
+		"", "A B", "A and", "or A", "2 of A", "2 of (A)", "0 of (A, B)", "2 of (A, B", "2 of (A,, B)", "(A", "A)", "of", "A and of", "A$",
+	} {
+		_, err = ParsePolicy(policy)
+		assert.Equal(ErrorInvalidPolicy, err, policy)
+	}
+}
+
+func TestCanReconstruct(t *testing.T) {
+	assert := assert.New(t)
+	structure, err := ParsePolicy("2 of (A, B, C) and 1 of (D, E)")
+	assert.NoError(err)
+	assert.True(structure.CanReconstruct([]string{"A", "C", "E"}))
+	assert.True(structure.CanReconstruct([]string{"A", "B", "C", "D", "E"}))
+	assert.False(structure.CanReconstruct([]string{"A", "B", "C"}))
+	assert.False(structure.CanReconstruct([]string{"A", "D", "E"}))
+	assert.False(structure.CanReconstruct(nil))
+}
+
+func TestShareAccessStructure(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	structure, err := ParsePolicy("2 of (A, B, C) and 1 of (D, E) or F")
+	assert.NoError(err)
+	shares, err := ShareAccessStructure(big.NewInt(42), fieldSize, structure)
+	assert.NoError(err)
+	assert.Len(shares, 6)
+
+	of := func(parties ...string) []AccessShare {
+		var selected []AccessShare
+		for _, share := range shares {
+			for _, party := range parties {
+				if share.Party == party {
+					selected = append(selected, share)
+				}
+			}
+		}
+		return selected
+	}
+	for _, parties := range [][]string{{"A", "C", "E"}, {"B", "C", "D", "E"}, {"F"}, {"A", "F"}} {
+		assert.True(structure.CanReconstruct(parties))
+		secret, err := CombineAccessStructure(structure, of(parties...))
+		assert.NoError(err)
+		assert.Equal(int64(42), secret.Int64())
+	}
+	for _, parties := range [][]string{{"A", "B"}, {"A", "D", "E"}} {
+		assert.False(structure.CanReconstruct(parties))
+		_, err = CombineAccessStructure(structure, of(parties...))
+		assert.Equal(ErrorTooFewShares, err)
+	}
+	_, err = CombineAccessStructure(structure, nil)
+	assert.Equal(ErrorNoShares, err)
+
+	// A single party holds the secret itself
+	shares, err = ShareAccessStructure(big.NewInt(42), fieldSize, AccessStructure{Party: "A"})
+	assert.NoError(err)
+	secret, err := CombineAccessStructure(AccessStructure{Party: "A"}, shares)
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+
+	_, err = ShareAccessStructure(big.NewInt(42), fieldSize, AccessStructure{Threshold: 3, Children: []AccessStructure{{Party: "A"}}})
+	assert.Equal(ErrorInvalidPolicy, err)
+}
@@ -0,0 +1,245 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrorDuplicateContribution = errors.New("A contribution was already received from this client")
+	ErrorMissingContribution   = errors.New("No contribution was received from this client")
+	ErrorInvalidParameters     = errors.New("Aggregation parameters are inconsistent")
+	ErrorParameterMismatch     = errors.New("Aggregation parameters differ between participants")
+	ErrorTooManyClients        = errors.New("More clients than the aggregation parameters allow")
+	ErrorDuplicateClient       = errors.New("A client is listed more than once")
+)
+
+// AggregationParameters are the public parameters of an aggregation committee, which all servers
+// and clients must agree on: the field size and degree of the sharing, the number of servers, and
+// the largest input and number of clients, which together guarantee that the sum does not wrap
+// around modulo the field size.
+type AggregationParameters struct {
+	FieldSize  *big.Int
+	Degree     int
+	NServers   int
+	MaxInput   *big.Int
+	MaxClients int
+}
+
+// ChooseAggregationParameters returns parameters for a committee of nServers servers with the given
+// degree, using the smallest registered field (see RegisterField) in which the sum of maxClients
+// inputs of at most maxInput does not wrap around.
+func ChooseAggregationParameters(maxInput *big.Int, maxClients int, nServers int, degree int) (AggregationParameters, error) {
+	params := AggregationParameters{Degree: degree, NServers: nServers, MaxInput: maxInput, MaxClients: maxClients}
+	for _, fieldSize := range registeredFields() {
+		params.FieldSize = fieldSize
+		if params.Validate() == nil {
+			return params, nil
+		}
+	}
+	return AggregationParameters{}, params.Validate()
+}
+
+// Validate checks that the parameters are consistent. It returns ErrorFieldTooSmall if the sum of
+// the inputs may wrap around, or there are too many servers for the field.
+func (p AggregationParameters) Validate() error {
+	if p.FieldSize == nil || p.MaxInput == nil || p.MaxInput.Sign() < 0 || p.MaxClients < 1 || p.Degree < 0 || p.NServers <= p.Degree {
+		return ErrorInvalidParameters
+	}
+	maxSum := big.NewInt(0).Mul(p.MaxInput, big.NewInt(int64(p.MaxClients)))
+	if p.FieldSize.Cmp(maxSum) <= 0 || p.FieldSize.Cmp(big.NewInt(int64(p.NServers))) <= 0 {
+		return ErrorFieldTooSmall
+	}
+	return nil
+}
+
+// Digest returns a SHA-256 hash of the parameters, which participants can compare to check that
+// they agree.
+func (p AggregationParameters) Digest() []byte {
+	h := sha256.New()
+	writeInt(h, p.FieldSize)
+	writeUint64(h, uint64(p.Degree))
+	writeUint64(h, uint64(p.NServers))
+	writeInt(h, p.MaxInput)
+	writeUint64(h, uint64(p.MaxClients))
+	return h.Sum(nil)
+}
+
+// Share validates the parameters and shares the input of a client, which must lie in
+// [0, MaxInput]. Share i is to be sent to the server with X coordinate i+1. Note that servers cannot
+// check the range of the inputs, so clients that deviate from the protocol can distort the sum.
+func (p AggregationParameters) Share(input *big.Int) ([]Share, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if input.Sign() < 0 || input.Cmp(p.MaxInput) > 0 {
+		return nil, ErrorSecretOutOfRange
+	}
+	return ShareFiniteField(input, p.FieldSize, p.Degree, p.NServers), nil
+}
+
+// AgreeParameters returns ErrorParameterMismatch unless all given parameters, as published by the
+// servers and used by the clients, are equal.
+func AgreeParameters(params ...AggregationParameters) error {
+	for _, p := range params {
+		if !bytes.Equal(p.Digest(), params[0].Digest()) {
+			return ErrorParameterMismatch
+		}
+	}
+	return nil
+}
+
+// An Aggregator is a server in a committee that computes the sum of the inputs of many clients,
+// without learning anything else about them. Every client shares its input among the committee
+// using ShareFiniteField with the committee's field size and degree, and sends share i to the
+// server with X coordinate i. Once the clients are done, the servers agree on the set of clients
+// to include using AgreeClients, and every server publishes its PartialSum. AggregateSum recovers
+// the sum from any degree+1 partial sums, so servers may drop out as long as enough remain.
+//
+// Clients that drop out while sending their shares are excluded from the sum by AgreeClients.
+// Note that the servers must not collude: degree+1 servers together can recover every input.
+//
+// An Aggregator is safe for concurrent use, so a server can receive shares from many clients at
+// the same time.
+type Aggregator struct {
+	x             int
+	fieldSize     *big.Int
+	degree        int
+	maxClients    int
+	mutex         sync.Mutex
+	contributions map[string]Share
+}
+
+// NewAggregator returns the Aggregator for the server with X coordinate x in a committee that uses
+// the given field size and sharing degree.
+func NewAggregator(x int, fieldSize *big.Int, degree int) *Aggregator {
+	return &Aggregator{
+		x:             x,
+		fieldSize:     fieldSize,
+		degree:        degree,
+		contributions: make(map[string]Share),
+	}
+}
+
+// NewAggregatorWithParameters returns the Aggregator for the server with X coordinate x in a
+// committee with the given parameters, after validating them. Its PartialSum returns
+// ErrorTooManyClients for more than MaxClients clients.
+func NewAggregatorWithParameters(x int, params AggregationParameters) (*Aggregator, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if x < 1 || x > params.NServers {
+		return nil, ErrorInvalidParameters
+	}
+	a := NewAggregator(x, params.FieldSize, params.Degree)
+	a.maxClients = params.MaxClients
+	return a, nil
+}
+
+// Receive stores the share of the input of a client. It returns an error if the share does not
+// belong to this server, or if the client already contributed.
+func (a *Aggregator) Receive(client string, share Share) error {
+	if !equalOrBothNil(a.fieldSize, share.FieldSize) || a.degree != share.Degree || a.x != share.X {
+		return ErrorIncompatibleShares
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if _, ok := a.contributions[client]; ok {
+		return ErrorDuplicateContribution
+	}
+	a.contributions[client] = share
+	return nil
+}
+
+// Clients returns the sorted identifiers of the clients that this server received a share from.
+func (a *Aggregator) Clients() []string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	clients := make([]string, 0, len(a.contributions))
+	for client := range a.contributions {
+		clients = append(clients, client)
+	}
+	sort.Strings(clients)
+	return clients
+}
+
+// PartialSum returns this server's share of the sum of the inputs of the given clients. All servers
+// must use the same set of clients, see AgreeClients. It returns ErrorDuplicateClient if a client is
+// listed more than once, rather than counting its input twice.
+func (a *Aggregator) PartialSum(clients []string) (Share, error) {
+	if a.maxClients > 0 && len(clients) > a.maxClients {
+		return Share{}, ErrorTooManyClients
+	}
+	sum := Share{
+		FieldSize: a.fieldSize,
+		Degree:    a.degree,
+		X:         a.x,
+		Y:         big.NewInt(0),
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	included := make(map[string]bool, len(clients))
+	for _, client := range clients {
+		if included[client] {
+			return Share{}, ErrorDuplicateClient
+		}
+		included[client] = true
+		share, ok := a.contributions[client]
+		if !ok {
+			return Share{}, ErrorMissingContribution
+		}
+		sum.Y.Add(sum.Y, share.Y)
+	}
+	sum.Y.Mod(sum.Y, a.fieldSize)
+	return sum, nil
+}
+
+// AgreeClients returns the sorted identifiers of the clients that appear in all given client lists,
+// as returned by Aggregator.Clients of the participating servers. A client listed more than once in
+// the same list is counted once.
+func AgreeClients(clientLists ...[]string) []string {
+	if len(clientLists) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, clients := range clientLists {
+		listed := make(map[string]bool, len(clients))
+		for _, client := range clients {
+			if !listed[client] {
+				listed[client] = true
+				counts[client]++
+			}
+		}
+	}
+	agreed := make([]string, 0, len(counts))
+	for client, count := range counts {
+		if count == len(clientLists) {
+			agreed = append(agreed, client)
+		}
+	}
+	sort.Strings(agreed)
+	return agreed
+}
+
+// AggregateSum recovers the sum of the client inputs from the partial sums of the servers.
+func AggregateSum(partialSums []Share) (*big.Int, error) {
+	return ShareCombine(partialSums)
+}
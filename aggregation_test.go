@@ -0,0 +1,168 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecureAggregation(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	servers := make([]*Aggregator, 5)
+	for i := range servers {
+		servers[i] = NewAggregator(i+1, fieldSize, 2)
+	}
+
+	for client := 0; client < 10; client++ {
+		shares := ShareFiniteField(big.NewInt(int64(client)), fieldSize, 2, len(servers))
+		for i := range servers {
+			// Client 9 drops out after sending its share to the first two servers
+			if client == 9 && i >= 2 {
+				break
+			}
+			assert.NoError(servers[i].Receive(fmt.Sprint(client), shares[i]))
+		}
+	}
+	assert.Equal(ErrorDuplicateContribution, servers[0].Receive("0", ShareFiniteField(big.NewInt(1), fieldSize, 2, 5)[0]))
+	assert.Equal(ErrorIncompatibleShares, servers[0].Receive("10", ShareFiniteField(big.NewInt(1), fieldSize, 2, 5)[1]))
+
+	// Server 5 drops out, the others agree on the clients to include
+	clientLists := make([][]string, 4)
+	for i := range clientLists {
+		clientLists[i] = servers[i].Clients()
+	}
+	clients := AgreeClients(clientLists...)
+	assert.Len(clients, 9)
+
+	_, err := servers[0].PartialSum(append(clients, "9"))
+	assert.NoError(err)
+	_, err = servers[3].PartialSum(append(clients, "9"))
+	assert.Equal(ErrorMissingContribution, err)
+
+	// Clients are counted once
+	_, err = servers[0].PartialSum([]string{"1", "1"})
+	assert.Equal(ErrorDuplicateClient, err)
+	assert.Equal([]string{"1"}, AgreeClients([]string{"1", "1"}, []string{"1", "2"}))
+
+	partialSums := make([]Share, 3)
+	for i := range partialSums {
+		partialSums[i], err = servers[i+1].PartialSum(clients)
+		assert.NoError(err)
+	}
+	sum, err := AggregateSum(partialSums)
+	assert.NoError(err)
+	if assert.NotNil(sum) {
+		assert.Equal(int64(36), sum.Int64())
+	}
+}
+
+func TestAggregatorConcurrency(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	aggregators := []*Aggregator{NewAggregator(1, fieldSize, 1), NewAggregator(2, fieldSize, 1)}
+
+	var wg sync.WaitGroup
+	for c := 0; c < 50; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			shares := ShareFiniteField(big.NewInt(int64(c)), fieldSize, 1, 2)
+			for i, aggregator := range aggregators {
+				assert.NoError(aggregator.Receive(fmt.Sprint("client ", c), shares[i]))
+				aggregator.Clients()
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	clients := AgreeClients(aggregators[0].Clients(), aggregators[1].Clients())
+	assert.Len(clients, 50)
+	partialSums := make([]Share, len(aggregators))
+	for i, aggregator := range aggregators {
+		var err error
+		partialSums[i], err = aggregator.PartialSum(clients)
+		assert.NoError(err)
+	}
+	sum, err := AggregateSum(partialSums)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(49*50/2), sum)
+}
+
+func TestAggregationParameters(t *testing.T) {
+	assert := assert.New(t)
+	// Sums of up to 2^30 inputs of 40 bits need more than 61 bits
+	params, err := ChooseAggregationParameters(big.NewInt(1<<40), 1<<30, 3, 1)
+	assert.NoError(err)
+	assert.Equal(Fast80().FieldSize, params.FieldSize)
+	params, err = ChooseAggregationParameters(big.NewInt(100), 1000, 3, 1)
+	assert.NoError(err)
+	assert.Equal("m61", FieldID(params.FieldSize))
+	_, err = ChooseAggregationParameters(big.NewInt(0).Lsh(big.NewInt(1), 300), 2, 3, 1)
+	assert.Equal(ErrorFieldTooSmall, err)
+	_, err = ChooseAggregationParameters(big.NewInt(100), 10, 3, 3)
+	assert.Equal(ErrorInvalidParameters, err)
+
+	// All servers and clients agree on the parameters
+	servers := make([]*Aggregator, params.NServers)
+	for i := range servers {
+		servers[i], err = NewAggregatorWithParameters(i+1, params)
+		assert.NoError(err)
+	}
+	other := params
+	other.MaxClients++
+	assert.NoError(AgreeParameters(params, params, params))
+	assert.Equal(ErrorParameterMismatch, AgreeParameters(params, other))
+	assert.NoError(AgreeParameters())
+
+	for client, input := range []int64{100, 0, 42} {
+		shares, err := params.Share(big.NewInt(input))
+		assert.NoError(err)
+		for i := range servers {
+			assert.NoError(servers[i].Receive(fmt.Sprint(client), shares[i]))
+		}
+	}
+	_, err = params.Share(big.NewInt(101))
+	assert.Equal(ErrorSecretOutOfRange, err)
+	_, err = params.Share(big.NewInt(-1))
+	assert.Equal(ErrorSecretOutOfRange, err)
+
+	clients := AgreeClients(servers[0].Clients(), servers[2].Clients())
+	partialSums := make([]Share, 2)
+	for i := range partialSums {
+		partialSums[i], err = servers[2*i].PartialSum(clients)
+		assert.NoError(err)
+	}
+	sum, err := AggregateSum(partialSums)
+	assert.NoError(err)
+	assert.Equal(int64(142), sum.Int64())
+
+	small := params
+	small.MaxClients = 2
+	server, err := NewAggregatorWithParameters(1, small)
+	assert.NoError(err)
+	_, err = server.PartialSum(clients)
+	assert.Equal(ErrorTooManyClients, err)
+	_, err = NewAggregatorWithParameters(4, params)
+	assert.Equal(ErrorInvalidParameters, err)
+	small.FieldSize = big.NewInt(101)
+	_, err = NewAggregatorWithParameters(1, small)
+	assert.Equal(ErrorFieldTooSmall, err)
+}
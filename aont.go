@@ -0,0 +1,82 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+var (
+	ErrorInvalidPayload = errors.New("Payload is malformed")
+)
+
+// aontKeySize is the size in bytes of the random key of the all-or-nothing transform.
+const aontKeySize = 32
+
+// AONTTransform applies an all-or-nothing transform to payload, following Rivest's package
+// transform: the payload is encrypted with AES-256-CTR under a fresh random key, and the key,
+// masked with the SHA-256 hash of the ciphertext, is appended. The result is 32 bytes longer than
+// the payload. Without every single byte of the result, the key and therefore any part of the
+// payload cannot be recovered, so if the result is shared in chunks, compromising the quorums of
+// some chunks reveals nothing.
+func AONTTransform(payload []byte) ([]byte, error) {
+	key := make([]byte, aontKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	transformed := make([]byte, len(payload)+aontKeySize)
+	if err := aontStream(key, transformed[:len(payload)], payload); err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(transformed[:len(payload)])
+	for i := range key {
+		transformed[len(payload)+i] = key[i] ^ digest[i]
+	}
+	return transformed, nil
+}
+
+// AONTInverse inverts AONTTransform. It cannot detect modifications of the transformed payload;
+// combine it with an integrity check if that is needed.
+func AONTInverse(transformed []byte) ([]byte, error) {
+	if len(transformed) < aontKeySize {
+		return nil, ErrorInvalidPayload
+	}
+	length := len(transformed) - aontKeySize
+	digest := sha256.Sum256(transformed[:length])
+	key := make([]byte, aontKeySize)
+	for i := range key {
+		key[i] = transformed[length+i] ^ digest[i]
+	}
+	payload := make([]byte, length)
+	if err := aontStream(key, payload, transformed[:length]); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// aontStream XORs src with the AES-256-CTR keystream for key and a zero IV into dst. Every key is
+// used only once.
+func aontStream(key []byte, dst []byte, src []byte) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(dst, src)
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAONT(t *testing.T) {
+	assert := assert.New(t)
+	for _, payload := range [][]byte{{}, []byte("a"), bytes.Repeat([]byte("all or nothing "), 100)} {
+		transformed, err := AONTTransform(payload)
+		assert.NoError(err)
+		assert.Len(transformed, len(payload)+32)
+
+		recovered, err := AONTInverse(transformed)
+		assert.NoError(err)
+		assert.Equal(payload, recovered)
+
+		// Transforming is randomized
+		again, err := AONTTransform(payload)
+		assert.NoError(err)
+		assert.NotEqual(transformed, again)
+	}
+
+	// Changing a single byte of the transformed payload garbles all of the payload
+	payload := bytes.Repeat([]byte{0}, 64)
+	transformed, err := AONTTransform(payload)
+	assert.NoError(err)
+	transformed[63] ^= 1
+	recovered, err := AONTInverse(transformed)
+	assert.NoError(err)
+	assert.NotEqual(payload[:32], recovered[:32])
+
+	_, err = AONTInverse(make([]byte, 31))
+	assert.Equal(ErrorInvalidPayload, err)
+}
@@ -0,0 +1,362 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+// A FieldElement is an element of a Field. Implementations are expected to be immutable and may
+// panic when combined with elements of another field.
+type FieldElement interface {
+	// Add, Sub and Mul return the sum, difference and product of the element and other.
+	Add(other FieldElement) FieldElement
+	Sub(other FieldElement) FieldElement
+	Mul(other FieldElement) FieldElement
+	// Inverse returns the multiplicative inverse of the element, or ErrorNotInvertible for zero.
+	Inverse() (FieldElement, error)
+	// Equal reports whether the element equals other.
+	Equal(other FieldElement) bool
+	// Bytes returns a canonical encoding of the element.
+	Bytes() []byte
+}
+
+// A Field is a finite field with arithmetic supplied by its implementation, such as the scalar field
+// of a curve that this package does not support, or hardware-accelerated arithmetic. ShareOver,
+// CombineOver and the operations on FieldShares work over any Field. PrimeField implements the
+// fields of Share, and GF256Field that of GF256Share.
+type Field interface {
+	// Zero and One return the neutral elements of addition and multiplication.
+	Zero() FieldElement
+	One() FieldElement
+	// Point returns the element at X coordinate x, or ErrorInvalidX if the field has no element
+	// for it. Different X coordinates must give different elements, and no X coordinate may give
+	// zero.
+	Point(x int) (FieldElement, error)
+	// Random returns a uniformly random element, reading randomness from random.
+	Random(random io.Reader) (FieldElement, error)
+}
+
+// A FieldShare is a share over a Field: Y is the value at the point of X of the polynomial of the
+// given degree whose constant term is the secret.
+type FieldShare struct {
+	Field  Field
+	Degree int
+	X      int
+	Y      FieldElement
+}
+
+// ShareOver shares a secret over field like ShareFiniteField, with nShares shares of the given
+// degree at X coordinates 1 to nShares. The randomness of the coefficients is read from random.
+func ShareOver(field Field, secret FieldElement, degree int, nShares int, random io.Reader) ([]FieldShare, error) {
+	if degree < 0 || degree >= nShares {
+		return nil, ErrorTooFewShares
+	}
+	coefficients := make([]FieldElement, degree)
+	for i := range coefficients {
+		var err error
+		if coefficients[i], err = field.Random(random); err != nil {
+			return nil, err
+		}
+	}
+	shares := make([]FieldShare, nShares)
+	for i := range shares {
+		x, err := field.Point(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		// Horner's rule
+		y := field.Zero()
+		for j := degree - 1; j >= 0; j-- {
+			y = y.Add(coefficients[j]).Mul(x)
+		}
+		shares[i] = FieldShare{Field: field, Degree: degree, X: i + 1, Y: y.Add(secret)}
+	}
+	return shares, nil
+}
+
+// CombineOver recovers the secret from degree+1 or more shares over the same field, like
+// ShareCombine. Like ShareCombine, it uses the first degree+1 shares.
+func CombineOver(shares []FieldShare) (FieldElement, error) {
+	if err := checkFieldShares(shares); err != nil {
+		return nil, err
+	}
+	if len(shares) <= shares[0].Degree {
+		return nil, ErrorTooFewShares
+	}
+	shares = shares[:shares[0].Degree+1]
+	field := shares[0].Field
+	points := make([]FieldElement, len(shares))
+	for i := range shares {
+		for _, other := range shares[:i] {
+			if shares[i].X == other.X {
+				return nil, ErrorDuplicateX
+			}
+		}
+		var err error
+		if points[i], err = field.Point(shares[i].X); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sum the numerators over the product of all denominators, so that a single inversion is needed
+	secret, denominator := field.Zero(), field.One()
+	for i := range shares {
+		numerator, difference := shares[i].Y, field.One()
+		for j := range shares {
+			if i != j {
+				numerator = numerator.Mul(points[j])
+				difference = difference.Mul(points[j].Sub(points[i]))
+			}
+		}
+		secret = secret.Mul(difference).Add(numerator.Mul(denominator))
+		denominator = denominator.Mul(difference)
+	}
+	inverse, err := denominator.Inverse()
+	if err != nil {
+		return nil, err
+	}
+	return secret.Mul(inverse), nil
+}
+
+// FieldShareAdd adds shares of secrets over the same field with equal X coordinates and degrees,
+// like ShareAdd.
+func FieldShareAdd(shares []FieldShare) (FieldShare, error) {
+	if err := checkFieldShares(shares); err != nil {
+		return FieldShare{}, err
+	}
+	sum := shares[0]
+	for _, share := range shares[1:] {
+		if share.X != sum.X || share.Degree != sum.Degree {
+			return FieldShare{}, ErrorIncompatibleShares
+		}
+		sum.Y = sum.Y.Add(share.Y)
+	}
+	return sum, nil
+}
+
+// FieldShareMul multiplies shares of secrets over the same field with equal X coordinates, like
+// ShareMul. The degree of the result is the sum of the degrees.
+func FieldShareMul(shares []FieldShare) (FieldShare, error) {
+	if err := checkFieldShares(shares); err != nil {
+		return FieldShare{}, err
+	}
+	product := shares[0]
+	for _, share := range shares[1:] {
+		if share.X != product.X {
+			return FieldShare{}, ErrorIncompatibleShares
+		}
+		product.Y = product.Y.Mul(share.Y)
+		product.Degree += share.Degree
+	}
+	return product, nil
+}
+
+// FieldShareAddConstant adds a public constant to the shared secret, like ShareAddConstant.
+func FieldShareAddConstant(share FieldShare, constant FieldElement) FieldShare {
+	share.Y = share.Y.Add(constant)
+	return share
+}
+
+// FieldShareMulConstant multiplies the shared secret by a public constant, like ShareMulConstant.
+func FieldShareMulConstant(share FieldShare, constant FieldElement) FieldShare {
+	share.Y = share.Y.Mul(constant)
+	return share
+}
+
+// checkFieldShares checks that shares is not empty, and that its shares are well-formed and over the
+// same field.
+func checkFieldShares(shares []FieldShare) error {
+	if len(shares) == 0 {
+		return ErrorNoShares
+	}
+	for _, share := range shares {
+		if share.Y == nil || share.Field == nil || share.Degree < 0 {
+			return ErrorInvalidShare
+		}
+		if share.Field != shares[0].Field {
+			return ErrorIncompatibleShares
+		}
+	}
+	return nil
+}
+
+// A PrimeField is the field of integers modulo a prime, the field of the shares of ShareFiniteField.
+// Use ToFieldShare and FromFieldShare to convert between Share and FieldShare.
+type PrimeField struct {
+	Modulus *big.Int
+}
+
+// Element returns the element of the field with the given value modulo the modulus.
+func (f *PrimeField) Element(value *big.Int) PrimeElement {
+	return PrimeElement{Field: f, Value: big.NewInt(0).Mod(value, f.Modulus)}
+}
+
+// Zero returns zero.
+func (f *PrimeField) Zero() FieldElement {
+	return f.Element(big.NewInt(0))
+}
+
+// One returns one.
+func (f *PrimeField) One() FieldElement {
+	return f.Element(big.NewInt(1))
+}
+
+// Point returns x modulo the modulus, for X coordinates from 1 to the modulus minus one.
+func (f *PrimeField) Point(x int) (FieldElement, error) {
+	if x < 1 || big.NewInt(int64(x)).Cmp(f.Modulus) >= 0 {
+		return nil, ErrorInvalidX
+	}
+	return f.Element(big.NewInt(int64(x))), nil
+}
+
+// Random returns a uniformly random element.
+func (f *PrimeField) Random(random io.Reader) (FieldElement, error) {
+	value, err := rand.Int(random, f.Modulus)
+	if err != nil {
+		return nil, err
+	}
+	return PrimeElement{Field: f, Value: value}, nil
+}
+
+// A PrimeElement is an element of a PrimeField.
+type PrimeElement struct {
+	Field *PrimeField
+	Value *big.Int
+}
+
+// Add returns the sum of the element and other.
+func (e PrimeElement) Add(other FieldElement) FieldElement {
+	return e.Field.Element(big.NewInt(0).Add(e.Value, other.(PrimeElement).Value))
+}
+
+// Sub returns the difference of the element and other.
+func (e PrimeElement) Sub(other FieldElement) FieldElement {
+	return e.Field.Element(big.NewInt(0).Sub(e.Value, other.(PrimeElement).Value))
+}
+
+// Mul returns the product of the element and other.
+func (e PrimeElement) Mul(other FieldElement) FieldElement {
+	return e.Field.Element(big.NewInt(0).Mul(e.Value, other.(PrimeElement).Value))
+}
+
+// Inverse returns the inverse of the element modulo the modulus.
+func (e PrimeElement) Inverse() (FieldElement, error) {
+	inverse := big.NewInt(0).ModInverse(e.Value, e.Field.Modulus)
+	if inverse == nil {
+		return nil, ErrorNotInvertible
+	}
+	return PrimeElement{Field: e.Field, Value: inverse}, nil
+}
+
+// Equal reports whether the element equals other.
+func (e PrimeElement) Equal(other FieldElement) bool {
+	o, ok := other.(PrimeElement)
+	return ok && e.Value.Cmp(o.Value) == 0 && e.Field.Modulus.Cmp(o.Field.Modulus) == 0
+}
+
+// Bytes returns the value, padded to the byte length of the modulus.
+func (e PrimeElement) Bytes() []byte {
+	return fieldElementBytes(e.Value, e.Field.Modulus)
+}
+
+// ToFieldShare converts a share over a finite field to a FieldShare over field, whose modulus must
+// be the field size of the share.
+func ToFieldShare(share Share, field *PrimeField) (FieldShare, error) {
+	if share.FieldSize == nil || share.Y == nil || share.FieldSize.Cmp(field.Modulus) != 0 {
+		return FieldShare{}, ErrorWrongShareType
+	}
+	return FieldShare{Field: field, Degree: share.Degree, X: share.X, Y: field.Element(share.Y)}, nil
+}
+
+// FromFieldShare converts a FieldShare over a PrimeField back to a Share.
+func FromFieldShare(share FieldShare) (Share, error) {
+	field, ok := share.Field.(*PrimeField)
+	y, isPrime := share.Y.(PrimeElement)
+	if !ok || !isPrime {
+		return Share{}, ErrorWrongShareType
+	}
+	return Share{FieldSize: field.Modulus, Degree: share.Degree, X: share.X, Y: y.Value}, nil
+}
+
+// GF256Field is GF(2^8) with the AES reduction polynomial, the field of GF256Share. Its elements
+// are GF256Elements, and the X coordinates 1 to 255 are the bytes with these values.
+type GF256Field struct{}
+
+// Zero returns zero.
+func (GF256Field) Zero() FieldElement {
+	return GF256Element(0)
+}
+
+// One returns one.
+func (GF256Field) One() FieldElement {
+	return GF256Element(1)
+}
+
+// Point returns the byte x, for X coordinates from 1 to 255.
+func (GF256Field) Point(x int) (FieldElement, error) {
+	if x < 1 || x > 255 {
+		return nil, ErrorInvalidX
+	}
+	return GF256Element(x), nil
+}
+
+// Random returns a uniformly random element.
+func (GF256Field) Random(random io.Reader) (FieldElement, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(random, b[:]); err != nil {
+		return nil, err
+	}
+	return GF256Element(b[0]), nil
+}
+
+// A GF256Element is an element of GF256Field.
+type GF256Element byte
+
+// Add returns the sum of the element and other, their XOR.
+func (e GF256Element) Add(other FieldElement) FieldElement {
+	return e ^ other.(GF256Element)
+}
+
+// Sub returns the difference of the element and other, which equals their sum.
+func (e GF256Element) Sub(other FieldElement) FieldElement {
+	return e ^ other.(GF256Element)
+}
+
+// Mul returns the product of the element and other.
+func (e GF256Element) Mul(other FieldElement) FieldElement {
+	return GF256Element(gf256Mul(byte(e), byte(other.(GF256Element))))
+}
+
+// Inverse returns the inverse of the element.
+func (e GF256Element) Inverse() (FieldElement, error) {
+	if e == 0 {
+		return nil, ErrorNotInvertible
+	}
+	return GF256Element(gf256Div(1, byte(e))), nil
+}
+
+// Equal reports whether the element equals other.
+func (e GF256Element) Equal(other FieldElement) bool {
+	o, ok := other.(GF256Element)
+	return ok && e == o
+}
+
+// Bytes returns the element as a single byte.
+func (e GF256Element) Bytes() []byte {
+	return []byte{byte(e)}
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrimeField(t *testing.T) {
+	assert := assert.New(t)
+	field := &PrimeField{Modulus: big.NewInt(2039)}
+	secret := field.Element(big.NewInt(1234))
+	shares, err := ShareOver(field, secret, 2, 5, rand.Reader)
+	assert.NoError(err)
+	assert.Len(shares, 5)
+
+	recovered, err := CombineOver([]FieldShare{shares[4], shares[1], shares[2]})
+	assert.NoError(err)
+	assert.True(secret.Equal(recovered))
+
+	_, err = CombineOver(shares[:2])
+	assert.Equal(ErrorTooFewShares, err)
+	_, err = CombineOver([]FieldShare{shares[0], shares[0], shares[1]})
+	assert.Equal(ErrorDuplicateX, err)
+	_, err = CombineOver(nil)
+	assert.Equal(ErrorNoShares, err)
+
+	// Shares convert to and from Share, and combine like them
+	converted := make([]Share, len(shares))
+	for i := range shares {
+		converted[i], err = FromFieldShare(shares[i])
+		assert.NoError(err)
+	}
+	combined, err := ShareCombine(converted)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(1234), combined)
+	back, err := ToFieldShare(converted[3], field)
+	assert.NoError(err)
+	assert.True(back.Y.Equal(shares[3].Y))
+	_, err = ToFieldShare(converted[3], &PrimeField{Modulus: big.NewInt(2053)})
+	assert.Equal(ErrorWrongShareType, err)
+
+	_, err = field.Point(2039)
+	assert.Equal(ErrorInvalidX, err)
+	_, err = field.Zero().Inverse()
+	assert.Equal(ErrorNotInvertible, err)
+	assert.Len(secret.Bytes(), 2)
+}
+
+func TestFieldShareArithmetic(t *testing.T) {
+	assert := assert.New(t)
+	field := &PrimeField{Modulus: big.NewInt(2039)}
+	a, _ := ShareOver(field, field.Element(big.NewInt(12)), 1, 5, rand.Reader)
+	b, _ := ShareOver(field, field.Element(big.NewInt(34)), 1, 5, rand.Reader)
+
+	sums := make([]FieldShare, 5)
+	products := make([]FieldShare, 5)
+	for i := range sums {
+		var err error
+		sums[i], err = FieldShareAdd([]FieldShare{a[i], b[i]})
+		assert.NoError(err)
+		products[i], err = FieldShareMul([]FieldShare{a[i], b[i]})
+		assert.NoError(err)
+		products[i] = FieldShareAddConstant(FieldShareMulConstant(products[i], field.Element(big.NewInt(2))), field.One())
+	}
+	sum, err := CombineOver(sums)
+	assert.NoError(err)
+	assert.True(field.Element(big.NewInt(46)).Equal(sum))
+	assert.Equal(2, products[0].Degree)
+	product, err := CombineOver(products)
+	assert.NoError(err)
+	assert.True(field.Element(big.NewInt(2*12*34 + 1)).Equal(product))
+
+	_, err = FieldShareAdd([]FieldShare{a[0], b[1]})
+	assert.Equal(ErrorIncompatibleShares, err)
+	other := &PrimeField{Modulus: big.NewInt(2039)}
+	c, _ := ShareOver(other, other.One(), 1, 5, rand.Reader)
+	_, err = FieldShareMul([]FieldShare{a[0], c[0]})
+	assert.Equal(ErrorIncompatibleShares, err)
+}
+
+func TestGF256Field(t *testing.T) {
+	assert := assert.New(t)
+	field := GF256Field{}
+	shares, err := ShareOver(field, GF256Element(0x42), 3, 255, rand.Reader)
+	assert.NoError(err)
+	recovered, err := CombineOver([]FieldShare{shares[254], shares[17], shares[3], shares[100]})
+	assert.NoError(err)
+	assert.Equal(GF256Element(0x42), recovered)
+
+	// Sharing byte by byte agrees with CombineGF256
+	gf256Shares := make([]GF256Share, 4)
+	for i, share := range shares[:4] {
+		gf256Shares[i] = GF256Share{X: byte(share.X), Y: share.Y.Bytes()}
+	}
+	secret, err := CombineGF256(gf256Shares)
+	assert.NoError(err)
+	assert.Equal([]byte{0x42}, secret)
+
+	_, err = ShareOver(field, field.One(), 2, 256, rand.Reader)
+	assert.Equal(ErrorInvalidX, err)
+	_, err = FromFieldShare(shares[0])
+	assert.Equal(ErrorWrongShareType, err)
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+)
+
+// challengeSize is the size in bytes of the challenges returned by NewChallenge.
+const challengeSize = 32
+
+// An Attestation proves that a custodian holds a valid share without revealing it. It is a Schnorr
+// proof of knowledge of the share y at X coordinate X for the share commitment y * G, see
+// Commitments.ShareCommitment, bound to a challenge of the verifier.
+type Attestation struct {
+	X        int
+	R        GroupElement
+	Response *big.Int
+}
+
+// NewChallenge returns a fresh random challenge for a round of attestations. Operators should issue
+// a new challenge for every round, for instance every month, so that custodians cannot answer with
+// attestations they made while they still had their share.
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// Attest proves possession of a share dealt by ShareFeldman with the given generator, in response to
+// challenge.
+func Attest(share Share, generator GroupElement, challenge []byte) (Attestation, error) {
+	order := generator.Order()
+	if share.Y == nil || !equalOrBothNil(share.FieldSize, order) {
+		return Attestation{}, ErrorWrongShareType
+	}
+	k, err := rand.Int(rand.Reader, order)
+	if err != nil {
+		return Attestation{}, err
+	}
+	r := generator.ScalarMult(k)
+	e := attestationChallenge(generator, generator.ScalarMult(share.Y), share.X, r, challenge)
+	response := e.Mul(e, share.Y).Add(e, k)
+	return Attestation{X: share.X, R: r, Response: response.Mod(response, order)}, nil
+}
+
+// VerifyAttestation checks that attestation proves possession of the share at its X coordinate of
+// the polynomial committed to, in response to challenge.
+func (c Commitments) VerifyAttestation(generator GroupElement, challenge []byte, attestation Attestation) bool {
+	if len(c) == 0 || attestation.R == nil || attestation.Response == nil || attestation.X < 1 {
+		return false
+	}
+	commitment := c.ShareCommitment(attestation.X)
+	e := attestationChallenge(generator, commitment, attestation.X, attestation.R, challenge)
+	// s * G = R + e * (y * G)
+	return generator.ScalarMult(attestation.Response).Equal(attestation.R.Add(commitment.ScalarMult(e)))
+}
+
+// FailedAttestations returns the custodians, identified by the X coordinates of their shares, that
+// did not provide a valid attestation for challenge. Operators can use it to detect lost or
+// destroyed shares, and deal new ones before too few remain for a recovery.
+func (c Commitments) FailedAttestations(generator GroupElement, challenge []byte, custodians []int, attestations []Attestation) []int {
+	valid := make(map[int]bool, len(attestations))
+	for _, attestation := range attestations {
+		if c.VerifyAttestation(generator, challenge, attestation) {
+			valid[attestation.X] = true
+		}
+	}
+	var failed []int
+	for _, x := range custodians {
+		if !valid[x] {
+			failed = append(failed, x)
+		}
+	}
+	return failed
+}
+
+// attestationChallenge derives the Fiat-Shamir challenge of an attestation from the public values
+// and the challenge of the verifier.
+func attestationChallenge(generator GroupElement, commitment GroupElement, x int, r GroupElement, challenge []byte) *big.Int {
+	h := sha256.New()
+	writeBytes(h, generator.Bytes())
+	writeBytes(h, commitment.Bytes())
+	writeUint64(h, uint64(x))
+	writeBytes(h, r.Bytes())
+	writeBytes(h, challenge)
+	e := big.NewInt(0).SetBytes(h.Sum(nil))
+	return e.Mod(e, generator.Order())
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttestation(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(777), g, 2, 5)
+	challenge, err := NewChallenge()
+	assert.NoError(err)
+	assert.Len(challenge, 32)
+
+	attestations := make([]Attestation, len(shares))
+	for i, share := range shares {
+		attestations[i], err = Attest(share, g, challenge)
+		assert.NoError(err)
+		assert.True(commitments.VerifyAttestation(g, challenge, attestations[i]))
+		assert.True(g.ScalarMult(share.Y).Equal(commitments.ShareCommitment(share.X)))
+	}
+
+	// An attestation does not carry over to another challenge or share
+	other, err := NewChallenge()
+	assert.NoError(err)
+	assert.False(commitments.VerifyAttestation(g, other, attestations[0]))
+	moved := attestations[0]
+	moved.X = 2
+	assert.False(commitments.VerifyAttestation(g, challenge, moved))
+
+	// A custodian that lost its share cannot attest with a guessed one
+	lost := shares[3]
+	lost.Y = big.NewInt(12345)
+	attestations[3], err = Attest(lost, g, challenge)
+	assert.NoError(err)
+	assert.False(commitments.VerifyAttestation(g, challenge, attestations[3]))
+
+	// Custodian 5 does not respond, and custodian 2 answers the previous round
+	previous, err := Attest(shares[1], g, other)
+	assert.NoError(err)
+	attestations[1] = previous
+	failed := commitments.FailedAttestations(g, challenge, []int{1, 2, 3, 4, 5}, attestations[:4])
+	assert.Equal([]int{2, 4, 5}, failed)
+
+	_, err = Attest(ShareFiniteField(big.NewInt(1), big.NewInt(7919), 1, 2)[0], g, challenge)
+	assert.Equal(ErrorWrongShareType, err)
+	assert.False(commitments.VerifyAttestation(g, challenge, Attestation{X: 1}))
+	assert.False(Commitments{}.VerifyAttestation(g, challenge, attestations[0]))
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+)
+
+// beaconSalt is the HKDF salt used for mixing local entropy with public randomness.
+var beaconSalt = []byte("github.com/TNO-MPC/shamir beacon")
+
+// ShareFiniteFieldWithBeacon shares a secret over a finite field like ShareFiniteField, but derives
+// the coefficients from 32 bytes of local entropy mixed with the given round and randomness of a
+// public beacon, such as a drand beacon (see the beacon package), using HKDF-SHA256. The shares are
+// only as secure as the local entropy, since the beacon is public, and the local entropy is
+// discarded, so nobody can check afterwards that the beacon was used at all.
+//
+// If transcript is not nil, the beacon round and randomness, the parameters and the fingerprints
+// of all shares are appended to it, see AppendBeacon. Publishing the resulting digest binds the
+// dealing to the beacon round it claims to use.
+func ShareFiniteFieldWithBeacon(secret *big.Int, fieldSize *big.Int, degree int, nShares int, round uint64, randomness []byte, transcript *Transcript) ([]Share, error) {
+	seed, err := beaconSeed(round, randomness)
+	if err != nil {
+		return nil, err
+	}
+	shares := ShareFiniteFieldSeeded(secret, fieldSize, degree, nShares, seed)
+	appendBeaconDealing(transcript, round, randomness, shares)
+	return shares, nil
+}
+
+// ShareIntegersWithBeacon shares a secret over the integers like ShareIntegers, mixing public
+// randomness into the coefficients and recording it in the transcript like
+// ShareFiniteFieldWithBeacon.
+func ShareIntegersWithBeacon(secret *big.Int, secretUpperBound *big.Int, statSecParam int, degree int, nShares int, round uint64, randomness []byte, transcript *Transcript) ([]Share, error) {
+	seed, err := beaconSeed(round, randomness)
+	if err != nil {
+		return nil, err
+	}
+	shares := ShareIntegersSeeded(secret, secretUpperBound, statSecParam, degree, nShares, seed)
+	appendBeaconDealing(transcript, round, randomness, shares)
+	return shares, nil
+}
+
+// beaconSeed returns a seed derived from fresh local entropy and the round and randomness of the
+// public beacon.
+func beaconSeed(round uint64, randomness []byte) ([]byte, error) {
+	entropy := make([]byte, 32)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	input := bytes.Buffer{}
+	input.Write(entropy)
+	writeUint64(&input, round)
+	input.Write(randomness)
+	return hkdfExtract(beaconSalt, input.Bytes()), nil
+}
+
+// appendBeaconDealing appends the beacon round and randomness and the resulting shares to
+// transcript, unless it is nil.
+func appendBeaconDealing(transcript *Transcript, round uint64, randomness []byte, shares []Share) {
+	if transcript == nil {
+		return
+	}
+	transcript.AppendBeacon(round, randomness)
+	if len(shares) == 0 {
+		return
+	}
+	transcript.AppendParameters(shares[0].FieldSize, shares[0].Degree, len(shares))
+	for _, share := range shares {
+		transcript.AppendShareFingerprint(ShareFingerprint(share))
+	}
+}
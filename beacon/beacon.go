@@ -0,0 +1,94 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package beacon fetches public randomness from a drand beacon over its HTTP API, for mixing into
+// dealings with shamir.ShareFiniteFieldWithBeacon.
+//
+// The randomness of a drand round is the SHA-256 hash of the threshold BLS signature of the round.
+// This package checks that relation, but it does not verify the signature against the public key of
+// the drand network, which needs a BLS12-381 implementation. It therefore trusts the HTTP endpoint:
+// an endpoint, or anyone who can tamper with the connection to it, can return any signature and
+// randomness for any round. Deployments that want to prove the freshness of a dealing should record
+// the round and signature, which anyone can verify later with the drand tools.
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrorInvalidRound = errors.New("Beacon returned an invalid round")
+)
+
+// A Round is a round of a drand beacon.
+type Round struct {
+	Round      uint64 `json:"round"`
+	Randomness []byte `json:"-"`
+	Signature  []byte `json:"-"`
+}
+
+// Fetch returns a round of the drand beacon at url, such as "https://api.drand.sh" or, for a chain
+// other than the default, "https://api.drand.sh/<chain hash>". Round 0 means the latest round. A
+// nil client means http.DefaultClient. The signature of the round is not verified, so the result is
+// only as trustworthy as the endpoint, see the package documentation.
+func Fetch(ctx context.Context, client *http.Client, url string, round uint64) (Round, error) {
+	path := "/public/latest"
+	if round != 0 {
+		path = "/public/" + strconv.FormatUint(round, 10)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(url, "/")+path, nil)
+	if err != nil {
+		return Round{}, err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return Round{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return Round{}, ErrorInvalidRound
+	}
+
+	var encoded struct {
+		Round      uint64 `json:"round"`
+		Randomness string `json:"randomness"`
+		Signature  string `json:"signature"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&encoded); err != nil {
+		return Round{}, err
+	}
+	result := Round{Round: encoded.Round}
+	if result.Randomness, err = hex.DecodeString(encoded.Randomness); err != nil {
+		return Round{}, ErrorInvalidRound
+	}
+	if result.Signature, err = hex.DecodeString(encoded.Signature); err != nil {
+		return Round{}, ErrorInvalidRound
+	}
+	digest := sha256.Sum256(result.Signature)
+	if (round != 0 && result.Round != round) || len(result.Signature) == 0 || !bytes.Equal(digest[:], result.Randomness) {
+		return Round{}, ErrorInvalidRound
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beacon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetch(t *testing.T) {
+	assert := assert.New(t)
+	signature := []byte("signature of round 7")
+	randomness := sha256.Sum256(signature)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chain/public/latest", "/chain/public/7":
+			fmt.Fprintf(w, `{"round": 7, "randomness": "%x", "signature": "%x"}`, randomness, signature)
+		case "/chain/public/8":
+			fmt.Fprintf(w, `{"round": 7, "randomness": "%x", "signature": "%x"}`, randomness, signature)
+		case "/chain/public/9":
+			fmt.Fprintf(w, `{"round": 9, "randomness": "00", "signature": "%x"}`, signature)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	for _, number := range []uint64{0, 7} {
+		round, err := Fetch(context.Background(), nil, server.URL+"/chain/", number)
+		assert.NoError(err)
+		assert.Equal(uint64(7), round.Round)
+		assert.Equal(hex.EncodeToString(randomness[:]), hex.EncodeToString(round.Randomness))
+		assert.Equal(signature, round.Signature)
+	}
+	for _, number := range []uint64{8, 9, 10} {
+		_, err := Fetch(context.Background(), server.Client(), server.URL+"/chain", number)
+		assert.Equal(ErrorInvalidRound, err)
+	}
+
+	round, err := Fetch(context.Background(), nil, server.URL+"/chain", 0)
+	assert.NoError(err)
+	shares, err := shamir.ShareFiniteFieldWithBeacon(big.NewInt(42), big.NewInt(7919), 1, 3, round.Round, round.Randomness, nil)
+	assert.NoError(err)
+	secret, err := shamir.ShareCombine(shares[:2])
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+}
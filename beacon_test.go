@@ -0,0 +1,68 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareWithBeacon(t *testing.T) {
+	assert := assert.New(t)
+	beacon := []byte("randomness of a beacon round")
+	fieldSize := big.NewInt(7919)
+	shares, err := ShareFiniteFieldWithBeacon(big.NewInt(42), fieldSize, 2, 5, 7, beacon, nil)
+	assert.NoError(err)
+	secret, err := ShareCombine(shares[1:4])
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+
+	// The local entropy makes every dealing different, even for the same beacon
+	other, err := ShareFiniteFieldWithBeacon(big.NewInt(42), Conservative128().FieldSize, 2, 5, 7, beacon, nil)
+	assert.NoError(err)
+	again, err := ShareFiniteFieldWithBeacon(big.NewInt(42), Conservative128().FieldSize, 2, 5, 7, beacon, nil)
+	assert.NoError(err)
+	assert.NotEqual(other[0].Y, again[0].Y)
+
+	shares, err = ShareIntegersWithBeacon(big.NewInt(42), big.NewInt(100), 40, 1, 3, 7, beacon, nil)
+	assert.NoError(err)
+	secret, err = ShareCombine(shares[:2])
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+}
+
+func TestShareWithBeaconTranscript(t *testing.T) {
+	assert := assert.New(t)
+	beacon := []byte("randomness of a beacon round")
+	transcript := NewTranscript([]byte("session"))
+	shares, err := ShareFiniteFieldWithBeacon(big.NewInt(42), big.NewInt(7919), 2, 5, 7, beacon, transcript)
+	assert.NoError(err)
+
+	// Anyone holding the shares can recompute the digest, but only for the beacon round it used
+	expected := func(round uint64, randomness []byte) []byte {
+		check := NewTranscript([]byte("session"))
+		check.AppendBeacon(round, randomness)
+		check.AppendParameters(big.NewInt(7919), 2, 5)
+		for _, share := range shares {
+			check.AppendShareFingerprint(ShareFingerprint(share))
+		}
+		return check.Digest()
+	}
+	assert.Equal(expected(7, beacon), transcript.Digest())
+	assert.NotEqual(expected(8, beacon), transcript.Digest())
+	assert.NotEqual(expected(7, []byte("randomness of another round")), transcript.Digest())
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The binary encoding of a share, produced by Share.MarshalBinary, is a version byte followed by
+// length-prefixed fields, as in WriteBundle:
+//
+//	version | fieldSize | factor | uint64 degree | uint64 X | Y | bound | fingerprint
+//
+// Big integers are written as a sign byte and a length-prefixed big-endian magnitude, or 0xff for
+// nil, and Y values over a finite field are padded to the byte length of the field size. The
+// fingerprint is a presence byte followed by the length-prefixed bytes. The types that embed a Share
+// write their own fields between the version byte and the fields of the share, so that encoding
+// them, for instance with encoding/gob, does not drop those fields.
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+var (
+	ErrorInvalidBinary = errors.New("Binary share encoding is malformed")
+)
+
+// shareBinaryVersion is the version of the binary encoding of shares.
+const shareBinaryVersion = 1
+
+// maxBinaryLength bounds the lengths of the strings and slices read by UnmarshalBinary.
+const maxBinaryLength = 1 << 16
+
+// MarshalBinary implements encoding.BinaryMarshaler with a compact, versioned encoding of all fields
+// of the share. Unlike AppendCompact, shares over the same finite field have encodings of the same
+// length, which does not reveal the magnitude of Y.
+func (s Share) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{shareBinaryVersion})
+	writeShareBinary(buf, s)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It returns ErrorInvalidBinary if data is
+// not an encoding produced by MarshalBinary.
+func (s *Share) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, func(r *bytes.Reader) error {
+		share, err := readShareBinary(r)
+		*s = share
+		return err
+	})
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, see Share.MarshalBinary.
+func (s NestedShare) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{shareBinaryVersion})
+	writeUint64(buf, uint64(len(s.Parents)))
+	for _, parent := range s.Parents {
+		writeUint64(buf, uint64(parent.X))
+		writeUint64(buf, uint64(parent.Degree))
+	}
+	writeShareBinary(buf, s.Share)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, see Share.UnmarshalBinary.
+func (s *NestedShare) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, func(r *bytes.Reader) error {
+		count, err := readBinaryLength(r)
+		if err != nil {
+			return err
+		}
+		var nested NestedShare
+		if count > 0 {
+			nested.Parents = make([]ParentShare, count)
+		}
+		for i := range nested.Parents {
+			if nested.Parents[i].X, err = readBinaryInt(r); err != nil {
+				return err
+			}
+			if nested.Parents[i].Degree, err = readBinaryInt(r); err != nil {
+				return err
+			}
+		}
+		nested.Share, err = readShareBinary(r)
+		*s = nested
+		return err
+	})
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, see Share.MarshalBinary.
+func (s GroupShare) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{shareBinaryVersion})
+	writeUint64(buf, uint64(s.Group))
+	writeUint64(buf, uint64(s.GroupDegree))
+	writeShareBinary(buf, s.Share)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, see Share.UnmarshalBinary.
+func (s *GroupShare) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, func(r *bytes.Reader) error {
+		var share GroupShare
+		var err error
+		if share.Group, err = readBinaryInt(r); err != nil {
+			return err
+		}
+		if share.GroupDegree, err = readBinaryInt(r); err != nil {
+			return err
+		}
+		share.Share, err = readShareBinary(r)
+		*s = share
+		return err
+	})
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, see Share.MarshalBinary.
+func (s AccessShare) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{shareBinaryVersion})
+	writeBytes(buf, []byte(s.Party))
+	writeUint64(buf, uint64(len(s.Path)))
+	for _, index := range s.Path {
+		writeUint64(buf, uint64(index))
+	}
+	writeShareBinary(buf, s.Share)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, see Share.UnmarshalBinary.
+func (s *AccessShare) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, func(r *bytes.Reader) error {
+		var share AccessShare
+		party, err := readBytes(r, maxBinaryLength)
+		if err != nil {
+			return err
+		}
+		share.Party = string(party)
+		count, err := readBinaryLength(r)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			share.Path = make([]int, count)
+		}
+		for i := range share.Path {
+			if share.Path[i], err = readBinaryInt(r); err != nil {
+				return err
+			}
+		}
+		share.Share, err = readShareBinary(r)
+		*s = share
+		return err
+	})
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, see Share.MarshalBinary.
+func (s AuthenticatedShare) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{shareBinaryVersion})
+	writeBytes(buf, s.Tag)
+	writeShareBinary(buf, s.Share)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, see Share.UnmarshalBinary.
+func (s *AuthenticatedShare) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, func(r *bytes.Reader) error {
+		var share AuthenticatedShare
+		var err error
+		if share.Tag, err = readBytes(r, maxBinaryLength); err != nil {
+			return err
+		}
+		share.Share, err = readShareBinary(r)
+		*s = share
+		return err
+	})
+}
+
+// unmarshalBinary checks the version of data, reads the rest with read and checks that nothing is
+// left. Any error is reported as ErrorInvalidBinary.
+func unmarshalBinary(data []byte, read func(r *bytes.Reader) error) error {
+	if len(data) == 0 || data[0] != shareBinaryVersion {
+		return ErrorInvalidBinary
+	}
+	r := bytes.NewReader(data[1:])
+	if err := read(r); err != nil || r.Len() != 0 {
+		return ErrorInvalidBinary
+	}
+	return nil
+}
+
+// writeShareBinary writes the fields of share to w.
+func writeShareBinary(w io.Writer, share Share) {
+	writeBundleShare(w, share)
+	writeInt(w, share.Bound)
+	writeFingerprint(w, share.SharingFingerprint)
+}
+
+// readShareBinary reads a share written by writeShareBinary.
+func readShareBinary(r io.Reader) (Share, error) {
+	share, err := readShare(r)
+	if err != nil {
+		return Share{}, err
+	}
+	if share.Bound, err = readInt(r); err != nil {
+		return Share{}, err
+	}
+	if share.SharingFingerprint, err = readFingerprint(r); err != nil {
+		return Share{}, err
+	}
+	return share, nil
+}
+
+// readBinaryInt reads an int written with writeUint64.
+func readBinaryInt(r io.Reader) (int, error) {
+	n, err := readUint64(r)
+	return int(int64(n)), err
+}
+
+// readBinaryLength reads the length of a slice written with writeUint64, and checks that it is not
+// too large to allocate.
+func readBinaryLength(r io.Reader) (int, error) {
+	n, err := readUint64(r)
+	if err != nil {
+		return 0, err
+	}
+	if n > maxBinaryLength {
+		return 0, ErrorInvalidBinary
+	}
+	return int(n), nil
+}
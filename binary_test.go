@@ -0,0 +1,78 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareMarshalBinary(t *testing.T) {
+	assert := assert.New(t)
+	fieldShares := ShareFiniteField(big.NewInt(42), mersenne61, 2, 5)
+	integerShares := WithSharingFingerprint(ShareIntegers(big.NewInt(42), big.NewInt(100), 40, 1, 3), []byte{1, 2, 3})
+	zero := Share{FieldSize: mersenne61, Degree: 1, X: 7, Y: big.NewInt(0)}
+	for _, share := range append(append(fieldShares, integerShares...), Share{}) {
+		data, err := share.MarshalBinary()
+		assert.NoError(err)
+		var decoded Share
+		assert.NoError(decoded.UnmarshalBinary(data))
+		assert.Equal(share, decoded)
+	}
+
+	// Shares over the same field have encodings of the same length, even for Y = 0
+	first, _ := fieldShares[0].MarshalBinary()
+	second, _ := zero.MarshalBinary()
+	assert.Equal(len(first), len(second))
+	var decoded Share
+	assert.NoError(decoded.UnmarshalBinary(second))
+	assert.Equal(0, decoded.Y.Sign())
+	assert.Equal(7, decoded.X)
+
+	assert.Equal(ErrorInvalidBinary, decoded.UnmarshalBinary(nil))
+	assert.Equal(ErrorInvalidBinary, decoded.UnmarshalBinary(append([]byte{2}, first[1:]...)))
+	assert.Equal(ErrorInvalidBinary, decoded.UnmarshalBinary(first[:len(first)-1]))
+	assert.Equal(ErrorInvalidBinary, decoded.UnmarshalBinary(append(first, 0)))
+}
+
+func TestEmbeddedShareMarshalBinary(t *testing.T) {
+	assert := assert.New(t)
+	share := ShareFiniteField(big.NewInt(42), big.NewInt(7919), 1, 3)[2]
+
+	// The fields of types that embed a Share survive encoding/gob, which uses MarshalBinary
+	for _, test := range []struct {
+		value   interface{}
+		decoded interface{}
+	}{
+		{&NestedShare{Parents: []ParentShare{{X: 2, Degree: 1}, {X: 4, Degree: 2}}, Share: share}, &NestedShare{}},
+		{&NestedShare{Share: share}, &NestedShare{}},
+		{&GroupShare{Group: 3, GroupDegree: 1, Share: share}, &GroupShare{}},
+		{&AccessShare{Party: "alice", Path: []int{0, 2}, Share: share}, &AccessShare{}},
+		{&AuthenticatedShare{Share: share, Tag: []byte{9, 8, 7}}, &AuthenticatedShare{}},
+	} {
+		var buf bytes.Buffer
+		assert.NoError(gob.NewEncoder(&buf).Encode(test.value))
+		assert.NoError(gob.NewDecoder(&buf).Decode(test.decoded))
+		assert.Equal(test.value, test.decoded)
+	}
+
+	data, _ := share.MarshalBinary()
+	var nested NestedShare
+	assert.Equal(ErrorInvalidBinary, nested.UnmarshalBinary(data))
+}
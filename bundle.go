@@ -0,0 +1,243 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+)
+
+var (
+	ErrorInvalidBundle          = errors.New("Share bundle is malformed")
+	ErrorBundleChecksumMismatch = errors.New("Share bundle checksum does not match")
+	ErrorBundleTooLarge         = errors.New("Share bundle exceeds the maximum size")
+)
+
+var bundleMagic = []byte("SHAMIRB1")
+
+// maxFingerprintSize bounds the size of the sharing fingerprints read from a bundle.
+const maxFingerprintSize = 64
+
+// maxBundleSize bounds the size of the body of a bundle read by ReadBundle, after decompression, so
+// that a small compressed bundle cannot make the reader allocate arbitrary amounts of memory. It
+// allows for hundreds of thousands of shares.
+const maxBundleSize = 64 << 20
+
+// The flags of a bundle, in the byte after the magic.
+const (
+	bundleCompressed   = 1
+	bundleFingerprints = 2
+)
+
+// WriteBundle serializes a bundle of shares to w in a compact binary format, followed by a SHA-256
+// checksum. If compress is set, the bundle is compressed with DEFLATE, which pays off for many
+// shares over the integers, whose factors and bounds repeat and whose values are large.
+//
+// Shares over a finite field are written with their Y values padded to the byte length of the field
+// size, so that every uncompressed bundle of the same number of such shares has the same size and
+// does not leak the magnitudes of the shares. Shares over the integers have no such bound, and
+// their size varies. Sharing fingerprints are only written if a share has one, so that bundles
+// without them can be read by earlier versions.
+func WriteBundle(w io.Writer, shares []Share, compress bool) error {
+	var flags byte
+	if compress {
+		flags |= bundleCompressed
+	}
+	for _, share := range shares {
+		if share.SharingFingerprint != nil {
+			flags |= bundleFingerprints
+		}
+	}
+	bw := bufio.NewWriter(w)
+	bw.Write(bundleMagic)
+	bw.WriteByte(flags)
+	var body io.Writer = bw
+	var fw *flate.Writer
+	if compress {
+		fw, _ = flate.NewWriter(bw, flate.BestCompression)
+		body = fw
+	}
+
+	h := sha256.New()
+	body = io.MultiWriter(body, h)
+	writeUint64(body, uint64(len(shares)))
+	for _, share := range shares {
+		writeBundleShare(body, share)
+		writeInt(body, share.Bound)
+		if flags&bundleFingerprints != 0 {
+			writeFingerprint(body, share.SharingFingerprint)
+		}
+	}
+	body.Write(h.Sum(nil))
+
+	if fw != nil {
+		if err := fw.Close(); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadBundle reads a bundle of shares written by WriteBundle, decompressing it if needed, and
+// verifies its checksum. It returns ErrorBundleTooLarge for a bundle whose body, after
+// decompression, exceeds 64 MiB.
+func ReadBundle(r io.Reader) ([]Share, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(bundleMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil || !bytes.Equal(header[:len(bundleMagic)], bundleMagic) {
+		return nil, ErrorInvalidBundle
+	}
+	flags := header[len(bundleMagic)]
+	if flags&^(bundleCompressed|bundleFingerprints) != 0 {
+		return nil, ErrorInvalidBundle
+	}
+	var body io.Reader = br
+	if flags&bundleCompressed != 0 {
+		fr := flate.NewReader(br)
+		defer fr.Close()
+		body = fr
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(body, maxBundleSize+1))
+	if err != nil || len(data) < sha256.Size {
+		return nil, ErrorInvalidBundle
+	}
+	if len(data) > maxBundleSize {
+		return nil, ErrorBundleTooLarge
+	}
+	content, checksum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	digest := sha256.Sum256(content)
+	if !bytes.Equal(checksum, digest[:]) {
+		return nil, ErrorBundleChecksumMismatch
+	}
+
+	cr := bytes.NewReader(content)
+	count, err := readUint64(cr)
+	if err != nil || count > uint64(len(content)) {
+		return nil, ErrorInvalidBundle
+	}
+	shares := make([]Share, count)
+	for i := range shares {
+		if shares[i], err = readShare(cr); err != nil {
+			return nil, ErrorInvalidBundle
+		}
+		if shares[i].Bound, err = readInt(cr); err != nil {
+			return nil, ErrorInvalidBundle
+		}
+		if flags&bundleFingerprints != 0 {
+			if shares[i].SharingFingerprint, err = readFingerprint(cr); err != nil {
+				return nil, ErrorInvalidBundle
+			}
+		}
+	}
+	if cr.Len() != 0 {
+		return nil, ErrorInvalidBundle
+	}
+	return shares, nil
+}
+
+// writeBundleShare writes share to w like writeShare, but pads the Y value of a share over a finite
+// field to the byte length of the field size. The sign is written as positive even for zero, so that
+// readShare reads the padded value unchanged.
+func writeBundleShare(w io.Writer, share Share) {
+	if share.FieldSize == nil || share.Y == nil {
+		writeShare(w, share)
+		return
+	}
+	writeInt(w, share.FieldSize)
+	writeInt(w, share.Factor)
+	writeUint64(w, uint64(share.Degree))
+	writeUint64(w, uint64(share.X))
+	w.Write([]byte{2})
+	writeBytes(w, fieldElementBytes(share.Y, share.FieldSize))
+}
+
+// writeFingerprint writes a sharing fingerprint, which may be nil, to w.
+func writeFingerprint(w io.Writer, fingerprint []byte) {
+	if fingerprint == nil {
+		w.Write([]byte{0})
+		return
+	}
+	w.Write([]byte{1})
+	writeBytes(w, fingerprint)
+}
+
+// readFingerprint reads a sharing fingerprint written by writeFingerprint.
+func readFingerprint(r io.Reader) ([]byte, error) {
+	var present [1]byte
+	if _, err := io.ReadFull(r, present[:]); err != nil {
+		return nil, err
+	}
+	switch present[0] {
+	case 0:
+		return nil, nil
+	case 1:
+		return readBytes(r, maxFingerprintSize)
+	}
+	return nil, ErrorInvalidBundle
+}
+
+// readShare reads a share written by writeShare or writeBundleShare.
+func readShare(r io.Reader) (Share, error) {
+	var share Share
+	var err error
+	if share.FieldSize, err = readInt(r); err != nil {
+		return Share{}, err
+	}
+	if share.Factor, err = readInt(r); err != nil {
+		return Share{}, err
+	}
+	degree, err := readUint64(r)
+	if err != nil {
+		return Share{}, err
+	}
+	x, err := readUint64(r)
+	if err != nil {
+		return Share{}, err
+	}
+	share.Degree, share.X = int(degree), int(x)
+	if share.Y, err = readInt(r); err != nil {
+		return Share{}, err
+	}
+	return share, nil
+}
+
+// readInt reads a big integer written by writeInt.
+func readInt(r io.Reader) (*big.Int, error) {
+	var sign [1]byte
+	if _, err := io.ReadFull(r, sign[:]); err != nil {
+		return nil, ErrorInvalidBundle
+	}
+	if sign[0] == 0xff {
+		return nil, nil
+	}
+	if sign[0] > 2 {
+		return nil, ErrorInvalidBundle
+	}
+	b, err := readBytes(r, 1<<24)
+	if err != nil {
+		return nil, ErrorInvalidBundle
+	}
+	n := big.NewInt(0).SetBytes(b)
+	if sign[0] == 0 {
+		n.Neg(n)
+	}
+	return n, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"compress/flate"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundle(t *testing.T) {
+	assert := assert.New(t)
+	var shares []Share
+	for i := 0; i < 20; i++ {
+		dealt, err := ShareSignedIntegers(big.NewInt(int64(-i)), big.NewInt(1000), 100, 5, 30)
+		assert.NoError(err)
+		shares = append(shares, dealt...)
+	}
+	shares = append(shares, ShareFiniteField(big.NewInt(5), big.NewInt(7919), 1, 3)...)
+
+	var plain, compressed bytes.Buffer
+	assert.NoError(WriteBundle(&plain, shares, false))
+	assert.NoError(WriteBundle(&compressed, shares, true))
+	assert.True(compressed.Len() < plain.Len())
+
+	for _, buf := range []*bytes.Buffer{&plain, &compressed} {
+		decoded, err := ReadBundle(bytes.NewReader(buf.Bytes()))
+		assert.NoError(err)
+		assert.Equal(shares, decoded)
+	}
+
+	// Sharing fingerprints are kept
+	tagged := WithSharingFingerprint(shares[:3], []byte{1, 2, 3})
+	tagged[1].SharingFingerprint = nil
+	var buf bytes.Buffer
+	assert.NoError(WriteBundle(&buf, tagged, true))
+	decoded, err := ReadBundle(&buf)
+	assert.NoError(err)
+	assert.Equal(tagged, decoded)
+
+	var empty bytes.Buffer
+	assert.NoError(WriteBundle(&empty, nil, true))
+	decoded, err = ReadBundle(&empty)
+	assert.NoError(err)
+	assert.Empty(decoded)
+}
+
+func TestBundleFixedSize(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := Conservative128().FieldSize
+	size := -1
+	for _, y := range []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(0).Sub(fieldSize, big.NewInt(1))} {
+		share := Share{FieldSize: fieldSize, Degree: 1, X: 2, Y: y}
+		var buf bytes.Buffer
+		assert.NoError(WriteBundle(&buf, []Share{share}, false))
+		if size < 0 {
+			size = buf.Len()
+		}
+		assert.Equal(size, buf.Len())
+
+		decoded, err := ReadBundle(&buf)
+		assert.NoError(err)
+		assert.Len(decoded, 1)
+		assert.Equal(0, y.Cmp(decoded[0].Y))
+	}
+}
+
+func TestBundleErrors(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareIntegers(big.NewInt(42), big.NewInt(1000), 40, 1, 3)
+	var buf bytes.Buffer
+	assert.NoError(WriteBundle(&buf, shares, false))
+	data := buf.Bytes()
+
+	corrupted := append([]byte{}, data...)
+	corrupted[20] ^= 1
+	_, err := ReadBundle(bytes.NewReader(corrupted))
+	assert.Equal(ErrorBundleChecksumMismatch, err)
+
+	_, err = ReadBundle(bytes.NewReader(data[:len(data)-1]))
+	assert.Equal(ErrorBundleChecksumMismatch, err)
+	_, err = ReadBundle(bytes.NewReader([]byte("SHAMIRB1")))
+	assert.Equal(ErrorInvalidBundle, err)
+	_, err = ReadBundle(bytes.NewReader([]byte("not a bundle")))
+	assert.Equal(ErrorInvalidBundle, err)
+}
+
+func TestReadBundleTooLarge(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	buf.Write(bundleMagic)
+	buf.WriteByte(bundleCompressed)
+	fw, err := flate.NewWriter(&buf, flate.BestCompression)
+	assert.NoError(err)
+	_, err = fw.Write(make([]byte, maxBundleSize+1))
+	assert.NoError(err)
+	assert.NoError(fw.Close())
+	assert.Less(buf.Len(), 1<<20)
+
+	_, err = ReadBundle(&buf)
+	assert.Equal(ErrorBundleTooLarge, err)
+}
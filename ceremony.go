@@ -0,0 +1,262 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+var (
+	ErrorDeadlineExceeded = errors.New("Deadline of the ceremony passed")
+)
+
+// A CeremonyPhase is the phase of a recovery Ceremony.
+type CeremonyPhase int
+
+const (
+	// CeremonyInviting is the phase before the custodians are invited.
+	CeremonyInviting CeremonyPhase = iota + 1
+	// CeremonyCollecting is the phase in which shares are collected from the custodians.
+	CeremonyCollecting
+	// CeremonyDelivered means that the secret was recovered and delivered to the recipient.
+	CeremonyDelivered
+	// CeremonyExpired means that the deadline passed before the secret was recovered.
+	CeremonyExpired
+	// CeremonyFailed means that all custodians responded, but their valid shares did not contain
+	// a consistent quorum.
+	CeremonyFailed
+)
+
+// String returns the name of the phase.
+func (p CeremonyPhase) String() string {
+	switch p {
+	case CeremonyInviting:
+		return "Inviting"
+	case CeremonyCollecting:
+		return "Collecting"
+	case CeremonyDelivered:
+		return "Delivered"
+	case CeremonyExpired:
+		return "Expired"
+	case CeremonyFailed:
+		return "Failed"
+	}
+	return "Unknown"
+}
+
+// A CeremonyState is the complete state of a Ceremony, which can be serialized with encoding/json
+// to persist the ceremony and resume it later with ResumeCeremony. While the ceremony is collecting,
+// the state contains the shares collected so far, so it must be stored as securely as the shares.
+// The shares are cleared when the ceremony ends.
+type CeremonyState struct {
+	Session       string        `json:"session"`
+	ID            int           `json:"id"`
+	Recipient     int           `json:"recipient"`
+	Custodians    []int         `json:"custodians"`
+	FieldSize     *big.Int      `json:"fieldSize"`
+	Degree        int           `json:"degree"`
+	Deadline      time.Time     `json:"deadline"`
+	Confirmations int           `json:"confirmations"`
+	Phase         CeremonyPhase `json:"phase"`
+	// Responded contains the custodians that revealed a share, and Rejected those of them whose
+	// share failed verification.
+	Responded []int   `json:"responded,omitempty"`
+	Rejected  []int   `json:"rejected,omitempty"`
+	Shares    []Share `json:"shares,omitempty"`
+}
+
+// A Ceremony orchestrates the recovery of a secret from its custodians: it invites the custodians
+// to reveal their shares, collects the shares until a deadline, verifies them, reconstructs the
+// secret from a consistent quorum and delivers it to a designated recipient. Like the state
+// machines of Dealer, Shareholder and Reconstructor, it does not assume a transport: custodians
+// answer the MessageRequest of the ceremony with a MessageReveal, and the secret is sent to the
+// recipient in a MessageDeliver. Time is passed in explicitly, so that a ceremony can be persisted
+// and resumed at any point, see State.
+type Ceremony struct {
+	state  CeremonyState
+	verify func(Share) bool
+}
+
+// NewCeremony returns a Ceremony with the given identifier that recovers the secret shared among
+// custodians in the given session and delivers it to recipient. The secret was shared with the
+// given degree over the finite field of size fieldSize, or over the integers if fieldSize is nil,
+// and shares with other parameters are rejected. Shares are accepted until deadline; a zero
+// deadline means no deadline. Like CombineAsync, the ceremony waits for degree+1+confirmations
+// shares that lie on a single polynomial, so with confirmations > 0 wrong shares are detected and
+// skipped.
+func NewCeremony(session string, id int, recipient int, custodians []int, fieldSize *big.Int, degree int, deadline time.Time, confirmations int) *Ceremony {
+	return &Ceremony{state: CeremonyState{
+		Session:       session,
+		ID:            id,
+		Recipient:     recipient,
+		Custodians:    append([]int{}, custodians...),
+		FieldSize:     fieldSize,
+		Degree:        degree,
+		Deadline:      deadline,
+		Confirmations: confirmations,
+		Phase:         CeremonyInviting,
+	}}
+}
+
+// ResumeCeremony returns a Ceremony that continues from a state returned by Ceremony.State. The
+// verifier is not part of the state and must be set again.
+func ResumeCeremony(state CeremonyState) *Ceremony {
+	state.Custodians = append([]int{}, state.Custodians...)
+	state.Responded = append([]int{}, state.Responded...)
+	state.Rejected = append([]int{}, state.Rejected...)
+	state.Shares = append([]Share{}, state.Shares...)
+	return &Ceremony{state: state}
+}
+
+// SetVerifier installs a function that checks every revealed share, for instance against Feldman
+// commitments with Commitments.Verify. Shares for which it returns false are rejected. By default,
+// all shares are accepted and only their consistency is checked.
+func (c *Ceremony) SetVerifier(verify func(Share) bool) {
+	c.verify = verify
+}
+
+// State returns a copy of the state of the ceremony for persisting.
+func (c *Ceremony) State() CeremonyState {
+	return ResumeCeremony(c.state).state
+}
+
+// Phase returns the current phase of the ceremony.
+func (c *Ceremony) Phase() CeremonyPhase {
+	return c.state.Phase
+}
+
+// Err returns why the ceremony ended without delivering the secret, or nil.
+func (c *Ceremony) Err() error {
+	switch c.state.Phase {
+	case CeremonyExpired:
+		return ErrorDeadlineExceeded
+	case CeremonyFailed:
+		return ErrorInconsistentShares
+	}
+	return nil
+}
+
+// Pending returns the custodians that have not revealed a share yet.
+func (c *Ceremony) Pending() []int {
+	var pending []int
+	for _, x := range c.state.Custodians {
+		if !containsInt(c.state.Responded, x) {
+			pending = append(pending, x)
+		}
+	}
+	return pending
+}
+
+// Invite returns a MessageRequest for every pending custodian and starts collecting shares. It can
+// be called again while collecting, for instance after resuming, to remind the custodians that have
+// not responded yet.
+func (c *Ceremony) Invite(now time.Time) ([]Message, error) {
+	if err := c.Tick(now); err != nil {
+		return nil, err
+	}
+	if c.state.Phase != CeremonyInviting && c.state.Phase != CeremonyCollecting {
+		return nil, ErrorUnexpectedMessage
+	}
+	c.state.Phase = CeremonyCollecting
+	var messages []Message
+	for _, x := range c.Pending() {
+		messages = append(messages, Message{
+			Type:    MessageRequest,
+			Session: c.state.Session,
+			From:    c.state.ID,
+			To:      x,
+		})
+	}
+	return messages, nil
+}
+
+// Handle processes a MessageReveal received at time now. Once a consistent quorum of shares has been
+// collected, the secret is reconstructed and returned in a MessageDeliver to the recipient, and the
+// ceremony ends. Shares from custodians that already responded are ignored.
+func (c *Ceremony) Handle(message Message, now time.Time) ([]Message, error) {
+	if message.Session != c.state.Session {
+		return nil, ErrorWrongSession
+	}
+	if err := c.Tick(now); err != nil {
+		return nil, err
+	}
+	if c.state.Phase != CeremonyCollecting || message.Type != MessageReveal || message.Share == nil ||
+		message.Share.X != message.From || !containsInt(c.state.Custodians, message.From) {
+		return nil, ErrorUnexpectedMessage
+	}
+	if containsInt(c.state.Responded, message.From) {
+		return nil, nil
+	}
+	c.state.Responded = append(c.state.Responded, message.From)
+
+	share := *message.Share
+	if c.verify != nil && !c.verify(share) {
+		c.state.Rejected = append(c.state.Rejected, share.X)
+	} else if equalOrBothNil(share.FieldSize, c.state.FieldSize) && share.Degree == c.state.Degree {
+		c.state.Shares = append(c.state.Shares, share)
+		// Shares over the integers may still differ in their factors
+		var candidates []Share
+		for _, other := range c.state.Shares {
+			if compatible(other, share) {
+				candidates = append(candidates, other)
+			}
+		}
+		if quorum := findQuorum(candidates, share.Degree+1+c.state.Confirmations); quorum != nil {
+			secret, err := ShareCombine(quorum)
+			if err == nil {
+				c.end(CeremonyDelivered)
+				return []Message{{
+					Type:    MessageDeliver,
+					Session: c.state.Session,
+					From:    c.state.ID,
+					To:      c.state.Recipient,
+					Secret:  secret,
+				}}, nil
+			}
+		}
+	} else {
+		c.state.Rejected = append(c.state.Rejected, share.X)
+	}
+
+	if len(c.Pending()) == 0 {
+		c.end(CeremonyFailed)
+		return nil, ErrorInconsistentShares
+	}
+	return nil, nil
+}
+
+// Tick ends the ceremony with ErrorDeadlineExceeded if the deadline has passed at time now and the
+// secret has not been delivered. It should be called periodically, so that an expired ceremony is
+// noticed even when no more messages arrive.
+func (c *Ceremony) Tick(now time.Time) error {
+	if (c.state.Phase == CeremonyInviting || c.state.Phase == CeremonyCollecting) &&
+		!c.state.Deadline.IsZero() && now.After(c.state.Deadline) {
+		c.end(CeremonyExpired)
+	}
+	return c.Err()
+}
+
+// end ends the ceremony in the given phase and forgets the collected shares.
+func (c *Ceremony) end(phase CeremonyPhase) {
+	c.state.Phase = phase
+	c.state.Shares = nil
+}
+
+// compatible reports whether two shares have the same parameters, so they can be combined.
+func compatible(a Share, b Share) bool {
+	return equalOrBothNil(a.FieldSize, b.FieldSize) && equalOrBothNil(a.Factor, b.Factor) && a.Degree == b.Degree
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reveal returns the MessageReveal with which a custodian answers a request of a ceremony.
+func reveal(request Message, share Share) Message {
+	return Message{Type: MessageReveal, Session: request.Session, From: request.To, To: request.From, Share: &share}
+}
+
+func TestCeremony(t *testing.T) {
+	assert := assert.New(t)
+	const ceremonyID, recipientID = 100, 101
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	g := testGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(42), g, 1, 5)
+
+	ceremony := NewCeremony("recovery", ceremonyID, recipientID, []int{1, 2, 3, 4, 5}, g.Order(), 1, start.Add(time.Hour), 1)
+	ceremony.SetVerifier(func(share Share) bool { return commitments.Verify(g, share) })
+	assert.Equal(CeremonyInviting, ceremony.Phase())
+	requests, err := ceremony.Invite(start)
+	assert.NoError(err)
+	assert.Len(requests, 5)
+	assert.Equal(CeremonyCollecting, ceremony.Phase())
+
+	// A wrong share is rejected by the verifier
+	wrong := shares[1]
+	wrong.Y = big.NewInt(0).Add(wrong.Y, big.NewInt(1))
+	messages, err := ceremony.Handle(reveal(requests[1], wrong), start.Add(time.Minute))
+	assert.NoError(err)
+	assert.Empty(messages)
+	messages, err = ceremony.Handle(reveal(requests[0], shares[0]), start.Add(time.Minute))
+	assert.NoError(err)
+	assert.Empty(messages)
+	// Repeated reveals are ignored
+	messages, err = ceremony.Handle(reveal(requests[0], shares[0]), start.Add(time.Minute))
+	assert.NoError(err)
+	assert.Empty(messages)
+	assert.Equal([]int{3, 4, 5}, ceremony.Pending())
+
+	// Persist and resume the ceremony
+	encoded, err := json.Marshal(ceremony.State())
+	assert.NoError(err)
+	var state CeremonyState
+	assert.NoError(json.Unmarshal(encoded, &state))
+	assert.Equal([]int{2}, state.Rejected)
+	ceremony = ResumeCeremony(state)
+	ceremony.SetVerifier(func(share Share) bool { return commitments.Verify(g, share) })
+	reminders, err := ceremony.Invite(start.Add(30 * time.Minute))
+	assert.NoError(err)
+	assert.Len(reminders, 3)
+
+	messages, err = ceremony.Handle(reveal(reminders[0], shares[2]), start.Add(40*time.Minute))
+	assert.NoError(err)
+	assert.Empty(messages)
+	messages, err = ceremony.Handle(reveal(reminders[2], shares[4]), start.Add(50*time.Minute))
+	assert.NoError(err)
+	assert.Len(messages, 1)
+	assert.Equal(MessageDeliver, messages[0].Type)
+	assert.Equal(recipientID, messages[0].To)
+	assert.Equal(int64(42), messages[0].Secret.Int64())
+	assert.Equal(CeremonyDelivered, ceremony.Phase())
+	assert.NoError(ceremony.Err())
+	assert.Empty(ceremony.State().Shares)
+
+	_, err = ceremony.Handle(reveal(reminders[1], shares[3]), start.Add(55*time.Minute))
+	assert.Equal(ErrorUnexpectedMessage, err)
+	_, err = ceremony.Invite(start.Add(55 * time.Minute))
+	assert.Equal(ErrorUnexpectedMessage, err)
+	assert.Equal("Delivered", ceremony.Phase().String())
+}
+
+func TestCeremonyFailure(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	shares := ShareFiniteField(big.NewInt(42), big.NewInt(7919), 1, 3)
+
+	// The deadline passes
+	ceremony := NewCeremony("recovery", 100, 101, []int{1, 2, 3}, big.NewInt(7919), 1, start.Add(time.Hour), 0)
+	requests, err := ceremony.Invite(start)
+	assert.NoError(err)
+	_, err = ceremony.Handle(reveal(requests[0], shares[0]), start.Add(time.Minute))
+	assert.NoError(err)
+	assert.NoError(ceremony.Tick(start.Add(time.Hour)))
+	assert.Equal(ErrorDeadlineExceeded, ceremony.Tick(start.Add(time.Hour+time.Second)))
+	assert.Equal(CeremonyExpired, ceremony.Phase())
+	assert.Empty(ceremony.State().Shares)
+	_, err = ceremony.Handle(reveal(requests[1], shares[1]), start.Add(2*time.Hour))
+	assert.Equal(ErrorDeadlineExceeded, err)
+
+	// All custodians respond without a consistent quorum
+	ceremony = NewCeremony("recovery", 100, 101, []int{1, 2, 3}, big.NewInt(7919), 1, time.Time{}, 1)
+	requests, err = ceremony.Invite(start)
+	assert.NoError(err)
+	wrong := shares[2]
+	wrong.Y = big.NewInt(0).Add(wrong.Y, big.NewInt(1))
+	_, err = ceremony.Handle(reveal(requests[0], shares[0]), start)
+	assert.NoError(err)
+	_, err = ceremony.Handle(reveal(requests[1], shares[1]), start)
+	assert.NoError(err)
+	_, err = ceremony.Handle(reveal(requests[2], wrong), start)
+	assert.Equal(ErrorInconsistentShares, err)
+	assert.Equal(CeremonyFailed, ceremony.Phase())
+	assert.Equal(ErrorInconsistentShares, ceremony.Err())
+
+	// Messages that do not belong to the ceremony
+	ceremony = NewCeremony("recovery", 100, 101, []int{1, 2}, big.NewInt(7919), 1, time.Time{}, 0)
+	_, err = ceremony.Handle(reveal(requests[0], shares[0]), start)
+	assert.Equal(ErrorUnexpectedMessage, err)
+	requests, err = ceremony.Invite(start)
+	assert.NoError(err)
+	message := reveal(requests[0], shares[0])
+	message.Session = "other"
+	_, err = ceremony.Handle(message, start)
+	assert.Equal(ErrorWrongSession, err)
+	_, err = ceremony.Handle(reveal(requests[0], shares[1]), start)
+	assert.Equal(ErrorUnexpectedMessage, err)
+	_, err = ceremony.Handle(Message{Type: MessageReveal, Session: "recovery", From: 3, Share: &shares[2]}, start)
+	assert.Equal(ErrorUnexpectedMessage, err)
+}
+
+func TestCeremonyMaliciousFirstShare(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	fieldSize := big.NewInt(7919)
+	shares := ShareFiniteField(big.NewInt(42), fieldSize, 2, 7)
+
+	// The first custodian answers with a share of degree 0, which must not shut out the others
+	ceremony := NewCeremony("recovery", 100, 101, []int{1, 2, 3, 4, 5, 6, 7}, fieldSize, 2, time.Time{}, 1)
+	requests, err := ceremony.Invite(start)
+	assert.NoError(err)
+	malicious := Share{FieldSize: fieldSize, Degree: 0, X: 1, Y: big.NewInt(1)}
+	messages, err := ceremony.Handle(reveal(requests[0], malicious), start)
+	assert.NoError(err)
+	assert.Empty(messages)
+	assert.Equal([]int{1}, ceremony.State().Rejected)
+	for i := 1; i < 4; i++ {
+		messages, err = ceremony.Handle(reveal(requests[i], shares[i]), start)
+		assert.NoError(err)
+		assert.Empty(messages)
+	}
+	messages, err = ceremony.Handle(reveal(requests[4], shares[4]), start)
+	assert.NoError(err)
+	if assert.Len(messages, 1) {
+		assert.Equal(int64(42), messages[0].Secret.Int64())
+	}
+	assert.Equal(CeremonyDelivered, ceremony.Phase())
+}
@@ -0,0 +1,220 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command demo runs a secure sum, and optionally a secure product, among parties connected over TCP.
+// Every party shares its input with the others, the parties add (and multiply) the shares, and only
+// the result is opened. It serves as an integration test of the shamir and pool packages and as a
+// template for applications.
+//
+// By default, all parties run in one process on the loopback interface:
+//
+//	demo -inputs 3,5,7 -degree 1 -product
+//
+// To run the parties as separate processes, possibly on different machines, start every party with
+// its own X coordinate and input and the addresses of all parties:
+//
+//	demo -party 1 -input 3 -addresses host1:7001,host2:7001,host3:7001 -degree 1
+//
+// The connections are not authenticated, so this should only be used on trusted networks.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/TNO-MPC/shamir/pool"
+)
+
+var errorUsage = errors.New("Invalid arguments")
+
+// config holds the public parameters of a computation, which all parties must agree on.
+type config struct {
+	nParties  int
+	degree    int
+	fieldSize *big.Int
+	product   bool
+	timeout   time.Duration
+}
+
+// result holds the opened outcomes of a computation.
+type result struct {
+	sum     *big.Int
+	product *big.Int
+}
+
+func main() {
+	inputs := flag.String("inputs", "3,5,7", "comma-separated inputs of all parties, to run them in this process")
+	party := flag.Int("party", 0, "X coordinate of the single party to run, from 1 to the number of addresses")
+	input := flag.String("input", "0", "input of the single party")
+	addresses := flag.String("addresses", "", "comma-separated addresses of all parties, for -party")
+	degree := flag.Int("degree", 1, "degree of the sharings; degree+1 parties can reconstruct")
+	field := flag.String("field", "p25519", "registered identifier or decimal size of the field")
+	product := flag.Bool("product", false, "also compute the product, which requires more than 2*degree parties")
+	timeout := flag.Duration("timeout", time.Minute, "time to wait for all parties to connect")
+	flag.Parse()
+
+	fieldSize, err := shamir.ParseFieldSize(*field)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg := config{degree: *degree, fieldSize: fieldSize, product: *product, timeout: *timeout}
+	var r result
+	if *party == 0 {
+		values, err := parseInputs(*inputs)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg.nParties = len(values)
+		r, err = runLocal(cfg, values)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		list := strings.Split(*addresses, ",")
+		value, err := parseInputs(*input)
+		if err != nil || len(value) != 1 {
+			log.Fatal(errorUsage)
+		}
+		cfg.nParties = len(list)
+		listener, err := listen(list, *party)
+		if err != nil {
+			log.Fatal(err)
+		}
+		r, err = runParty(cfg, *party, listener, list, value[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Println("sum:", r.sum)
+	if cfg.product {
+		fmt.Println("product:", r.product)
+	}
+}
+
+// parseInputs parses comma-separated decimal inputs.
+func parseInputs(s string) ([]*big.Int, error) {
+	var values []*big.Int
+	for _, field := range strings.Split(s, ",") {
+		value, ok := big.NewInt(0).SetString(strings.TrimSpace(field), 10)
+		if !ok {
+			return nil, errorUsage
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// listen listens on the port of the party with X coordinate x on all interfaces.
+func listen(addresses []string, x int) (net.Listener, error) {
+	if x < 1 || x > len(addresses) {
+		return nil, pool.ErrorUnknownParty
+	}
+	_, port, err := net.SplitHostPort(addresses[x-1])
+	if err != nil {
+		return nil, err
+	}
+	return net.Listen("tcp", ":"+port)
+}
+
+// runLocal runs all parties in this process, connected over the loopback interface, with the given
+// inputs, and returns the result of the first party.
+func runLocal(cfg config, inputs []*big.Int) (result, error) {
+	listeners := make([]net.Listener, len(inputs))
+	addresses := make([]string, len(inputs))
+	for i := range listeners {
+		var err error
+		if listeners[i], err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+			return result{}, err
+		}
+		defer listeners[i].Close()
+		addresses[i] = listeners[i].Addr().String()
+	}
+	results := make([]result, len(inputs))
+	errs := make([]error, len(inputs))
+	var wg sync.WaitGroup
+	for i := range inputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = runParty(cfg, i+1, listeners[i], addresses, inputs[i])
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return result{}, err
+		}
+	}
+	return results[0], nil
+}
+
+// runParty runs the party with X coordinate x with the given input, listening on listener and
+// connecting to the other parties at addresses.
+func runParty(cfg config, x int, listener net.Listener, addresses []string, input *big.Int) (result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout)
+	defer cancel()
+	connections, err := pool.NewTCPPool(ctx, x, listener, addresses, nil)
+	if err != nil {
+		return result{}, err
+	}
+	defer connections.Close()
+	p := shamir.NewParty(x, cfg.nParties, cfg.fieldSize, cfg.degree, pool.NewNetwork(x, cfg.nParties, connections))
+
+	// Every party deals shares of its input in turn
+	shares := make([]shamir.ShareVector, cfg.nParties)
+	for owner := 1; owner <= cfg.nParties; owner++ {
+		var secrets []*big.Int
+		if owner == x {
+			secrets = []*big.Int{input}
+		}
+		if shares[owner-1], err = p.Input(owner, secrets, 1); err != nil {
+			return result{}, err
+		}
+	}
+
+	var r result
+	sum, err := shamir.ShareVectorAdd(shares)
+	if err != nil {
+		return result{}, err
+	}
+	opened, err := p.Open(sum)
+	if err != nil {
+		return result{}, err
+	}
+	r.sum = opened[0]
+
+	if cfg.product {
+		// Mul reduces the degree of every product back to the degree of the inputs
+		product := shares[0]
+		for _, v := range shares[1:] {
+			if product, err = p.Mul(product, v); err != nil {
+				return result{}, err
+			}
+		}
+		if opened, err = p.Open(product); err != nil {
+			return result{}, err
+		}
+		r.product = opened[0]
+	}
+	return r, nil
+}
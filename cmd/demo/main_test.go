@@ -0,0 +1,43 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunLocal(t *testing.T) {
+	assert := assert.New(t)
+	inputs, err := parseInputs("3, 5,7")
+	assert.NoError(err)
+	cfg := config{nParties: 3, degree: 1, fieldSize: shamir.Conservative128().FieldSize, product: true, timeout: 10 * time.Second}
+	r, err := runLocal(cfg, inputs)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(15), r.sum)
+	assert.Equal(big.NewInt(105), r.product)
+
+	// Products need more than 2*degree parties
+	cfg.degree = 2
+	_, err = runLocal(cfg, inputs)
+	assert.Equal(shamir.ErrorTooFewParties, err)
+
+	_, err = parseInputs("3,five")
+	assert.Equal(errorUsage, err)
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"context"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorInconsistentShares = errors.New("No consistent quorum of shares was found")
+)
+
+// A CombineResult is the outcome of CombineAsync. On success, Shares holds the quorum of shares
+// that the secret was recovered from.
+type CombineResult struct {
+	Secret *big.Int
+	Shares []Share
+	Err    error
+}
+
+// CombineAsync consumes shares from a channel, for instance fed by network handlers, and emits the
+// secret on the returned channel as soon as a consistent quorum is reached: degree+1+confirmations
+// shares that lie on a single polynomial of the degree of the shares. With confirmations > 0, wrong
+// shares are detected and skipped, as long as enough correct shares arrive. Shares that are
+// malformed, incompatible with the first share, or that repeat an X coordinate, are ignored.
+//
+// Exactly one result is sent. If shares is closed before a quorum is reached, the result holds
+// ErrorTooFewShares, or ErrorInconsistentShares if enough shares arrived but they do not agree. If
+// ctx is done first, for instance because of a timeout, the result holds ctx.Err(). After the
+// result, no more shares are consumed, so producers should not block on sending indefinitely.
+func CombineAsync(ctx context.Context, shares <-chan Share, confirmations int) <-chan CombineResult {
+	results := make(chan CombineResult, 1)
+	go func() {
+		defer close(results)
+		results <- combineAsync(ctx, shares, confirmations)
+	}()
+	return results
+}
+
+func combineAsync(ctx context.Context, shares <-chan Share, confirmations int) CombineResult {
+	var received []Share
+	seen := make(map[int]bool)
+	interpolator := NewInterpolator()
+	for {
+		var share Share
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return CombineResult{Err: ctx.Err()}
+		case share, ok = <-shares:
+		}
+		if !ok {
+			if len(received) > 0 && len(received) >= received[0].Degree+1+confirmations {
+				return CombineResult{Err: ErrorInconsistentShares}
+			}
+			return CombineResult{Err: ErrorTooFewShares}
+		}
+
+		if seen[share.X] || (len(received) > 0 && (!equalOrBothNil(received[0].FieldSize, share.FieldSize) ||
+			!equalOrBothNil(received[0].Factor, share.Factor) || received[0].Degree != share.Degree)) {
+			continue
+		}
+		if interpolator.Add(share) == ErrorInvalidShare {
+			continue
+		}
+		seen[share.X] = true
+		received = append(received, share)
+
+		// As long as all shares agree, which the interpolator checks as they arrive, the quorum
+		// consists of all shares received; otherwise, search for a quorum among them
+		need := share.Degree + 1 + confirmations
+		if interpolator.Consistent() {
+			if len(received) == need {
+				secret, err := emitCombine("", received, func([]Share) (*big.Int, error) { return interpolator.Secret() })
+				return CombineResult{Secret: secret, Shares: received, Err: err}
+			}
+			continue
+		}
+		if quorum := findQuorum(received, need); quorum != nil {
+			secret, err := ShareCombine(quorum)
+			return CombineResult{Secret: secret, Shares: quorum, Err: err}
+		}
+	}
+}
+
+// findQuorum returns need shares that lie on a polynomial through the last share and degree other
+// shares, or nil if there are none. Quorums without the last share are assumed to have been
+// checked before.
+func findQuorum(shares []Share, need int) []Share {
+	last := shares[len(shares)-1]
+	if len(shares) < need {
+		return nil
+	}
+	others := shares[:len(shares)-1]
+	basis := make([]Share, 0, last.Degree+1)
+	var search func(start int) []Share
+	search = func(start int) []Share {
+		if len(basis) == last.Degree {
+			points := append(append([]Share{}, basis...), last)
+			quorum := points
+			for _, share := range others {
+				if len(quorum) < need && !containsX(points, share.X) && onPolynomial(points, share) {
+					quorum = append(quorum, share)
+				}
+			}
+			if len(quorum) >= need {
+				return quorum
+			}
+			return nil
+		}
+		for i := start; i < len(others); i++ {
+			basis = append(basis, others[i])
+			if quorum := search(i + 1); quorum != nil {
+				return quorum
+			}
+			basis = basis[:len(basis)-1]
+		}
+		return nil
+	}
+	return search(0)
+}
+
+func containsX(shares []Share, x int) bool {
+	for _, share := range shares {
+		if share.X == x {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineAsync(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareFiniteField(big.NewInt(42), big.NewInt(7919), 2, 7)
+
+	in := make(chan Share)
+	results := CombineAsync(context.Background(), in, 0)
+	go func() {
+		for _, share := range shares[4:] {
+			in <- share
+		}
+	}()
+	result := <-results
+	assert.NoError(result.Err)
+	assert.Equal(big.NewInt(42), result.Secret)
+	assert.Len(result.Shares, 3)
+	_, ok := <-results
+	assert.False(ok)
+}
+
+func TestCombineAsyncWrongShares(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareFiniteField(big.NewInt(42), big.NewInt(7919), 1, 6)
+	wrong := shares[1]
+	wrong.Y = big.NewInt(0).Add(wrong.Y, big.NewInt(1))
+	other := ShareFiniteField(big.NewInt(42), big.NewInt(7919), 2, 6)[3]
+
+	in := make(chan Share, 10)
+	for _, share := range []Share{shares[0], wrong, other, shares[0], shares[2], shares[3]} {
+		in <- share
+	}
+	result := <-CombineAsync(context.Background(), in, 1)
+	assert.NoError(result.Err)
+	assert.Equal(big.NewInt(42), result.Secret)
+	assert.Len(result.Shares, 3)
+	for _, share := range result.Shares {
+		assert.NotEqual(2, share.X)
+	}
+
+	// Without enough correct shares
+	in = make(chan Share, 10)
+	in <- shares[0]
+	in <- wrong
+	in <- shares[4]
+	close(in)
+	result = <-CombineAsync(context.Background(), in, 1)
+	assert.Equal(ErrorInconsistentShares, result.Err)
+
+	in = make(chan Share, 10)
+	in <- shares[0]
+	close(in)
+	result = <-CombineAsync(context.Background(), in, 0)
+	assert.Equal(ErrorTooFewShares, result.Err)
+}
+
+func TestCombineAsyncTimeout(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	in := make(chan Share)
+	result := <-CombineAsync(ctx, in, 0)
+	assert.Equal(context.DeadlineExceeded, result.Err)
+}
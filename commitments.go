@@ -0,0 +1,177 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+)
+
+// Commitments are Feldman commitments to the polynomial of a sharing over the finite field of
+// integers modulo the order of a group: element j is a_j * G for the coefficient a_j of X^j and a
+// generator G, so element 0 commits to the secret. Anyone holding the commitments can check a share
+// with Verify without learning the secret, although a_0 * G itself is revealed.
+type Commitments []GroupElement
+
+// ShareFeldman shares a secret like ShareFiniteField over the field of integers modulo the order of
+// generator, and returns Feldman commitments to the sharing polynomial along with the shares. The
+// shares carry the sharing fingerprint of the commitments.
+func ShareFeldman(secret *big.Int, generator GroupElement, degree int, nShares int) ([]Share, Commitments) {
+	fieldSize := generator.Order()
+	coefficients := make([]*big.Int, degree)
+	for i := range coefficients {
+		coefficients[i], _ = rand.Int(rand.Reader, fieldSize)
+	}
+	shares := shareFiniteField(secret, fieldSize, coefficients, nShares)
+	commitments := make(Commitments, degree+1)
+	commitments[0] = generator.ScalarMult(secret)
+	for i := range coefficients {
+		commitments[i+1] = generator.ScalarMult(coefficients[i])
+	}
+	return WithSharingFingerprint(shares, commitments.SharingFingerprint()), commitments
+}
+
+// Verify checks that share lies on the polynomial committed to, given the generator used when
+// sharing.
+func (c Commitments) Verify(generator GroupElement, share Share) bool {
+	if len(c) == 0 || share.Degree != len(c)-1 || share.Y == nil || !equalOrBothNil(share.FieldSize, generator.Order()) {
+		return false
+	}
+	return generator.ScalarMult(share.Y).Equal(c.ShareCommitment(share.X))
+}
+
+// ShareCommitment returns the commitment y * G to the share y at X coordinate x, computed from the
+// commitments to the polynomial. The commitments must not be empty.
+func (c Commitments) ShareCommitment(x int) GroupElement {
+	// sum_j x^j C_j by Horner's rule
+	point := big.NewInt(int64(x))
+	commitment := c[len(c)-1]
+	for j := len(c) - 2; j >= 0; j-- {
+		commitment = commitment.ScalarMult(point).Add(c[j])
+	}
+	return commitment
+}
+
+// batchWeightBits is the size in bits of the random weights of batch verification, which accepts
+// invalid shares with probability at most 2^-batchWeightBits, or one over the group order if it is
+// smaller.
+const batchWeightBits = 128
+
+// VerifyBatch checks that all shares lie on the polynomial committed to, like Verify for every
+// share, but at the cost of one multi-scalar multiplication of degree+1 terms in total instead of
+// degree+2 scalar multiplications per share. It checks a random linear combination of the shares,
+// so it only reports whether all shares are valid; use Verify to find the invalid shares if it
+// fails.
+func (c Commitments) VerifyBatch(generator GroupElement, shares []Share) bool {
+	if len(c) == 0 {
+		return false
+	}
+	order := generator.Order()
+	// Check sum_i r_i y_i * G = sum_j (sum_i r_i x_i^j) * C_j for random weights r_i
+	y := big.NewInt(0)
+	scalars := make([]*big.Int, len(c))
+	for j := range scalars {
+		scalars[j] = big.NewInt(0)
+	}
+	for _, share := range shares {
+		if !c.batchable(share, order) {
+			return false
+		}
+		r, err := batchWeight()
+		if err != nil {
+			return false
+		}
+		y.Add(y, big.NewInt(0).Mul(r, share.Y))
+		x := big.NewInt(int64(share.X))
+		for j := range c {
+			scalars[j].Add(scalars[j], r).Mod(scalars[j], order)
+			r.Mul(r, x).Mod(r, order)
+		}
+	}
+	sum, err := MultiScalarMult(c, scalars)
+	return err == nil && generator.ScalarMult(y.Mod(y, order)).Equal(sum)
+}
+
+// VerifyDealings checks that shares[i] lies on the polynomial committed to by commitments[i] for
+// every i, for instance the shares that a party received from many dealers, with a single random
+// linear combination like VerifyBatch, with a single multi-scalar multiplication of all commitments.
+// The commitments may have different degrees.
+func VerifyDealings(generator GroupElement, commitments []Commitments, shares []Share) bool {
+	if len(commitments) != len(shares) {
+		return false
+	}
+	order := generator.Order()
+	// Check sum_i r_i y_i * G = sum_i sum_j r_i x_i^j * C_ij for random weights r_i
+	y := big.NewInt(0)
+	elements := []GroupElement{}
+	scalars := []*big.Int{}
+	for i, share := range shares {
+		c := commitments[i]
+		if !c.batchable(share, order) {
+			return false
+		}
+		r, err := batchWeight()
+		if err != nil {
+			return false
+		}
+		y.Add(y, big.NewInt(0).Mul(r, share.Y))
+		r.Mod(r, order)
+		x := big.NewInt(int64(share.X))
+		for j := range c {
+			elements = append(elements, c[j])
+			scalars = append(scalars, big.NewInt(0).Set(r))
+			r.Mul(r, x).Mod(r, order)
+		}
+	}
+	if len(shares) == 0 {
+		return true
+	}
+	sum, err := MultiScalarMult(elements, scalars)
+	return err == nil && generator.ScalarMult(y.Mod(y, order)).Equal(sum)
+}
+
+// batchable reports whether share can be verified against the commitments, given the group order.
+func (c Commitments) batchable(share Share, order *big.Int) bool {
+	return len(c) > 0 && share.Degree == len(c)-1 && share.Y != nil && equalOrBothNil(share.FieldSize, order)
+}
+
+// batchWeight returns a random weight for batch verification.
+func batchWeight() (*big.Int, error) {
+	return rand.Int(rand.Reader, big.NewInt(0).Lsh(big.NewInt(1), batchWeightBits))
+}
+
+// Add returns the commitments to the sum of the committed polynomials, which have the same degree.
+func (c Commitments) Add(other Commitments) (Commitments, error) {
+	if len(c) != len(other) {
+		return nil, ErrorIncompatibleShares
+	}
+	sum := make(Commitments, len(c))
+	for j := range c {
+		sum[j] = c[j].Add(other[j])
+	}
+	return sum, nil
+}
+
+// Digest returns a SHA-256 hash of the commitments, which can be published before the commitments
+// themselves to commit to them.
+func (c Commitments) Digest() []byte {
+	h := sha256.New()
+	writeUint64(h, uint64(len(c)))
+	for _, element := range c {
+		writeBytes(h, element.Bytes())
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareFeldman(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(777), g, 2, 5)
+	assert.Len(commitments, 3)
+	assert.True(commitments[0].Equal(g.ScalarMult(big.NewInt(777))))
+	for _, share := range shares {
+		assert.True(commitments.Verify(g, share))
+	}
+	secret, err := ShareCombine(shares[2:])
+	assert.NoError(err)
+	assert.Equal(int64(777), secret.Int64())
+
+	wrong := shares[1]
+	wrong.Y = big.NewInt(0).Add(wrong.Y, big.NewInt(1))
+	assert.False(commitments.Verify(g, wrong))
+	wrong = shares[1]
+	wrong.X = 6
+	assert.False(commitments.Verify(g, wrong))
+	assert.False(commitments[:2].Verify(g, shares[1]))
+}
+
+func TestVerifyBatch(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(777), g, 3, 20)
+	assert.True(commitments.VerifyBatch(g, shares))
+	assert.True(commitments.VerifyBatch(g, nil))
+
+	wrong := append([]Share{}, shares...)
+	wrong[7].Y = big.NewInt(0).Add(wrong[7].Y, big.NewInt(1))
+	assert.False(commitments.VerifyBatch(g, wrong))
+	wrong[7] = shares[7]
+	wrong[7].X = 21
+	assert.False(commitments.VerifyBatch(g, wrong))
+	assert.False(commitments[:3].VerifyBatch(g, shares))
+	assert.False(Commitments{}.VerifyBatch(g, shares))
+
+	// Shares at X coordinate 2 of many dealings of different degrees
+	var dealings []Commitments
+	var received []Share
+	for degree := 0; degree < 10; degree++ {
+		shares, commitments := ShareFeldman(big.NewInt(int64(degree)), g, degree, 12)
+		dealings = append(dealings, commitments)
+		received = append(received, shares[1])
+	}
+	assert.True(VerifyDealings(g, dealings, received))
+	assert.True(VerifyDealings(g, nil, nil))
+	assert.False(VerifyDealings(g, dealings[1:], received))
+	received[4].Y = big.NewInt(0).Sub(received[4].Y, big.NewInt(1))
+	assert.False(VerifyDealings(g, dealings, received))
+	received[4] = received[5]
+	assert.False(VerifyDealings(g, dealings, received))
+}
+
+func TestCommitmentsAdd(t *testing.T) {
+	assert := assert.New(t)
+	g := testGroup.Generator()
+	a, ca := ShareFeldman(big.NewInt(100), g, 1, 3)
+	b, cb := ShareFeldman(big.NewInt(200), g, 1, 3)
+	sum, err := ca.Add(cb)
+	assert.NoError(err)
+	for i := range a {
+		share, err := ShareAdd([]Share{a[i], b[i]})
+		assert.NoError(err)
+		assert.True(sum.Verify(g, share))
+	}
+	assert.NotEqual(ca.Digest(), cb.Digest())
+	assert.Equal(ca.Digest(), Commitments{ca[0], ca[1]}.Digest())
+
+	_, c := ShareFeldman(big.NewInt(200), g, 2, 3)
+	_, err = ca.Add(c)
+	assert.Equal(ErrorIncompatibleShares, err)
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The compact encoding of a share is a flags byte followed by the fields present, in this order:
+//
+//	flags | [fieldSize] | [factor] | uvarint degree | uvarint X | [Y] | [bound] | [fingerprint]
+//
+// Integers are written as a uvarint h. If the low bit of h is 0, the integer is small and h >> 1 is
+// its zigzag encoding, as in encoding/binary.PutVarint. Otherwise h >> 2 is the length of the
+// big-endian magnitude that follows, and bit 1 of h is set for negative integers. Fingerprints are
+// written as a uvarint length followed by the bytes.
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	ErrorInvalidCompact = errors.New("Compact share encoding is malformed")
+)
+
+// The flags of the compact encoding, for the optional fields of a share.
+const (
+	compactFieldSize   = 1
+	compactFactor      = 2
+	compactY           = 4
+	compactBound       = 8
+	compactFingerprint = 16
+)
+
+// AppendCompact appends a compact encoding of share to dst and returns the result. All numbers are
+// written as varints, so shares over small fields, such as counters or identifiers shared over a
+// field of 16 bits, take a few bytes each instead of the tens of bytes of WriteBundle or JSON.
+// Encodings can be concatenated and read back one by one with ReadCompact.
+//
+// Unlike WriteBundle, the encoding is not padded, so its length reveals the magnitude of Y. Only
+// use it where the sizes of individual shares are not observed by others.
+func AppendCompact(dst []byte, share Share) []byte {
+	var flags byte
+	if share.FieldSize != nil {
+		flags |= compactFieldSize
+	}
+	if share.Factor != nil {
+		flags |= compactFactor
+	}
+	if share.Y != nil {
+		flags |= compactY
+	}
+	if share.Bound != nil {
+		flags |= compactBound
+	}
+	if share.SharingFingerprint != nil {
+		flags |= compactFingerprint
+	}
+	dst = append(dst, flags)
+	if share.FieldSize != nil {
+		dst = appendCompactInt(dst, share.FieldSize)
+	}
+	if share.Factor != nil {
+		dst = appendCompactInt(dst, share.Factor)
+	}
+	dst = appendUvarint(dst, uint64(share.Degree))
+	dst = appendUvarint(dst, uint64(share.X))
+	if share.Y != nil {
+		dst = appendCompactInt(dst, share.Y)
+	}
+	if share.Bound != nil {
+		dst = appendCompactInt(dst, share.Bound)
+	}
+	if share.SharingFingerprint != nil {
+		dst = appendUvarint(dst, uint64(len(share.SharingFingerprint)))
+		dst = append(dst, share.SharingFingerprint...)
+	}
+	return dst
+}
+
+// ReadCompact reads a share encoded by AppendCompact from r, for instance a bytes.Reader over
+// concatenated encodings. It returns io.EOF if r is at its end, and ErrorInvalidCompact if the
+// encoding is malformed or truncated.
+func ReadCompact(r io.ByteReader) (Share, error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return Share{}, io.EOF
+	}
+	if flags&^(compactFieldSize|compactFactor|compactY|compactBound|compactFingerprint) != 0 {
+		return Share{}, ErrorInvalidCompact
+	}
+	var share Share
+	if flags&compactFieldSize != 0 {
+		if share.FieldSize, err = readCompactInt(r); err != nil {
+			return Share{}, err
+		}
+	}
+	if flags&compactFactor != 0 {
+		if share.Factor, err = readCompactInt(r); err != nil {
+			return Share{}, err
+		}
+	}
+	degree, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Share{}, ErrorInvalidCompact
+	}
+	x, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Share{}, ErrorInvalidCompact
+	}
+	share.Degree, share.X = int(degree), int(x)
+	if flags&compactY != 0 {
+		if share.Y, err = readCompactInt(r); err != nil {
+			return Share{}, err
+		}
+	}
+	if flags&compactBound != 0 {
+		if share.Bound, err = readCompactInt(r); err != nil {
+			return Share{}, err
+		}
+	}
+	if flags&compactFingerprint != 0 {
+		if share.SharingFingerprint, err = readCompactBytes(r, maxFingerprintSize); err != nil {
+			return Share{}, err
+		}
+	}
+	return share, nil
+}
+
+// appendCompactInt appends n in the integer format of the compact encoding.
+func appendCompactInt(dst []byte, n *big.Int) []byte {
+	if n.IsInt64() && n.Int64() >= -1<<61 && n.Int64() < 1<<61 {
+		v := n.Int64()
+		return appendUvarint(dst, (uint64(v<<1)^uint64(v>>63))<<1)
+	}
+	magnitude := n.Bytes()
+	header := uint64(len(magnitude))<<2 | 1
+	if n.Sign() < 0 {
+		header |= 2
+	}
+	return append(appendUvarint(dst, header), magnitude...)
+}
+
+// readCompactInt reads an integer written by appendCompactInt.
+func readCompactInt(r io.ByteReader) (*big.Int, error) {
+	header, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, ErrorInvalidCompact
+	}
+	if header&1 == 0 {
+		zigzag := header >> 1
+		return big.NewInt(int64(zigzag>>1) ^ -int64(zigzag&1)), nil
+	}
+	if header>>2 > 1<<24 {
+		return nil, ErrorInvalidCompact
+	}
+	magnitude := make([]byte, header>>2)
+	for i := range magnitude {
+		if magnitude[i], err = r.ReadByte(); err != nil {
+			return nil, ErrorInvalidCompact
+		}
+	}
+	n := big.NewInt(0).SetBytes(magnitude)
+	if header&2 != 0 {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// readCompactBytes reads at most maxLength bytes prefixed with their uvarint length.
+func readCompactBytes(r io.ByteReader, maxLength int) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil || length > uint64(maxLength) {
+		return nil, ErrorInvalidCompact
+	}
+	b := make([]byte, length)
+	for i := range b {
+		if b[i], err = r.ReadByte(); err != nil {
+			return nil, ErrorInvalidCompact
+		}
+	}
+	return b, nil
+}
+
+func appendUvarint(dst []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(dst, buf[:binary.PutUvarint(buf[:], v)]...)
+}
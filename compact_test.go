@@ -0,0 +1,68 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompact(t *testing.T) {
+	assert := assert.New(t)
+
+	// Shares of a 16-bit counter take at most 9 bytes
+	var encoded []byte
+	shares := ShareFiniteField(big.NewInt(4242), big.NewInt(65521), 2, 5)
+	for _, share := range shares {
+		before := len(encoded)
+		encoded = AppendCompact(encoded, share)
+		assert.LessOrEqual(len(encoded)-before, 9)
+	}
+	r := bytes.NewReader(encoded)
+	for _, share := range shares {
+		decoded, err := ReadCompact(r)
+		assert.NoError(err)
+		assert.Equal(share, decoded)
+	}
+	_, err := ReadCompact(r)
+	assert.Equal(io.EOF, err)
+
+	// Large and negative integers, bounds, fingerprints and missing values round trip
+	signed, err := ShareSignedIntegers(big.NewInt(-1000), big.NewInt(1<<40), 80, 3, 6)
+	assert.NoError(err)
+	_, commitments := ShareFeldman(big.NewInt(5), largeTestGroup.Generator(), 1, 3)
+	fingerprinted := WithSharingFingerprint(ShareFiniteField(big.NewInt(5), largeTestGroup.Q, 1, 3), commitments.SharingFingerprint())
+	for _, share := range append(append(signed, fingerprinted...), Share{}, Share{X: -3, Y: big.NewInt(-1 << 61)}) {
+		decoded, err := ReadCompact(bytes.NewReader(AppendCompact(nil, share)))
+		assert.NoError(err)
+		assert.Equal(0, decoded.Y.Cmp(share.Y), "%v", share)
+		decoded.Y = share.Y
+		assert.Equal(share, decoded)
+	}
+
+	encoded = AppendCompact(nil, signed[0])
+	for i := 1; i < len(encoded); i++ {
+		_, err = ReadCompact(bytes.NewReader(encoded[:i]))
+		assert.Equal(ErrorInvalidCompact, err)
+	}
+	for _, malformed := range [][]byte{{0x20}, {0x01, 0xff, 0xff, 0xff, 0xff, 0x0f}} {
+		_, err = ReadCompact(bytes.NewReader(malformed))
+		assert.Equal(ErrorInvalidCompact, err)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The comparison protocol in this file follows Catrina and de Hoogh, "Improved primitives for
+// secure multiparty integer computation" (SCN 2010). A value is compared to zero by truncating it,
+// which in turn opens the value masked with a statistically hiding random mask whose low bits are
+// known in shared form, and compares the low bits of the opened value to those of the mask.
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorFieldTooSmall = errors.New("Field is too small for this operation")
+)
+
+// comparisonSecurity is the statistical security parameter, in bits, of the comparison protocol.
+const comparisonSecurity = 40
+
+// LessThan returns shares of the bits a[i] < b[i], for secrets in [0, 2^bits). The field size must
+// exceed (nParties+2) * 2^(bits+41). The secrets are statistically hidden with 40 bits of security.
+func (p *Party) LessThan(a ShareVector, b ShareVector, bits int) (ShareVector, error) {
+	if len(a) != len(b) {
+		return nil, ErrorVectorLength
+	}
+	if err := p.checkComparisonField(bits + 1); err != nil {
+		return nil, err
+	}
+
+	differences := make(ShareVector, len(a))
+	for i := range a {
+		var err error
+		differences[i], err = ShareAdd([]Share{a[i], ShareMulConstant(b[i], big.NewInt(-1))})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p.lessThanZero(differences, bits+1)
+}
+
+// LessThanConstant returns shares of the bits a[i] < c[i] for public constants c[i], for secrets
+// and constants in [0, 2^bits). It is a convenience wrapper that subtracts the constants locally
+// and runs the same protocol as LessThan, so it has the same cost and field size requirement; it
+// only spares the caller from sharing the constants first. All parties must pass the same
+// constants. For the bits a[i] >= c[i], subtract the result from 1 with ShareMulConstant and
+// ShareAddConstant.
+func (p *Party) LessThanConstant(a ShareVector, c []*big.Int, bits int) (ShareVector, error) {
+	if len(a) != len(c) {
+		return nil, ErrorVectorLength
+	}
+	if err := p.checkComparisonField(bits + 1); err != nil {
+		return nil, err
+	}
+	differences := make(ShareVector, len(a))
+	for i := range a {
+		differences[i] = ShareAddConstant(a[i], big.NewInt(0).Neg(c[i]))
+	}
+	return p.lessThanZero(differences, bits+1)
+}
+
+// checkComparisonField returns ErrorFieldTooSmall unless the field size exceeds
+// (nParties+2) * 2^(k+40), so that comparisons of k-bit secrets are statistically hidden.
+func (p *Party) checkComparisonField(k int) error {
+	bound := big.NewInt(int64(p.nParties + 2))
+	bound.Lsh(bound, uint(k+comparisonSecurity))
+	if p.fieldSize.Cmp(bound) <= 0 {
+		return ErrorFieldTooSmall
+	}
+	return nil
+}
+
+// lessThanZero returns shares of the bits a[i] < 0, for secrets in [-2^(k-1), 2^(k-1)).
+func (p *Party) lessThanZero(a ShareVector, k int) (ShareVector, error) {
+	// a >> (k-1) equals -1 for negative a and 0 otherwise
+	truncated, err := p.truncate(a, k, k-1)
+	if err != nil {
+		return nil, err
+	}
+	for i := range truncated {
+		truncated[i] = ShareMulConstant(truncated[i], big.NewInt(-1))
+	}
+	return truncated, nil
+}
+
+// truncate returns shares of floor(a[i] / 2^m), for secrets in [-2^(k-1), 2^(k-1)).
+func (p *Party) truncate(a ShareVector, k int, m int) (ShareVector, error) {
+	remainders, err := p.mod2m(a, k, m)
+	if err != nil {
+		return nil, err
+	}
+	inverse := big.NewInt(1)
+	inverse.Lsh(inverse, uint(m)).ModInverse(inverse, p.fieldSize)
+	truncated := make(ShareVector, len(a))
+	for i := range a {
+		truncated[i], err = ShareAdd([]Share{a[i], ShareMulConstant(remainders[i], big.NewInt(-1))})
+		if err != nil {
+			return nil, err
+		}
+		truncated[i] = ShareMulConstant(truncated[i], inverse)
+	}
+	return truncated, nil
+}
+
+// mod2m returns shares of a[i] mod 2^m, for secrets in [-2^(k-1), 2^(k-1)).
+func (p *Party) mod2m(a ShareVector, k int, m int) (ShareVector, error) {
+	maskBits, err := p.RandomBits(len(a) * m)
+	if err != nil {
+		return nil, err
+	}
+	highBound := big.NewInt(1)
+	highBound.Lsh(highBound, uint(k+comparisonSecurity-m))
+	highMasks, err := p.randomBelow(len(a), highBound)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open 2^(k-1) + a + 2^m r'' + r', where r' are the low bits of the mask
+	offset := big.NewInt(1)
+	offset.Lsh(offset, uint(k-1))
+	twoM := big.NewInt(1)
+	twoM.Lsh(twoM, uint(m))
+	lowMasks := make(ShareVector, len(a))
+	masked := make(ShareVector, len(a))
+	for i := range a {
+		terms := make([]Share, 0, m+1)
+		for j := 0; j < m; j++ {
+			terms = append(terms, ShareMulConstant(maskBits[i*m+j], big.NewInt(0).Lsh(big.NewInt(1), uint(j))))
+		}
+		lowMasks[i], err = ShareAdd(terms)
+		if err != nil {
+			return nil, err
+		}
+		masked[i], err = ShareAdd([]Share{a[i], lowMasks[i], ShareMulConstant(highMasks[i], twoM)})
+		if err != nil {
+			return nil, err
+		}
+		masked[i] = ShareAddConstant(masked[i], offset)
+	}
+	opened, err := p.Open(masked)
+	if err != nil {
+		return nil, err
+	}
+
+	// a mod 2^m = (c mod 2^m) - r' + 2^m [c mod 2^m < r']
+	low := make([]*big.Int, len(a))
+	for i := range opened {
+		low[i] = big.NewInt(0).Mod(opened[i], twoM)
+	}
+	underflows, err := p.bitLessThan(low, maskBits, m)
+	if err != nil {
+		return nil, err
+	}
+	remainders := make(ShareVector, len(a))
+	for i := range a {
+		remainders[i], err = ShareAdd([]Share{ShareMulConstant(underflows[i], twoM), ShareMulConstant(lowMasks[i], big.NewInt(-1))})
+		if err != nil {
+			return nil, err
+		}
+		remainders[i] = ShareAddConstant(remainders[i], low[i])
+	}
+	return remainders, nil
+}
+
+// bitLessThan returns shares of the bits c[i] < r[i], where c[i] are public m-bit integers and
+// r[i*m+j] is bit j of the shared r[i].
+func (p *Party) bitLessThan(c []*big.Int, r ShareVector, m int) (ShareVector, error) {
+	// Scanning from the least significant bit, the result becomes r_j if c_j != r_j, and otherwise
+	// stays the same: lt = (1 - c_j) r_j + (1 - (c_j xor r_j)) lt
+	var lessThan ShareVector
+	one := big.NewInt(1)
+	for j := 0; j < m; j++ {
+		differs := make(ShareVector, len(c))
+		equal := make(ShareVector, len(c))
+		for i := range c {
+			bit := r[i*m+j]
+			if c[i].Bit(j) == 0 {
+				differs[i] = bit
+				equal[i] = ShareAddConstant(ShareMulConstant(bit, big.NewInt(-1)), one)
+			} else {
+				differs[i] = ShareMulConstant(bit, big.NewInt(0))
+				equal[i] = bit
+			}
+		}
+		if lessThan == nil {
+			lessThan = differs
+			continue
+		}
+		kept, err := p.Mul(equal, lessThan)
+		if err != nil {
+			return nil, err
+		}
+		lessThan, err = ShareVectorAdd([]ShareVector{differs, kept})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if lessThan == nil {
+		return p.constant(big.NewInt(0), len(c)), nil
+	}
+	return lessThan, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLessThan(t *testing.T) {
+	assert := assert.New(t)
+	a := bigInts(0, 0, 1, 5, 200, 255, 128, 127)
+	b := bigInts(0, 1, 0, 5, 100, 0, 127, 128)
+	results := runParties(t, 3, 1, mersenne61, func(p *Party) ([]*big.Int, error) {
+		x, err := p.Input(1, a, len(a))
+		if err != nil {
+			return nil, err
+		}
+		y, err := p.Input(2, b, len(b))
+		if err != nil {
+			return nil, err
+		}
+		lt, err := p.LessThan(x, y, 8)
+		if err != nil {
+			return nil, err
+		}
+		return p.Open(lt)
+	})
+	assert.Equal(bigInts(0, 1, 0, 0, 0, 0, 0, 1), results[0])
+}
+
+func TestLessThanFieldTooSmall(t *testing.T) {
+	assert := assert.New(t)
+	p := NewParty(1, 3, big.NewInt(7919), 1, nil)
+	shares := ShareVectorFiniteField(bigInts(1), big.NewInt(7919), 1, 3)
+	_, err := p.LessThan(shares[0], shares[0], 8)
+	assert.Equal(ErrorFieldTooSmall, err)
+	_, err = p.LessThan(shares[0], nil, 8)
+	assert.Equal(ErrorVectorLength, err)
+}
+
+func TestLessThanConstant(t *testing.T) {
+	assert := assert.New(t)
+	a := bigInts(0, 0, 1, 5, 200, 255, 128, 127)
+	c := bigInts(0, 1, 0, 5, 100, 0, 127, 128)
+	results := runParties(t, 3, 1, mersenne61, func(p *Party) ([]*big.Int, error) {
+		x, err := p.Input(1, a, len(a))
+		if err != nil {
+			return nil, err
+		}
+		lt, err := p.LessThanConstant(x, c, 8)
+		if err != nil {
+			return nil, err
+		}
+		return p.Open(lt)
+	})
+	assert.Equal(bigInts(0, 1, 0, 0, 0, 0, 0, 1), results[0])
+
+	p := NewParty(1, 3, big.NewInt(7919), 1, nil)
+	shares := ShareVectorFiniteField(bigInts(1), big.NewInt(7919), 1, 3)
+	_, err := p.LessThanConstant(shares[0], bigInts(2), 8)
+	assert.Equal(ErrorFieldTooSmall, err)
+	_, err = p.LessThanConstant(shares[0], nil, 8)
+	assert.Equal(ErrorVectorLength, err)
+}
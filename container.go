@@ -0,0 +1,413 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The container format stores the shares of a single custodian of a stream that was split into
+// chunks. All integers are big-endian and all big integers are length-prefixed magnitudes.
+//
+//	header:  "SHAMIRC1" | fieldSize | uint64 degree | uint64 nShares | uint64 X | uint64 chunkSize
+//	chunk:   'C' | uint64 index | uint64 length | uint64 count | count * Y | SHA-256 of the header hash and the above
+//	trailer: 'E' | uint64 total length | uint64 chunk count | SHA-256 of the header hash and all chunk hashes
+//
+// Y values are padded to the byte length of the field size, so containers of the same stream have
+// the same size for every custodian.
+//
+// The header hash is the SHA-256 of the header. The chunk hashes and the manifest hash in the
+// trailer detect corrupted, truncated or reordered container files, and since both include the
+// header hash, a corrupted header is detected at the first chunk. They cover the header and the
+// shares only, so they reveal nothing about the stream.
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	ErrorInvalidContainer  = errors.New("Container is malformed")
+	ErrorChecksumMismatch  = errors.New("Container checksum does not match")
+	ErrorContainerMismatch = errors.New("Containers belong to different streams")
+)
+
+var containerMagic = []byte("SHAMIRC1")
+
+const (
+	containerChunkTag   = 'C'
+	containerTrailerTag = 'E'
+)
+
+// maxContainerChunkSize bounds the chunk size of a container, so that the chunk size in the header
+// of a forged container cannot make the reader allocate arbitrary amounts of memory.
+const maxContainerChunkSize = 4 << 20
+
+// A ContainerHeader describes the sharing of a stream in the container of a single custodian.
+type ContainerHeader struct {
+	FieldSize *big.Int
+	Degree    int
+	NShares   int
+	X         int
+	ChunkSize int
+}
+
+// SplitStream reads a stream of any size from r, splits it into chunks of chunkSize bytes and shares
+// every chunk over a finite field like SharePayload. It writes a container for every custodian to
+// the corresponding writer, so len(custodians) shares are produced. Only a single chunk is held in
+// memory at a time, so this is suitable for multi-gigabyte files. The chunk size must be at most
+// 4 MiB.
+func SplitStream(r io.Reader, custodians []io.Writer, fieldSize *big.Int, degree int, chunkSize int) error {
+	if elementSize(fieldSize) < 1 {
+		return ErrorFieldTooSmall
+	}
+	if chunkSize < 1 || chunkSize > maxContainerChunkSize || len(custodians) == 0 {
+		return ErrorInvalidPayload
+	}
+	writers := make([]*containerWriter, len(custodians))
+	for i := range custodians {
+		writers[i] = newContainerWriter(custodians[i], ContainerHeader{
+			FieldSize: fieldSize,
+			Degree:    degree,
+			NShares:   len(custodians),
+			X:         i + 1,
+			ChunkSize: chunkSize,
+		})
+	}
+
+	chunk := make([]byte, chunkSize)
+	for index := 0; ; index++ {
+		n, err := io.ReadFull(r, chunk)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		vectors := ShareVectorFiniteField(encodeBytes(chunk[:n], fieldSize), fieldSize, degree, len(custodians))
+		for i := range writers {
+			writers[i].writeChunk(index, n, vectors[i])
+		}
+		if err == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+	for i := range writers {
+		if err := writers[i].close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CombineStreams reads the containers of several custodians, written by SplitStream, and writes the
+// recovered stream to w chunk by chunk. It verifies the checksums of all containers and returns
+// ErrorChecksumMismatch if any of them is corrupted. Note that chunks may have been written to w
+// before an error is detected.
+func CombineStreams(custodians []io.Reader, w io.Writer) error {
+	_, err := combineContainers(custodians, w, false)
+	return err
+}
+
+// CombineStreamsChecked recovers a stream like CombineStreams, but also checks that the shares of
+// every chunk of all custodians lie on polynomials of the degree of the sharing, which requires more
+// than degree+1 custodians to have any effect. It aborts at the first inconsistent chunk with
+// ErrorInconsistentShares, without writing that chunk, and reports the number of bytes written to w
+// before, which is the offset of the chunk in the stream. Use this to stop reading multi-gigabyte
+// containers as soon as a custodian turns out to hold shares of another stream or wrong shares with
+// valid checksums.
+func CombineStreamsChecked(custodians []io.Reader, w io.Writer) (int64, error) {
+	return combineContainers(custodians, w, true)
+}
+
+// combineContainers combines the containers of the custodians and writes the stream to w, checking
+// the consistency of every chunk if check is set. It returns the number of bytes written.
+func combineContainers(custodians []io.Reader, w io.Writer, check bool) (int64, error) {
+	if len(custodians) == 0 {
+		return 0, ErrorNoShares
+	}
+	readers := make([]*containerReader, len(custodians))
+	seen := make(map[int]bool, len(custodians))
+	for i := range custodians {
+		var err error
+		readers[i], err = newContainerReader(custodians[i])
+		if err != nil {
+			return 0, err
+		}
+		header, first := readers[i].header, readers[0].header
+		if header.FieldSize.Cmp(first.FieldSize) != 0 || header.Degree != first.Degree || header.NShares != first.NShares || header.ChunkSize != first.ChunkSize {
+			return 0, ErrorContainerMismatch
+		}
+		if seen[header.X] {
+			return 0, ErrorDuplicateX
+		}
+		seen[header.X] = true
+	}
+
+	var written int64
+	vectors := make([]ShareVector, len(readers))
+	for index := 0; ; index++ {
+		done, length := false, 0
+		for i, reader := range readers {
+			var chunkLength int
+			var err error
+			vectors[i], chunkLength, err = reader.readChunk(index)
+			if err != nil {
+				return written, err
+			}
+			if i > 0 && ((vectors[i] == nil) != done || chunkLength != length) {
+				return written, ErrorContainerMismatch
+			}
+			done, length = vectors[i] == nil, chunkLength
+		}
+		if done {
+			break
+		}
+		if check && !consistentChunk(vectors) {
+			return written, ErrorInconsistentShares
+		}
+		elements, err := CombineVector(vectors)
+		if err != nil {
+			return written, err
+		}
+		chunk, err := decodeBytes(elements, readers[0].header.FieldSize, length)
+		if err != nil {
+			return written, err
+		}
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// consistentChunk reports whether the shares of every element of a chunk lie on a polynomial of the
+// degree of the shares.
+func consistentChunk(vectors []ShareVector) bool {
+	degree := vectors[0][0].Degree
+	if len(vectors) <= degree+1 {
+		return true
+	}
+	shares := make([]Share, len(vectors))
+	for j := range vectors[0] {
+		for i := range vectors {
+			shares[i] = vectors[i][j]
+		}
+		for _, share := range shares[degree+1:] {
+			if !onPolynomial(shares[:degree+1], share) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// containerWriter writes a container. Write errors are recorded by the buffered writer and reported
+// by close.
+type containerWriter struct {
+	w        *bufio.Writer
+	header   ContainerHeader
+	hash     []byte
+	manifest []byte
+	total    int
+	chunks   int
+}
+
+func newContainerWriter(w io.Writer, header ContainerHeader) *containerWriter {
+	var record bytes.Buffer
+	record.Write(containerMagic)
+	writeBytes(&record, header.FieldSize.Bytes())
+	writeUint64(&record, uint64(header.Degree))
+	writeUint64(&record, uint64(header.NShares))
+	writeUint64(&record, uint64(header.X))
+	writeUint64(&record, uint64(header.ChunkSize))
+	hash := sha256.Sum256(record.Bytes())
+	cw := &containerWriter{w: bufio.NewWriter(w), header: header, hash: hash[:], manifest: hash[:]}
+	cw.w.Write(record.Bytes())
+	return cw
+}
+
+func (cw *containerWriter) writeChunk(index int, length int, v ShareVector) {
+	var record bytes.Buffer
+	record.WriteByte(containerChunkTag)
+	writeUint64(&record, uint64(index))
+	writeUint64(&record, uint64(length))
+	writeUint64(&record, uint64(len(v)))
+	for _, share := range v {
+		writeBytes(&record, fieldElementBytes(share.Y, cw.header.FieldSize))
+	}
+	digest := chunkDigest(cw.hash, record.Bytes())
+	cw.w.Write(record.Bytes())
+	cw.w.Write(digest[:])
+	cw.manifest = append(cw.manifest, digest[:]...)
+	cw.total += length
+	cw.chunks++
+}
+
+func (cw *containerWriter) close() error {
+	digest := sha256.Sum256(cw.manifest)
+	cw.w.WriteByte(containerTrailerTag)
+	writeUint64(cw.w, uint64(cw.total))
+	writeUint64(cw.w, uint64(cw.chunks))
+	cw.w.Write(digest[:])
+	return cw.w.Flush()
+}
+
+// containerReader reads a container and verifies its checksums.
+type containerReader struct {
+	r        *bufio.Reader
+	header   ContainerHeader
+	hash     []byte
+	manifest []byte
+	total    int
+}
+
+func newContainerReader(r io.Reader) (*containerReader, error) {
+	cr := &containerReader{r: bufio.NewReader(r)}
+	var record bytes.Buffer
+	hr := io.TeeReader(cr.r, &record)
+	magic := make([]byte, len(containerMagic))
+	if _, err := io.ReadFull(hr, magic); err != nil || !bytes.Equal(magic, containerMagic) {
+		return nil, ErrorInvalidContainer
+	}
+	fieldSize, err := readBytes(hr, 1<<16)
+	if err != nil {
+		return nil, err
+	}
+	cr.header.FieldSize = big.NewInt(0).SetBytes(fieldSize)
+	var fields [4]uint64
+	for i := range fields {
+		if fields[i], err = readUint64(hr); err != nil {
+			return nil, err
+		}
+	}
+	hash := sha256.Sum256(record.Bytes())
+	cr.hash, cr.manifest = hash[:], hash[:]
+	cr.header.Degree, cr.header.NShares, cr.header.X, cr.header.ChunkSize = int(fields[0]), int(fields[1]), int(fields[2]), int(fields[3])
+	if elementSize(cr.header.FieldSize) < 1 || fields[3] < 1 || fields[3] > maxContainerChunkSize ||
+		cr.header.X < 1 || cr.header.X > cr.header.NShares || cr.header.Degree < 0 {
+		return nil, ErrorInvalidContainer
+	}
+	return cr, nil
+}
+
+// readChunk reads the chunk with the given index and returns the shares and the length of the
+// chunk. At the trailer, it verifies the manifest and returns a nil vector.
+func (cr *containerReader) readChunk(index int) (ShareVector, int, error) {
+	tag, err := cr.r.ReadByte()
+	if err != nil {
+		return nil, 0, ErrorInvalidContainer
+	}
+	switch tag {
+	case containerChunkTag:
+	case containerTrailerTag:
+		return nil, 0, cr.readTrailer(index)
+	default:
+		return nil, 0, ErrorInvalidContainer
+	}
+
+	var record bytes.Buffer
+	record.WriteByte(tag)
+	r := io.TeeReader(cr.r, &record)
+	var fields [3]uint64
+	for i := range fields {
+		if fields[i], err = readUint64(r); err != nil {
+			return nil, 0, err
+		}
+	}
+	size := elementSize(cr.header.FieldSize)
+	if fields[0] != uint64(index) || fields[1] < 1 || fields[1] > uint64(cr.header.ChunkSize) || fields[2] != (fields[1]+uint64(size)-1)/uint64(size) {
+		return nil, 0, ErrorInvalidContainer
+	}
+	v := make(ShareVector, fields[2])
+	maxLength := (cr.header.FieldSize.BitLen() + 7) / 8
+	for j := range v {
+		y, err := readBytes(r, maxLength)
+		if err != nil {
+			return nil, 0, err
+		}
+		v[j] = Share{FieldSize: cr.header.FieldSize, Degree: cr.header.Degree, X: cr.header.X, Y: big.NewInt(0).SetBytes(y)}
+	}
+	digest := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(cr.r, digest); err != nil {
+		return nil, 0, ErrorInvalidContainer
+	}
+	expected := chunkDigest(cr.hash, record.Bytes())
+	if !bytes.Equal(digest, expected[:]) {
+		return nil, 0, ErrorChecksumMismatch
+	}
+	cr.manifest = append(cr.manifest, digest...)
+	cr.total += int(fields[1])
+	return v, int(fields[1]), nil
+}
+
+func (cr *containerReader) readTrailer(chunks int) error {
+	total, err := readUint64(cr.r)
+	if err != nil {
+		return err
+	}
+	count, err := readUint64(cr.r)
+	if err != nil {
+		return err
+	}
+	digest := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(cr.r, digest); err != nil {
+		return ErrorInvalidContainer
+	}
+	expected := sha256.Sum256(cr.manifest)
+	if total != uint64(cr.total) || count != uint64(chunks) || !bytes.Equal(digest, expected[:]) {
+		return ErrorChecksumMismatch
+	}
+	return nil
+}
+
+// chunkDigest returns the hash of a chunk record in the container with the given header hash.
+func chunkDigest(headerHash []byte, record []byte) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write(headerHash)
+	h.Write(record)
+	var digest [sha256.Size]byte
+	h.Sum(digest[:0])
+	return digest
+}
+
+// readBytes reads data written by writeBytes, of at most maxLength bytes.
+func readBytes(r io.Reader, maxLength int) ([]byte, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, ErrorInvalidContainer
+	}
+	length := binary.BigEndian.Uint32(buf[:])
+	if length > uint32(maxLength) {
+		return nil, ErrorInvalidContainer
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, ErrorInvalidContainer
+	}
+	return b, nil
+}
+
+// readUint64 reads data written by writeUint64.
+func readUint64(r io.Reader) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, ErrorInvalidContainer
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// splitStream splits data into containers for nShares custodians.
+func splitStream(t *testing.T, data []byte, degree int, nShares int, chunkSize int) [][]byte {
+	buffers := make([]bytes.Buffer, nShares)
+	writers := make([]io.Writer, nShares)
+	for i := range buffers {
+		writers[i] = &buffers[i]
+	}
+	assert.NoError(t, SplitStream(bytes.NewReader(data), writers, mersenne61, degree, chunkSize))
+	containers := make([][]byte, nShares)
+	for i := range buffers {
+		containers[i] = buffers[i].Bytes()
+	}
+	return containers
+}
+
+// combineStreams combines the given containers.
+func combineStreams(containers ...[]byte) ([]byte, error) {
+	readers := make([]io.Reader, len(containers))
+	for i := range containers {
+		readers[i] = bytes.NewReader(containers[i])
+	}
+	var out bytes.Buffer
+	err := CombineStreams(readers, &out)
+	return out.Bytes(), err
+}
+
+func TestSplitStream(t *testing.T) {
+	assert := assert.New(t)
+	for _, size := range []int{0, 1, 99, 100, 101, 1000} {
+		data := make([]byte, size)
+		rand.Read(data)
+		containers := splitStream(t, data, 2, 5, 100)
+		for _, container := range containers {
+			assert.Equal(len(containers[0]), len(container))
+		}
+
+		recovered, err := combineStreams(containers[4], containers[1], containers[2])
+		assert.NoError(err)
+		assert.Equal(len(data), len(recovered))
+		assert.True(bytes.Equal(data, recovered))
+
+		recovered, err = combineStreams(containers...)
+		assert.NoError(err)
+		assert.True(bytes.Equal(data, recovered))
+	}
+}
+
+func TestCombineStreamsErrors(t *testing.T) {
+	assert := assert.New(t)
+	data := make([]byte, 500)
+	rand.Read(data)
+	containers := splitStream(t, data, 1, 3, 64)
+
+	_, err := combineStreams()
+	assert.Equal(ErrorNoShares, err)
+	_, err = combineStreams(containers[0])
+	assert.Equal(ErrorTooFewShares, err)
+	_, err = combineStreams(containers[0], containers[0])
+	assert.Equal(ErrorDuplicateX, err)
+	_, err = combineStreams(containers[0], []byte("not a container"))
+	assert.Equal(ErrorInvalidContainer, err)
+
+	// Corrupting a chunk is detected by its checksum, which precedes the 49-byte trailer
+	corrupted := append([]byte{}, containers[1]...)
+	corrupted[len(corrupted)-50] ^= 1
+	_, err = combineStreams(containers[0], corrupted)
+	assert.Equal(ErrorChecksumMismatch, err)
+
+	// Truncation is detected
+	_, err = combineStreams(containers[0], containers[1][:len(containers[1])-100])
+	assert.Equal(ErrorInvalidContainer, err)
+
+	// Containers of another stream
+	other := splitStream(t, data, 1, 3, 32)
+	_, err = combineStreams(containers[0], other[1])
+	assert.Equal(ErrorContainerMismatch, err)
+
+	// A forged chunk size, the last field of the header, is rejected before allocating a chunk
+	forged := append([]byte{}, containers[0]...)
+	offset := len(containerMagic) + 4 + 8 + 4*8
+	binary.BigEndian.PutUint64(forged[offset:], 1<<40)
+	_, err = combineStreams(forged, containers[1])
+	assert.Equal(ErrorInvalidContainer, err)
+	assert.Equal(ErrorInvalidPayload, SplitStream(bytes.NewReader(data), []io.Writer{&bytes.Buffer{}}, mersenne61, 0, maxContainerChunkSize+1))
+
+	// Swapping the X coordinates in the headers, the last byte of which follows the magic, the
+	// field size of mersenne61 and three uint64 fields, is detected by the chunk hashes
+	swapped := [][]byte{append([]byte{}, containers[0]...), append([]byte{}, containers[1]...)}
+	offset = len(containerMagic) + 4 + 8 + 3*8 - 1
+	swapped[0][offset], swapped[1][offset] = swapped[1][offset], swapped[0][offset]
+	_, err = combineStreams(swapped...)
+	assert.Equal(ErrorChecksumMismatch, err)
+}
+
+func TestCombineStreamsChecked(t *testing.T) {
+	assert := assert.New(t)
+	data := make([]byte, 500)
+	rand.Read(data)
+	containers := splitStream(t, data, 1, 3, 64)
+	readers := func(containers ...[]byte) []io.Reader {
+		readers := make([]io.Reader, len(containers))
+		for i := range containers {
+			readers[i] = bytes.NewReader(containers[i])
+		}
+		return readers
+	}
+
+	var out bytes.Buffer
+	written, err := CombineStreamsChecked(readers(containers...), &out)
+	assert.NoError(err)
+	assert.Equal(int64(500), written)
+	assert.True(bytes.Equal(data, out.Bytes()))
+
+	// Rewrite the container of the third custodian with a wrong share in the chunk at offset 192,
+	// keeping the checksums valid
+	cr, err := newContainerReader(bytes.NewReader(containers[2]))
+	assert.NoError(err)
+	var rewritten bytes.Buffer
+	cw := newContainerWriter(&rewritten, cr.header)
+	for index := 0; ; index++ {
+		v, length, err := cr.readChunk(index)
+		assert.NoError(err)
+		if v == nil {
+			break
+		}
+		if index == 3 {
+			v[2].Y = big.NewInt(0).Add(v[2].Y, big.NewInt(1))
+		}
+		cw.writeChunk(index, length, v)
+	}
+	assert.NoError(cw.close())
+
+	out.Reset()
+	written, err = CombineStreamsChecked(readers(containers[0], containers[1], rewritten.Bytes()), &out)
+	assert.Equal(ErrorInconsistentShares, err)
+	assert.Equal(int64(192), written)
+	assert.True(bytes.Equal(data[:192], out.Bytes()))
+
+	// CombineStreams uses the first two custodians only and does not notice
+	recovered, err := combineStreams(containers[0], containers[1], rewritten.Bytes())
+	assert.NoError(err)
+	assert.True(bytes.Equal(data, recovered))
+
+	// With degree+1 custodians, there is nothing to check
+	written, err = CombineStreamsChecked(readers(containers[1], rewritten.Bytes()), &bytes.Buffer{})
+	assert.NoError(err)
+	assert.Equal(int64(500), written)
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// A PartialDecapsulator returns the partial result of the committee member with X coordinate x for
+// an encapsulation, see DecapsulateShare. Implementations usually ask the member over the network,
+// for instance with pool.RemoteDecapsulator.
+type PartialDecapsulator func(x int, encapsulation GroupElement) (GroupElement, error)
+
+// A CommitteeDecrypter is a crypto.Decrypter for a key held by a committee, so that existing code
+// can decrypt with a threshold-protected key. Decrypt gathers the partial results of the members
+// and combines them; no single party, including the caller, ever holds the key.
+type CommitteeDecrypter struct {
+	publicKey GroupElement
+	decode    func([]byte) (GroupElement, error)
+	members   []int
+	degree    int
+	partial   PartialDecapsulator
+}
+
+// NewCommitteeDecrypter returns a decrypter for the committee with the given public key and
+// members, whose shares are of the given degree. The decode function parses encapsulations, for
+// instance ModPGroup.Decode, and partial obtains the partial results of the members.
+func NewCommitteeDecrypter(publicKey GroupElement, decode func([]byte) (GroupElement, error), members []int, degree int, partial PartialDecapsulator) *CommitteeDecrypter {
+	return &CommitteeDecrypter{publicKey: publicKey, decode: decode, members: members, degree: degree, partial: partial}
+}
+
+// Public returns the public key of the committee, a GroupElement.
+func (d *CommitteeDecrypter) Public() crypto.PublicKey {
+	return d.publicKey
+}
+
+// Decrypt decrypts a ciphertext produced by SealToCommittee. The random source and options are
+// ignored. All members are asked concurrently, and members that fail or return wrong partial results
+// are tolerated as long as degree+1 members answer correctly. It returns ErrorDecryption if the
+// ciphertext is malformed or no quorum of partial results decrypts it, and ErrorTooFewShares if
+// fewer than degree+1 members answer.
+func (d *CommitteeDecrypter) Decrypt(_ io.Reader, ciphertext []byte, _ crypto.DecrypterOpts) ([]byte, error) {
+	if len(ciphertext) < 4 || uint64(binary.BigEndian.Uint32(ciphertext)) > uint64(len(ciphertext)-4) {
+		return nil, ErrorDecryption
+	}
+	size := int(binary.BigEndian.Uint32(ciphertext))
+	encapsulation, err := d.decode(ciphertext[4 : 4+size])
+	if err != nil {
+		return nil, ErrorDecryption
+	}
+	sealed := ciphertext[4+size:]
+
+	results := make([]GroupElement, len(d.members))
+	errs := make([]error, len(d.members))
+	var wg sync.WaitGroup
+	for i, x := range d.members {
+		wg.Add(1)
+		go func(i int, x int) {
+			defer wg.Done()
+			results[i], errs[i] = d.partial(x, encapsulation)
+		}(i, x)
+	}
+	wg.Wait()
+	var partials []GroupElement
+	var xs []int
+	var firstErr error
+	for i, result := range results {
+		if errs[i] != nil || result == nil {
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		partials = append(partials, result)
+		xs = append(xs, d.members[i])
+	}
+	if len(partials) < d.degree+1 {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, ErrorTooFewShares
+	}
+
+	// Wrong partial results are only detected by the authenticated encryption, so try quorums until
+	// one decrypts; with honest members the first one does.
+	var message []byte
+	found := searchQuorums(len(partials), d.degree+1, func(quorum []int) bool {
+		selected := make([]GroupElement, len(quorum))
+		selectedXs := make([]int, len(quorum))
+		for i, j := range quorum {
+			selected[i], selectedXs[i] = partials[j], xs[j]
+		}
+		message, err = DecryptFromCommittee(encapsulation, sealed, selected, selectedXs)
+		return err == nil
+	})
+	if found {
+		return message, nil
+	}
+	return nil, ErrorDecryption
+}
+
+// SealToCommittee encrypts a message with EncryptToCommittee and encodes the encapsulation and the
+// ciphertext into a single byte string, as expected by CommitteeDecrypter.
+func SealToCommittee(publicKey GroupElement, generator GroupElement, message []byte) ([]byte, error) {
+	encapsulation, ciphertext, err := EncryptToCommittee(publicKey, generator, message)
+	if err != nil {
+		return nil, err
+	}
+	encoded := encapsulation.Bytes()
+	sealed := make([]byte, 4, 4+len(encoded)+len(ciphertext))
+	binary.BigEndian.PutUint32(sealed, uint32(len(encoded)))
+	return append(append(sealed, encoded...), ciphertext...), nil
+}
+
+// searchQuorums calls try for the subsets of size elements of 0, ..., n-1, in lexicographic order,
+// until it returns true, and reports whether it did.
+func searchQuorums(n int, size int, try func(quorum []int) bool) bool {
+	quorum := make([]int, 0, size)
+	var search func(start int) bool
+	search = func(start int) bool {
+		if len(quorum) == size {
+			return try(quorum)
+		}
+		for i := start; i < n; i++ {
+			quorum = append(quorum, i)
+			if search(i + 1) {
+				return true
+			}
+			quorum = quorum[:len(quorum)-1]
+		}
+		return false
+	}
+	return search(0)
+}
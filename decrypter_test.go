@@ -0,0 +1,73 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitteeDecrypter(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(123456), g, 2, 5)
+	message := []byte("Only three of five can read this")
+	ciphertext, err := SealToCommittee(commitments[0], g, message)
+	assert.NoError(err)
+
+	offline := errors.New("offline")
+	var wrong, down map[int]bool
+	partial := func(x int, encapsulation GroupElement) (GroupElement, error) {
+		if down[x] {
+			return nil, offline
+		}
+		result, err := DecapsulateShare(shares[x-1], encapsulation)
+		if wrong[x] {
+			result = result.Add(g.ScalarMult(big.NewInt(int64(x))))
+		}
+		return result, err
+	}
+	var decrypter crypto.Decrypter = NewCommitteeDecrypter(commitments[0], largeTestGroup.Decode, []int{1, 2, 3, 4, 5}, 2, partial)
+	assert.True(commitments[0].Equal(decrypter.Public().(GroupElement)))
+	decrypted, err := decrypter.Decrypt(nil, ciphertext, nil)
+	assert.NoError(err)
+	assert.Equal(message, decrypted)
+
+	// Failing and cheating members are tolerated as long as a quorum is honest
+	wrong, down = map[int]bool{1: true}, map[int]bool{4: true}
+	decrypted, err = decrypter.Decrypt(nil, ciphertext, nil)
+	assert.NoError(err)
+	assert.Equal(message, decrypted)
+	wrong, down = map[int]bool{1: true, 2: true}, map[int]bool{4: true}
+	_, err = decrypter.Decrypt(nil, ciphertext, nil)
+	assert.Equal(ErrorDecryption, err)
+	wrong, down = nil, map[int]bool{1: true, 2: true, 3: true}
+	_, err = decrypter.Decrypt(nil, ciphertext, nil)
+	assert.Equal(offline, err)
+
+	// Malformed ciphertexts
+	down = nil
+	for _, malformed := range [][]byte{nil, ciphertext[:3], ciphertext[:20], append([]byte{0, 0, 1}, ciphertext[3:]...)} {
+		_, err = decrypter.Decrypt(nil, malformed, nil)
+		assert.Equal(ErrorDecryption, err)
+	}
+	ciphertext[len(ciphertext)-1] ^= 1
+	_, err = decrypter.Decrypt(nil, ciphertext, nil)
+	assert.Equal(ErrorDecryption, err)
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+)
+
+// coefficientSalt is the HKDF salt used for deriving polynomial coefficients from a seed.
+var coefficientSalt = []byte("github.com/TNO-MPC/shamir coefficients")
+
+// ShareFiniteFieldSeeded shares a secret over a finite field of integers modulo fieldSize, like
+// ShareFiniteField, but derives the coefficients of the polynomial from seed using HKDF-SHA256.
+// Dealing twice with the same seed and parameters produces the same shares, which allows a lost
+// share to be re-issued. The seed must be kept as secret as the secret itself, should contain at
+// least 32 bytes of entropy, and must never be reused for another secret.
+func ShareFiniteFieldSeeded(secret *big.Int, fieldSize *big.Int, degree int, nShares int, seed []byte) []Share {
+	return shareFiniteField(secret, fieldSize, seededCoefficients(seed, fieldSize, degree), nShares)
+}
+
+// ShareIntegersSeeded shares a secret over the integers, like ShareIntegers, but derives the
+// coefficients of the polynomial from seed using HKDF-SHA256. The same requirements on the seed as
+// for ShareFiniteFieldSeeded apply.
+func ShareIntegersSeeded(secret *big.Int, secretUpperBound *big.Int, statSecParam int, degree int, nShares int, seed []byte) []Share {
+	coefficientUpperBound := integerCoefficientBound(secretUpperBound, statSecParam, nShares)
+	return shareIntegers(secret, secretUpperBound, seededCoefficients(seed, coefficientUpperBound, degree), nShares)
+}
+
+// seededCoefficients derives degree coefficients in [0, bound) from seed. Coefficient j (counting
+// from 1) is the HKDF output for info "coefficient" || uint32(j), 16 bytes longer than bound, reduced
+// modulo bound. The extra bytes make the modulo bias negligible.
+func seededCoefficients(seed []byte, bound *big.Int, degree int) []*big.Int {
+	prk := hkdfExtract(coefficientSalt, seed)
+	length := (bound.BitLen()+7)/8 + 16
+	coefficients := make([]*big.Int, degree)
+	for j := range coefficients {
+		info := make([]byte, len("coefficient")+4)
+		copy(info, "coefficient")
+		binary.BigEndian.PutUint32(info[len("coefficient"):], uint32(j+1))
+		coefficients[j] = big.NewInt(0).SetBytes(hkdfExpand(prk, info, length))
+		coefficients[j].Mod(coefficients[j], bound)
+	}
+	return coefficients
+}
+
+// hkdfExtract implements the extract step of HKDF-SHA256 (RFC 5869).
+func hkdfExtract(salt, secret []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(secret)
+	return mac.Sum(nil)
+}
+
+// hkdfExpand implements the expand step of HKDF-SHA256 (RFC 5869).
+func hkdfExpand(prk, info []byte, length int) []byte {
+	mac := hmac.New(sha256.New, prk)
+	okm := make([]byte, 0, length+sha256.Size)
+	var block []byte
+	for counter := byte(1); len(okm) < length; counter++ {
+		mac.Reset()
+		mac.Write(block)
+		mac.Write(info)
+		mac.Write([]byte{counter})
+		block = mac.Sum(nil)
+		okm = append(okm, block...)
+	}
+	return okm[:length]
+}
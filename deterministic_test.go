@@ -0,0 +1,69 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHKDF(t *testing.T) {
+	assert := assert.New(t)
+
+	// RFC 5869, test case 1
+	ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	prk := hkdfExtract(salt, ikm)
+	assert.Equal("077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5", hex.EncodeToString(prk))
+	okm := hkdfExpand(prk, info, 42)
+	assert.Equal("3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865", hex.EncodeToString(okm))
+}
+
+func TestSeededSecretSharing(t *testing.T) {
+	assert := assert.New(t)
+	seed := []byte("a seed that is used for testing only")
+
+	shares1 := ShareFiniteFieldSeeded(big.NewInt(123), big.NewInt(7919), 3, 5, seed)
+	shares2 := ShareFiniteFieldSeeded(big.NewInt(123), big.NewInt(7919), 3, 5, seed)
+	assert.Equal(shares1, shares2)
+
+	shares3 := ShareFiniteFieldSeeded(big.NewInt(123), big.NewInt(7919), 3, 5, []byte("another seed"))
+	assert.NotEqual(shares1, shares3)
+
+	secret, err := ShareCombine(shares1[1:5])
+	assert.NoError(err)
+	if assert.NotNil(secret) {
+		assert.Equal(int64(123), secret.Int64())
+	}
+}
+
+func TestSeededIntegerSecretSharing(t *testing.T) {
+	assert := assert.New(t)
+	seed := []byte("a seed that is used for testing only")
+
+	shares1 := ShareIntegersSeeded(big.NewInt(-123), big.NewInt(10000), 100, 3, 5, seed)
+	shares2 := ShareIntegersSeeded(big.NewInt(-123), big.NewInt(10000), 100, 3, 5, seed)
+	assert.Equal(shares1, shares2)
+
+	secret, err := ShareCombine(shares1[0:4])
+	assert.NoError(err)
+	if assert.NotNil(secret) {
+		assert.Equal(int64(-123), secret.Int64())
+	}
+}
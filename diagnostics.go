@@ -0,0 +1,110 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+)
+
+// A CombineReport describes how a secret was reconstructed by CombineWithReport.
+type CombineReport struct {
+	// Used contains the X coordinates of the shares that were interpolated, and Surplus those of
+	// the remaining shares.
+	Used    []int
+	Surplus []int
+	// Inconsistent contains the X coordinates of the surplus shares that do not lie on the
+	// polynomial through the used shares. Consistent is true if there are none.
+	Inconsistent []int
+	Consistent   bool
+	// InterpolatedDegree is the lowest degree of a polynomial through all given shares. It exceeds
+	// the degree of the shares if the shares are inconsistent.
+	InterpolatedDegree int
+	// For shares over the integers, ScaledSecret is the interpolated value at zero, which is
+	// divided by Factor to obtain the secret. FactorDivides reports whether this division is exact,
+	// which it is for consistent shares.
+	Factor        *big.Int
+	ScaledSecret  *big.Int
+	FactorDivides bool
+}
+
+// CombineWithReport combines shares like ShareCombine, and additionally returns a report that makes
+// failed or suspicious reconstructions debuggable. The report is filled as far as the reconstruction
+// got.
+func CombineWithReport(shares []Share) (*big.Int, CombineReport, error) {
+	var report CombineReport
+	secret, err := emitCombine("", shares, func(shares []Share) (*big.Int, error) {
+		if err := checkCombinable(shares); err != nil {
+			return nil, err
+		}
+		used := shares[:shares[0].Degree+1]
+		for _, share := range used {
+			report.Used = append(report.Used, share.X)
+		}
+		report.Consistent = true
+		for _, share := range shares[len(used):] {
+			report.Surplus = append(report.Surplus, share.X)
+			if !onPolynomial(used, share) {
+				report.Inconsistent = append(report.Inconsistent, share.X)
+				report.Consistent = false
+			}
+		}
+		report.InterpolatedDegree = interpolatedDegree(shares)
+
+		if shares[0].FieldSize != nil {
+			return shareCombine(shares)
+		}
+		report.Factor = shares[0].Factor
+		scaled, err := interpolate(used, 0)
+		if err != nil {
+			return nil, err
+		}
+		report.ScaledSecret = scaled
+		remainder := big.NewInt(0).Mod(scaled, report.Factor)
+		report.FactorDivides = remainder.Sign() == 0
+		return shareCombine(shares)
+	})
+	return secret, report, err
+}
+
+// onPolynomial reports whether share lies on the polynomial through the points of shares.
+func onPolynomial(shares []Share, share Share) bool {
+	y, err := interpolate(shares, share.X)
+	if err != nil {
+		return false
+	}
+	expected := share.Y
+	if share.FieldSize != nil {
+		expected = big.NewInt(0).Mod(expected, share.FieldSize)
+	}
+	return y.Cmp(expected) == 0
+}
+
+// interpolatedDegree returns the lowest degree of a polynomial through the points of the shares,
+// which must have distinct X coordinates.
+func interpolatedDegree(shares []Share) int {
+	for degree := 0; degree < len(shares)-1; degree++ {
+		consistent := true
+		for _, share := range shares[degree+1:] {
+			if !onPolynomial(shares[:degree+1], share) {
+				consistent = false
+				break
+			}
+		}
+		if consistent {
+			return degree
+		}
+	}
+	return len(shares) - 1
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineWithReport(t *testing.T) {
+	assert := assert.New(t)
+	shares := shareFiniteField(big.NewInt(123), big.NewInt(7919), bigInts(5, 7), 5)
+
+	secret, report, err := CombineWithReport(shares)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(123), secret)
+	assert.Equal([]int{1, 2, 3}, report.Used)
+	assert.Equal([]int{4, 5}, report.Surplus)
+	assert.Empty(report.Inconsistent)
+	assert.True(report.Consistent)
+	assert.Equal(2, report.InterpolatedDegree)
+	assert.Nil(report.Factor)
+
+	// A share of a constant polynomial interpolates to degree 0
+	_, report, err = CombineWithReport(shareFiniteField(big.NewInt(123), big.NewInt(7919), bigInts(0, 0), 4))
+	assert.NoError(err)
+	assert.Equal(0, report.InterpolatedDegree)
+
+	// Tamper with a surplus share
+	tampered := append([]Share{}, shares...)
+	tampered[4].Y = big.NewInt(0).Add(shares[4].Y, big.NewInt(1))
+	secret, report, err = CombineWithReport(tampered)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(123), secret)
+	assert.Equal([]int{5}, report.Inconsistent)
+	assert.False(report.Consistent)
+	assert.Equal(4, report.InterpolatedDegree)
+
+	_, report, err = CombineWithReport(shares[:2])
+	assert.Equal(ErrorTooFewShares, err)
+	assert.Empty(report.Used)
+	_, _, err = CombineWithReport([]Share{shares[0], shares[1], shares[1]})
+	assert.Equal(ErrorDuplicateX, err)
+}
+
+func TestIntegerCombineWithReport(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareIntegers(big.NewInt(-42), big.NewInt(100), 40, 1, 4)
+
+	secret, report, err := CombineWithReport(shares)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(-42), secret)
+	assert.True(report.Consistent)
+	assert.Equal(1, report.InterpolatedDegree)
+	assert.Equal(big.NewInt(24), report.Factor)
+	assert.Equal(big.NewInt(-42*24), report.ScaledSecret)
+	assert.True(report.FactorDivides)
+
+	tampered := append([]Share{}, shares...)
+	tampered[0].Y = big.NewInt(0).Add(shares[0].Y, big.NewInt(2))
+	_, report, _ = CombineWithReport(tampered)
+	assert.False(report.FactorDivides)
+	assert.Equal([]int{3, 4}, report.Inconsistent)
+	assert.Equal(3, report.InterpolatedDegree)
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shamir implements Shamir secret sharing over finite fields and secret sharing over the
+// integers, together with computations on shares and protocols built on top of them.
+//
+// # Concurrency
+//
+// Unless documented otherwise, the functions of this package may be called from multiple
+// goroutines at the same time. They never modify their arguments, so shares, vectors and matrices
+// can be shared freely between goroutines as long as the caller does not modify them either; note
+// that shares may share their big.Int values, such as FieldSize, Factor and Bound, with each
+// other. The package-level settings, SetEventHandler and SetParallelism, may be changed at any
+// time; an installed EventHandler is called from multiple goroutines.
+//
+// Vault and Aggregator are safe for concurrent use, so a single instance can serve all requests
+// of a service. Transcript and Party are not: a Transcript must be protected by the caller, and a
+// Party performs a sequence of rounds that must be called from a single goroutine.
+package shamir
@@ -0,0 +1,286 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrorJSONPathNotFound = errors.New("Path does not match any field of the document")
+	ErrorInvalidJSONShare = errors.New("Invalid shared field in JSON document")
+)
+
+// jsonShareKey is the only key of the objects that replace shared fields in partial documents.
+const jsonShareKey = "$shamir"
+
+// A jsonShare is the share of a single party of a shared field, as it appears in a partial
+// document. The field size is formatted with FormatFieldSize.
+type jsonShare struct {
+	FieldSize string   `json:"fieldSize"`
+	Degree    int      `json:"degree"`
+	X         int      `json:"x"`
+	Y         []string `json:"y"`
+}
+
+// jsonSecret marks a field that is selected for sharing while building the partial documents.
+type jsonSecret struct {
+	vectors []ShareVector
+}
+
+// SplitJSON shares selected fields of a JSON document over a finite field, and returns a partial
+// document for every party. The rest of the document is left in plaintext, so that for instance a
+// configuration file remains readable while its passwords and keys are shared.
+//
+// Every path selects fields by their keys separated by dots, such as "database.password". An
+// element of an array is selected by its index, and "*" selects all keys of an object or all
+// elements of an array, as in "users.*.token". The empty path selects the whole document. Every
+// selected value, which may be an object or array itself, is shared with ShareBytes as its JSON
+// encoding, which reveals its approximate length. In the partial documents, it is replaced by an
+// object with the single key "$shamir". Every path must select at least one field.
+//
+// The keys of objects in the partial documents are sorted, and white space is removed.
+func SplitJSON(document []byte, paths []string, fieldSize *big.Int, degree int, nShares int) ([][]byte, error) {
+	value, err := decodeJSON(document)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		var segments []string
+		if path != "" {
+			segments = strings.Split(path, ".")
+		}
+		var matches int
+		value, matches, err = selectJSON(value, segments, func(selected interface{}) (interface{}, error) {
+			encoded, err := json.Marshal(selected)
+			if err != nil {
+				return nil, err
+			}
+			vectors, err := ShareBytes(encoded, fieldSize, degree, nShares)
+			if err != nil {
+				return nil, err
+			}
+			return &jsonSecret{vectors: vectors}, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if matches == 0 {
+			return nil, ErrorJSONPathNotFound
+		}
+	}
+
+	documents := make([][]byte, nShares)
+	for i := range documents {
+		documents[i], err = json.Marshal(partialJSON(value, i))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return documents, nil
+}
+
+// CombineJSON combines the partial documents produced by SplitJSON and recovers the original
+// document, up to the order of keys and white space. The plaintext fields are taken from the first
+// document.
+func CombineJSON(documents [][]byte) ([]byte, error) {
+	if len(documents) == 0 {
+		return nil, ErrorNoShares
+	}
+	values := make([]interface{}, len(documents))
+	for i := range documents {
+		var err error
+		values[i], err = decodeJSON(documents[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	value, err := combineJSON(values)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// selectJSON calls replace for every value selected by path, and returns value with the selected
+// values replaced, and the number of selected values. Values that are already shared, or lie within a
+// value that is already shared, by an earlier path are counted, but not replaced again.
+func selectJSON(value interface{}, path []string, replace func(interface{}) (interface{}, error)) (interface{}, int, error) {
+	if _, ok := value.(*jsonSecret); ok {
+		return value, 1, nil
+	}
+	if len(path) == 0 {
+		replaced, err := replace(value)
+		return replaced, 1, err
+	}
+
+	total := 0
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, element := range v {
+			if path[0] != "*" && path[0] != key {
+				continue
+			}
+			replaced, matches, err := selectJSON(element, path[1:], replace)
+			if err != nil {
+				return nil, 0, err
+			}
+			v[key] = replaced
+			total += matches
+		}
+	case []interface{}:
+		for i, element := range v {
+			if path[0] != "*" && path[0] != strconv.Itoa(i) {
+				continue
+			}
+			replaced, matches, err := selectJSON(element, path[1:], replace)
+			if err != nil {
+				return nil, 0, err
+			}
+			v[i] = replaced
+			total += matches
+		}
+	}
+	return value, total, nil
+}
+
+// partialJSON returns a copy of value in which the shared fields are replaced by the shares of the
+// party with index i.
+func partialJSON(value interface{}, i int) interface{} {
+	switch v := value.(type) {
+	case *jsonSecret:
+		share := jsonShare{FieldSize: FormatFieldSize(v.vectors[i][0].FieldSize), Degree: v.vectors[i][0].Degree, X: v.vectors[i][0].X}
+		// Pad the values with zeros to the number of digits of the field size, so that they do not
+		// reveal their magnitude
+		width := len(v.vectors[i][0].FieldSize.String())
+		for _, s := range v.vectors[i] {
+			share.Y = append(share.Y, fmt.Sprintf("%0*s", width, s.Y.String()))
+		}
+		return map[string]interface{}{jsonShareKey: share}
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			copied[key] = partialJSON(element, i)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for j, element := range v {
+			copied[j] = partialJSON(element, i)
+		}
+		return copied
+	}
+	return value
+}
+
+// combineJSON recovers the shared fields of the partial documents values, which must have the same
+// structure around every shared field.
+func combineJSON(values []interface{}) (interface{}, error) {
+	switch v := values[0].(type) {
+	case map[string]interface{}:
+		if _, ok := v[jsonShareKey]; ok && len(v) == 1 {
+			return combineJSONShares(values)
+		}
+		combined := make(map[string]interface{}, len(v))
+		elements := make([]interface{}, len(values))
+		for key := range v {
+			for i := range values {
+				object, ok := values[i].(map[string]interface{})
+				if !ok {
+					return nil, ErrorIncompatibleShares
+				}
+				if elements[i], ok = object[key]; !ok {
+					return nil, ErrorIncompatibleShares
+				}
+			}
+			var err error
+			if combined[key], err = combineJSON(elements); err != nil {
+				return nil, err
+			}
+		}
+		return combined, nil
+	case []interface{}:
+		combined := make([]interface{}, len(v))
+		elements := make([]interface{}, len(values))
+		for j := range v {
+			for i := range values {
+				array, ok := values[i].([]interface{})
+				if !ok || len(array) != len(v) {
+					return nil, ErrorIncompatibleShares
+				}
+				elements[i] = array[j]
+			}
+			var err error
+			if combined[j], err = combineJSON(elements); err != nil {
+				return nil, err
+			}
+		}
+		return combined, nil
+	}
+	return values[0], nil
+}
+
+// combineJSONShares recovers a shared field from the objects that replace it in the partial
+// documents values.
+func combineJSONShares(values []interface{}) (interface{}, error) {
+	vectors := make([]ShareVector, len(values))
+	for i := range values {
+		object, ok := values[i].(map[string]interface{})
+		if !ok || len(object) != 1 {
+			return nil, ErrorIncompatibleShares
+		}
+		encoded, err := json.Marshal(object[jsonShareKey])
+		if err != nil {
+			return nil, err
+		}
+		var share jsonShare
+		if err := json.Unmarshal(encoded, &share); err != nil {
+			return nil, ErrorInvalidJSONShare
+		}
+		fieldSize, err := ParseFieldSize(share.FieldSize)
+		if err != nil || len(share.Y) == 0 {
+			return nil, ErrorInvalidJSONShare
+		}
+		vectors[i] = make(ShareVector, len(share.Y))
+		for j, y := range share.Y {
+			vectors[i][j] = Share{FieldSize: fieldSize, Degree: share.Degree, X: share.X}
+			if vectors[i][j].Y, ok = big.NewInt(0).SetString(y, 10); !ok {
+				return nil, ErrorInvalidJSONShare
+			}
+		}
+	}
+	encoded, err := CombineBytes(vectors)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(encoded)
+}
+
+// decodeJSON decodes a JSON document, keeping numbers as they are written.
+func decodeJSON(document []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitJSON(t *testing.T) {
+	assert := assert.New(t)
+	document := []byte(`{
+		"name": "service",
+		"database": {"host": "db.example.com", "port": 5432, "password": "hunter2"},
+		"users": [{"name": "alice", "token": {"id": 1, "value": "abc"}}, {"name": "bob", "token": null}],
+		"limits": [1.50, 2e3]
+	}`)
+	fieldSize := Conservative128().FieldSize
+
+	documents, err := SplitJSON(document, []string{"database.password", "users.*.token", "limits.1"}, fieldSize, 1, 3)
+	assert.NoError(err)
+	assert.Len(documents, 3)
+	for _, partial := range documents {
+		assert.Contains(string(partial), `"host":"db.example.com"`)
+		assert.NotContains(string(partial), "hunter2")
+		assert.NotContains(string(partial), "abc")
+		assert.NotContains(string(partial), "2e3")
+		assert.Equal(4, strings.Count(string(partial), jsonShareKey))
+		assert.Equal(len(documents[0]), len(partial))
+	}
+
+	combined, err := CombineJSON(documents[1:])
+	assert.NoError(err)
+	assert.JSONEq(string(document), string(combined))
+	assert.Contains(string(combined), "2e3")
+
+	// The whole document
+	documents, err = SplitJSON(document, []string{""}, fieldSize, 2, 3)
+	assert.NoError(err)
+	combined, err = CombineJSON(documents)
+	assert.NoError(err)
+	assert.JSONEq(string(document), string(combined))
+
+	// Overlapping paths share the outer value once
+	documents, err = SplitJSON(document, []string{"database", "database.password"}, fieldSize, 1, 2)
+	assert.NoError(err)
+	assert.Equal(1, strings.Count(string(documents[0]), jsonShareKey))
+
+	_, err = SplitJSON(document, []string{"database.username"}, fieldSize, 1, 2)
+	assert.Equal(ErrorJSONPathNotFound, err)
+	_, err = SplitJSON([]byte("{"), nil, fieldSize, 1, 2)
+	assert.Error(err)
+}
+
+func TestCombineJSONErrors(t *testing.T) {
+	assert := assert.New(t)
+	document := []byte(`{"a": "secret", "b": [1, 2]}`)
+	documents, err := SplitJSON(document, []string{"a"}, big.NewInt(7919), 1, 3)
+	assert.NoError(err)
+
+	_, err = CombineJSON(nil)
+	assert.Equal(ErrorNoShares, err)
+	_, err = CombineJSON(documents[:1])
+	assert.Equal(ErrorTooFewShares, err)
+
+	var changed map[string]interface{}
+	assert.NoError(json.Unmarshal(documents[1], &changed))
+	delete(changed, "a")
+	encoded, err := json.Marshal(changed)
+	assert.NoError(err)
+	_, err = CombineJSON([][]byte{documents[0], encoded})
+	assert.Equal(ErrorIncompatibleShares, err)
+
+	assert.NoError(json.Unmarshal(documents[1], &changed))
+	changed["a"].(map[string]interface{})[jsonShareKey].(map[string]interface{})["y"] = []string{"x"}
+	encoded, err = json.Marshal(changed)
+	assert.NoError(err)
+	_, err = CombineJSON([][]byte{documents[0], encoded})
+	assert.Equal(ErrorInvalidJSONShare, err)
+}
+
+func TestSplitJSONFieldID(t *testing.T) {
+	assert := assert.New(t)
+	documents, err := SplitJSON([]byte(`{"key": "secret"}`), []string{"key"}, Conservative128().FieldSize, 1, 2)
+	assert.NoError(err)
+	assert.Contains(string(documents[0]), `"fieldSize":"p25519"`)
+	combined, err := CombineJSON(documents)
+	assert.NoError(err)
+	assert.JSONEq(`{"key": "secret"}`, string(combined))
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+)
+
+var (
+	ErrorDecryption = errors.New("Decryption failed")
+)
+
+// encryptionKeySize is the size in bytes of the AES-256 keys used by SplitEncrypted.
+const encryptionKeySize = 32
+
+// SplitEncrypted encrypts data of any size with AES-256-GCM under a fresh random key, and shares only
+// the key over the field of Conservative128. It returns the ciphertext, which can be stored or sent
+// in the open, and a share of the key for every party. This is much more efficient than sharing a
+// large payload itself, at the price of computational rather than information-theoretic security.
+func SplitEncrypted(data []byte, degree int, nShares int) ([]byte, []PayloadShare, error) {
+	key := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	ciphertext := aead.Seal(nonce, nonce, data, nil)
+
+	shares, err := SharePayload(key, Conservative128().FieldSize, degree, nShares, encryptionKeySize, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ciphertext, shares, nil
+}
+
+// CombineEncrypted recovers the key from shares produced by SplitEncrypted and decrypts the
+// ciphertext. It returns ErrorDecryption if the ciphertext was modified or the key is wrong.
+func CombineEncrypted(ciphertext []byte, shares []PayloadShare) ([]byte, error) {
+	key, err := CombinePayload(shares)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != encryptionKeySize {
+		return nil, ErrorInvalidPayload
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrorDecryption
+	}
+	data, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrorDecryption
+	}
+	return data, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitEncrypted(t *testing.T) {
+	assert := assert.New(t)
+	data := bytes.Repeat([]byte("a large secret document "), 1000)
+
+	ciphertext, shares, err := SplitEncrypted(data, 2, 5)
+	assert.NoError(err)
+	assert.Len(shares, 5)
+	assert.Len(ciphertext, len(data)+12+16)
+
+	recovered, err := CombineEncrypted(ciphertext, []PayloadShare{shares[4], shares[0], shares[2]})
+	assert.NoError(err)
+	assert.Equal(data, recovered)
+
+	_, err = CombineEncrypted(ciphertext, shares[:2])
+	assert.Equal(ErrorTooFewShares, err)
+
+	tampered := append([]byte{}, ciphertext...)
+	tampered[100] ^= 1
+	_, err = CombineEncrypted(tampered, shares)
+	assert.Equal(ErrorDecryption, err)
+	_, err = CombineEncrypted(ciphertext[:5], shares)
+	assert.Equal(ErrorDecryption, err)
+
+	// Shares of another key
+	_, otherShares, err := SplitEncrypted(data, 2, 5)
+	assert.NoError(err)
+	_, err = CombineEncrypted(ciphertext, otherShares)
+	assert.Equal(ErrorDecryption, err)
+}
@@ -0,0 +1,274 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// Escrowed shares are encrypted bit by bit with ElGamal in the exponent under the public key
+// P = s * G of the escrow agent: bit b_i of the share y is encrypted as (r_i * G, b_i * G + r_i * P).
+// Each bit carries a disjunctive Chaum-Pedersen proof that it encrypts 0 or 1. Weighing the bit
+// ciphertexts by 2^i gives an encryption (R * G, y * G + R * P) of y itself, with R = sum_i 2^i r_i,
+// and a Chaum-Pedersen proof that R * G and R * P have the same discrete logarithm, where R * P is
+// this ciphertext minus the share commitment y * G, shows that it encrypts the committed share. The
+// agent decrypts every bit by comparing b_i * G with G, so decryption needs no discrete logarithm.
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"hash"
+	"math/big"
+)
+
+// An EscrowedShare is a share encrypted for an escrow agent with a proof, which anyone can check
+// with Commitments.VerifyEscrowedShare, that it encrypts the share at X coordinate X of the sharing
+// committed to. The agent cannot read it without its private key.
+type EscrowedShare struct {
+	X                  int
+	Degree             int
+	SharingFingerprint []byte
+	Bits               []EscrowedBit
+	// Challenge and Response prove that the bits encrypt the committed share
+	Challenge *big.Int
+	Response  *big.Int
+}
+
+// An EscrowedBit is the encryption (C1, C2) of a bit of an escrowed share, with a proof that it is
+// 0 or 1: the challenges and responses for either value.
+type EscrowedBit struct {
+	C1         GroupElement
+	C2         GroupElement
+	Challenges [2]*big.Int
+	Responses  [2]*big.Int
+}
+
+// NewEscrowKey generates a key pair for an escrow agent: a private key s and the public key s * G.
+func NewEscrowKey(generator GroupElement) (*big.Int, GroupElement, error) {
+	privateKey, err := rand.Int(rand.Reader, generator.Order())
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, generator.ScalarMult(privateKey), nil
+}
+
+// EscrowShare encrypts a share dealt by ShareFeldman with the given generator for the escrow agent
+// with the given public key, and proves that the ciphertext encrypts the share.
+func EscrowShare(share Share, generator GroupElement, escrowKey GroupElement) (EscrowedShare, error) {
+	order := generator.Order()
+	if share.Y == nil || !equalOrBothNil(share.FieldSize, order) || share.Y.Sign() < 0 || share.Y.Cmp(order) >= 0 {
+		return EscrowedShare{}, ErrorWrongShareType
+	}
+	escrowed := EscrowedShare{
+		X:                  share.X,
+		Degree:             share.Degree,
+		SharingFingerprint: share.SharingFingerprint,
+		Bits:               make([]EscrowedBit, order.BitLen()),
+	}
+	randomness := make([]*big.Int, len(escrowed.Bits))
+	for i := range escrowed.Bits {
+		r, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			return EscrowedShare{}, err
+		}
+		randomness[i] = r
+		escrowed.Bits[i].C1 = generator.ScalarMult(r)
+		escrowed.Bits[i].C2 = escrowKey.ScalarMult(r)
+		if share.Y.Bit(i) == 1 {
+			escrowed.Bits[i].C2 = escrowed.Bits[i].C2.Add(generator)
+		}
+	}
+	context := escrowContext(generator, escrowKey, escrowed)
+	for i := range escrowed.Bits {
+		if err := proveBit(generator, escrowKey, context, i, &escrowed.Bits[i], share.Y.Bit(i), randomness[i]); err != nil {
+			return EscrowedShare{}, err
+		}
+	}
+
+	// Prove that R * G and y * G + R * P - y * G share the discrete logarithm R
+	r := big.NewInt(0)
+	for i := len(randomness) - 1; i >= 0; i-- {
+		r.Lsh(r, 1).Add(r, randomness[i])
+	}
+	k, err := rand.Int(rand.Reader, order)
+	if err != nil {
+		return EscrowedShare{}, err
+	}
+	escrowed.Challenge = escrowChallenge(context, -1, generator.ScalarMult(k), escrowKey.ScalarMult(k))
+	response := big.NewInt(0).Mul(escrowed.Challenge, r)
+	escrowed.Response = response.Add(response, k).Mod(response, order)
+	return escrowed, nil
+}
+
+// VerifyEscrowedShare checks that escrowed encrypts the share at its X coordinate of the polynomial
+// committed to, for the escrow agent with the given public key.
+func (c Commitments) VerifyEscrowedShare(generator GroupElement, escrowKey GroupElement, escrowed EscrowedShare) bool {
+	order := generator.Order()
+	if len(c) == 0 || escrowed.Degree != len(c)-1 || escrowed.X < 1 || len(escrowed.Bits) != order.BitLen() ||
+		escrowed.Challenge == nil || escrowed.Response == nil {
+		return false
+	}
+	context := escrowContext(generator, escrowKey, escrowed)
+	c1s := make([]GroupElement, len(escrowed.Bits))
+	c2s := make([]GroupElement, len(escrowed.Bits))
+	weights := make([]*big.Int, len(escrowed.Bits))
+	for i, bit := range escrowed.Bits {
+		if !verifyBit(generator, escrowKey, context, i, bit) {
+			return false
+		}
+		c1s[i], c2s[i] = bit.C1, bit.C2
+		weights[i] = big.NewInt(0).Lsh(big.NewInt(1), uint(i))
+	}
+	c1, err := MultiScalarMult(c1s, weights)
+	if err != nil {
+		return false
+	}
+	c2, err := MultiScalarMult(c2s, weights)
+	if err != nil {
+		return false
+	}
+	d := c2.Add(negate(c.ShareCommitment(escrowed.X)))
+	return escrowed.Challenge.Cmp(verifyEquality(generator, escrowKey, context, -1, c1, d, escrowed.Challenge, escrowed.Response)) == 0
+}
+
+// OpenEscrowedShare decrypts an escrowed share with the private key of the escrow agent. It returns
+// ErrorDecryption if a bit does not decrypt to 0 or 1, which cannot happen for an escrowed share
+// that passed VerifyEscrowedShare.
+func OpenEscrowedShare(escrowed EscrowedShare, generator GroupElement, privateKey *big.Int) (Share, error) {
+	y := big.NewInt(0)
+	for i := len(escrowed.Bits) - 1; i >= 0; i-- {
+		bit := escrowed.Bits[i]
+		if bit.C1 == nil || bit.C2 == nil {
+			return Share{}, ErrorDecryption
+		}
+		mask := bit.C1.ScalarMult(privateKey)
+		y.Lsh(y, 1)
+		if mask.Add(generator).Equal(bit.C2) {
+			y.SetBit(y, 0, 1)
+		} else if !mask.Equal(bit.C2) {
+			return Share{}, ErrorDecryption
+		}
+	}
+	return Share{
+		FieldSize:          generator.Order(),
+		Degree:             escrowed.Degree,
+		X:                  escrowed.X,
+		Y:                  y.Mod(y, generator.Order()),
+		SharingFingerprint: escrowed.SharingFingerprint,
+	}, nil
+}
+
+// proveBit sets the proof of bit that its ciphertext, with randomness r, encrypts 0 or 1, given its
+// actual value: it proves that C1 and C2 - b * G have the same discrete logarithm for b = 0 or 1. The
+// proof for the other value is simulated with a chosen challenge.
+func proveBit(generator GroupElement, escrowKey GroupElement, context []byte, i int, bit *EscrowedBit, value uint, r *big.Int) error {
+	order := generator.Order()
+	other := 1 - value
+	var err error
+	if bit.Challenges[other], err = rand.Int(rand.Reader, order); err != nil {
+		return err
+	}
+	if bit.Responses[other], err = rand.Int(rand.Reader, order); err != nil {
+		return err
+	}
+	k, err := rand.Int(rand.Reader, order)
+	if err != nil {
+		return err
+	}
+	var a, b [2]GroupElement
+	a[value], b[value] = generator.ScalarMult(k), escrowKey.ScalarMult(k)
+	a[other], b[other] = simulate(generator, escrowKey, bit.C1, bitTarget(generator, bit.C2, other), bit.Challenges[other], bit.Responses[other])
+	e := escrowChallenge(context, i, a[0], b[0], a[1], b[1])
+	bit.Challenges[value] = e.Sub(e, bit.Challenges[other]).Mod(e, order)
+	response := big.NewInt(0).Mul(bit.Challenges[value], r)
+	bit.Responses[value] = response.Add(response, k).Mod(response, order)
+	return nil
+}
+
+// verifyBit checks the proof of bit that it encrypts 0 or 1.
+func verifyBit(generator GroupElement, escrowKey GroupElement, context []byte, i int, bit EscrowedBit) bool {
+	if bit.C1 == nil || bit.C2 == nil {
+		return false
+	}
+	var a, b [2]GroupElement
+	for value := range a {
+		if bit.Challenges[value] == nil || bit.Responses[value] == nil {
+			return false
+		}
+		a[value], b[value] = simulate(generator, escrowKey, bit.C1, bitTarget(generator, bit.C2, uint(value)), bit.Challenges[value], bit.Responses[value])
+	}
+	e := big.NewInt(0).Add(bit.Challenges[0], bit.Challenges[1])
+	return e.Mod(e, generator.Order()).Cmp(escrowChallenge(context, i, a[0], b[0], a[1], b[1])) == 0
+}
+
+// verifyEquality recomputes the challenge of a Chaum-Pedersen proof that u = x * G and v = x * P for
+// the same x, which is valid if it equals the given challenge.
+func verifyEquality(generator GroupElement, escrowKey GroupElement, context []byte, i int, u GroupElement, v GroupElement, e *big.Int, s *big.Int) *big.Int {
+	a, b := simulate(generator, escrowKey, u, v, e, s)
+	return escrowChallenge(context, i, a, b)
+}
+
+// simulate returns the commitments s * G - e * u and s * P - e * v of a Chaum-Pedersen proof that u
+// and v have the same discrete logarithm with respect to G and P, for challenge e and response s.
+func simulate(generator GroupElement, escrowKey GroupElement, u GroupElement, v GroupElement, e *big.Int, s *big.Int) (GroupElement, GroupElement) {
+	minusE := big.NewInt(0).Sub(generator.Order(), e)
+	minusE.Mod(minusE, generator.Order())
+	return generator.ScalarMult(s).Add(u.ScalarMult(minusE)), escrowKey.ScalarMult(s).Add(v.ScalarMult(minusE))
+}
+
+// bitTarget returns C2 - value * G, which equals r * P if C2 encrypts value.
+func bitTarget(generator GroupElement, c2 GroupElement, value uint) GroupElement {
+	if value == 0 {
+		return c2
+	}
+	return c2.Add(negate(generator))
+}
+
+// negate returns the additive inverse of element.
+func negate(element GroupElement) GroupElement {
+	return element.ScalarMult(big.NewInt(0).Sub(element.Order(), big.NewInt(1)))
+}
+
+// escrowContext hashes the public values of an escrowed share, to which all its proofs are bound.
+func escrowContext(generator GroupElement, escrowKey GroupElement, escrowed EscrowedShare) []byte {
+	h := sha256.New()
+	writeBytes(h, generator.Bytes())
+	writeBytes(h, escrowKey.Bytes())
+	writeUint64(h, uint64(escrowed.X))
+	writeUint64(h, uint64(escrowed.Degree))
+	for _, bit := range escrowed.Bits {
+		writeElement(h, bit.C1)
+		writeElement(h, bit.C2)
+	}
+	return h.Sum(nil)
+}
+
+// escrowChallenge derives the Fiat-Shamir challenge of proof i of an escrowed share, with -1 for the
+// proof of the whole share, from the context and the commitments of the proof.
+func escrowChallenge(context []byte, i int, commitments ...GroupElement) *big.Int {
+	h := sha256.New()
+	writeBytes(h, context)
+	writeUint64(h, uint64(i+1))
+	for _, commitment := range commitments {
+		writeBytes(h, commitment.Bytes())
+	}
+	e := big.NewInt(0).SetBytes(h.Sum(nil))
+	return e.Mod(e, commitments[0].Order())
+}
+
+// writeElement writes element to h, or an empty encoding if it is nil.
+func writeElement(h hash.Hash, element GroupElement) {
+	if element == nil {
+		writeBytes(h, nil)
+		return
+	}
+	writeBytes(h, element.Bytes())
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscrowShare(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(4242), g, 2, 5)
+	privateKey, escrowKey, err := NewEscrowKey(g)
+	assert.NoError(err)
+
+	escrowed := make([]EscrowedShare, len(shares))
+	for i, share := range shares {
+		escrowed[i], err = EscrowShare(share, g, escrowKey)
+		assert.NoError(err)
+		assert.True(commitments.VerifyEscrowedShare(g, escrowKey, escrowed[i]))
+	}
+
+	// The escrow agent recovers the shares, which combine to the secret
+	opened := make([]Share, len(escrowed))
+	for i := range escrowed {
+		opened[i], err = OpenEscrowedShare(escrowed[i], g, privateKey)
+		assert.NoError(err)
+		assert.Equal(shares[i], opened[i])
+	}
+	secret, err := ShareCombine(opened[1:4])
+	assert.NoError(err)
+	assert.Equal(big.NewInt(4242), secret)
+
+	// Another sharing, key or X coordinate does not verify
+	_, otherCommitments := ShareFeldman(big.NewInt(4242), g, 2, 5)
+	assert.False(otherCommitments.VerifyEscrowedShare(g, escrowKey, escrowed[0]))
+	_, otherKey, _ := NewEscrowKey(g)
+	assert.False(commitments.VerifyEscrowedShare(g, otherKey, escrowed[0]))
+	moved := escrowed[0]
+	moved.X = 2
+	assert.False(commitments.VerifyEscrowedShare(g, escrowKey, moved))
+}
+
+func TestEscrowShareTampered(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(7), g, 1, 3)
+	privateKey, escrowKey, _ := NewEscrowKey(g)
+	escrowed, err := EscrowShare(shares[0], g, escrowKey)
+	assert.NoError(err)
+
+	// Encrypting a wrong share fails verification, even with honestly generated proofs
+	wrong := shares[0]
+	wrong.Y = big.NewInt(0).Add(wrong.Y, big.NewInt(1))
+	wrong.Y.Mod(wrong.Y, largeTestGroup.Q)
+	wrongEscrowed, err := EscrowShare(wrong, g, escrowKey)
+	assert.NoError(err)
+	assert.False(commitments.VerifyEscrowedShare(g, escrowKey, wrongEscrowed))
+
+	// Swapping or re-encrypting a bit breaks the proofs
+	swapped := escrowed
+	swapped.Bits = append([]EscrowedBit{}, escrowed.Bits...)
+	swapped.Bits[0], swapped.Bits[1] = swapped.Bits[1], swapped.Bits[0]
+	assert.False(commitments.VerifyEscrowedShare(g, escrowKey, swapped))
+	forged := escrowed
+	forged.Bits = append([]EscrowedBit{}, escrowed.Bits...)
+	forged.Bits[3].C2 = forged.Bits[3].C2.Add(g)
+	assert.False(commitments.VerifyEscrowedShare(g, escrowKey, forged))
+	assert.False(commitments.VerifyEscrowedShare(g, escrowKey, EscrowedShare{X: 1, Degree: 1}))
+
+	// A bit that encrypts 2 does not decrypt
+	forged.Bits[3].C2 = forged.Bits[3].C2.Add(g)
+	_, err = OpenEscrowedShare(forged, g, privateKey)
+	assert.Equal(ErrorDecryption, err)
+
+	_, err = EscrowShare(Share{FieldSize: big.NewInt(11), Y: big.NewInt(1)}, g, escrowKey)
+	assert.Equal(ErrorWrongShareType, err)
+}
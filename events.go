@@ -0,0 +1,187 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// An EventKind identifies a step in the lifecycle of shares.
+type EventKind int
+
+const (
+	// EventDealingStarted is emitted when a secret is about to be shared.
+	EventDealingStarted EventKind = iota + 1
+	// EventShareIssued is emitted for every share produced by a dealing.
+	EventShareIssued
+	// EventCombineAttempted is emitted when shares are combined.
+	EventCombineAttempted
+	// EventCombineFailed is emitted when combining shares fails, with the reason in Err.
+	EventCombineFailed
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventDealingStarted:
+		return "dealing started"
+	case EventShareIssued:
+		return "share issued"
+	case EventCombineAttempted:
+		return "combine attempted"
+	case EventCombineFailed:
+		return "combine failed"
+	default:
+		return "unknown event"
+	}
+}
+
+// An Event describes a step in the lifecycle of shares. It never contains secrets or share values,
+// so it can be logged safely.
+type Event struct {
+	Kind EventKind
+	// FieldSize is the size of the finite field, or nil for sharing over the integers.
+	FieldSize *big.Int
+	Degree    int
+	// NShares is the number of shares dealt or given to combine.
+	NShares int
+	// X is the X coordinate of an issued share.
+	X int
+	// Sharing is the sharing fingerprint of the shares given to combine, if they have one.
+	Sharing []byte
+	// Session is the session of the Party that combined the shares, if it has one, see
+	// Party.SetSession.
+	Session string
+	// Err is the reason why combining failed.
+	Err error
+}
+
+// Scheme returns the scheme fingerprint of the event, see SchemeFingerprint.
+func (e Event) Scheme() string {
+	return schemeFingerprint(e.FieldSize, e.Degree, e.Sharing, e.Session)
+}
+
+// An EventHandler receives events. It is called synchronously from the goroutine performing the
+// operation, so it should return quickly and must be safe for concurrent use.
+type EventHandler func(Event)
+
+var (
+	eventMutex   sync.RWMutex
+	eventHandler EventHandler
+)
+
+// SetEventHandler installs a handler that is called for every dealing and every combination of
+// shares, so that applications can log and audit the lifecycle of shares. Passing nil removes the
+// handler; by default, no handler is installed and the package does not report anything.
+func SetEventHandler(handler EventHandler) {
+	eventMutex.Lock()
+	defer eventMutex.Unlock()
+	eventHandler = handler
+}
+
+// emit passes event to the installed handler, if any.
+func emit(event Event) {
+	eventMutex.RLock()
+	handler := eventHandler
+	eventMutex.RUnlock()
+	if handler != nil {
+		handler(event)
+	}
+}
+
+// emitCombine reports an attempt to combine shares with the given function in the given session,
+// which may be empty, and its failure. If the shares have a sharing fingerprint or the session is
+// not empty, the error is annotated with the scheme fingerprint, see WithScheme.
+func emitCombine(session string, shares []Share, combine func([]Share) (*big.Int, error)) (*big.Int, error) {
+	event := Event{Kind: EventCombineAttempted, NShares: len(shares), Session: session}
+	if len(shares) > 0 {
+		event.FieldSize = shares[0].FieldSize
+		event.Degree = shares[0].Degree
+		event.Sharing = shares[0].SharingFingerprint
+	}
+	emit(event)
+	secret, err := combine(shares)
+	if err != nil {
+		event.Kind = EventCombineFailed
+		event.Err = err
+		emit(event)
+		if len(shares) > 0 && (shares[0].SharingFingerprint != nil || session != "") {
+			err = WithScheme(err, session, shares[0])
+		}
+	}
+	return secret, err
+}
+
+// A SchemeError annotates an error with the scheme fingerprint of the shares involved, so that
+// operators of services handling many concurrent sharings can correlate failures. Use errors.Is to
+// compare it to the errors of this package.
+type SchemeError struct {
+	Scheme string
+	Err    error
+}
+
+func (e *SchemeError) Error() string {
+	return e.Err.Error() + " [" + e.Scheme + "]"
+}
+
+// Unwrap returns the annotated error.
+func (e *SchemeError) Unwrap() error {
+	return e.Err
+}
+
+// WithScheme annotates err with the scheme fingerprint of share and the given session, which may be
+// empty, see SchemeFingerprint. An existing annotation of err is replaced. It returns nil if err is
+// nil.
+func WithScheme(err error, session string, share Share) error {
+	if err == nil {
+		return nil
+	}
+	if annotated, ok := err.(*SchemeError); ok {
+		err = annotated.Err
+	}
+	return &SchemeError{Scheme: schemeFingerprint(share.FieldSize, share.Degree, share.SharingFingerprint, session), Err: err}
+}
+
+// SchemeFingerprint describes the sharing that share belongs to, without revealing anything about
+// the secret or the share: the field, by its identifier if it is registered, the degree, and the
+// sharing fingerprint and session, if any. For instance, "field=m61 degree=2 sharing=0a1b2c3d4e5f6071
+// session=backup-7".
+func SchemeFingerprint(session string, share Share) string {
+	return schemeFingerprint(share.FieldSize, share.Degree, share.SharingFingerprint, session)
+}
+
+func schemeFingerprint(fieldSize *big.Int, degree int, sharing []byte, session string) string {
+	var b strings.Builder
+	b.WriteString("field=")
+	if fieldSize == nil {
+		b.WriteString("integers")
+	} else {
+		b.WriteString(FormatFieldSize(fieldSize))
+	}
+	b.WriteString(" degree=")
+	b.WriteString(strconv.Itoa(degree))
+	if len(sharing) > 0 {
+		b.WriteString(" sharing=")
+		b.WriteString(hex.EncodeToString(sharing))
+	}
+	if session != "" {
+		b.WriteString(" session=")
+		b.WriteString(session)
+	}
+	return b.String()
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvents(t *testing.T) {
+	assert := assert.New(t)
+	var events []Event
+	SetEventHandler(func(event Event) {
+		events = append(events, event)
+	})
+	defer SetEventHandler(nil)
+
+	fieldSize := big.NewInt(7919)
+	shares := ShareFiniteField(big.NewInt(42), fieldSize, 1, 3)
+	if assert.Len(events, 4) {
+		assert.Equal(Event{Kind: EventDealingStarted, FieldSize: fieldSize, Degree: 1, NShares: 3}, events[0])
+		for i := 1; i <= 3; i++ {
+			assert.Equal(Event{Kind: EventShareIssued, FieldSize: fieldSize, Degree: 1, NShares: 3, X: i}, events[i])
+		}
+	}
+
+	events = nil
+	_, err := ShareCombine(shares[:2])
+	assert.Nil(err)
+	assert.Equal([]Event{{Kind: EventCombineAttempted, FieldSize: fieldSize, Degree: 1, NShares: 2}}, events)
+
+	events = nil
+	_, err = ShareCombine(shares[:1])
+	assert.Equal(ErrorTooFewShares, err)
+	assert.Equal([]Event{
+		{Kind: EventCombineAttempted, FieldSize: fieldSize, Degree: 1, NShares: 1},
+		{Kind: EventCombineFailed, FieldSize: fieldSize, Degree: 1, NShares: 1, Err: ErrorTooFewShares},
+	}, events)
+
+	events = nil
+	ShareIntegers(big.NewInt(42), big.NewInt(100), 40, 2, 4)
+	assert.Len(events, 5)
+	assert.Nil(events[0].FieldSize)
+	assert.Equal("dealing started", events[0].Kind.String())
+
+	SetEventHandler(nil)
+	events = nil
+	ShareFiniteField(big.NewInt(42), fieldSize, 1, 3)
+	assert.Empty(events)
+}
+
+func TestSchemeFingerprint(t *testing.T) {
+	assert := assert.New(t)
+	var events []Event
+	SetEventHandler(func(event Event) {
+		events = append(events, event)
+	})
+	defer SetEventHandler(nil)
+
+	shares := WithSharingFingerprint(ShareFiniteField(big.NewInt(42), mersenne61, 2, 5), []byte{1, 2, 3, 4, 5, 6, 7, 8})
+	events = nil
+	_, err := ShareCombine(shares[:2])
+	assert.True(errors.Is(err, ErrorTooFewShares))
+	if assert.Len(events, 2) {
+		assert.Equal([]byte{1, 2, 3, 4, 5, 6, 7, 8}, events[1].Sharing)
+		assert.Equal("field=m61 degree=2 sharing=0102030405060708", events[1].Scheme())
+	}
+
+	err = WithScheme(err, "backup-7", shares[0])
+	assert.Equal("Too few shares given [field=m61 degree=2 sharing=0102030405060708 session=backup-7]", err.Error())
+	assert.True(errors.Is(err, ErrorTooFewShares))
+	var schemeError *SchemeError
+	if assert.True(errors.As(err, &schemeError)) {
+		assert.Equal(SchemeFingerprint("backup-7", shares[0]), schemeError.Scheme)
+	}
+	assert.Nil(WithScheme(nil, "backup-7", shares[0]))
+
+	integer := ShareIntegers(big.NewInt(42), big.NewInt(100), 40, 1, 3)
+	assert.Equal("field=integers degree=1", SchemeFingerprint("", integer[0]))
+	assert.Equal("field=7919 degree=0", SchemeFingerprint("", Share{FieldSize: big.NewInt(7919), Y: big.NewInt(1)}))
+}
+
+// failingNetwork is a Network on which every exchange fails.
+type failingNetwork struct{}
+
+func (failingNetwork) Exchange(outgoing [][]NestedShare) ([][]NestedShare, error) {
+	return nil, ErrorNetwork
+}
+
+func TestPartySession(t *testing.T) {
+	assert := assert.New(t)
+	p := NewParty(1, 3, mersenne61, 1, failingNetwork{})
+	_, err := p.Random(1)
+	assert.Equal(ErrorNetwork, err)
+
+	p.SetSession("job-3")
+	_, err = p.Random(1)
+	assert.True(errors.Is(err, ErrorNetwork))
+	assert.Equal("Unexpected message received from the network [field=m61 degree=1 session=job-3]", err.Error())
+	q, _, err := p.ChangeDegree(nil, 2)
+	assert.Nil(q)
+	assert.Equal("Unexpected message received from the network [field=m61 degree=2 session=job-3]", err.Error())
+
+	var mutex sync.Mutex
+	var events []Event
+	SetEventHandler(func(event Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, event)
+	})
+	defer SetEventHandler(nil)
+	runParties(t, 3, 1, mersenne61, func(p *Party) ([]*big.Int, error) {
+		p.SetSession("job-4")
+		v, err := p.Input(1, bigInts(42), 1)
+		if err != nil {
+			return nil, err
+		}
+		return p.Open(v)
+	})
+	// Input deals the secret without a session, and every party opens it in the session
+	combined := 0
+	for _, event := range events {
+		if event.Kind == EventCombineAttempted {
+			combined++
+			assert.Equal("field=m61 degree=1 session=job-4", event.Scheme())
+		} else {
+			assert.Empty(event.Session)
+		}
+	}
+	assert.Equal(3, combined)
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+var (
+	ErrorInvalidElement = errors.New("Encoding is not an element of the group")
+)
+
+// A GroupElement is an element of a cyclic group of prime order, written additively. Secrets shared
+// over the finite field of integers modulo the order can be used as exponents (scalars) of the
+// group elements. Implementations are expected to be immutable and may panic when combined with
+// elements of another group.
+type GroupElement interface {
+	// Add returns the sum of the element and other.
+	Add(other GroupElement) GroupElement
+	// ScalarMult returns the element multiplied by k, i.e. added to itself k times.
+	ScalarMult(k *big.Int) GroupElement
+	// Equal reports whether the element equals other.
+	Equal(other GroupElement) bool
+	// Order returns the order of the group.
+	Order() *big.Int
+	// Bytes returns a canonical encoding of the element, for instance to hash commitments.
+	Bytes() []byte
+}
+
+// CombineExponent performs Lagrange interpolation in the exponent: given the partial results
+// partials[i] = s_i * G of the holders of the shares s_i at X coordinates xs[i], for shares of a
+// secret s modulo the group order, it returns s * G. This allows threshold decryption or signing
+// without any party learning the shares or the secret. Like ShareCombine, it requires degree+1
+// partial results, and all partials must belong to the same group.
+func CombineExponent(partials []GroupElement, xs []int) (GroupElement, error) {
+	if len(partials) == 0 {
+		return nil, ErrorNoShares
+	}
+	if len(partials) != len(xs) {
+		return nil, ErrorVectorLength
+	}
+	coefficients, err := LagrangeCoefficients(xs, 0, partials[0].Order())
+	if err != nil {
+		return nil, err
+	}
+	return MultiScalarMult(partials, coefficients)
+}
+
+// MultiScalarMult returns the sum of scalars[i] * elements[i], computed with Pippenger's bucket
+// method. For n terms, this takes about n / log(n) group additions per bit of the scalars instead of
+// the n scalar multiplications of the naive sum, which dominates the verification of commitments of
+// high degree. The scalars are reduced modulo the group order. At least one element must be given,
+// and all elements must belong to the same group.
+func MultiScalarMult(elements []GroupElement, scalars []*big.Int) (GroupElement, error) {
+	if len(elements) == 0 || len(elements) != len(scalars) {
+		return nil, ErrorVectorLength
+	}
+	order := elements[0].Order()
+	reduced := make([]*big.Int, len(scalars))
+	maxBits := 0
+	for i, scalar := range scalars {
+		reduced[i] = big.NewInt(0).Mod(scalar, order)
+		if reduced[i].BitLen() > maxBits {
+			maxBits = reduced[i].BitLen()
+		}
+	}
+	if len(elements) < 4 {
+		result := elements[0].ScalarMult(reduced[0])
+		for i := 1; i < len(elements); i++ {
+			result = result.Add(elements[i].ScalarMult(reduced[i]))
+		}
+		return result, nil
+	}
+
+	// Process the scalars in windows of c bits from the most significant one. Within a window, every
+	// element is added to the bucket of its digit, and the sum of digit * bucket is accumulated with
+	// running sums. nil represents the identity, which GroupElement does not provide.
+	c := bits.Len(uint(len(elements))) - 1
+	buckets := make([]GroupElement, 1<<uint(c))
+	var result GroupElement
+	for window := (maxBits+c-1)/c - 1; window >= 0; window-- {
+		for i := 0; i < c && result != nil; i++ {
+			result = result.Add(result)
+		}
+		for k := range buckets {
+			buckets[k] = nil
+		}
+		for i, scalar := range reduced {
+			digit := 0
+			for b := c - 1; b >= 0; b-- {
+				digit = digit<<1 | int(scalar.Bit(window*c+b))
+			}
+			if digit != 0 {
+				buckets[digit] = addOrNil(buckets[digit], elements[i])
+			}
+		}
+		var running, sum GroupElement
+		for k := len(buckets) - 1; k > 0; k-- {
+			running = addOrNil(running, buckets[k])
+			sum = addOrNil(sum, running)
+		}
+		result = addOrNil(result, sum)
+	}
+	if result == nil {
+		return elements[0].ScalarMult(big.NewInt(0)), nil
+	}
+	return result, nil
+}
+
+// addOrNil returns a + b, where nil represents the identity.
+func addOrNil(a GroupElement, b GroupElement) GroupElement {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return a.Add(b)
+}
+
+// A ModPGroup is the subgroup of prime order Q of the multiplicative group of integers modulo the
+// prime P, generated by G. The caller must ensure that these parameters are valid.
+type ModPGroup struct {
+	P *big.Int
+	Q *big.Int
+	G *big.Int
+}
+
+// Generator returns the generator of the group.
+func (g *ModPGroup) Generator() ModPElement {
+	return ModPElement{Group: g, Value: g.G}
+}
+
+// Element returns the element of the group with the given value modulo P. The caller must ensure
+// that the value lies in the subgroup.
+func (g *ModPGroup) Element(value *big.Int) ModPElement {
+	return ModPElement{Group: g, Value: big.NewInt(0).Mod(value, g.P)}
+}
+
+// Decode parses an element encoded with Bytes, checking that it lies in the subgroup.
+func (g *ModPGroup) Decode(b []byte) (GroupElement, error) {
+	value := big.NewInt(0).SetBytes(b)
+	if len(b) != (g.P.BitLen()+7)/8 || value.Sign() == 0 || value.Cmp(g.P) >= 0 ||
+		big.NewInt(0).Exp(value, g.Q, g.P).Cmp(big.NewInt(1)) != 0 {
+		return nil, ErrorInvalidElement
+	}
+	return ModPElement{Group: g, Value: value}, nil
+}
+
+// A ModPElement is an element of a ModPGroup. In the additive notation of GroupElement, Add
+// multiplies values modulo P and ScalarMult exponentiates them.
+type ModPElement struct {
+	Group *ModPGroup
+	Value *big.Int
+}
+
+// Add returns the product of the element and other modulo P.
+func (e ModPElement) Add(other GroupElement) GroupElement {
+	value := big.NewInt(0).Mul(e.Value, other.(ModPElement).Value)
+	return ModPElement{Group: e.Group, Value: value.Mod(value, e.Group.P)}
+}
+
+// ScalarMult returns the element raised to the power k modulo P.
+func (e ModPElement) ScalarMult(k *big.Int) GroupElement {
+	exponent := big.NewInt(0).Mod(k, e.Group.Q)
+	return ModPElement{Group: e.Group, Value: big.NewInt(0).Exp(e.Value, exponent, e.Group.P)}
+}
+
+// Equal reports whether the element equals other.
+func (e ModPElement) Equal(other GroupElement) bool {
+	o, ok := other.(ModPElement)
+	return ok && e.Value.Cmp(o.Value) == 0 && e.Group.P.Cmp(o.Group.P) == 0
+}
+
+// Order returns Q.
+func (e ModPElement) Order() *big.Int {
+	return e.Group.Q
+}
+
+// Bytes returns the value as a big-endian number of the byte length of P.
+func (e ModPElement) Bytes() []byte {
+	b := make([]byte, (e.Group.P.BitLen()+7)/8)
+	return e.Value.FillBytes(b)
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testGroup is the subgroup of order 1019 of the integers modulo the safe prime 2039.
+var testGroup = &ModPGroup{P: big.NewInt(2039), Q: big.NewInt(1019), G: big.NewInt(4)}
+
+// largeTestGroup is a group of 127-bit order, for tests in which testGroup would accept a forgery
+// with non-negligible probability.
+var largeTestGroup = &ModPGroup{
+	P: parseTestInt("340282366920938463463374607431768196007"),
+	Q: parseTestInt("170141183460469231731687303715884098003"),
+	G: big.NewInt(4),
+}
+
+func parseTestInt(s string) *big.Int {
+	n, _ := big.NewInt(0).SetString(s, 10)
+	return n
+}
+
+func TestModPGroup(t *testing.T) {
+	assert := assert.New(t)
+	g := testGroup.Generator()
+	assert.True(g.ScalarMult(testGroup.Q).Equal(testGroup.Element(big.NewInt(1))))
+	assert.True(g.ScalarMult(big.NewInt(5)).Equal(g.ScalarMult(big.NewInt(2)).Add(g.ScalarMult(big.NewInt(3)))))
+	assert.True(g.ScalarMult(big.NewInt(-1)).Add(g).Equal(testGroup.Element(big.NewInt(1))))
+	assert.False(g.Equal(g.ScalarMult(big.NewInt(2))))
+
+	h := g.ScalarMult(big.NewInt(77))
+	decoded, err := testGroup.Decode(h.Bytes())
+	assert.NoError(err)
+	assert.True(h.Equal(decoded))
+	for _, invalid := range [][]byte{{0, 0}, {0x07, 0xf6}, {0x07, 0xf7}, {4}, {0, 0, 4}} {
+		_, err = testGroup.Decode(invalid)
+		assert.Equal(ErrorInvalidElement, err)
+	}
+}
+
+func TestCombineExponent(t *testing.T) {
+	assert := assert.New(t)
+	g := testGroup.Generator()
+	secret := big.NewInt(777)
+	shares := ShareFiniteField(secret, testGroup.Q, 2, 5)
+
+	partials := []GroupElement{}
+	xs := []int{}
+	for _, i := range []int{4, 0, 2} {
+		partials = append(partials, g.ScalarMult(shares[i].Y))
+		xs = append(xs, shares[i].X)
+	}
+	combined, err := CombineExponent(partials, xs)
+	assert.NoError(err)
+	assert.True(combined.Equal(g.ScalarMult(secret)))
+
+	_, err = CombineExponent(nil, nil)
+	assert.Equal(ErrorNoShares, err)
+	_, err = CombineExponent(partials, xs[:2])
+	assert.Equal(ErrorVectorLength, err)
+	_, err = CombineExponent(partials[:2], []int{1, 1})
+	assert.Equal(ErrorDuplicateX, err)
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	for _, n := range []int{1, 3, 4, 17, 100} {
+		elements := make([]GroupElement, n)
+		scalars := make([]*big.Int, n)
+		expected := g.ScalarMult(big.NewInt(0))
+		for i := range elements {
+			k, _ := rand.Int(rand.Reader, largeTestGroup.Q)
+			elements[i] = g.ScalarMult(k)
+			scalars[i], _ = rand.Int(rand.Reader, largeTestGroup.Q)
+			if i%5 == 1 {
+				scalars[i].SetInt64(0)
+			} else if i%5 == 2 {
+				scalars[i].Neg(scalars[i])
+			}
+			expected = expected.Add(elements[i].ScalarMult(scalars[i]))
+		}
+		sum, err := MultiScalarMult(elements, scalars)
+		assert.NoError(err)
+		assert.True(expected.Equal(sum))
+
+		for i := range scalars {
+			scalars[i] = big.NewInt(0)
+		}
+		sum, err = MultiScalarMult(elements, scalars)
+		assert.NoError(err)
+		assert.True(g.ScalarMult(big.NewInt(0)).Equal(sum))
+	}
+
+	_, err := MultiScalarMult(nil, nil)
+	assert.Equal(ErrorVectorLength, err)
+	_, err = MultiScalarMult([]GroupElement{g}, nil)
+	assert.Equal(ErrorVectorLength, err)
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrorUnknownField    = errors.New("Unknown field identifier")
+	ErrorInvalidFieldID  = errors.New("Field identifiers must be non-empty and not a number")
+	ErrorFieldRegistered = errors.New("Field identifier or field size is already registered")
+)
+
+var (
+	fieldMutex sync.RWMutex
+	// fieldSizes maps identifiers to field sizes, and fieldIDs maps decimal field sizes back to
+	// identifiers.
+	fieldSizes = make(map[string]*big.Int)
+	fieldIDs   = make(map[string]string)
+	// fieldImplementations maps identifiers to the arithmetic of the fields, including a PrimeField
+	// for every registered field size.
+	fieldImplementations = make(map[string]Field)
+)
+
+func init() {
+	for id, fieldSize := range map[string]*big.Int{
+		"p25519": Conservative128().FieldSize,
+		"m127":   Fast80().FieldSize,
+		"m61":    big.NewInt(0).Sub(big.NewInt(0).Lsh(big.NewInt(1), 61), big.NewInt(1)),
+	} {
+		if err := RegisterField(id, fieldSize); err != nil {
+			panic(err)
+		}
+	}
+	for id, field := range map[string]Field{
+		"gf256":        GF256Field{},
+		"p25519-fixed": FixedField{},
+	} {
+		if err := RegisterFieldImplementation(id, field); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterField registers an identifier for the finite field of integers modulo fieldSize, so that
+// serialized shares can refer to the field by a short name such as "p25519" instead of its size.
+// The fields of 2^255 - 19 ("p25519"), 2^127 - 1 ("m127") and 2^61 - 1 ("m61") are registered by
+// default. Identifiers are case-sensitive, and applications should prefix their own identifiers with
+// a domain, such as "example.com/p" or an OID, to avoid collisions. Neither an identifier nor a
+// field size can be registered twice. The field is also registered as a PrimeField, see
+// LookupFieldImplementation.
+func RegisterField(id string, fieldSize *big.Int) error {
+	if id == "" || strings.Trim(id, "0123456789") == "" {
+		return ErrorInvalidFieldID
+	}
+	if fieldSize.Cmp(big.NewInt(1)) <= 0 {
+		return ErrorFieldTooSmall
+	}
+	fieldMutex.Lock()
+	defer fieldMutex.Unlock()
+	if _, ok := fieldImplementations[id]; ok {
+		return ErrorFieldRegistered
+	}
+	if _, ok := fieldIDs[fieldSize.String()]; ok {
+		return ErrorFieldRegistered
+	}
+	fieldSizes[id] = big.NewInt(0).Set(fieldSize)
+	fieldIDs[fieldSize.String()] = id
+	fieldImplementations[id] = &PrimeField{Modulus: big.NewInt(0).Set(fieldSize)}
+	return nil
+}
+
+// RegisterFieldImplementation registers an identifier for a Field with its own arithmetic, such as
+// GF256Field, so that serialized FieldShares can refer to it and deserialization can find the
+// arithmetic by LookupFieldImplementation. GF256Field is registered as "gf256" and FixedField as
+// "p25519-fixed" by default. A PrimeField is registered by its modulus with RegisterField. The
+// identifier rules of RegisterField apply, and an identifier cannot be registered twice.
+func RegisterFieldImplementation(id string, field Field) error {
+	if prime, ok := field.(*PrimeField); ok {
+		return RegisterField(id, prime.Modulus)
+	}
+	if id == "" || strings.Trim(id, "0123456789") == "" {
+		return ErrorInvalidFieldID
+	}
+	fieldMutex.Lock()
+	defer fieldMutex.Unlock()
+	if _, ok := fieldImplementations[id]; ok {
+		return ErrorFieldRegistered
+	}
+	fieldImplementations[id] = field
+	return nil
+}
+
+// LookupFieldImplementation returns the Field registered under id. Every lookup of an identifier
+// returns the same Field, so that FieldShares deserialized separately can be combined; the Field
+// must not be modified.
+func LookupFieldImplementation(id string) (Field, error) {
+	fieldMutex.RLock()
+	defer fieldMutex.RUnlock()
+	field, ok := fieldImplementations[id]
+	if !ok {
+		return nil, ErrorUnknownField
+	}
+	return field, nil
+}
+
+// FieldImplementationID returns the identifier that field is registered under, or the empty string
+// if it is not registered. A PrimeField is found by its modulus, other fields if they equal the
+// registered Field.
+func FieldImplementationID(field Field) string {
+	if prime, ok := field.(*PrimeField); ok {
+		return FieldID(prime.Modulus)
+	}
+	if field == nil || !reflect.TypeOf(field).Comparable() {
+		return ""
+	}
+	fieldMutex.RLock()
+	defer fieldMutex.RUnlock()
+	for id, registered := range fieldImplementations {
+		if reflect.TypeOf(registered) == reflect.TypeOf(field) && registered == field {
+			return id
+		}
+	}
+	return ""
+}
+
+// LookupField returns the size of the field registered under id.
+func LookupField(id string) (*big.Int, error) {
+	fieldMutex.RLock()
+	defer fieldMutex.RUnlock()
+	fieldSize, ok := fieldSizes[id]
+	if !ok {
+		return nil, ErrorUnknownField
+	}
+	return big.NewInt(0).Set(fieldSize), nil
+}
+
+// FieldID returns the identifier of the field of integers modulo fieldSize, or the empty string if
+// the field is not registered.
+func FieldID(fieldSize *big.Int) string {
+	fieldMutex.RLock()
+	defer fieldMutex.RUnlock()
+	return fieldIDs[fieldSize.String()]
+}
+
+// FormatFieldSize returns the identifier of the field of integers modulo fieldSize if it is
+// registered, or the field size in decimal otherwise. ParseFieldSize reverses it.
+func FormatFieldSize(fieldSize *big.Int) string {
+	if id := FieldID(fieldSize); id != "" {
+		return id
+	}
+	return fieldSize.String()
+}
+
+// ParseFieldSize returns the size of the field that s refers to, which is either a registered
+// identifier or a field size in decimal.
+func ParseFieldSize(s string) (*big.Int, error) {
+	if fieldSize, ok := big.NewInt(0).SetString(s, 10); ok {
+		if fieldSize.Cmp(big.NewInt(1)) <= 0 {
+			return nil, ErrorFieldTooSmall
+		}
+		return fieldSize, nil
+	}
+	return LookupField(s)
+}
+
+// registeredFields returns the sizes of all registered fields in increasing order.
+func registeredFields() []*big.Int {
+	fieldMutex.RLock()
+	defer fieldMutex.RUnlock()
+	fields := make([]*big.Int, 0, len(fieldSizes))
+	for _, fieldSize := range fieldSizes {
+		fields = append(fields, big.NewInt(0).Set(fieldSize))
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Cmp(fields[j]) < 0
+	})
+	return fields
+}
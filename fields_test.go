@@ -0,0 +1,123 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterField(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize, err := LookupField("p25519")
+	assert.NoError(err)
+	assert.Equal(Conservative128().FieldSize, fieldSize)
+	assert.Equal("m127", FieldID(Fast80().FieldSize))
+	assert.Equal("", FieldID(big.NewInt(7919)))
+
+	// The registry hands out copies
+	fieldSize.SetInt64(5)
+	fieldSize, err = LookupField("p25519")
+	assert.NoError(err)
+	assert.Equal(Conservative128().FieldSize, fieldSize)
+
+	assert.NoError(RegisterField("example.com/p7907", big.NewInt(7907)))
+	assert.Equal("example.com/p7907", FieldID(big.NewInt(7907)))
+	assert.Equal(ErrorFieldRegistered, RegisterField("example.com/p7907", big.NewInt(7901)))
+	assert.Equal(ErrorFieldRegistered, RegisterField("example.com/other", big.NewInt(7907)))
+	assert.Equal(ErrorInvalidFieldID, RegisterField("", big.NewInt(7901)))
+	assert.Equal(ErrorInvalidFieldID, RegisterField("7901", big.NewInt(7901)))
+	assert.Equal(ErrorFieldTooSmall, RegisterField("one", big.NewInt(1)))
+	_, err = LookupField("p7901")
+	assert.Equal(ErrorUnknownField, err)
+}
+
+// testField is a Field with its own arithmetic, registered by TestRegisterFieldImplementation.
+type testField struct {
+	GF256Field
+	name string
+}
+
+func TestRegisterFieldImplementation(t *testing.T) {
+	assert := assert.New(t)
+	field, err := LookupFieldImplementation("gf256")
+	assert.NoError(err)
+	assert.Equal(GF256Field{}, field)
+	assert.Equal("gf256", FieldImplementationID(GF256Field{}))
+
+	// Registered field sizes come with their prime field, which is the same for every lookup
+	field, err = LookupFieldImplementation("m61")
+	assert.NoError(err)
+	assert.Equal(&PrimeField{Modulus: mersenne61}, field)
+	again, err := LookupFieldImplementation("m61")
+	assert.NoError(err)
+	assert.True(field == again)
+	assert.Equal("m61", FieldImplementationID(&PrimeField{Modulus: mersenne61}))
+	assert.Equal("", FieldImplementationID(&PrimeField{Modulus: big.NewInt(7919)}))
+
+	// Shares deserialized with the looked up arithmetic combine
+	shares, err := ShareOver(field, field.(*PrimeField).Element(big.NewInt(42)), 1, 3, rand.Reader)
+	assert.NoError(err)
+	for i := range shares {
+		shares[i].Field = again
+	}
+	secret, err := CombineOver(shares[:2])
+	assert.NoError(err)
+	assert.True(secret.Equal(again.(*PrimeField).Element(big.NewInt(42))))
+
+	assert.NoError(RegisterFieldImplementation("example.com/test", testField{name: "test"}))
+	field, err = LookupFieldImplementation("example.com/test")
+	assert.NoError(err)
+	assert.Equal(testField{name: "test"}, field)
+	assert.Equal("example.com/test", FieldImplementationID(testField{name: "test"}))
+	assert.Equal("", FieldImplementationID(testField{name: "other"}))
+	assert.Equal(ErrorFieldRegistered, RegisterFieldImplementation("example.com/test", testField{}))
+	assert.Equal(ErrorFieldRegistered, RegisterFieldImplementation("m61", testField{}))
+	assert.Equal(ErrorFieldRegistered, RegisterField("gf256", big.NewInt(7901)))
+	assert.Equal(ErrorInvalidFieldID, RegisterFieldImplementation("256", testField{}))
+
+	// Prime fields are registered by their modulus
+	assert.NoError(RegisterFieldImplementation("example.com/p7877", &PrimeField{Modulus: big.NewInt(7877)}))
+	assert.Equal("example.com/p7877", FieldID(big.NewInt(7877)))
+
+	_, err = LookupFieldImplementation("unknown")
+	assert.Equal(ErrorUnknownField, err)
+	_, err = LookupField("gf256")
+	assert.Equal(ErrorUnknownField, err)
+}
+
+func TestParseFieldSize(t *testing.T) {
+	assert := assert.New(t)
+	for _, fieldSize := range []*big.Int{Conservative128().FieldSize, big.NewInt(7919)} {
+		parsed, err := ParseFieldSize(FormatFieldSize(fieldSize))
+		assert.NoError(err)
+		assert.Equal(fieldSize, parsed)
+	}
+	assert.Equal("p25519", FormatFieldSize(Conservative128().FieldSize))
+	assert.Equal("7919", FormatFieldSize(big.NewInt(7919)))
+
+	_, err := ParseFieldSize("unknown")
+	assert.Equal(ErrorUnknownField, err)
+	_, err = ParseFieldSize("0")
+	assert.Equal(ErrorFieldTooSmall, err)
+
+	// Test vectors may refer to registered fields
+	vector := NewTestVector("registered", big.NewInt(42), Conservative128().FieldSize, 1, 3, []byte("seed"))
+	vector.FieldSize = "p25519"
+	assert.NoError(vector.Check())
+}
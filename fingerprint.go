@@ -0,0 +1,60 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+)
+
+// sharingFingerprintSize is the size in bytes of sharing fingerprints. Fingerprints only need to
+// tell apart the sharings that a custodian or tool may confuse, not resist forgery, so they are
+// short enough to read out or print on a label.
+const sharingFingerprintSize = 8
+
+// SharingFingerprint returns a short fingerprint of the sharing committed to, which ShareFeldman
+// sets on the shares it deals.
+func (c Commitments) SharingFingerprint() []byte {
+	return c.Digest()[:sharingFingerprintSize]
+}
+
+// SharingFingerprint returns a short fingerprint of the transcript so far, for instance of a
+// dealing whose parameters and share fingerprints were appended to it, to set on its shares with
+// WithSharingFingerprint.
+func (t *Transcript) SharingFingerprint() []byte {
+	return t.Digest()[:sharingFingerprintSize]
+}
+
+// WithSharingFingerprint returns copies of shares with the given sharing fingerprint, so that
+// custodians and tools can check with SameSharing that two shares belong together before attempting
+// a reconstruction.
+func WithSharingFingerprint(shares []Share, fingerprint []byte) []Share {
+	tagged := make([]Share, len(shares))
+	for i, share := range shares {
+		tagged[i] = share
+		tagged[i].SharingFingerprint = append([]byte{}, fingerprint...)
+	}
+	return tagged
+}
+
+// SameSharing reports whether a and b can belong to the same sharing: their parameters are equal,
+// and so are their sharing fingerprints if both have one.
+func SameSharing(a Share, b Share) bool {
+	return equalOrBothNil(a.FieldSize, b.FieldSize) && equalOrBothNil(a.Factor, b.Factor) && a.Degree == b.Degree && sameFingerprint(a, b)
+}
+
+// sameFingerprint reports whether a and b have equal sharing fingerprints, or one of them has none.
+func sameFingerprint(a Share, b Share) bool {
+	return a.SharingFingerprint == nil || b.SharingFingerprint == nil || bytes.Equal(a.SharingFingerprint, b.SharingFingerprint)
+}
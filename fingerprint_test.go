@@ -0,0 +1,68 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharingFingerprint(t *testing.T) {
+	assert := assert.New(t)
+	g := testGroup.Generator()
+	a, ca := ShareFeldman(big.NewInt(1), g, 1, 3)
+	b, cb := ShareFeldman(big.NewInt(2), g, 1, 3)
+	assert.Len(ca.SharingFingerprint(), sharingFingerprintSize)
+	assert.NotEqual(ca.SharingFingerprint(), cb.SharingFingerprint())
+	for i := range a {
+		assert.Equal(ca.SharingFingerprint(), a[i].SharingFingerprint)
+		assert.True(SameSharing(a[0], a[i]))
+		assert.False(SameSharing(a[i], b[i]))
+	}
+
+	// Shares of different sharings with the same parameters are not combined
+	_, err := ShareCombine([]Share{a[0], b[1]})
+	assert.True(errors.Is(err, ErrorIncompatibleShares))
+	secret, err := ShareCombine([]Share{a[2], a[0]})
+	assert.NoError(err)
+	assert.Equal(int64(1), secret.Int64())
+	// Shares without a fingerprint match any sharing
+	untagged := a[1]
+	untagged.SharingFingerprint = nil
+	assert.True(SameSharing(untagged, b[0]))
+	// The operations on shares give shares of a new sharing
+	sum, err := ShareAdd([]Share{a[0], b[0]})
+	assert.NoError(err)
+	assert.Nil(sum.SharingFingerprint)
+
+	transcript := NewTranscript([]byte("session"))
+	transcript.AppendParameters(big.NewInt(7919), 1, 3)
+	shares := WithSharingFingerprint(ShareFiniteField(big.NewInt(5), big.NewInt(7919), 1, 3), transcript.SharingFingerprint())
+	assert.Equal(transcript.SharingFingerprint(), shares[2].SharingFingerprint)
+	assert.False(SameSharing(shares[0], ShareFiniteField(big.NewInt(5), big.NewInt(7919), 2, 3)[0]))
+
+	encoded, err := json.Marshal(untagged)
+	assert.NoError(err)
+	assert.NotContains(string(encoded), "SharingFingerprint")
+	encoded, err = json.Marshal(a[0])
+	assert.NoError(err)
+	var decoded Share
+	assert.NoError(json.Unmarshal(encoded, &decoded))
+	assert.Equal(a[0].SharingFingerprint, decoded.SharingFingerprint)
+}
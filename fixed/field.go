@@ -0,0 +1,167 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixed
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+var (
+	ErrorNotCanonical = errors.New("Encoding is not a canonical field element")
+)
+
+// An Element is an element of the field of integers modulo p = 2^255 - 19, stored as four 64-bit
+// limbs, least significant first. Elements are always reduced modulo p.
+type Element [4]uint64
+
+// p is the field size 2^255 - 19.
+var p = Element{0xffffffffffffffed, 0xffffffffffffffff, 0xffffffffffffffff, 0x7fffffffffffffff}
+
+// FromBytes decodes a 32-byte big-endian encoding of an element, which must be smaller than p.
+func FromBytes(b [32]byte) (Element, error) {
+	var e Element
+	for i := range e {
+		e[i] = binary.BigEndian.Uint64(b[24-8*i:])
+	}
+	if !e.less(p) {
+		return Element{}, ErrorNotCanonical
+	}
+	return e, nil
+}
+
+// FromUint64 returns the element with value n.
+func FromUint64(n uint64) Element {
+	return Element{n, 0, 0, 0}
+}
+
+// Bytes returns the 32-byte big-endian encoding of the element.
+func (e Element) Bytes() [32]byte {
+	var b [32]byte
+	for i := range e {
+		binary.BigEndian.PutUint64(b[24-8*i:], e[i])
+	}
+	return b
+}
+
+// Add returns e + f.
+func (e Element) Add(f Element) Element {
+	// e + f < 2p < 2^256, so there is no carry out of the top limb
+	var sum Element
+	var carry uint64
+	for i := range sum {
+		sum[i], carry = bits.Add64(e[i], f[i], carry)
+	}
+	return sum.reduceOnce()
+}
+
+// Sub returns e - f.
+func (e Element) Sub(f Element) Element {
+	var difference Element
+	var borrow uint64
+	for i := range difference {
+		difference[i], borrow = bits.Sub64(e[i], f[i], borrow)
+	}
+	if borrow != 0 {
+		var carry uint64
+		for i := range difference {
+			difference[i], carry = bits.Add64(difference[i], p[i], carry)
+		}
+	}
+	return difference
+}
+
+// Mul returns e * f.
+func (e Element) Mul(f Element) Element {
+	// Schoolbook multiplication into eight limbs
+	var product [8]uint64
+	for i := range e {
+		var carry uint64
+		for j := range f {
+			hi, lo := bits.Mul64(e[i], f[j])
+			var c uint64
+			lo, c = bits.Add64(lo, product[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			product[i+j] = lo
+			carry = hi
+		}
+		product[i+4] = carry
+	}
+
+	// 2^256 = 38 mod p, so fold the upper limbs onto the lower ones
+	var folded Element
+	var top uint64
+	for i := range folded {
+		hi, lo := bits.Mul64(product[i+4], 38)
+		var c uint64
+		folded[i], c = bits.Add64(product[i], lo, 0)
+		hi += c
+		folded[i], c = bits.Add64(folded[i], top, 0)
+		top = hi + c
+	}
+	// Fold the remaining carry, which is small
+	var carry uint64
+	folded[0], carry = bits.Add64(folded[0], top*38, 0)
+	for i := 1; i < len(folded) && carry != 0; i++ {
+		folded[i], carry = bits.Add64(folded[i], 0, carry)
+	}
+	if carry != 0 {
+		folded[0] += 38
+	}
+	return folded.reduceOnce().reduceOnce()
+}
+
+// Inverse returns the multiplicative inverse of e, or zero for zero. It computes e^(p-2).
+func (e Element) Inverse() Element {
+	exponent := p.Sub(FromUint64(2))
+	result := FromUint64(1)
+	for i := 255; i >= 0; i-- {
+		result = result.Mul(result)
+		if exponent[i/64]>>(uint(i)%64)&1 == 1 {
+			result = result.Mul(e)
+		}
+	}
+	return result
+}
+
+// IsZero reports whether e is zero.
+func (e Element) IsZero() bool {
+	return e == Element{}
+}
+
+// reduceOnce subtracts p from e if e >= p.
+func (e Element) reduceOnce() Element {
+	if e.less(p) {
+		return e
+	}
+	var borrow uint64
+	for i := range e {
+		e[i], borrow = bits.Sub64(e[i], p[i], borrow)
+	}
+	return e
+}
+
+// less reports whether e < f as integers.
+func (e Element) less(f Element) bool {
+	for i := len(e) - 1; i >= 0; i-- {
+		if e[i] != f[i] {
+			return e[i] < f[i]
+		}
+	}
+	return false
+}
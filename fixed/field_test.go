@@ -0,0 +1,73 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixed
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var bigP = big.NewInt(0).Sub(big.NewInt(0).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+func toBig(e Element) *big.Int {
+	b := e.Bytes()
+	return big.NewInt(0).SetBytes(b[:])
+}
+
+func fromBig(n *big.Int) Element {
+	var b [32]byte
+	big.NewInt(0).Mod(n, bigP).FillBytes(b[:])
+	e, err := FromBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func TestFieldArithmetic(t *testing.T) {
+	assert := assert.New(t)
+	pMinusOne := big.NewInt(0).Sub(bigP, big.NewInt(1))
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(19), big.NewInt(38), pMinusOne, big.NewInt(0).Rsh(bigP, 1)}
+	for i := 0; i < 50; i++ {
+		v, _ := rand.Int(rand.Reader, bigP)
+		values = append(values, v)
+	}
+
+	for _, a := range values {
+		for _, b := range values[:10] {
+			ea, eb := fromBig(a), fromBig(b)
+			expected := big.NewInt(0).Add(a, b)
+			assert.Equal(expected.Mod(expected, bigP).String(), toBig(ea.Add(eb)).String())
+			expected = big.NewInt(0).Sub(a, b)
+			assert.Equal(expected.Mod(expected, bigP).String(), toBig(ea.Sub(eb)).String())
+			expected = big.NewInt(0).Mul(a, b)
+			assert.Equal(expected.Mod(expected, bigP).String(), toBig(ea.Mul(eb)).String())
+			expected = big.NewInt(0).Mul(b, a)
+			assert.Equal(expected.Mod(expected, bigP).String(), toBig(eb.Mul(ea)).String())
+		}
+		if a.Sign() != 0 {
+			assert.Equal(big.NewInt(0).ModInverse(a, bigP).String(), toBig(fromBig(a).Inverse()).String())
+		}
+	}
+
+	var encoded [32]byte
+	bigP.FillBytes(encoded[:])
+	_, err := FromBytes(encoded)
+	assert.Equal(ErrorNotCanonical, err)
+	assert.True(FromUint64(0).IsZero())
+}
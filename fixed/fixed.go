@@ -0,0 +1,118 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fixed implements the split and combine core of Shamir secret sharing over the fixed field
+// of integers modulo 2^255 - 19, using 256-bit arithmetic on 64-bit limbs instead of math/big. It
+// has no dependencies beyond a few small standard library packages, so it can be compiled with
+// TinyGo or to WebAssembly for custodian endpoints such as browsers and small devices.
+//
+// Its shares are compatible with the shares of the shamir package with FieldSize 2^255 - 19, see
+// shamir.Conservative128.
+package fixed
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+var (
+	ErrorNoShares           = errors.New("Empty share slice given")
+	ErrorTooFewShares       = errors.New("Too few shares given")
+	ErrorIncompatibleShares = errors.New("Attempted to combine shares with different parameters")
+	ErrorDuplicateX         = errors.New("Shares with equal X coordinates given")
+)
+
+// A Share is a share of a secret element.
+type Share struct {
+	Degree int
+	X      int
+	Y      Element
+}
+
+// Split shares a secret using a random polynomial of the given degree, and returns nShares shares.
+// The coefficients are read from crypto/rand.
+func Split(secret Element, degree int, nShares int) ([]Share, error) {
+	coefficients := make([]Element, degree)
+	for i := range coefficients {
+		var err error
+		coefficients[i], err = Random(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+	shares := make([]Share, nShares)
+	for i := range shares {
+		// Horner's rule
+		x := FromUint64(uint64(i + 1))
+		y := Element{}
+		for j := len(coefficients) - 1; j >= 0; j-- {
+			y = y.Add(coefficients[j]).Mul(x)
+		}
+		shares[i] = Share{Degree: degree, X: i + 1, Y: y.Add(secret)}
+	}
+	return shares, nil
+}
+
+// Combine recovers the secret from degree+1 or more shares, using the first degree+1 of them.
+func Combine(shares []Share) (Element, error) {
+	if len(shares) == 0 {
+		return Element{}, ErrorNoShares
+	}
+	degree := shares[0].Degree
+	if len(shares) <= degree {
+		return Element{}, ErrorTooFewShares
+	}
+	for _, share := range shares {
+		if share.Degree != degree || share.X < 1 {
+			return Element{}, ErrorIncompatibleShares
+		}
+	}
+	shares = shares[:degree+1]
+
+	var secret Element
+	for i := range shares {
+		// Lagrange coefficient at zero: prod(j != i) x_j / (x_j - x_i)
+		numerator, denominator := FromUint64(1), FromUint64(1)
+		xi := FromUint64(uint64(shares[i].X))
+		for j := range shares {
+			if i == j {
+				continue
+			}
+			if shares[i].X == shares[j].X {
+				return Element{}, ErrorDuplicateX
+			}
+			xj := FromUint64(uint64(shares[j].X))
+			numerator = numerator.Mul(xj)
+			denominator = denominator.Mul(xj.Sub(xi))
+		}
+		secret = secret.Add(shares[i].Y.Mul(numerator).Mul(denominator.Inverse()))
+	}
+	return secret, nil
+}
+
+// Random returns a uniformly random element, reading randomness from random and using rejection
+// sampling.
+func Random(random io.Reader) (Element, error) {
+	for {
+		var b [32]byte
+		if _, err := io.ReadFull(random, b[:]); err != nil {
+			return Element{}, err
+		}
+		b[0] &= 0x7f
+		if e, err := FromBytes(b); err == nil {
+			return e, nil
+		}
+	}
+}
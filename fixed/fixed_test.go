@@ -0,0 +1,41 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixed
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitCombine(t *testing.T) {
+	assert := assert.New(t)
+	secret := fromBig(big.NewInt(123456789))
+	shares, err := Split(secret, 2, 5)
+	assert.NoError(err)
+	assert.Len(shares, 5)
+
+	combined, err := Combine([]Share{shares[4], shares[1], shares[3]})
+	assert.NoError(err)
+	assert.Equal(secret, combined)
+
+	_, err = Combine(shares[:2])
+	assert.Equal(ErrorTooFewShares, err)
+	_, err = Combine([]Share{shares[0], shares[0], shares[1]})
+	assert.Equal(ErrorDuplicateX, err)
+	_, err = Combine(nil)
+	assert.Equal(ErrorNoShares, err)
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorInvalidWindow = errors.New("Window size must be between 1 and 16 bits")
+)
+
+// A FixedBase is a generator with precomputed multiples, for dealers that repeatedly deal in the
+// same group. With a window of w bits, ScalarMult takes one group addition per w bits of the group
+// order instead of the doublings and additions of a plain scalar multiplication, at the cost of
+// storing (2^w - 1) elements per w bits of the group order.
+//
+// A FixedBase can be passed as the generator to ShareFeldman, Commitments.Verify, Attest,
+// Encapsulate and the other functions that take a generator. It is a GroupElement itself, but only
+// as the receiver of Add and Equal; pass Generator() as their argument instead.
+type FixedBase struct {
+	generator GroupElement
+	identity  GroupElement
+	window    int
+	// table[i][d-1] is d * 2^(window*i) * generator
+	table [][]GroupElement
+}
+
+// NewFixedBase precomputes the multiples of generator for windows of windowBits bits. A window of 4
+// to 8 bits is a reasonable choice for most groups.
+func NewFixedBase(generator GroupElement, windowBits int) (*FixedBase, error) {
+	if windowBits < 1 || windowBits > 16 {
+		return nil, ErrorInvalidWindow
+	}
+	f := &FixedBase{generator: generator, identity: generator.ScalarMult(big.NewInt(0)), window: windowBits}
+	base := generator
+	for i := 0; i*windowBits < generator.Order().BitLen(); i++ {
+		multiples := make([]GroupElement, 1<<uint(windowBits)-1)
+		multiples[0] = base
+		for d := 1; d < len(multiples); d++ {
+			multiples[d] = multiples[d-1].Add(base)
+		}
+		f.table = append(f.table, multiples)
+		base = multiples[len(multiples)-1].Add(base)
+	}
+	return f, nil
+}
+
+// Generator returns the generator of which the multiples are precomputed.
+func (f *FixedBase) Generator() GroupElement {
+	return f.generator
+}
+
+// ScalarMult returns the generator multiplied by k, using the precomputed multiples.
+func (f *FixedBase) ScalarMult(k *big.Int) GroupElement {
+	scalar := big.NewInt(0).Mod(k, f.generator.Order())
+	var result GroupElement
+	for i, multiples := range f.table {
+		digit := 0
+		for b := f.window - 1; b >= 0; b-- {
+			digit = digit<<1 | int(scalar.Bit(i*f.window+b))
+		}
+		if digit != 0 {
+			result = addOrNil(result, multiples[digit-1])
+		}
+	}
+	if result == nil {
+		return f.identity
+	}
+	return result
+}
+
+// Add returns the sum of the generator and other.
+func (f *FixedBase) Add(other GroupElement) GroupElement {
+	return f.generator.Add(other)
+}
+
+// Equal reports whether the generator equals other.
+func (f *FixedBase) Equal(other GroupElement) bool {
+	if o, ok := other.(*FixedBase); ok {
+		other = o.generator
+	}
+	return f.generator.Equal(other)
+}
+
+// Order returns the order of the group.
+func (f *FixedBase) Order() *big.Int {
+	return f.generator.Order()
+}
+
+// Bytes returns the encoding of the generator, so that a FixedBase and its generator are hashed
+// alike.
+func (f *FixedBase) Bytes() []byte {
+	return f.generator.Bytes()
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedBase(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	for _, window := range []int{1, 4, 7} {
+		f, err := NewFixedBase(g, window)
+		assert.NoError(err)
+		assert.True(f.Equal(g))
+		assert.True(g.Equal(f.Generator()))
+		assert.Equal(g.Bytes(), f.Bytes())
+		assert.Equal(largeTestGroup.Q, f.Order())
+		for _, k := range []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(-1), largeTestGroup.Q} {
+			assert.True(g.ScalarMult(k).Equal(f.ScalarMult(k)))
+		}
+		for i := 0; i < 10; i++ {
+			k, _ := rand.Int(rand.Reader, largeTestGroup.P)
+			assert.True(g.ScalarMult(k).Equal(f.ScalarMult(k)))
+		}
+		assert.True(g.Add(g).Equal(f.Add(g)))
+
+		shares, commitments := ShareFeldman(big.NewInt(777), f, 2, 5)
+		for _, share := range shares {
+			assert.True(commitments.Verify(g, share))
+			assert.True(commitments.Verify(f, share))
+		}
+		assert.True(commitments[0].Equal(g.ScalarMult(big.NewInt(777))))
+	}
+
+	_, err := NewFixedBase(g, 0)
+	assert.Equal(ErrorInvalidWindow, err)
+	_, err = NewFixedBase(g, 17)
+	assert.Equal(ErrorInvalidWindow, err)
+}
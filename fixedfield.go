@@ -0,0 +1,116 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/TNO-MPC/shamir/fixed"
+)
+
+// FixedField is the field of integers modulo 2^255 - 19 with the arithmetic of the fixed package,
+// which uses 256-bit limbs instead of math/big. It is the field of Conservative128, so ShareOver and
+// CombineOver can use either FixedField or the PrimeField of that field size, and ToFixedShare and
+// FromFixedShare convert between Share and the shares of the fixed package. FixedField is
+// registered as "p25519-fixed", see LookupFieldImplementation.
+type FixedField struct{}
+
+// Zero returns zero.
+func (FixedField) Zero() FieldElement {
+	return FixedElement{}
+}
+
+// One returns one.
+func (FixedField) One() FieldElement {
+	return FixedElement(fixed.FromUint64(1))
+}
+
+// Point returns x, for positive X coordinates.
+func (FixedField) Point(x int) (FieldElement, error) {
+	if x < 1 {
+		return nil, ErrorInvalidX
+	}
+	return FixedElement(fixed.FromUint64(uint64(x))), nil
+}
+
+// Random returns a uniformly random element.
+func (FixedField) Random(random io.Reader) (FieldElement, error) {
+	e, err := fixed.Random(random)
+	if err != nil {
+		return nil, err
+	}
+	return FixedElement(e), nil
+}
+
+// A FixedElement is an element of FixedField.
+type FixedElement fixed.Element
+
+// Add returns the sum of the element and other.
+func (e FixedElement) Add(other FieldElement) FieldElement {
+	return FixedElement(fixed.Element(e).Add(fixed.Element(other.(FixedElement))))
+}
+
+// Sub returns the difference of the element and other.
+func (e FixedElement) Sub(other FieldElement) FieldElement {
+	return FixedElement(fixed.Element(e).Sub(fixed.Element(other.(FixedElement))))
+}
+
+// Mul returns the product of the element and other.
+func (e FixedElement) Mul(other FieldElement) FieldElement {
+	return FixedElement(fixed.Element(e).Mul(fixed.Element(other.(FixedElement))))
+}
+
+// Inverse returns the inverse of the element.
+func (e FixedElement) Inverse() (FieldElement, error) {
+	if fixed.Element(e).IsZero() {
+		return nil, ErrorNotInvertible
+	}
+	return FixedElement(fixed.Element(e).Inverse()), nil
+}
+
+// Equal reports whether the element equals other.
+func (e FixedElement) Equal(other FieldElement) bool {
+	o, ok := other.(FixedElement)
+	return ok && e == o
+}
+
+// Bytes returns the 32-byte big-endian encoding of the element, which equals that of the
+// PrimeElement with the same value.
+func (e FixedElement) Bytes() []byte {
+	b := fixed.Element(e).Bytes()
+	return b[:]
+}
+
+// ToFixedShare converts a share over the field of Conservative128 to a share of the fixed package.
+func ToFixedShare(share Share) (fixed.Share, error) {
+	if share.FieldSize == nil || share.Y == nil || share.FieldSize.Cmp(Conservative128().FieldSize) != 0 || share.Y.Sign() < 0 || share.Y.Cmp(share.FieldSize) >= 0 {
+		return fixed.Share{}, ErrorWrongShareType
+	}
+	var b [32]byte
+	share.Y.FillBytes(b[:])
+	y, err := fixed.FromBytes(b)
+	if err != nil {
+		return fixed.Share{}, ErrorWrongShareType
+	}
+	return fixed.Share{Degree: share.Degree, X: share.X, Y: y}, nil
+}
+
+// FromFixedShare converts a share of the fixed package to a share over the field of
+// Conservative128.
+func FromFixedShare(share fixed.Share) Share {
+	b := share.Y.Bytes()
+	return Share{FieldSize: Conservative128().FieldSize, Degree: share.Degree, X: share.X, Y: big.NewInt(0).SetBytes(b[:])}
+}
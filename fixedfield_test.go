@@ -0,0 +1,75 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/TNO-MPC/shamir/fixed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedField(t *testing.T) {
+	assert := assert.New(t)
+	prime := &PrimeField{Modulus: Conservative128().FieldSize}
+	secret, _ := big.NewInt(0).SetString("98765432109876543210987654321", 10)
+
+	// FixedField computes like the PrimeField of the same size
+	shares, err := ShareOver(FixedField{}, FixedElement(fixed.FromUint64(1234)), 2, 4, rand.Reader)
+	assert.NoError(err)
+	combined, err := CombineOver(shares[1:])
+	assert.NoError(err)
+	assert.Equal(prime.Element(big.NewInt(1234)).Bytes(), combined.Bytes())
+	field, err := LookupFieldImplementation("p25519-fixed")
+	assert.NoError(err)
+	assert.Equal(FixedField{}, field)
+
+	_, err = FixedField{}.Zero().Inverse()
+	assert.Equal(ErrorNotInvertible, err)
+	seven, err := FixedField{}.Point(7)
+	assert.NoError(err)
+	inverse, err := seven.Inverse()
+	assert.NoError(err)
+	assert.True(inverse.Mul(seven).Equal(FixedField{}.One()))
+	_, err = FixedField{}.Point(0)
+	assert.Equal(ErrorInvalidX, err)
+
+	// Shares of the fixed package can be combined by this package
+	fixedShares, err := fixed.Split(fixed.FromUint64(42), 3, 6)
+	assert.NoError(err)
+	converted := make([]Share, len(fixedShares))
+	for i, share := range fixedShares {
+		converted[i] = FromFixedShare(share)
+	}
+	result, err := ShareCombine(converted[2:])
+	assert.NoError(err)
+	assert.Equal(int64(42), result.Int64())
+
+	// And the other way around
+	for i, share := range ShareFiniteField(secret, Conservative128().FieldSize, 3, 6) {
+		fixedShares[i], err = ToFixedShare(share)
+		assert.NoError(err)
+	}
+	element, err := fixed.Combine(fixedShares[1:5])
+	assert.NoError(err)
+	assert.Equal(secret, FromFixedShare(fixed.Share{Y: element}).Y)
+
+	_, err = ToFixedShare(ShareFiniteField(secret, mersenne61, 1, 2)[0])
+	assert.Equal(ErrorWrongShareType, err)
+	_, err = ToFixedShare(Share{FieldSize: Conservative128().FieldSize, Y: Conservative128().FieldSize})
+	assert.Equal(ErrorWrongShareType, err)
+}
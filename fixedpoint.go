@@ -0,0 +1,99 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorNotFinite = errors.New("Value is not a finite number")
+)
+
+// A FixedPoint encodes signed rational numbers as elements of the finite field of integers modulo
+// FieldSize, by scaling them by 2^Precision and rounding to the nearest integer. Negative numbers
+// are represented by their additive inverses, so encoded numbers can be added and multiplied by
+// integer constants on shares like integers. The product of two encoded numbers, see ShareMul, has
+// twice the precision and must be decoded with a FixedPoint of Precision 2*Precision.
+//
+// Numbers are encoded and decoded correctly as long as their scaled absolute value, and that of the
+// results of all computations on them, stays below FieldSize/2.
+type FixedPoint struct {
+	FieldSize *big.Int
+	Precision uint
+}
+
+// Encode returns the field element representing x, rounded to the nearest multiple of
+// 2^-Precision with ties away from zero. It returns ErrorSecretOutOfRange if x is too large for the field.
+func (f FixedPoint) Encode(x *big.Rat) (*big.Int, error) {
+	// round(|x| * 2^precision) = floor((2 * |num| * 2^precision + den) / (2 * den))
+	numerator := big.NewInt(0).Abs(x.Num())
+	numerator.Lsh(numerator, f.Precision+1).Add(numerator, x.Denom())
+	denominator := big.NewInt(0).Lsh(x.Denom(), 1)
+	scaled := numerator.Div(numerator, denominator)
+	if x.Sign() < 0 {
+		scaled.Neg(scaled)
+	}
+
+	half := big.NewInt(0).Rsh(f.FieldSize, 1)
+	if big.NewInt(0).Abs(scaled).Cmp(half) > 0 {
+		return nil, ErrorSecretOutOfRange
+	}
+	return scaled.Mod(scaled, f.FieldSize), nil
+}
+
+// EncodeFloat is like Encode for a float64, and returns ErrorNotFinite for infinities and NaN.
+func (f FixedPoint) EncodeFloat(x float64) (*big.Int, error) {
+	r := big.NewRat(0, 1).SetFloat64(x)
+	if r == nil {
+		return nil, ErrorNotFinite
+	}
+	return f.Encode(r)
+}
+
+// Decode returns the number represented by the field element y, interpreting y as a signed
+// integer in (-FieldSize/2, FieldSize/2].
+func (f FixedPoint) Decode(y *big.Int) *big.Rat {
+	signed := big.NewInt(0).Mod(y, f.FieldSize)
+	if signed.Cmp(big.NewInt(0).Rsh(f.FieldSize, 1)) > 0 {
+		signed.Sub(signed, f.FieldSize)
+	}
+	return big.NewRat(0, 1).SetFrac(signed, big.NewInt(0).Lsh(big.NewInt(1), f.Precision))
+}
+
+// DecodeFloat is like Decode, but returns the nearest float64.
+func (f FixedPoint) DecodeFloat(y *big.Int) float64 {
+	x, _ := f.Decode(y).Float64()
+	return x
+}
+
+// Share encodes x and shares it over the field, see ShareFiniteField.
+func (f FixedPoint) Share(x *big.Rat, degree int, nShares int) ([]Share, error) {
+	y, err := f.Encode(x)
+	if err != nil {
+		return nil, err
+	}
+	return ShareFiniteField(y, f.FieldSize, degree, nShares), nil
+}
+
+// Combine combines shares of an encoded number and decodes it.
+func (f FixedPoint) Combine(shares []Share) (*big.Rat, error) {
+	y, err := ShareCombine(shares)
+	if err != nil {
+		return nil, err
+	}
+	return f.Decode(y), nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedPoint(t *testing.T) {
+	assert := assert.New(t)
+	f := FixedPoint{FieldSize: mersenne61, Precision: 16}
+
+	for _, x := range []float64{0, 1, -1, 0.5, -0.25, 3.14159, -2718.28, 1e6} {
+		y, err := f.EncodeFloat(x)
+		assert.NoError(err)
+		assert.InDelta(x, f.DecodeFloat(y), 1.0/(1<<17))
+	}
+
+	// Rounding to the nearest multiple of 2^-16, with ties away from zero
+	y, err := f.Encode(big.NewRat(1, 1<<17))
+	assert.NoError(err)
+	assert.Equal(big.NewRat(1, 1<<16), f.Decode(y))
+	y, err = f.Encode(big.NewRat(-1, 1<<17))
+	assert.NoError(err)
+	assert.Equal(big.NewRat(-1, 1<<16), f.Decode(y))
+	y, err = f.Encode(big.NewRat(1, 3))
+	assert.NoError(err)
+	assert.Equal(big.NewRat(21845, 1<<16), f.Decode(y))
+
+	_, err = f.EncodeFloat(math.Inf(1))
+	assert.Equal(ErrorNotFinite, err)
+	_, err = f.EncodeFloat(math.NaN())
+	assert.Equal(ErrorNotFinite, err)
+	_, err = f.EncodeFloat(1e20)
+	assert.Equal(ErrorSecretOutOfRange, err)
+}
+
+func TestFixedPointShares(t *testing.T) {
+	assert := assert.New(t)
+	f := FixedPoint{FieldSize: mersenne61, Precision: 10}
+	a, err := f.Share(big.NewRat(-3, 2), 1, 3)
+	assert.NoError(err)
+	b, err := f.Share(big.NewRat(5, 4), 1, 3)
+	assert.NoError(err)
+
+	sums := make([]Share, 3)
+	products := make([]Share, 3)
+	for i := range a {
+		sums[i], err = ShareAdd([]Share{a[i], b[i]})
+		assert.NoError(err)
+		products[i], err = ShareMul([]Share{a[i], b[i]})
+		assert.NoError(err)
+	}
+	sum, err := f.Combine(sums[:2])
+	assert.NoError(err)
+	assert.Equal(big.NewRat(-1, 4), sum)
+
+	product, err := FixedPoint{FieldSize: mersenne61, Precision: 20}.Combine(products)
+	assert.NoError(err)
+	assert.Equal(big.NewRat(-15, 8), product)
+}
@@ -0,0 +1,331 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// Shamir sharing over Z_{2^k} is not possible directly, since the differences between X coordinates
+// must be invertible and only odd numbers are invertible modulo 2^k. The Galois ring
+// GR(2^k, d) = Z_{2^k}[X]/(f), for a monic f of degree d that is irreducible modulo 2, extends
+// Z_{2^k} such that the 2^d elements with coefficients in {0, 1} have invertible differences. These
+// serve as the X coordinates of up to 2^d-1 parties, and secrets in Z_{2^k} are embedded as
+// constant polynomials. Coefficients are uint64 values, so arithmetic modulo 2^64 is native.
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+var (
+	ErrorInvalidRing   = errors.New("Galois ring parameters out of range")
+	ErrorTooManyShares = errors.New("Too many shares for the Galois ring")
+	ErrorWrongRing     = errors.New("Element does not belong to the Galois ring")
+	ErrorNotUnit       = errors.New("Element of the Galois ring is not invertible")
+)
+
+const maxGaloisRingDegree = 32
+
+// A GaloisRing is the Galois ring GR(2^K, D) of polynomials of degree less than D with coefficients
+// modulo 2^K, reduced modulo Modulus.
+type GaloisRing struct {
+	K int
+	D int
+	// Modulus holds the coefficients of X^0 to X^(D-1) of the monic reduction polynomial, which are
+	// 0 or 1.
+	Modulus []uint64
+}
+
+// A RingElement is an element of a GaloisRing, given by its D coefficients starting at X^0.
+type RingElement []uint64
+
+// A RingShare is a Shamir share over a GaloisRing. Its X coordinate is the party index, which is
+// mapped to a ring element by GaloisRing.Point.
+type RingShare struct {
+	Ring   *GaloisRing
+	Degree int
+	X      int
+	Y      RingElement
+}
+
+// NewGaloisRing returns the Galois ring GR(2^k, d), using the lexicographically smallest reduction
+// polynomial that is irreducible modulo 2. It supports 1 <= k <= 64 and 1 <= d <= 32.
+func NewGaloisRing(k int, d int) (*GaloisRing, error) {
+	if k < 1 || k > 64 || d < 1 || d > maxGaloisRingDegree {
+		return nil, ErrorInvalidRing
+	}
+	// Binary polynomials are represented as bit masks, with bit i the coefficient of X^i
+	for low := uint64(1); low < 1<<uint(d); low += 2 {
+		f := low | 1<<uint(d)
+		if !irreducibleGF2(f, d) {
+			continue
+		}
+		modulus := make([]uint64, d)
+		for i := range modulus {
+			modulus[i] = f >> uint(i) & 1
+		}
+		return &GaloisRing{K: k, D: d, Modulus: modulus}, nil
+	}
+	// X+1 is irreducible for d = 1, so this is never reached
+	return nil, ErrorInvalidRing
+}
+
+// Element returns the constant polynomial v, which embeds Z_{2^K} into the ring.
+func (r *GaloisRing) Element(v uint64) RingElement {
+	e := r.zero()
+	e[0] = v & r.mask()
+	return e
+}
+
+// Point returns the ring element used as the X coordinate of the party with index x, whose
+// coefficients are the bits of x.
+func (r *GaloisRing) Point(x int) RingElement {
+	e := r.zero()
+	for i := range e {
+		e[i] = uint64(x) >> uint(i) & 1
+	}
+	return e
+}
+
+// MaxShares returns the maximum number of shares of a sharing over the ring, 2^D-1.
+func (r *GaloisRing) MaxShares() int {
+	return 1<<uint(r.D) - 1
+}
+
+// Add returns a+b.
+func (r *GaloisRing) Add(a RingElement, b RingElement) RingElement {
+	c := r.zero()
+	for i := range c {
+		c[i] = (a[i] + b[i]) & r.mask()
+	}
+	return c
+}
+
+// Sub returns a-b.
+func (r *GaloisRing) Sub(a RingElement, b RingElement) RingElement {
+	c := r.zero()
+	for i := range c {
+		c[i] = (a[i] - b[i]) & r.mask()
+	}
+	return c
+}
+
+// Mul returns a*b.
+func (r *GaloisRing) Mul(a RingElement, b RingElement) RingElement {
+	product := make([]uint64, 2*r.D-1)
+	for i := range a {
+		for j := range b {
+			product[i+j] += a[i] * b[j]
+		}
+	}
+	// X^D = -(Modulus[0] + Modulus[1] X + ... + Modulus[D-1] X^(D-1))
+	for i := len(product) - 1; i >= r.D; i-- {
+		for j, m := range r.Modulus {
+			product[i-r.D+j] -= product[i] * m
+		}
+	}
+	c := RingElement(product[:r.D])
+	for i := range c {
+		c[i] &= r.mask()
+	}
+	return c
+}
+
+// Inverse returns the inverse of a, or ErrorNotUnit if a is not invertible, which is the case
+// exactly when all its coefficients are even.
+func (r *GaloisRing) Inverse(a RingElement) (RingElement, error) {
+	unit := false
+	for _, c := range a {
+		unit = unit || c&1 == 1
+	}
+	if !unit {
+		return nil, ErrorNotUnit
+	}
+	// a^(2^D-2) is the inverse of a modulo 2, since the unit group of GF(2^D) has order 2^D-1.
+	// Newton's iteration y <- y(2-ay) then doubles the number of correct bits in every step.
+	y := r.Element(1)
+	for i := 1; i < r.D; i++ {
+		y = r.Mul(r.Mul(y, y), a)
+	}
+	y = r.Mul(y, y)
+	two := r.Element(2)
+	for precision := 1; precision < r.K; precision *= 2 {
+		y = r.Mul(y, r.Sub(two, r.Mul(a, y)))
+	}
+	return y, nil
+}
+
+// Equal reports whether a and b are the same element.
+func (r *GaloisRing) Equal(a RingElement, b RingElement) bool {
+	for i := range a {
+		if a[i]&r.mask() != b[i]&r.mask() {
+			return false
+		}
+	}
+	return true
+}
+
+// Random returns a uniformly random element of the ring.
+func (r *GaloisRing) Random() (RingElement, error) {
+	buffer := make([]byte, 8*r.D)
+	if _, err := rand.Read(buffer); err != nil {
+		return nil, err
+	}
+	e := r.zero()
+	for i := range e {
+		e[i] = binary.BigEndian.Uint64(buffer[8*i:]) & r.mask()
+	}
+	return e, nil
+}
+
+// Share shares a secret over the ring with a random polynomial of the given degree. Note that
+// degree+1 shares are required for reconstruction of the secret, and that at most MaxShares shares
+// can be produced.
+func (r *GaloisRing) Share(secret RingElement, degree int, nShares int) ([]RingShare, error) {
+	if len(secret) != r.D {
+		return nil, ErrorWrongRing
+	}
+	if nShares > r.MaxShares() {
+		return nil, ErrorTooManyShares
+	}
+	coefficients := make([]RingElement, degree)
+	for i := range coefficients {
+		var err error
+		coefficients[i], err = r.Random()
+		if err != nil {
+			return nil, err
+		}
+	}
+	shares := make([]RingShare, nShares)
+	for i := range shares {
+		// Horner's rule
+		point := r.Point(i + 1)
+		y := r.zero()
+		for j := len(coefficients) - 1; j >= 0; j-- {
+			y = r.Mul(r.Add(y, coefficients[j]), point)
+		}
+		shares[i] = RingShare{
+			Ring:   r,
+			Degree: degree,
+			X:      i + 1,
+			Y:      r.Add(y, secret),
+		}
+	}
+	return shares, nil
+}
+
+// CombineRing combines shares over a Galois ring and recovers the secret.
+func CombineRing(shares []RingShare) (RingElement, error) {
+	if len(shares) == 0 {
+		return nil, ErrorNoShares
+	}
+	first := shares[0]
+	if len(shares) <= first.Degree {
+		return nil, ErrorTooFewShares
+	}
+	for _, share := range shares {
+		if share.Ring != first.Ring || share.Degree != first.Degree || share.X < 1 || share.X > first.Ring.MaxShares() {
+			return nil, ErrorIncompatibleShares
+		}
+	}
+	r := first.Ring
+	shares = shares[:first.Degree+1]
+	for i := range shares {
+		for j := 0; j < i; j++ {
+			if shares[i].X == shares[j].X {
+				return nil, ErrorDuplicateX
+			}
+		}
+	}
+
+	// The Lagrange coefficient of share i at 0 is the product of x_j / (x_j - x_i) for j != i
+	secret := r.zero()
+	for i := range shares {
+		numerator, denominator := r.Element(1), r.Element(1)
+		for j := range shares {
+			if j == i {
+				continue
+			}
+			xj := r.Point(shares[j].X)
+			numerator = r.Mul(numerator, xj)
+			denominator = r.Mul(denominator, r.Sub(xj, r.Point(shares[i].X)))
+		}
+		inverse, err := r.Inverse(denominator)
+		if err != nil {
+			return nil, err
+		}
+		secret = r.Add(secret, r.Mul(shares[i].Y, r.Mul(numerator, inverse)))
+	}
+	return secret, nil
+}
+
+// RingShareAdd adds shares of the same party over a Galois ring, giving a share of the sum of the
+// secrets.
+func RingShareAdd(shares []RingShare) (RingShare, error) {
+	if len(shares) == 0 {
+		return RingShare{}, ErrorNoShares
+	}
+	sum := shares[0]
+	for _, share := range shares[1:] {
+		if share.Ring != sum.Ring || share.Degree != sum.Degree || share.X != sum.X {
+			return RingShare{}, ErrorIncompatibleShares
+		}
+		sum.Y = sum.Ring.Add(sum.Y, share.Y)
+	}
+	return sum, nil
+}
+
+// RingShareMul multiplies shares of the same party over a Galois ring, giving a share of the
+// product of the secrets whose degree is the sum of the degrees of the factors.
+func RingShareMul(shares []RingShare) (RingShare, error) {
+	if len(shares) == 0 {
+		return RingShare{}, ErrorNoShares
+	}
+	product := shares[0]
+	for _, share := range shares[1:] {
+		if share.Ring != product.Ring || share.X != product.X {
+			return RingShare{}, ErrorIncompatibleShares
+		}
+		product.Y = product.Ring.Mul(product.Y, share.Y)
+		product.Degree += share.Degree
+	}
+	return product, nil
+}
+
+func (r *GaloisRing) zero() RingElement {
+	return make(RingElement, r.D)
+}
+
+func (r *GaloisRing) mask() uint64 {
+	return ^uint64(0) >> uint(64-r.K)
+}
+
+// irreducibleGF2 reports whether the binary polynomial f of the given degree, represented as a bit
+// mask, is irreducible over GF(2), by trial division by all polynomials of at most half its degree.
+func irreducibleGF2(f uint64, degree int) bool {
+	for g := uint64(2); g < 1<<uint(degree/2+1); g++ {
+		if modGF2(f, g) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// modGF2 returns the remainder of the binary polynomial f divided by g.
+func modGF2(f uint64, g uint64) uint64 {
+	for bits.Len64(f) >= bits.Len64(g) {
+		f ^= g << uint(bits.Len64(f)-bits.Len64(g))
+	}
+	return f
+}
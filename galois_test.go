@@ -0,0 +1,113 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGaloisRing(t *testing.T) {
+	assert := assert.New(t)
+	r, err := NewGaloisRing(64, 3)
+	assert.NoError(err)
+	// X^3 + X + 1
+	assert.Equal([]uint64{1, 1, 0}, r.Modulus)
+	r, err = NewGaloisRing(32, 8)
+	assert.NoError(err)
+	// X^8 + X^4 + X^3 + X + 1
+	assert.Equal([]uint64{1, 1, 0, 1, 1, 0, 0, 0}, r.Modulus)
+
+	_, err = NewGaloisRing(65, 3)
+	assert.Equal(ErrorInvalidRing, err)
+	_, err = NewGaloisRing(64, 0)
+	assert.Equal(ErrorInvalidRing, err)
+}
+
+func TestGaloisRingArithmetic(t *testing.T) {
+	assert := assert.New(t)
+	for _, k := range []int{1, 13, 64} {
+		r, err := NewGaloisRing(k, 4)
+		assert.NoError(err)
+		for i := 0; i < 20; i++ {
+			a, _ := r.Random()
+			b, _ := r.Random()
+			c, _ := r.Random()
+			assert.True(r.Equal(r.Mul(a, r.Add(b, c)), r.Add(r.Mul(a, b), r.Mul(a, c))))
+			assert.True(r.Equal(r.Mul(r.Mul(a, b), c), r.Mul(a, r.Mul(b, c))))
+			assert.True(r.Equal(r.Sub(r.Add(a, b), b), a))
+
+			inverse, err := r.Inverse(a)
+			if a[0]&1 == 0 && a[1]&1 == 0 && a[2]&1 == 0 && a[3]&1 == 0 {
+				assert.Equal(ErrorNotUnit, err)
+				continue
+			}
+			assert.NoError(err)
+			assert.True(r.Equal(r.Element(1), r.Mul(a, inverse)))
+		}
+	}
+}
+
+func TestGaloisRingSharing(t *testing.T) {
+	assert := assert.New(t)
+	r, err := NewGaloisRing(64, 3)
+	assert.NoError(err)
+	secret := r.Element(1<<63 + 12345)
+	shares, err := r.Share(secret, 2, 7)
+	assert.NoError(err)
+
+	recovered, err := CombineRing(shares[4:])
+	assert.NoError(err)
+	assert.Equal(secret, recovered)
+	recovered, err = CombineRing([]RingShare{shares[6], shares[0], shares[3]})
+	assert.NoError(err)
+	assert.Equal(secret, recovered)
+
+	_, err = CombineRing(shares[:2])
+	assert.Equal(ErrorTooFewShares, err)
+	_, err = CombineRing([]RingShare{shares[0], shares[0], shares[1]})
+	assert.Equal(ErrorDuplicateX, err)
+	_, err = r.Share(secret, 2, 8)
+	assert.Equal(ErrorTooManyShares, err)
+}
+
+func TestRingShareArithmetic(t *testing.T) {
+	assert := assert.New(t)
+	r, err := NewGaloisRing(64, 3)
+	assert.NoError(err)
+	// Arithmetic wraps around modulo 2^64
+	a, _ := r.Share(r.Element(1<<62), 1, 5)
+	b, _ := r.Share(r.Element(6), 1, 5)
+
+	sums := make([]RingShare, 5)
+	products := make([]RingShare, 5)
+	for i := range sums {
+		sums[i], err = RingShareAdd([]RingShare{a[i], b[i]})
+		assert.NoError(err)
+		products[i], err = RingShareMul([]RingShare{a[i], b[i]})
+		assert.NoError(err)
+	}
+	sum, err := CombineRing(sums)
+	assert.NoError(err)
+	assert.Equal(r.Element(1<<62+6), sum)
+	product, err := CombineRing(products)
+	assert.NoError(err)
+	assert.Equal(r.Element(1<<63), product)
+	assert.Equal(2, products[0].Degree)
+
+	_, err = RingShareAdd([]RingShare{a[0], b[1]})
+	assert.Equal(ErrorIncompatibleShares, err)
+}
@@ -0,0 +1,181 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorTooManyErrors = errors.New("Shares contain more wrong shares than can be corrected")
+)
+
+// CombineGao reconstructs a secret from shares over a finite field of prime size of which some may
+// be wrong, with Gao's decoder for Reed-Solomon codes ("A new algorithm for decoding Reed-Solomon
+// codes", 2003). Shares that are missing are simply not given; of the n given shares of degree d,
+// up to (n-d-1)/2 may be wrong. Unlike CombineMajority, which combines every subset of shares, this
+// takes a single pass of polynomial arithmetic, quadratic in n. It returns the secret and the X
+// coordinates of the wrong shares, or ErrorTooManyErrors if the shares cannot be corrected.
+func CombineGao(shares []Share) (*big.Int, []int, error) {
+	var wrong []int
+	secret, err := emitCombine("", shares, func(shares []Share) (*big.Int, error) {
+		if err := checkCombinable(shares); err != nil {
+			return nil, err
+		}
+		p := shares[0].FieldSize
+		if p == nil {
+			return nil, ErrorWrongShareType
+		}
+		n, k := len(shares), shares[0].Degree+1
+
+		// g0 is the polynomial with the X coordinates as roots, and g1 interpolates all shares
+		g0 := []*big.Int{big.NewInt(1)}
+		for _, share := range shares {
+			g0 = polyMul(g0, []*big.Int{big.NewInt(int64(-share.X)), big.NewInt(1)}, p)
+		}
+		g1 := []*big.Int{}
+		for _, share := range shares {
+			basis, _, err := polyDivMod(g0, []*big.Int{big.NewInt(int64(-share.X)), big.NewInt(1)}, p)
+			if err != nil {
+				return nil, err
+			}
+			scale := big.NewInt(0).ModInverse(polyEvaluate(basis, big.NewInt(int64(share.X)), p), p)
+			if scale == nil {
+				return nil, ErrorNotInvertible
+			}
+			scale.Mul(scale, share.Y)
+			g1 = polyAdd(g1, polyMul(basis, []*big.Int{scale}, p), p)
+		}
+
+		// Run the extended Euclidean algorithm on g0 and g1 until the remainder has degree below
+		// (n+k)/2; then the remainder divided by its cofactor is the sharing polynomial
+		r0, r1 := g0, g1
+		v0, v1 := []*big.Int{}, []*big.Int{big.NewInt(1)}
+		for 2*(len(r1)-1) >= n+k {
+			q, r, err := polyDivMod(r0, r1, p)
+			if err != nil {
+				return nil, err
+			}
+			r0, r1 = r1, r
+			v0, v1 = v1, polyAdd(v0, polyMul(polyNeg(q, p), v1, p), p)
+		}
+		f, r, err := polyDivMod(r1, v1, p)
+		if err != nil {
+			return nil, err
+		}
+		if len(r) != 0 || len(f) > k {
+			return nil, ErrorTooManyErrors
+		}
+		for _, share := range shares {
+			if polyEvaluate(f, big.NewInt(int64(share.X)), p).Cmp(big.NewInt(0).Mod(share.Y, p)) != 0 {
+				wrong = append(wrong, share.X)
+			}
+		}
+		return polyEvaluate(f, big.NewInt(0), p), nil
+	})
+	return secret, wrong, err
+}
+
+// The polynomials below have their coefficients modulo p, constant term first, without trailing
+// zero coefficients, so that the zero polynomial is empty and the degree is the length minus one.
+
+func polyTrim(a []*big.Int) []*big.Int {
+	for len(a) > 0 && a[len(a)-1].Sign() == 0 {
+		a = a[:len(a)-1]
+	}
+	return a
+}
+
+func polyAdd(a, b []*big.Int, p *big.Int) []*big.Int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	sum := make([]*big.Int, len(a))
+	for i := range a {
+		sum[i] = big.NewInt(0).Set(a[i])
+		if i < len(b) {
+			sum[i].Add(sum[i], b[i]).Mod(sum[i], p)
+		}
+	}
+	return polyTrim(sum)
+}
+
+func polyNeg(a []*big.Int, p *big.Int) []*big.Int {
+	negated := make([]*big.Int, len(a))
+	for i := range a {
+		negated[i] = big.NewInt(0).Neg(a[i])
+		negated[i].Mod(negated[i], p)
+	}
+	return negated
+}
+
+func polyMul(a, b []*big.Int, p *big.Int) []*big.Int {
+	if len(a) == 0 || len(b) == 0 {
+		return []*big.Int{}
+	}
+	product := make([]*big.Int, len(a)+len(b)-1)
+	for i := range product {
+		product[i] = big.NewInt(0)
+	}
+	term := big.NewInt(0)
+	for i := range a {
+		for j := range b {
+			product[i+j].Add(product[i+j], term.Mul(a[i], b[j]))
+		}
+	}
+	for i := range product {
+		product[i].Mod(product[i], p)
+	}
+	return polyTrim(product)
+}
+
+// polyDivMod divides a by b, and returns ErrorNotInvertible if the leading coefficient of b is not
+// invertible, which includes division by the zero polynomial.
+func polyDivMod(a, b []*big.Int, p *big.Int) ([]*big.Int, []*big.Int, error) {
+	b = polyTrim(b)
+	if len(b) == 0 {
+		return nil, nil, ErrorNotInvertible
+	}
+	inverse := big.NewInt(0).ModInverse(b[len(b)-1], p)
+	if inverse == nil {
+		return nil, nil, ErrorNotInvertible
+	}
+	remainder := make([]*big.Int, len(a))
+	for i := range a {
+		remainder[i] = big.NewInt(0).Mod(a[i], p)
+	}
+	if len(a) < len(b) {
+		return []*big.Int{}, polyTrim(remainder), nil
+	}
+	quotient := make([]*big.Int, len(a)-len(b)+1)
+	term := big.NewInt(0)
+	for i := len(quotient) - 1; i >= 0; i-- {
+		c := big.NewInt(0).Mul(remainder[i+len(b)-1], inverse)
+		quotient[i] = c.Mod(c, p)
+		for j := range b {
+			remainder[i+j].Sub(remainder[i+j], term.Mul(c, b[j])).Mod(remainder[i+j], p)
+		}
+	}
+	return polyTrim(quotient), polyTrim(remainder[:len(b)-1]), nil
+}
+
+func polyEvaluate(a []*big.Int, x *big.Int, p *big.Int) *big.Int {
+	result := big.NewInt(0)
+	for i := len(a) - 1; i >= 0; i-- {
+		result.Mul(result, x).Add(result, a[i]).Mod(result, p)
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineGao(t *testing.T) {
+	assert := assert.New(t)
+	secret := big.NewInt(1234567)
+	shares := ShareFiniteField(secret, mersenne61, 2, 9)
+
+	recovered, wrong, err := CombineGao(shares)
+	assert.NoError(err)
+	assert.Equal(secret, recovered)
+	assert.Empty(wrong)
+
+	// Up to (9-3)/2 wrong shares are corrected, also when some shares are missing
+	for i, x := range []int{2, 5, 9} {
+		shares[x-1].Y = big.NewInt(int64(i))
+	}
+	recovered, wrong, err = CombineGao(shares)
+	assert.NoError(err)
+	assert.Equal(secret, recovered)
+	assert.Equal([]int{2, 5, 9}, wrong)
+	recovered, wrong, err = CombineGao(append(append([]Share{}, shares[1:4]...), shares[5:]...))
+	assert.NoError(err)
+	assert.Equal(secret, recovered)
+	assert.Equal([]int{2, 9}, wrong)
+
+	shares[0].Y = big.NewInt(7)
+	_, _, err = CombineGao(shares)
+	assert.Equal(ErrorTooManyErrors, err)
+	_, _, err = CombineGao(shares[:2])
+	assert.Equal(ErrorTooFewShares, err)
+	_, _, err = CombineGao(ShareIntegers(secret, big.NewInt(1<<30), 40, 1, 3))
+	assert.Equal(ErrorWrongShareType, err)
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// Many Go libraries share byte strings byte-wise over GF(2^8) with the AES reduction polynomial
+// x^8+x^4+x^3+x+1, such as github.com/codahale/sss, github.com/hashicorp/vault/shamir and its fork
+// github.com/corvus-ch/shamir. They differ only in how shares are represented. The functions in
+// this file combine and produce such shares, so that secrets dealt with these libraries can be
+// recovered with this package without dealing them again, and shares dealt here can be handed to
+// tools built on these libraries.
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+var (
+	ErrorInvalidGF256Share = errors.New("Invalid GF(256) share")
+)
+
+// A GF256Share is a byte-wise share over GF(2^8): Y[i] is the value at X of the polynomial that
+// shares byte i of the secret. X must not be zero.
+type GF256Share struct {
+	X byte
+	Y []byte
+}
+
+// gf256Exp and gf256Log are the exponentials and logarithms of the generator 3 of GF(2^8).
+var gf256Exp, gf256Log = gf256Tables()
+
+func gf256Tables() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = x, x
+		log[x] = byte(i)
+		// Multiply by 3 = x+1, reducing by x^8+x^4+x^3+x+1
+		doubled := x << 1
+		if x&0x80 != 0 {
+			doubled ^= 0x1b
+		}
+		x ^= doubled
+	}
+	return exp, log
+}
+
+func gf256Mul(a byte, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf256Exp[int(gf256Log[a])+int(gf256Log[b])]
+}
+
+func gf256Div(a byte, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gf256Exp[int(gf256Log[a])+255-int(gf256Log[b])]
+}
+
+// SplitGF256 shares a byte string byte-wise over GF(2^8) with polynomials of the given degree, for
+// use with other libraries. The shares have X coordinates 1 to nShares, so at most 255 shares can
+// be produced.
+func SplitGF256(secret []byte, degree int, nShares int) ([]GF256Share, error) {
+	if nShares > 255 || degree < 0 || degree >= nShares {
+		return nil, ErrorInvalidGF256Share
+	}
+	coefficients := make([]byte, degree*len(secret))
+	if _, err := rand.Read(coefficients); err != nil {
+		return nil, err
+	}
+	shares := make([]GF256Share, nShares)
+	for i := range shares {
+		x := byte(i + 1)
+		shares[i] = GF256Share{X: x, Y: make([]byte, len(secret))}
+		for j := range secret {
+			// Horner's rule
+			y := byte(0)
+			for k := degree - 1; k >= 0; k-- {
+				y = gf256Mul(y^coefficients[k*len(secret)+j], x)
+			}
+			shares[i].Y[j] = y ^ secret[j]
+		}
+	}
+	return shares, nil
+}
+
+// CombineGF256 recovers a byte string from byte-wise shares over GF(2^8). The number of shares must
+// be exactly the threshold of the sharing: unlike the shares of this package, these shares do not
+// record their degree, so surplus shares cannot be detected and would give a wrong secret unless
+// they lie on the same polynomial.
+func CombineGF256(shares []GF256Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrorNoShares
+	}
+	length := len(shares[0].Y)
+	for i, share := range shares {
+		if share.X == 0 || len(share.Y) != length {
+			return nil, ErrorInvalidGF256Share
+		}
+		for _, other := range shares[:i] {
+			if other.X == share.X {
+				return nil, ErrorDuplicateX
+			}
+		}
+	}
+
+	secret := make([]byte, length)
+	for i := range shares {
+		// The Lagrange coefficient at 0 is the product of x_j / (x_j - x_i), and subtraction is XOR
+		coefficient := byte(1)
+		for j := range shares {
+			if j != i {
+				coefficient = gf256Mul(coefficient, gf256Div(shares[j].X, shares[j].X^shares[i].X))
+			}
+		}
+		for k := range secret {
+			secret[k] ^= gf256Mul(coefficient, shares[i].Y[k])
+		}
+	}
+	return secret, nil
+}
+
+// FromCodahale converts shares in the representation of github.com/codahale/sss, a map from X
+// coordinate to Y values, into GF256Shares ordered by X coordinate.
+func FromCodahale(shares map[byte][]byte) []GF256Share {
+	converted := make([]GF256Share, 0, len(shares))
+	for x := 0; x < 256; x++ {
+		if y, ok := shares[byte(x)]; ok {
+			converted = append(converted, GF256Share{X: byte(x), Y: y})
+		}
+	}
+	return converted
+}
+
+// ToCodahale converts GF256Shares into the representation of github.com/codahale/sss.
+func ToCodahale(shares []GF256Share) map[byte][]byte {
+	converted := make(map[byte][]byte, len(shares))
+	for _, share := range shares {
+		converted[share.X] = share.Y
+	}
+	return converted
+}
+
+// FromHashiCorp converts shares in the representation of github.com/hashicorp/vault/shamir and
+// github.com/corvus-ch/shamir, the Y values followed by the X coordinate, into GF256Shares.
+func FromHashiCorp(shares [][]byte) ([]GF256Share, error) {
+	converted := make([]GF256Share, len(shares))
+	for i, share := range shares {
+		if len(share) < 2 {
+			return nil, ErrorInvalidGF256Share
+		}
+		converted[i] = GF256Share{X: share[len(share)-1], Y: share[:len(share)-1]}
+	}
+	return converted, nil
+}
+
+// ToHashiCorp converts GF256Shares into the representation of github.com/hashicorp/vault/shamir and
+// github.com/corvus-ch/shamir.
+func ToHashiCorp(shares []GF256Share) [][]byte {
+	converted := make([][]byte, len(shares))
+	for i, share := range shares {
+		converted[i] = append(append([]byte{}, share.Y...), share.X)
+	}
+	return converted
+}
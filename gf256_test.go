@@ -0,0 +1,85 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGF256Arithmetic(t *testing.T) {
+	assert := assert.New(t)
+	// Examples from FIPS 197, section 4.2
+	assert.Equal(byte(0xc1), gf256Mul(0x57, 0x83))
+	assert.Equal(byte(0xfe), gf256Mul(0x57, 0x13))
+	for a := 1; a < 256; a++ {
+		assert.Equal(byte(1), gf256Mul(byte(a), gf256Div(1, byte(a))))
+	}
+}
+
+func TestGF256Sharing(t *testing.T) {
+	assert := assert.New(t)
+	secret := []byte("correct horse battery staple")
+	shares, err := SplitGF256(secret, 2, 5)
+	assert.NoError(err)
+	recovered, err := CombineGF256([]GF256Share{shares[4], shares[0], shares[2]})
+	assert.NoError(err)
+	assert.Equal(secret, recovered)
+	recovered, err = CombineGF256(shares[:2])
+	assert.NoError(err)
+	assert.NotEqual(secret, recovered)
+
+	_, err = SplitGF256(secret, 2, 256)
+	assert.Equal(ErrorInvalidGF256Share, err)
+	_, err = CombineGF256([]GF256Share{shares[0], shares[0]})
+	assert.Equal(ErrorDuplicateX, err)
+	_, err = CombineGF256([]GF256Share{shares[0], {X: 0, Y: shares[1].Y}})
+	assert.Equal(ErrorInvalidGF256Share, err)
+}
+
+func TestGF256Representations(t *testing.T) {
+	assert := assert.New(t)
+	secret := []byte("secret")
+	shares, err := SplitGF256(secret, 1, 3)
+	assert.NoError(err)
+
+	codahale := ToCodahale(shares)
+	assert.Len(codahale, 3)
+	assert.Equal(shares, FromCodahale(codahale))
+
+	hashicorp := ToHashiCorp(shares)
+	assert.Equal(append(append([]byte{}, shares[1].Y...), 2), hashicorp[1])
+	converted, err := FromHashiCorp(hashicorp[1:])
+	assert.NoError(err)
+	recovered, err := CombineGF256(converted)
+	assert.NoError(err)
+	assert.Equal(secret, recovered)
+	_, err = FromHashiCorp([][]byte{{1}})
+	assert.Equal(ErrorInvalidGF256Share, err)
+
+	// A share of the byte 0x42 with threshold 2 in the format of github.com/hashicorp/vault/shamir,
+	// on the polynomial 0x42 + 0x17 x
+	recovered, err = CombineGF256(fromHashiCorp(t, [][]byte{{0x42 ^ gf256Mul(0x17, 0x05), 0x05}, {0x42 ^ gf256Mul(0x17, 0xa0), 0xa0}}))
+	assert.NoError(err)
+	assert.Equal([]byte{0x42}, recovered)
+}
+
+// fromHashiCorp converts shares with FromHashiCorp, which must succeed.
+func fromHashiCorp(t *testing.T, shares [][]byte) []GF256Share {
+	converted, err := FromHashiCorp(shares)
+	assert.NoError(t, err)
+	return converted
+}
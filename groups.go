@@ -0,0 +1,101 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// A GroupSpec describes a group in a two-level sharing. The share of the group is split among
+// Members members using a polynomial of degree Degree, so Degree+1 members are required to recover it.
+type GroupSpec struct {
+	Degree  int
+	Members int
+}
+
+// A GroupShare is the share of a member of a group in a two-level sharing. The embedded Share is a
+// share of the group's share, which has X coordinate Group and was dealt with degree GroupDegree.
+type GroupShare struct {
+	Group       int
+	GroupDegree int
+	Share
+}
+
+// ShareGroups shares a secret over a finite field of integers modulo fieldSize in two levels, in the
+// style of SLIP-39. The secret is first shared among len(groups) groups using a polynomial of degree
+// groupDegree, and the share of every group is then shared among its members as described by its
+// GroupSpec. The result contains the member shares of every group, in the order of groups.
+func ShareGroups(secret *big.Int, fieldSize *big.Int, groupDegree int, groups []GroupSpec) [][]GroupShare {
+	groupShares := ShareFiniteField(secret, fieldSize, groupDegree, len(groups))
+	memberShares := make([][]GroupShare, len(groups))
+	for i, group := range groups {
+		shares := ShareFiniteField(groupShares[i].Y, fieldSize, group.Degree, group.Members)
+		memberShares[i] = make([]GroupShare, len(shares))
+		for j := range shares {
+			memberShares[i][j] = GroupShare{
+				Group:       groupShares[i].X,
+				GroupDegree: groupDegree,
+				Share:       shares[j],
+			}
+		}
+	}
+	return memberShares
+}
+
+// CombineGroups recovers a secret shared with ShareGroups from member shares of any number of groups.
+// The shares of every group for which enough members are present are combined into the group's
+// share, and the secret is recovered from those using ShareCombine. Groups with too few members are
+// ignored.
+func CombineGroups(shares []GroupShare) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, ErrorNoShares
+	}
+	members := make(map[int][]Share)
+	for _, share := range shares {
+		if share.FieldSize == nil || share.GroupDegree != shares[0].GroupDegree {
+			return nil, ErrorIncompatibleShares
+		}
+		members[share.Group] = append(members[share.Group], share.Share)
+	}
+
+	groups := make([]int, 0, len(members))
+	for group := range members {
+		groups = append(groups, group)
+	}
+	sort.Ints(groups)
+
+	groupShares := make([]Share, 0, len(groups))
+	for _, group := range groups {
+		y, err := ShareCombine(members[group])
+		if errors.Is(err, ErrorTooFewShares) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		groupShares = append(groupShares, Share{
+			FieldSize: shares[0].FieldSize,
+			Degree:    shares[0].GroupDegree,
+			X:         group,
+			Y:         y,
+		})
+	}
+	if len(groupShares) == 0 {
+		return nil, ErrorTooFewShares
+	}
+	return ShareCombine(groupShares)
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupSecretSharing(t *testing.T) {
+	assert := assert.New(t)
+	groups := ShareGroups(big.NewInt(123), big.NewInt(7919), 1, []GroupSpec{
+		{Degree: 0, Members: 1},
+		{Degree: 1, Members: 3},
+		{Degree: 2, Members: 5},
+	})
+	assert.Len(groups, 3)
+	assert.Len(groups[2], 5)
+
+	var secret *big.Int
+	var err error
+
+	secret, err = CombineGroups(nil)
+	assert.Nil(secret)
+	assert.Equal(ErrorNoShares, err)
+
+	// Enough members of a single group only
+	secret, err = CombineGroups(groups[2][0:3])
+	assert.Nil(secret)
+	assert.Equal(ErrorTooFewShares, err)
+
+	// Enough members of the second and third group
+	shares := append(append([]GroupShare{}, groups[1][1:3]...), groups[2][2:5]...)
+	secret, err = CombineGroups(shares)
+	assert.NoError(err)
+	if assert.NotNil(secret) {
+		assert.Equal(int64(123), secret.Int64())
+	}
+
+	// Too few members of the third group
+	shares = append(append([]GroupShare{}, groups[0]...), groups[2][0:2]...)
+	secret, err = CombineGroups(shares)
+	assert.Nil(secret)
+	assert.Equal(ErrorTooFewShares, err)
+
+	shares = append(shares, groups[1][0:2]...)
+	secret, err = CombineGroups(shares)
+	assert.NoError(err)
+	if assert.NotNil(secret) {
+		assert.Equal(int64(123), secret.Int64())
+	}
+
+	shares[0].GroupDegree = 2
+	_, err = CombineGroups(shares)
+	assert.Equal(ErrorIncompatibleShares, err)
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+)
+
+// identitySalt separates the hashes of identities from other uses of the identities.
+var identitySalt = []byte("github.com/TNO-MPC/shamir identity")
+
+// maxIdentityX bounds the X coordinates derived from identities, so that they fit in an int on all
+// platforms.
+const maxIdentityX = 1<<31 - 1
+
+// IdentityX derives the X coordinate of a party from its identity, such as its public key or an
+// e-mail address, by hashing the identity into [1, min(fieldSize-1, 2^31-1)]. Shares dealt at these
+// coordinates are bound to their holders: anyone can recompute the X coordinate of a share from the
+// identity of its holder, see CheckIdentity, regardless of the order in which shares are listed.
+// Since X coordinates are ints, only about 31 bits of the hash are used, so the binding does not
+// prevent deliberate collisions.
+func IdentityX(identity []byte, fieldSize *big.Int) int {
+	h := sha256.New()
+	writeBytes(h, identitySalt)
+	writeBytes(h, identity)
+	bound := big.NewInt(maxIdentityX)
+	if limit := big.NewInt(0).Sub(fieldSize, big.NewInt(1)); limit.Cmp(bound) < 0 {
+		bound = limit
+	}
+	x := big.NewInt(0).SetBytes(h.Sum(nil))
+	return int(x.Mod(x, bound).Int64()) + 1
+}
+
+// IdentityXs derives the X coordinates of several parties with IdentityX. It returns
+// ErrorDuplicateX if two identities map to the same X coordinate, which happens for repeated
+// identities, or by chance with a probability of about n^2 / 2^32 for n identities in a large field.
+func IdentityXs(identities [][]byte, fieldSize *big.Int) ([]int, error) {
+	xs := make([]int, len(identities))
+	seen := make(map[int]bool, len(identities))
+	for i, identity := range identities {
+		xs[i] = IdentityX(identity, fieldSize)
+		if seen[xs[i]] {
+			return nil, ErrorDuplicateX
+		}
+		seen[xs[i]] = true
+	}
+	return xs, nil
+}
+
+// ShareFiniteFieldAt shares a secret over a finite field like ShareFiniteField, but at the given X
+// coordinates instead of 1 to nShares. The X coordinates must be distinct and lie in
+// [1, fieldSize).
+func ShareFiniteFieldAt(secret *big.Int, fieldSize *big.Int, degree int, xs []int) ([]Share, error) {
+	seen := make(map[int]bool, len(xs))
+	for _, x := range xs {
+		if x < 1 || big.NewInt(int64(x)).Cmp(fieldSize) >= 0 {
+			return nil, ErrorInvalidX
+		}
+		if seen[x] {
+			return nil, ErrorDuplicateX
+		}
+		seen[x] = true
+	}
+	coefficients := make([]*big.Int, degree)
+	for i := range coefficients {
+		coefficients[i], _ = rand.Int(rand.Reader, fieldSize)
+	}
+	return shareFiniteFieldAt(secret, fieldSize, coefficients, xs), nil
+}
+
+// ShareForIdentities shares a secret over a finite field among the parties with the given
+// identities, at the X coordinates derived with IdentityXs. Share i belongs to identity i.
+func ShareForIdentities(secret *big.Int, fieldSize *big.Int, degree int, identities [][]byte) ([]Share, error) {
+	xs, err := IdentityXs(identities, fieldSize)
+	if err != nil {
+		return nil, err
+	}
+	return ShareFiniteFieldAt(secret, fieldSize, degree, xs)
+}
+
+// CheckIdentity reports whether share was dealt to the party with the given identity by
+// ShareForIdentities.
+func CheckIdentity(share Share, identity []byte) bool {
+	return share.FieldSize != nil && share.X == IdentityX(identity, share.FieldSize)
+}
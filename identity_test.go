@@ -0,0 +1,79 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareForIdentities(t *testing.T) {
+	assert := assert.New(t)
+	identities := [][]byte{[]byte("alice@example.com"), []byte("bob@example.com"), []byte("carol@example.com")}
+	fieldSize := Conservative128().FieldSize
+	shares, err := ShareForIdentities(big.NewInt(42), fieldSize, 1, identities)
+	assert.NoError(err)
+	for i, share := range shares {
+		assert.True(CheckIdentity(share, identities[i]))
+		assert.False(CheckIdentity(share, identities[(i+1)%3]))
+		assert.Greater(share.X, 0)
+		assert.LessOrEqual(share.X, maxIdentityX)
+	}
+
+	// Reordering the shares does not matter
+	secret, err := ShareCombine([]Share{shares[2], shares[0]})
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+	recovered, err := CombineAt(shares[1:], IdentityX(identities[0], fieldSize))
+	assert.NoError(err)
+	assert.Equal(shares[0].Y, recovered)
+
+	_, err = ShareForIdentities(big.NewInt(42), fieldSize, 1, [][]byte{identities[0], identities[0]})
+	assert.Equal(ErrorDuplicateX, err)
+	assert.False(CheckIdentity(ShareIntegers(big.NewInt(1), big.NewInt(10), 40, 1, 2)[0], identities[0]))
+}
+
+func TestIdentityXSmallField(t *testing.T) {
+	assert := assert.New(t)
+	// In a small field, collisions are likely, and the X coordinates stay below the field size
+	seen := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		x := IdentityX([]byte{byte(i)}, big.NewInt(11))
+		assert.Greater(x, 0)
+		assert.Less(x, 11)
+		seen[x] = true
+	}
+	assert.Len(seen, 10)
+}
+
+func TestShareFiniteFieldAt(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	shares, err := ShareFiniteFieldAt(big.NewInt(42), fieldSize, 2, []int{5, 100, 7918})
+	assert.NoError(err)
+	assert.Equal(100, shares[1].X)
+	secret, err := ShareCombine(shares)
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+
+	_, err = ShareFiniteFieldAt(big.NewInt(42), fieldSize, 1, []int{1, 7919})
+	assert.Equal(ErrorInvalidX, err)
+	_, err = ShareFiniteFieldAt(big.NewInt(42), fieldSize, 1, []int{0, 1})
+	assert.Equal(ErrorInvalidX, err)
+	_, err = ShareFiniteFieldAt(big.NewInt(42), fieldSize, 1, []int{3, 3})
+	assert.Equal(ErrorDuplicateX, err)
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The types in this file mirror the share types of go.dedis.ch/kyber and github.com/binance-chain/
+// tss-lib, so that their outputs, such as the shares of a distributed key generation, can be used
+// with this package without it depending on them. Kyber and tss-lib share over the field of
+// integers modulo the order of a curve, so their shares are Shares with that field size.
+
+import (
+	"math"
+	"math/big"
+)
+
+// A KyberPriShare mirrors share.PriShare of kyber: the value at I+1 of the sharing polynomial, for
+// the index I, with the scalar V as marshalled by kyber.Scalar.MarshalBinary.
+type KyberPriShare struct {
+	I int
+	V []byte
+}
+
+// A KyberPubShare mirrors share.PubShare of kyber: the value at I+1 of a polynomial in the exponent,
+// with the point V as marshalled by kyber.Point.MarshalBinary.
+type KyberPubShare struct {
+	I int
+	V []byte
+}
+
+// A TSSShare mirrors vss.Share of tss-lib: the value Share at the party key ID of a sharing
+// polynomial of degree Threshold.
+type TSSShare struct {
+	Threshold int
+	ID        *big.Int
+	Share     *big.Int
+}
+
+// FromKyberPriShares converts kyber private shares of the given degree over the scalar field of a
+// group of the given order into Shares. Scalars of Ed25519 are marshalled little-endian and those
+// of most other kyber groups big-endian.
+func FromKyberPriShares(shares []KyberPriShare, order *big.Int, degree int, littleEndian bool) ([]Share, error) {
+	converted := make([]Share, len(shares))
+	for i, share := range shares {
+		if share.I < 0 || share.I >= math.MaxInt32 {
+			return nil, ErrorInvalidX
+		}
+		v := share.V
+		if littleEndian {
+			v = reversed(v)
+		}
+		y := big.NewInt(0).SetBytes(v)
+		if y.Cmp(order) >= 0 {
+			return nil, ErrorInvalidShare
+		}
+		converted[i] = Share{FieldSize: order, Degree: degree, X: share.I + 1, Y: y}
+	}
+	return converted, nil
+}
+
+// ToKyberPriShares converts shares over a finite field into kyber private shares, with scalars of
+// the byte length of the field size in the given byte order, which kyber.Scalar.UnmarshalBinary
+// accepts.
+func ToKyberPriShares(shares []Share, littleEndian bool) ([]KyberPriShare, error) {
+	converted := make([]KyberPriShare, len(shares))
+	for i, share := range shares {
+		if share.FieldSize == nil || share.Y == nil {
+			return nil, ErrorWrongShareType
+		}
+		if share.X < 1 {
+			return nil, ErrorInvalidX
+		}
+		v := fieldElementBytes(share.Y, share.FieldSize)
+		if littleEndian {
+			v = reversed(v)
+		}
+		converted[i] = KyberPriShare{I: share.X - 1, V: v}
+	}
+	return converted, nil
+}
+
+// FromKyberPubShares converts kyber public shares into the partial results and X coordinates that
+// CombineExponent takes, decoding the points with decode, for instance ModPGroup.Decode or a
+// wrapper of the kyber group.
+func FromKyberPubShares(shares []KyberPubShare, decode func([]byte) (GroupElement, error)) ([]GroupElement, []int, error) {
+	partials := make([]GroupElement, len(shares))
+	xs := make([]int, len(shares))
+	for i, share := range shares {
+		if share.I < 0 || share.I >= math.MaxInt32 {
+			return nil, nil, ErrorInvalidX
+		}
+		var err error
+		if partials[i], err = decode(share.V); err != nil {
+			return nil, nil, err
+		}
+		xs[i] = share.I + 1
+	}
+	return partials, xs, nil
+}
+
+// FromTSSShares converts tss-lib shares over the scalar field of a curve of the given order into
+// Shares. The party keys become the X coordinates, so they must be small positive integers, for
+// instance 1 to n; it returns ErrorInvalidX for the random 256-bit keys that tss-lib examples use,
+// which cannot be X coordinates of this package.
+func FromTSSShares(shares []TSSShare, order *big.Int) ([]Share, error) {
+	converted := make([]Share, len(shares))
+	for i, share := range shares {
+		if share.ID == nil || share.ID.Sign() <= 0 || !share.ID.IsInt64() || share.ID.Int64() > math.MaxInt32 {
+			return nil, ErrorInvalidX
+		}
+		if share.Share == nil || share.Share.Sign() < 0 || share.Share.Cmp(order) >= 0 {
+			return nil, ErrorInvalidShare
+		}
+		converted[i] = Share{FieldSize: order, Degree: share.Threshold, X: int(share.ID.Int64()), Y: big.NewInt(0).Set(share.Share)}
+	}
+	return converted, nil
+}
+
+// ToTSSShares converts shares over a finite field into tss-lib shares, with the X coordinates as
+// party keys.
+func ToTSSShares(shares []Share) ([]TSSShare, error) {
+	converted := make([]TSSShare, len(shares))
+	for i, share := range shares {
+		if share.FieldSize == nil || share.Y == nil {
+			return nil, ErrorWrongShareType
+		}
+		if share.X < 1 {
+			return nil, ErrorInvalidX
+		}
+		converted[i] = TSSShare{Threshold: share.Degree, ID: big.NewInt(int64(share.X)), Share: big.NewInt(0).Set(share.Y)}
+	}
+	return converted, nil
+}
+
+// reversed returns a reversed copy of b.
+func reversed(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKyberPriShares(t *testing.T) {
+	assert := assert.New(t)
+	// The order of the Ed25519 base point, whose scalars kyber marshals little-endian
+	order, _ := big.NewInt(0).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	shares := ShareFiniteField(big.NewInt(1234), order, 2, 4)
+
+	converted, err := ToKyberPriShares(shares, true)
+	assert.NoError(err)
+	assert.Equal(0, converted[0].I)
+	assert.Len(converted[0].V, 32)
+	assert.Equal(fieldElementBytes(shares[2].Y, order)[31], converted[2].V[0])
+
+	back, err := FromKyberPriShares(converted[1:], order, 2, true)
+	assert.NoError(err)
+	assert.Equal(shares[1:], back)
+	secret, err := ShareCombine(back)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(1234), secret)
+
+	bigEndian, err := ToKyberPriShares(shares, false)
+	assert.NoError(err)
+	assert.Equal(fieldElementBytes(shares[0].Y, order), bigEndian[0].V)
+
+	_, err = FromKyberPriShares([]KyberPriShare{{I: 0, V: order.Bytes()}}, order, 2, false)
+	assert.Equal(ErrorInvalidShare, err)
+	_, err = FromKyberPriShares([]KyberPriShare{{I: -1}}, order, 2, false)
+	assert.Equal(ErrorInvalidX, err)
+	_, err = ToKyberPriShares(ShareIntegers(big.NewInt(1), big.NewInt(2), 40, 1, 2), false)
+	assert.Equal(ErrorWrongShareType, err)
+}
+
+func TestKyberPubShares(t *testing.T) {
+	assert := assert.New(t)
+	g := testGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(77), g, 1, 3)
+	pubShares := make([]KyberPubShare, len(shares))
+	for i, share := range shares {
+		pubShares[i] = KyberPubShare{I: share.X - 1, V: g.ScalarMult(share.Y).Bytes()}
+	}
+	partials, xs, err := FromKyberPubShares(pubShares[1:], testGroup.Decode)
+	assert.NoError(err)
+	assert.Equal([]int{2, 3}, xs)
+	public, err := CombineExponent(partials, xs)
+	assert.NoError(err)
+	assert.True(commitments[0].Equal(public))
+
+	_, _, err = FromKyberPubShares([]KyberPubShare{{I: 0, V: []byte{0, 0}}}, testGroup.Decode)
+	assert.Equal(ErrorInvalidElement, err)
+}
+
+func TestTSSShares(t *testing.T) {
+	assert := assert.New(t)
+	// The order of secp256k1, the curve of tss-lib ECDSA
+	order, _ := big.NewInt(0).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	shares := ShareFiniteField(big.NewInt(99), order, 1, 3)
+	converted, err := ToTSSShares(shares)
+	assert.NoError(err)
+	assert.Equal(1, converted[0].Threshold)
+	assert.Equal(big.NewInt(3), converted[2].ID)
+
+	back, err := FromTSSShares(converted[:2], order)
+	assert.NoError(err)
+	secret, err := ShareCombine(back)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(99), secret)
+
+	// Random party keys cannot be X coordinates
+	converted[0].ID = order
+	_, err = FromTSSShares(converted, order)
+	assert.Equal(ErrorInvalidX, err)
+}
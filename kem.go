@@ -0,0 +1,108 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The threshold KEM in this file is hashed ElGamal over a GroupElement group. The committee holds
+// shares s_i of a decryption key s, dealt with ShareFeldman, and the public key is s * G, which is
+// element 0 of the commitments. A sender encapsulates a key as r * G, derived from r * (s * G). To
+// decapsulate, degree+1 members compute their partial results s_i * (r * G) with DecapsulateShare,
+// and CombineDecapsulation interpolates these in the exponent to s * r * G, see CombineExponent. No
+// member learns s, and the encapsulation can be decapsulated any number of times.
+
+import (
+	"crypto/rand"
+)
+
+var kemSalt = []byte("github.com/TNO-MPC/shamir kem")
+
+// Encapsulate generates a fresh key for the committee with the given public key, which is usually
+// element 0 of the commitments returned by ShareFeldman. It returns the encapsulation, to be sent
+// to the committee, and the 32-byte key.
+func Encapsulate(publicKey GroupElement, generator GroupElement) (GroupElement, []byte, error) {
+	r, err := rand.Int(rand.Reader, generator.Order())
+	if err != nil {
+		return nil, nil, err
+	}
+	encapsulation := generator.ScalarMult(r)
+	return encapsulation, kemKey(encapsulation, publicKey.ScalarMult(r)), nil
+}
+
+// DecapsulateShare returns the partial result of a committee member holding share for the
+// encapsulation. Partial results reveal nothing about the share, but should only be sent to the
+// party entitled to the key.
+func DecapsulateShare(share Share, encapsulation GroupElement) (GroupElement, error) {
+	if share.Y == nil || !equalOrBothNil(share.FieldSize, encapsulation.Order()) {
+		return nil, ErrorWrongShareType
+	}
+	return encapsulation.ScalarMult(share.Y), nil
+}
+
+// CombineDecapsulation recovers the key of an encapsulation from the partial results of degree+1
+// committee members with X coordinates xs. Wrong partial results are not detected here and give a
+// wrong key; EncryptToCommittee and DecryptFromCommittee detect them by authenticated encryption.
+func CombineDecapsulation(encapsulation GroupElement, partials []GroupElement, xs []int) ([]byte, error) {
+	shared, err := CombineExponent(partials, xs)
+	if err != nil {
+		return nil, err
+	}
+	return kemKey(encapsulation, shared), nil
+}
+
+// EncryptToCommittee encrypts a message of any size such that any degree+1 members of the committee
+// with the given public key can decrypt it jointly. It encapsulates a key and encrypts the message
+// with AES-256-GCM under it. It returns the encapsulation, which the members need for their partial
+// results, and the ciphertext.
+func EncryptToCommittee(publicKey GroupElement, generator GroupElement, message []byte) (GroupElement, []byte, error) {
+	encapsulation, key, err := Encapsulate(publicKey, generator)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return encapsulation, aead.Seal(nonce, nonce, message, encapsulation.Bytes()), nil
+}
+
+// DecryptFromCommittee decrypts a ciphertext produced by EncryptToCommittee, given the partial
+// results of degree+1 committee members with X coordinates xs for the encapsulation. It returns
+// ErrorDecryption if the ciphertext was modified or a partial result is wrong.
+func DecryptFromCommittee(encapsulation GroupElement, ciphertext []byte, partials []GroupElement, xs []int) ([]byte, error) {
+	key, err := CombineDecapsulation(encapsulation, partials, xs)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrorDecryption
+	}
+	message, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], encapsulation.Bytes())
+	if err != nil {
+		return nil, ErrorDecryption
+	}
+	return message, nil
+}
+
+// kemKey derives a key from an encapsulation and the shared group element.
+func kemKey(encapsulation GroupElement, shared GroupElement) []byte {
+	return hkdfExpand(hkdfExtract(kemSalt, shared.Bytes()), encapsulation.Bytes(), encryptionKeySize)
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholdKEM(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	secretKey, err := rand.Int(rand.Reader, g.Order())
+	assert.NoError(err)
+	shares, commitments := ShareFeldman(secretKey, g, 2, 5)
+	publicKey := commitments[0]
+
+	encapsulation, key, err := Encapsulate(publicKey, g)
+	assert.NoError(err)
+	assert.Len(key, 32)
+	partials := make([]GroupElement, len(shares))
+	xs := make([]int, len(shares))
+	for i, share := range shares {
+		partials[i], err = DecapsulateShare(share, encapsulation)
+		assert.NoError(err)
+		xs[i] = share.X
+	}
+	recovered, err := CombineDecapsulation(encapsulation, partials[1:4], xs[1:4])
+	assert.NoError(err)
+	assert.Equal(key, recovered)
+	recovered, err = CombineDecapsulation(encapsulation, partials[:2], xs[:2])
+	assert.NoError(err)
+	assert.NotEqual(key, recovered)
+
+	_, err = DecapsulateShare(ShareFiniteField(big.NewInt(1), big.NewInt(7919), 1, 2)[0], encapsulation)
+	assert.Equal(ErrorWrongShareType, err)
+}
+
+func TestEncryptToCommittee(t *testing.T) {
+	assert := assert.New(t)
+	g := testGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(123456), g, 1, 3)
+	message := []byte("Only two of three can read this")
+
+	encapsulation, ciphertext, err := EncryptToCommittee(commitments[0], g, message)
+	assert.NoError(err)
+	partials := make([]GroupElement, len(shares))
+	for i, share := range shares {
+		partials[i], err = DecapsulateShare(share, encapsulation)
+		assert.NoError(err)
+	}
+	decrypted, err := DecryptFromCommittee(encapsulation, ciphertext, partials[1:], []int{2, 3})
+	assert.NoError(err)
+	assert.Equal(message, decrypted)
+
+	// A wrong partial result or a modified ciphertext is detected
+	wrong := []GroupElement{partials[0], partials[1].Add(g)}
+	_, err = DecryptFromCommittee(encapsulation, ciphertext, wrong, []int{1, 2})
+	assert.Equal(ErrorDecryption, err)
+	ciphertext[len(ciphertext)-1] ^= 1
+	_, err = DecryptFromCommittee(encapsulation, ciphertext, partials[:2], []int{1, 2})
+	assert.Equal(ErrorDecryption, err)
+	_, err = DecryptFromCommittee(encapsulation, ciphertext[:4], partials[:2], []int{1, 2})
+	assert.Equal(ErrorDecryption, err)
+}
@@ -0,0 +1,174 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorDuplicateX    = errors.New("Shares with equal X coordinates given")
+	ErrorNotInvertible = errors.New("Differences of X coordinates are not invertible modulo the modulus")
+)
+
+// CombineAt interpolates the polynomial through a set of shares and returns its value f(x) at any
+// point x, where ShareCombine always recovers f(0). This can be used, for example, to recover
+// secrets that were packed at points other than zero, or to compute the share Y of a new
+// shareholder at coordinate x without revealing the secret. Like ShareCombine, it uses the first
+// degree+1 shares.
+//
+// For shares over a finite field, the result is reduced modulo the field size. For shares over the
+// integers, f(0) equals the secret multiplied by Factor, and f(x) is always an integer for
+// compatible shares; ErrorFractionalSecret is returned otherwise.
+func CombineAt(shares []Share, x int) (*big.Int, error) {
+	return emitCombine("", shares, func(shares []Share) (*big.Int, error) {
+		if err := checkCombinable(shares); err != nil {
+			return nil, err
+		}
+		return interpolate(shares[:shares[0].Degree+1], x)
+	})
+}
+
+// RecoverShares recomputes the shares of all nShares parties, with X coordinates 1 to nShares, from
+// a quorum of degree+1 shares. This gives the full evaluation vector of the polynomial, as needed
+// by protocols that echo or recover the shares of other parties, without learning the polynomial
+// itself. Like ShareCombine, it uses the first degree+1 shares; use CheckShares to check surplus
+// shares first.
+func RecoverShares(shares []Share, nShares int) ([]Share, error) {
+	if err := checkCombinable(shares); err != nil {
+		return nil, err
+	}
+	quorum := shares[:shares[0].Degree+1]
+	recovered := make([]Share, nShares)
+	for i := range recovered {
+		y, err := interpolate(quorum, i+1)
+		if err != nil {
+			return nil, err
+		}
+		recovered[i] = quorum[0]
+		recovered[i].X = i + 1
+		recovered[i].Y = y
+	}
+	return recovered, nil
+}
+
+// CombineMod reconstructs a secret shared over the integers directly modulo a public modulus m,
+// without computing the possibly much larger secret over the integers. This is needed, for example,
+// for exponent arithmetic in threshold RSA or Paillier. The differences of the X coordinates and
+// the Factor of the shares must be invertible modulo m, which holds if gcd(nShares!, m) = 1;
+// ErrorNotInvertible is returned otherwise. Like ShareCombine, it uses the first degree+1 shares.
+func CombineMod(shares []Share, m *big.Int) (*big.Int, error) {
+	return emitCombine("", shares, func(shares []Share) (*big.Int, error) {
+		if err := checkCombinable(shares); err != nil {
+			return nil, err
+		}
+		if shares[0].FieldSize != nil || shares[0].Factor == nil {
+			return nil, ErrorWrongShareType
+		}
+		shares = shares[:shares[0].Degree+1]
+		xs := make([]int, len(shares))
+		for i := range shares {
+			xs[i] = shares[i].X
+		}
+		coefficients, err := LagrangeCoefficients(xs, 0, m)
+		if err != nil {
+			return nil, err
+		}
+		inverseFactor := big.NewInt(0).Mod(shares[0].Factor, m)
+		if inverseFactor.ModInverse(inverseFactor, m) == nil {
+			return nil, ErrorNotInvertible
+		}
+		secret := big.NewInt(0)
+		for i := range shares {
+			secret.Add(secret, big.NewInt(0).Mul(coefficients[i], shares[i].Y))
+		}
+		secret.Mul(secret.Mod(secret, m), inverseFactor)
+		return secret.Mod(secret, m), nil
+	})
+}
+
+// interpolate evaluates the polynomial of lowest degree through all given shares at x. The shares
+// must be compatible.
+func interpolate(shares []Share, x int) (*big.Int, error) {
+	xs := make([]int, len(shares))
+	for i := range shares {
+		xs[i] = shares[i].X
+	}
+
+	if fieldSize := shares[0].FieldSize; fieldSize != nil {
+		coefficients, err := LagrangeCoefficients(xs, x, fieldSize)
+		if err != nil {
+			return nil, err
+		}
+		y := big.NewInt(0)
+		for i := range shares {
+			y.Add(y, big.NewInt(0).Mul(coefficients[i], shares[i].Y))
+		}
+		return y.Mod(y, fieldSize), nil
+	}
+
+	y := big.NewRat(0, 1)
+	for i := range shares {
+		term := big.NewRat(0, 1).SetInt(shares[i].Y)
+		for j := range shares {
+			if i == j {
+				continue
+			}
+			if xs[i] == xs[j] {
+				return nil, ErrorDuplicateX
+			}
+			term.Mul(term, big.NewRat(int64(x-xs[j]), int64(xs[i]-xs[j])))
+		}
+		y.Add(y, term)
+	}
+	if !y.IsInt() {
+		return nil, ErrorFractionalSecret
+	}
+	return big.NewInt(0).Set(y.Num()), nil
+}
+
+// LagrangeCoefficients computes the Lagrange coefficients for interpolating a polynomial through
+// the points xs at the point at, modulo modulus. Coefficient i equals
+// prod(j != i) (at - xs[j]) / (xs[i] - xs[j]). Higher-level protocols, such as threshold signing or
+// decryption, can use them to weight the partial results of the holders of the shares at xs. The
+// modulus need not be prime, but all differences xs[i] - xs[j] must be invertible modulo it;
+// ErrorNotInvertible is returned otherwise.
+func LagrangeCoefficients(xs []int, at int, modulus *big.Int) ([]*big.Int, error) {
+	coefficients := make([]*big.Int, len(xs))
+	numerator := big.NewInt(0)
+	denominator := big.NewInt(0)
+	for i := range xs {
+		numerator.SetInt64(1)
+		denominator.SetInt64(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			if xs[i] == xs[j] {
+				return nil, ErrorDuplicateX
+			}
+			numerator.Mul(numerator, big.NewInt(int64(at-xs[j])))
+			denominator.Mul(denominator, big.NewInt(int64(xs[i]-xs[j])))
+		}
+		denominator.Mod(denominator, modulus)
+		if denominator.ModInverse(denominator, modulus) == nil {
+			return nil, ErrorNotInvertible
+		}
+		coefficients[i] = big.NewInt(0).Mul(numerator, denominator)
+		coefficients[i].Mod(coefficients[i], modulus)
+	}
+	return coefficients, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineAt(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	coefficients := bigInts(17, 4000)
+	shares := shareFiniteField(big.NewInt(123), fieldSize, coefficients, 5)
+
+	y, err := CombineAt(shares, 0)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(123), y)
+
+	// Recover a share from other shares
+	y, err = CombineAt(shares[2:], 1)
+	assert.NoError(err)
+	assert.Equal(shares[0].Y, y)
+
+	// Values at points other than the share coordinates
+	for _, x := range []int{-3, -1, 6, 100} {
+		y, err = CombineAt(shares[1:4], x)
+		assert.NoError(err)
+		expected := evaluatePolynomial(big.NewInt(123), coefficients, x)
+		assert.Equal(expected.Mod(expected, fieldSize), y)
+	}
+
+	// Enroll a new shareholder at X = 6
+	enrolled := Share{FieldSize: fieldSize, Degree: 2, X: 6}
+	enrolled.Y, err = CombineAt(shares[:3], 6)
+	assert.NoError(err)
+	secret, err := ShareCombine([]Share{shares[3], enrolled, shares[4]})
+	assert.NoError(err)
+	assert.Equal(big.NewInt(123), secret)
+
+	_, err = CombineAt(shares[:2], 1)
+	assert.Equal(ErrorTooFewShares, err)
+	_, err = CombineAt([]Share{shares[0], shares[0], shares[1]}, 1)
+	assert.Equal(ErrorDuplicateX, err)
+}
+
+func TestIntegerCombineAt(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareIntegers(big.NewInt(-42), big.NewInt(100), 40, 2, 5)
+
+	y, err := CombineAt(shares, 0)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(0).Mul(big.NewInt(-42), shares[0].Factor), y)
+
+	y, err = CombineAt(shares[2:], 2)
+	assert.NoError(err)
+	assert.Equal(shares[1].Y, y)
+
+	_, err = CombineAt([]Share{shares[0], shares[1], shares[1]}, 3)
+	assert.Equal(ErrorDuplicateX, err)
+}
+
+func TestRecoverShares(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareFiniteField(big.NewInt(123), big.NewInt(7919), 2, 6)
+	recovered, err := RecoverShares([]Share{shares[5], shares[1], shares[3]}, 6)
+	assert.NoError(err)
+	assert.Equal(shares, recovered)
+
+	integerShares := ShareIntegers(big.NewInt(123), big.NewInt(1000), 40, 1, 4)
+	recovered, err = RecoverShares(integerShares[2:], 4)
+	assert.NoError(err)
+	assert.Equal(integerShares, recovered)
+
+	_, err = RecoverShares(shares[:2], 6)
+	assert.Equal(ErrorTooFewShares, err)
+}
+
+func TestLagrangeCoefficients(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	shares := ShareFiniteField(big.NewInt(123), fieldSize, 2, 5)
+	xs := []int{shares[4].X, shares[1].X, shares[2].X}
+
+	for _, at := range []int{0, 1, 4} {
+		coefficients, err := LagrangeCoefficients(xs, at, fieldSize)
+		assert.NoError(err)
+		y := big.NewInt(0)
+		for i, x := range xs {
+			y.Add(y, big.NewInt(0).Mul(coefficients[i], shares[x-1].Y))
+		}
+		y.Mod(y, fieldSize)
+		expected, err := CombineAt(shares, at)
+		assert.NoError(err)
+		assert.Equal(expected, y)
+	}
+
+	// Interpolating at one of the points selects that point
+	coefficients, err := LagrangeCoefficients([]int{1, 2, 3}, 2, fieldSize)
+	assert.NoError(err)
+	assert.Equal(bigInts(0, 1, 0), coefficients)
+
+	_, err = LagrangeCoefficients([]int{1, 2, 1}, 0, fieldSize)
+	assert.Equal(ErrorDuplicateX, err)
+	_, err = LagrangeCoefficients([]int{1, 3}, 0, big.NewInt(10))
+	assert.Equal(ErrorNotInvertible, err)
+}
+
+func TestCombineMod(t *testing.T) {
+	assert := assert.New(t)
+	secret, _ := big.NewInt(0).SetString("-123456789012345678901234567890", 10)
+	shares := ShareIntegers(secret, big.NewInt(0).Abs(secret), 40, 2, 5)
+
+	for _, m := range []*big.Int{big.NewInt(7919), big.NewInt(1000003 * 1000033), big.NewInt(0).Lsh(big.NewInt(1), 127).Sub(big.NewInt(0).Lsh(big.NewInt(1), 127), big.NewInt(1))} {
+		combined, err := CombineMod(shares[2:], m)
+		assert.NoError(err)
+		assert.Equal(big.NewInt(0).Mod(secret, m), combined)
+	}
+
+	// Works for products, whose factor is (5!)^2
+	products := make([]Share, len(shares))
+	for i := range shares {
+		var err error
+		products[i], err = ShareMul([]Share{shares[i], shares[i]})
+		assert.NoError(err)
+	}
+	combined, err := CombineMod(products, big.NewInt(7919))
+	assert.NoError(err)
+	expected := big.NewInt(0).Mul(secret, secret)
+	assert.Equal(expected.Mod(expected, big.NewInt(7919)), combined)
+
+	_, err = CombineMod(shares, big.NewInt(2))
+	assert.Equal(ErrorNotInvertible, err)
+	_, err = CombineMod(shares[2:], big.NewInt(5))
+	assert.Equal(ErrorNotInvertible, err)
+	_, err = CombineMod(ShareFiniteField(big.NewInt(1), big.NewInt(7919), 1, 3), big.NewInt(7919))
+	assert.Equal(ErrorWrongShareType, err)
+}
@@ -0,0 +1,222 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"time"
+)
+
+var (
+	ErrorUnknownShareSet = errors.New("No share set with this identifier or fingerprint")
+	ErrorShareSetExists  = errors.New("A share set with this identifier already exists")
+	ErrorUnknownPolicy   = errors.New("No rotation policy with this name")
+	ErrorShareExpired    = errors.New("Share is outside the validity period of its share set")
+)
+
+// A RotationPolicy decides how long the shares of a share set are valid and how they are renewed.
+// Proactive security relies on rotating shares before an attacker can collect degree+1 of them, so
+// Lifetime should be shorter than the time it would take to compromise that many custodians.
+type RotationPolicy struct {
+	// Lifetime is the validity period of the shares after they are issued or rotated.
+	Lifetime time.Duration
+	// Lead is how long before the end of the validity period a share set is due for rotation.
+	Lead time.Duration
+	// Rotate renews the shares of set, for instance with Party.Refresh, ShareRotation or a reshare
+	// to new custodians, and returns the sharing fingerprint of the new shares, or nil if they have
+	// none.
+	Rotate func(set ShareSet) ([]byte, error)
+}
+
+// A ShareSet describes the shares of one sharing without their values, so it can be stored
+// alongside them and serialized with encoding/json.
+type ShareSet struct {
+	ID string `json:"id"`
+	// Policy is the name of the RotationPolicy of the set in its Lifecycle.
+	Policy string `json:"policy"`
+	// Fingerprint is the sharing fingerprint of the current shares, see WithSharingFingerprint.
+	Fingerprint []byte `json:"fingerprint,omitempty"`
+	// Generation counts the rotations of the set.
+	Generation int       `json:"generation"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+}
+
+// Valid reports whether the shares of the set are valid at time now.
+func (s ShareSet) Valid(now time.Time) bool {
+	return !now.Before(s.NotBefore) && now.Before(s.NotAfter)
+}
+
+// A Lifecycle tracks the validity periods of share sets and rotates them according to their
+// policies. Like Ceremony, it does not keep time itself: it is driven by Tick, and its state can be
+// persisted with State and resumed with ResumeLifecycle. The policies are not part of the state and
+// must be set again.
+type Lifecycle struct {
+	sets     map[string]*ShareSet
+	policies map[string]RotationPolicy
+}
+
+// NewLifecycle returns a Lifecycle without share sets or policies.
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{sets: make(map[string]*ShareSet), policies: make(map[string]RotationPolicy)}
+}
+
+// ResumeLifecycle returns a Lifecycle that tracks the share sets returned by Lifecycle.State.
+func ResumeLifecycle(sets []ShareSet) *Lifecycle {
+	l := NewLifecycle()
+	for i := range sets {
+		set := sets[i]
+		set.Fingerprint = append([]byte(nil), set.Fingerprint...)
+		l.sets[set.ID] = &set
+	}
+	return l
+}
+
+// SetPolicy sets the rotation policy with the given name, replacing any policy of that name.
+func (l *Lifecycle) SetPolicy(name string, policy RotationPolicy) {
+	l.policies[name] = policy
+}
+
+// Register starts tracking the shares with the given sharing fingerprint, issued at time issued,
+// as the share set id. They are valid for the lifetime of the named policy.
+func (l *Lifecycle) Register(id string, policy string, fingerprint []byte, issued time.Time) (ShareSet, error) {
+	p, ok := l.policies[policy]
+	if !ok {
+		return ShareSet{}, ErrorUnknownPolicy
+	}
+	if _, ok := l.sets[id]; ok {
+		return ShareSet{}, ErrorShareSetExists
+	}
+	set := &ShareSet{
+		ID:          id,
+		Policy:      policy,
+		Fingerprint: append([]byte(nil), fingerprint...),
+		NotBefore:   issued,
+		NotAfter:    issued.Add(p.Lifetime),
+	}
+	l.sets[id] = set
+	return *set, nil
+}
+
+// Remove stops tracking the share set id, for instance when its secret is no longer needed.
+func (l *Lifecycle) Remove(id string) {
+	delete(l.sets, id)
+}
+
+// Get returns the share set id.
+func (l *Lifecycle) Get(id string) (ShareSet, error) {
+	set, ok := l.sets[id]
+	if !ok {
+		return ShareSet{}, ErrorUnknownShareSet
+	}
+	return *set, nil
+}
+
+// State returns the share sets tracked, ordered by identifier, for persisting.
+func (l *Lifecycle) State() []ShareSet {
+	sets := make([]ShareSet, 0, len(l.sets))
+	for _, set := range l.sets {
+		copied := *set
+		copied.Fingerprint = append([]byte(nil), set.Fingerprint...)
+		sets = append(sets, copied)
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].ID < sets[j].ID
+	})
+	return sets
+}
+
+// Due returns the share sets that are due for rotation at time now, because the end of their
+// validity period is at most the lead time of their policy away, ordered by the end of their
+// validity period. Sets with an unknown policy are due when they expire.
+func (l *Lifecycle) Due(now time.Time) []ShareSet {
+	var due []ShareSet
+	for _, set := range l.State() {
+		if !now.Before(set.NotAfter.Add(-l.policies[set.Policy].Lead)) {
+			due = append(due, set)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NotAfter.Before(due[j].NotAfter)
+	})
+	return due
+}
+
+// Expired returns the share sets whose validity period has ended at time now, which were not
+// rotated in time.
+func (l *Lifecycle) Expired(now time.Time) []ShareSet {
+	var expired []ShareSet
+	for _, set := range l.State() {
+		if !now.Before(set.NotAfter) {
+			expired = append(expired, set)
+		}
+	}
+	return expired
+}
+
+// Tick rotates every share set that is due at time now with the Rotate hook of its policy. A set
+// that is rotated successfully gets the new fingerprint and a new validity period from now. Sets
+// whose policy has no hook are skipped, and Tick continues after a failing hook and returns the
+// first error, so the failed sets remain due and are tried again at the next Tick. It should be
+// called periodically, for instance daily.
+func (l *Lifecycle) Tick(now time.Time) error {
+	var firstErr error
+	for _, set := range l.Due(now) {
+		policy, ok := l.policies[set.Policy]
+		if !ok {
+			if firstErr == nil {
+				firstErr = ErrorUnknownPolicy
+			}
+			continue
+		}
+		if policy.Rotate == nil {
+			continue
+		}
+		fingerprint, err := policy.Rotate(set)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if current, ok := l.sets[set.ID]; ok {
+			current.Fingerprint = append([]byte(nil), fingerprint...)
+			current.Generation++
+			current.NotBefore = now
+			current.NotAfter = now.Add(policy.Lifetime)
+		}
+	}
+	return firstErr
+}
+
+// CheckShare checks that share belongs to a tracked share set, by its sharing fingerprint, and is
+// valid at time now. It returns ErrorUnknownShareSet for shares of no tracked set, including shares
+// from before a rotation, and ErrorShareExpired outside the validity period.
+func (l *Lifecycle) CheckShare(share Share, now time.Time) error {
+	if share.SharingFingerprint == nil {
+		return ErrorUnknownShareSet
+	}
+	for _, set := range l.sets {
+		if bytes.Equal(set.Fingerprint, share.SharingFingerprint) {
+			if !set.Valid(now) {
+				return ErrorShareExpired
+			}
+			return nil
+		}
+	}
+	return ErrorUnknownShareSet
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	day := 24 * time.Hour
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	shares, commitments := ShareFeldman(big.NewInt(42), largeTestGroup.Generator(), 1, 3)
+
+	l := NewLifecycle()
+	_, err := l.Register("root", "monthly", commitments.SharingFingerprint(), start)
+	assert.Equal(ErrorUnknownPolicy, err)
+	l.SetPolicy("monthly", RotationPolicy{
+		Lifetime: 30 * day,
+		Lead:     7 * day,
+		Rotate: func(set ShareSet) ([]byte, error) {
+			deltas, deltaCommitments := ShareFeldman(big.NewInt(0), largeTestGroup.Generator(), 1, 3)
+			for i := range shares {
+				shares[i], _ = RotateShare(shares[i], deltas[i])
+			}
+			commitments, _ = commitments.Add(deltaCommitments)
+			fingerprint := commitments.SharingFingerprint()
+			shares = WithSharingFingerprint(shares, fingerprint)
+			return fingerprint, nil
+		},
+	})
+	set, err := l.Register("root", "monthly", commitments.SharingFingerprint(), start)
+	assert.NoError(err)
+	assert.Equal(start.Add(30*day), set.NotAfter)
+	_, err = l.Register("root", "monthly", nil, start)
+	assert.Equal(ErrorShareSetExists, err)
+	assert.NoError(l.CheckShare(shares[0], start.Add(day)))
+
+	// Not due until a week before expiry
+	assert.Empty(l.Due(start.Add(22 * day)))
+	assert.NoError(l.Tick(start.Add(22 * day)))
+	old := shares[0]
+	assert.Len(l.Due(start.Add(23*day)), 1)
+	assert.NoError(l.Tick(start.Add(23 * day)))
+	assert.Empty(l.Due(start.Add(23 * day)))
+
+	set, err = l.Get("root")
+	assert.NoError(err)
+	assert.Equal(1, set.Generation)
+	assert.Equal(start.Add(53*day), set.NotAfter)
+	assert.NoError(l.CheckShare(shares[0], start.Add(30*day)))
+	assert.Equal(ErrorUnknownShareSet, l.CheckShare(old, start.Add(30*day)))
+	assert.Equal(ErrorShareExpired, l.CheckShare(shares[0], start.Add(60*day)))
+	assert.Len(l.Expired(start.Add(60*day)), 1)
+
+	secret, err := ShareCombine(shares[1:])
+	assert.NoError(err)
+	assert.Equal(big.NewInt(42), secret)
+}
+
+func TestLifecycleResume(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLifecycle()
+	failure := errors.New("custodian unreachable")
+	l.SetPolicy("hourly", RotationPolicy{Lifetime: time.Hour, Rotate: func(ShareSet) ([]byte, error) {
+		return nil, failure
+	}})
+	l.SetPolicy("manual", RotationPolicy{Lifetime: 2 * time.Hour})
+	_, err := l.Register("b", "hourly", []byte{1}, start)
+	assert.NoError(err)
+	_, err = l.Register("a", "manual", []byte{2}, start)
+	assert.NoError(err)
+
+	// A failing hook leaves the set due, and sets without a hook are only reported
+	assert.Equal(failure, l.Tick(start.Add(3*time.Hour)))
+	due := l.Due(start.Add(3 * time.Hour))
+	assert.Len(due, 2)
+	assert.Equal("b", due[0].ID)
+
+	encoded, err := json.Marshal(l.State())
+	assert.NoError(err)
+	var state []ShareSet
+	assert.NoError(json.Unmarshal(encoded, &state))
+	resumed := ResumeLifecycle(state)
+	assert.Equal(l.State(), resumed.State())
+	assert.Equal(ErrorUnknownPolicy, resumed.Tick(start.Add(3*time.Hour)))
+
+	resumed.Remove("b")
+	_, err = resumed.Get("b")
+	assert.Equal(ErrorUnknownShareSet, err)
+	assert.Len(resumed.State(), 1)
+}
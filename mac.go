@@ -0,0 +1,91 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorInvalidMAC = errors.New("Share does not match its MAC")
+)
+
+// macKeySize is the size in bytes of the keys returned by NewMACKey.
+const macKeySize = 32
+
+// An AuthenticatedShare is a share with an HMAC-SHA256 tag under a key of the dealer. It detects
+// accidental corruption and tampering by anyone who does not know the key, without the cost of
+// ShareFeldman, but only the holder of the key can check it. The dealer gives the key to the party
+// that combines the shares, not to the shareholders.
+type AuthenticatedShare struct {
+	Share
+	Tag []byte
+}
+
+// NewMACKey returns a fresh random key for AuthenticateShares.
+func NewMACKey() ([]byte, error) {
+	key := make([]byte, macKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// AuthenticateShares attaches a tag under key to every share. The tag covers all fields of the
+// share.
+func AuthenticateShares(shares []Share, key []byte) []AuthenticatedShare {
+	authenticated := make([]AuthenticatedShare, len(shares))
+	for i, share := range shares {
+		authenticated[i] = AuthenticatedShare{Share: share, Tag: shareMAC(share, key)}
+	}
+	return authenticated
+}
+
+// Verify reports whether the tag of the share is valid under key.
+func (s AuthenticatedShare) Verify(key []byte) bool {
+	return hmac.Equal(s.Tag, shareMAC(s.Share, key))
+}
+
+// CombineAuthenticated checks the tags of the shares under key and recovers the secret like
+// ShareCombine. If a tag is invalid, it returns ErrorInvalidMAC along with the X coordinates of the
+// shares with invalid tags, which can be left out to combine the others.
+func CombineAuthenticated(shares []AuthenticatedShare, key []byte) (*big.Int, []int, error) {
+	var invalid []int
+	plain := make([]Share, len(shares))
+	for i, share := range shares {
+		if !share.Verify(key) {
+			invalid = append(invalid, share.X)
+		}
+		plain[i] = share.Share
+	}
+	if invalid != nil {
+		return nil, invalid, ErrorInvalidMAC
+	}
+	secret, err := ShareCombine(plain)
+	return secret, nil, err
+}
+
+// shareMAC returns the HMAC-SHA256 of the share under key.
+func shareMAC(share Share, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	writeShare(mac, share)
+	writeInt(mac, share.Bound)
+	writeBytes(mac, share.SharingFingerprint)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineAuthenticated(t *testing.T) {
+	assert := assert.New(t)
+	key, err := NewMACKey()
+	assert.NoError(err)
+	shares := AuthenticateShares(ShareFiniteField(big.NewInt(1234), big.NewInt(7919), 2, 5), key)
+	for _, share := range shares {
+		assert.True(share.Verify(key))
+	}
+	secret, invalid, err := CombineAuthenticated(shares[2:], key)
+	assert.NoError(err)
+	assert.Nil(invalid)
+	assert.Equal(big.NewInt(1234), secret)
+
+	// Corrupted shares and shares with changed metadata are detected
+	shares[1].Y = big.NewInt(0).Add(shares[1].Y, big.NewInt(1))
+	shares[3].X = 6
+	_, invalid, err = CombineAuthenticated(shares, key)
+	assert.Equal(ErrorInvalidMAC, err)
+	assert.Equal([]int{2, 6}, invalid)
+
+	// Tags under another key are invalid
+	other, _ := NewMACKey()
+	assert.False(shares[0].Verify(other))
+
+	// Integer shares are covered as well
+	integer := AuthenticateShares(ShareIntegers(big.NewInt(42), big.NewInt(100), 40, 1, 3), key)
+	secret, _, err = CombineAuthenticated(integer[:2], key)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(42), secret)
+	integer[0].Factor = big.NewInt(1)
+	assert.False(integer[0].Verify(key))
+}
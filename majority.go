@@ -0,0 +1,114 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorNoMajority = errors.New("No value was reconstructed by a majority of the subsets")
+)
+
+// A MajorityReport describes the vote of CombineMajority.
+type MajorityReport struct {
+	// Subsets is the number of subsets of degree+1 shares that were combined, and Votes the number
+	// of them that reconstructed the returned secret.
+	Subsets int
+	Votes   int
+	// Disagreeing contains, for every subset that reconstructed a different value or failed to
+	// reconstruct, the X coordinates of its shares.
+	Disagreeing [][]int
+}
+
+// CombineMajority combines every subset of degree+1 of the given shares, and returns the secret
+// reconstructed by a strict majority of the subsets. A wrong share changes the value of every
+// subset that contains it, so the secret is recovered as long as most subsets contain only correct
+// shares, for instance one wrong share among five shares of degree 1. The report lists the
+// disagreeing subsets, which point at the wrong shares.
+//
+// The number of subsets grows binomially in the number of shares, so this is only suitable for
+// small share sets; use CombineGao to correct larger sets over a finite field, or CombineWithReport
+// to check them for consistency. If no value has a strict majority, ErrorNoMajority is returned
+// together with the report.
+func CombineMajority(shares []Share) (*big.Int, MajorityReport, error) {
+	var report MajorityReport
+	secret, err := emitCombine("", shares, func(shares []Share) (*big.Int, error) {
+		if err := checkCombinable(shares); err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			if containsX(shares[:i], shares[i].X) {
+				return nil, ErrorDuplicateX
+			}
+		}
+
+		var values []*big.Int
+		var xs [][]int
+		subset := make([]Share, 0, shares[0].Degree+1)
+		var combine func(start int)
+		combine = func(start int) {
+			if len(subset) == cap(subset) {
+				value, err := shareCombine(subset)
+				if err != nil {
+					value = nil
+				}
+				values = append(values, value)
+				x := make([]int, len(subset))
+				for i := range subset {
+					x[i] = subset[i].X
+				}
+				xs = append(xs, x)
+				return
+			}
+			for i := start; i < len(shares); i++ {
+				subset = append(subset, shares[i])
+				combine(i + 1)
+				subset = subset[:len(subset)-1]
+			}
+		}
+		combine(0)
+		report.Subsets = len(values)
+
+		// Boyer-Moore majority vote, followed by a count to check the candidate
+		var candidate *big.Int
+		count := 0
+		for _, value := range values {
+			switch {
+			case count == 0:
+				candidate, count = value, 1
+			case equalOrBothNil(candidate, value):
+				count++
+			default:
+				count--
+			}
+		}
+		for i, value := range values {
+			if candidate != nil && equalOrBothNil(candidate, value) {
+				report.Votes++
+			} else {
+				report.Disagreeing = append(report.Disagreeing, xs[i])
+			}
+		}
+		if candidate == nil || 2*report.Votes <= report.Subsets {
+			report.Votes = 0
+			report.Disagreeing = xs
+			return nil, ErrorNoMajority
+		}
+		return candidate, nil
+	})
+	return secret, report, err
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineMajority(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareFiniteField(big.NewInt(42), big.NewInt(7919), 1, 5)
+	secret, report, err := CombineMajority(shares)
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+	assert.Equal(10, report.Subsets)
+	assert.Equal(10, report.Votes)
+	assert.Empty(report.Disagreeing)
+
+	// The four subsets containing the wrong share are outvoted
+	shares[2].Y = big.NewInt(0).Add(shares[2].Y, big.NewInt(1))
+	secret, report, err = CombineMajority(shares)
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+	assert.Equal(6, report.Votes)
+	assert.Equal([][]int{{1, 3}, {2, 3}, {3, 4}, {3, 5}}, report.Disagreeing)
+
+	// With two wrong shares, only three of ten subsets agree
+	shares[4].Y = big.NewInt(0).Add(shares[4].Y, big.NewInt(1))
+	_, report, err = CombineMajority(shares)
+	assert.Equal(ErrorNoMajority, err)
+	assert.Len(report.Disagreeing, 10)
+
+	_, _, err = CombineMajority([]Share{shares[0], shares[0], shares[1]})
+	assert.Equal(ErrorDuplicateX, err)
+	_, _, err = CombineMajority(shares[:1])
+	assert.Equal(ErrorTooFewShares, err)
+}
+
+func TestIntegerCombineMajority(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareIntegers(big.NewInt(42), big.NewInt(100), 40, 1, 5)
+	// Subsets with the wrong share fail to divide by the factor or give a different secret
+	shares[0].Y = big.NewInt(0).Add(shares[0].Y, big.NewInt(1))
+	secret, report, err := CombineMajority(shares)
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+	assert.Equal(10, report.Subsets)
+	assert.Equal(6, report.Votes)
+}
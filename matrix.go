@@ -0,0 +1,145 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorMatrixDimensions = errors.New("Matrices with incompatible dimensions given")
+)
+
+// A ShareMatrix holds the shares of a single party of a matrix of secrets, row by row.
+type ShareMatrix []ShareVector
+
+// ShareMatrixFiniteField shares every secret in the matrix secrets over a finite field, see
+// ShareFiniteField. It returns a ShareMatrix for every party.
+func ShareMatrixFiniteField(secrets [][]*big.Int, fieldSize *big.Int, degree int, nShares int) []ShareMatrix {
+	matrices := make([]ShareMatrix, nShares)
+	for i := range matrices {
+		matrices[i] = make(ShareMatrix, len(secrets))
+	}
+	for r, row := range secrets {
+		vectors := ShareVectorFiniteField(row, fieldSize, degree, nShares)
+		for i := range matrices {
+			matrices[i][r] = vectors[i]
+		}
+	}
+	return matrices
+}
+
+// CombineMatrix combines the ShareMatrices of several parties and recovers the matrix of secrets.
+func CombineMatrix(matrices []ShareMatrix) ([][]*big.Int, error) {
+	if len(matrices) == 0 {
+		return nil, ErrorNoShares
+	}
+	secrets := make([][]*big.Int, len(matrices[0]))
+	rows := make([]ShareVector, len(matrices))
+	for r := range secrets {
+		for i := range matrices {
+			if len(matrices[i]) != len(secrets) {
+				return nil, ErrorMatrixDimensions
+			}
+			rows[i] = matrices[i][r]
+		}
+		var err error
+		secrets[r], err = CombineVector(rows)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return secrets, nil
+}
+
+// Mul returns a share of the matrix product of the matrices shared by m and other. Its degree is
+// the sum of the degrees of the factors, see ShareMul.
+func (m ShareMatrix) Mul(other ShareMatrix) (ShareMatrix, error) {
+	if len(m) == 0 || len(other) == 0 {
+		return nil, ErrorNoShares
+	}
+	columns := make([]ShareVector, len(other[0]))
+	for c := range columns {
+		columns[c] = make(ShareVector, len(other))
+		for r := range other {
+			if len(other[r]) != len(columns) {
+				return nil, ErrorMatrixDimensions
+			}
+			columns[c][r] = other[r][c]
+		}
+	}
+
+	product := make(ShareMatrix, len(m))
+	for r := range m {
+		if len(m[r]) != len(other) {
+			return nil, ErrorMatrixDimensions
+		}
+		product[r] = make(ShareVector, len(columns))
+	}
+	err := parallelFor(len(m)*len(columns), func(j int) error {
+		r, c := j/len(columns), j%len(columns)
+		var err error
+		product[r][c], err = m[r].InnerProduct(columns[c])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// MulVector returns a share of the product of the matrix shared by m and the vector shared by v.
+// Its degree is the sum of the degrees of the factors.
+func (m ShareMatrix) MulVector(v ShareVector) (ShareVector, error) {
+	product := make(ShareVector, len(m))
+	for r := range m {
+		if len(m[r]) != len(v) {
+			return nil, ErrorMatrixDimensions
+		}
+	}
+	err := parallelFor(len(m), func(r int) error {
+		var err error
+		product[r], err = m[r].InnerProduct(v)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// Vector returns the shares of the matrix row by row as a single vector, for instance to reshare
+// them with ShareVector.Split.
+func (m ShareMatrix) Vector() ShareVector {
+	var v ShareVector
+	for _, row := range m {
+		v = append(v, row...)
+	}
+	return v
+}
+
+// ShareMatrixFromVector is the inverse of ShareMatrix.Vector for matrices with the given number
+// of columns.
+func ShareMatrixFromVector(v ShareVector, columns int) (ShareMatrix, error) {
+	if columns <= 0 || len(v)%columns != 0 {
+		return nil, ErrorMatrixDimensions
+	}
+	m := make(ShareMatrix, len(v)/columns)
+	for r := range m {
+		m[r] = v[r*columns : (r+1)*columns]
+	}
+	return m, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareMatrix(t *testing.T) {
+	assert := assert.New(t)
+	a := ShareMatrixFiniteField([][]*big.Int{bigInts(1, 2), bigInts(3, 4), bigInts(5, 6)}, big.NewInt(7919), 1, 3)
+	b := ShareMatrixFiniteField([][]*big.Int{bigInts(1, 0, 2), bigInts(0, 1, 3)}, big.NewInt(7919), 1, 3)
+	v := ShareVectorFiniteField(bigInts(1, -1), big.NewInt(7919), 1, 3)
+
+	products := make([]ShareMatrix, 3)
+	vectorProducts := make([]ShareVector, 3)
+	var err error
+	for i := range products {
+		products[i], err = a[i].Mul(b[i])
+		assert.NoError(err)
+		vectorProducts[i], err = a[i].MulVector(v[i])
+		assert.NoError(err)
+	}
+
+	product, err := CombineMatrix(products)
+	assert.NoError(err)
+	assert.Equal([][]*big.Int{bigInts(1, 2, 8), bigInts(3, 4, 18), bigInts(5, 6, 28)}, product)
+
+	vectorProduct, err := CombineVector(vectorProducts)
+	assert.NoError(err)
+	assert.Equal(bigInts(7918, 7918, 7918), vectorProduct)
+
+	_, err = a[0].Mul(a[0])
+	assert.Equal(ErrorMatrixDimensions, err)
+	_, err = b[0].MulVector(v[0])
+	assert.Equal(ErrorMatrixDimensions, err)
+}
+
+func TestShareMatrixVector(t *testing.T) {
+	assert := assert.New(t)
+	matrices := ShareMatrixFiniteField([][]*big.Int{bigInts(1, 2, 3), bigInts(4, 5, 6)}, big.NewInt(7919), 1, 3)
+
+	v := matrices[0].Vector()
+	assert.Len(v, 6)
+	m, err := ShareMatrixFromVector(v, 3)
+	assert.NoError(err)
+	assert.Equal(matrices[0], m)
+
+	_, err = ShareMatrixFromVector(v, 4)
+	assert.Equal(ErrorMatrixDimensions, err)
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// A Multiplier runs the resharing multiplication of Gennaro, Rabin and Rabin, "Simplified VSS and
+// fast-track multiparty computations with applications to threshold cryptography" (PODC 1998), like
+// Party.Mul, but as a state machine for a single product that does not assume a transport. The
+// local product h(i) = a(i) * b(i) of party i is a share of a polynomial h of degree 2t with
+// h(0) = ab. Party i shares h(i) with a fresh polynomial s_i of degree t, and party j receives the
+// sub-shares s_i(j) of all parties. Since ab = sum_i lambda_i h(i) for the Lagrange coefficients of
+// any set of at least 2t+1 parties, sum_i lambda_i s_i(j) is a share of degree t of ab, as long as
+// all parties use the same set. Party j computes it by interpolating the points (i, s_i(j)) at 0.
+// By default, that set contains all parties; Finish lets them agree on a smaller one. The Multiplier
+// checks that every sub-share is well-formed and comes from a distinct party, but like Party.Mul it
+// is only secure against semi-honest parties: a wrong sub-share gives a wrong product share.
+
+// A Multiplier multiplies two shared secrets for the party with X coordinate x of nParties parties.
+type Multiplier struct {
+	session   string
+	x         int
+	nParties  int
+	product   Share
+	started   bool
+	subshares []Share
+	result    *Share
+}
+
+// NewMultiplier returns the Multiplier of the party holding shares a and b of degree t over a
+// finite field in the given session, among nParties parties with X coordinates 1 to nParties. It
+// returns ErrorTooFewParties unless nParties > 2t.
+func NewMultiplier(session string, a Share, b Share, nParties int) (*Multiplier, error) {
+	if a.FieldSize == nil || b.FieldSize == nil {
+		return nil, ErrorWrongShareType
+	}
+	if nParties <= 2*a.Degree {
+		return nil, ErrorTooFewParties
+	}
+	if a.X < 1 || a.X > nParties {
+		return nil, ErrorInvalidX
+	}
+	product, err := ShareMul([]Share{a, b})
+	if err != nil {
+		return nil, err
+	}
+	return &Multiplier{session: session, x: a.X, nParties: nParties, product: product}, nil
+}
+
+// Start reshares the local product and returns a MessageSubshare for every other party. It can only
+// be called once.
+func (m *Multiplier) Start() ([]Message, error) {
+	if m.started {
+		return nil, ErrorUnexpectedMessage
+	}
+	m.started = true
+	subshares := ShareFiniteField(m.product.Y, m.product.FieldSize, m.product.Degree/2, m.nParties)
+	m.product.Y = nil
+	var messages []Message
+	for i := range subshares {
+		if subshares[i].X == m.x {
+			m.subshares = append(m.subshares, Share{FieldSize: m.product.FieldSize, X: m.x, Y: subshares[i].Y})
+			continue
+		}
+		messages = append(messages, Message{
+			Type:    MessageSubshare,
+			Session: m.session,
+			From:    m.x,
+			To:      subshares[i].X,
+			Share:   &subshares[i],
+		})
+	}
+	return messages, m.finishIfComplete()
+}
+
+// Handle processes a MessageSubshare. Once the sub-shares of all parties have been received, they
+// are recombined and Done returns true. Repeated sub-shares from the same party, and sub-shares
+// arriving after Finish, are ignored.
+func (m *Multiplier) Handle(message Message) ([]Message, error) {
+	if message.Session != m.session {
+		return nil, ErrorWrongSession
+	}
+	if message.Type != MessageSubshare || !m.started || message.Share == nil ||
+		message.Share.X != m.x || message.From < 1 || message.From > m.nParties ||
+		!equalOrBothNil(message.Share.FieldSize, m.product.FieldSize) || message.Share.Degree != m.product.Degree/2 ||
+		message.Share.Y == nil {
+		return nil, ErrorUnexpectedMessage
+	}
+	if m.result != nil || m.received(message.From) {
+		return nil, nil
+	}
+	m.subshares = append(m.subshares, Share{FieldSize: m.product.FieldSize, X: message.From, Y: message.Share.Y})
+	return nil, m.finishIfComplete()
+}
+
+// Finish recombines the sub-shares of the parties in quorum, for instance after a deadline passed
+// without all parties sending theirs. All parties must call it with the same quorum, or their
+// shares of the product are inconsistent. It returns ErrorTooFewShares unless the sub-shares of at
+// least 2t+1 parties in quorum have been received; sub-shares of the others are not used.
+func (m *Multiplier) Finish(quorum []int) error {
+	if m.result != nil {
+		return nil
+	}
+	var points []Share
+	for _, share := range m.subshares {
+		if containsInt(quorum, share.X) {
+			points = append(points, share)
+		}
+	}
+	if len(points) <= m.product.Degree {
+		return ErrorTooFewShares
+	}
+	return m.combine(points)
+}
+
+// Done reports whether the product has been computed.
+func (m *Multiplier) Done() bool {
+	return m.result != nil
+}
+
+// Result returns the share of the product, of the same degree as the factors, or ErrorTooFewShares
+// if the multiplier is not done.
+func (m *Multiplier) Result() (Share, error) {
+	if m.result == nil {
+		return Share{}, ErrorTooFewShares
+	}
+	return *m.result, nil
+}
+
+// received reports whether the sub-share of the party with X coordinate from has been received.
+func (m *Multiplier) received(from int) bool {
+	return containsX(m.subshares, from)
+}
+
+// finishIfComplete recombines the sub-shares once those of all parties have been received.
+func (m *Multiplier) finishIfComplete() error {
+	if len(m.subshares) < m.nParties {
+		return nil
+	}
+	return m.combine(m.subshares)
+}
+
+// combine interpolates the points (i, s_i(x)) at 0. They lie on a polynomial of degree at most
+// len(points)-1 whose value at 0 is the share of the product.
+func (m *Multiplier) combine(points []Share) error {
+	points = append([]Share(nil), points...)
+	for i := range points {
+		points[i].Degree = len(points) - 1
+	}
+	y, err := ShareCombine(points)
+	if err != nil {
+		return err
+	}
+	m.result = &Share{FieldSize: m.product.FieldSize, Degree: m.product.Degree / 2, X: m.x, Y: y}
+	return nil
+}
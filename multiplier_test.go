@@ -0,0 +1,140 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// multiply runs a Multiplier for every party on the given shares, delivering the messages in
+// order, and returns the multipliers. tamper may modify messages before delivery.
+func multiply(t *testing.T, a []Share, b []Share, tamper func(*Message)) []*Multiplier {
+	multipliers := make([]*Multiplier, len(a))
+	var messages []Message
+	for i := range a {
+		var err error
+		multipliers[i], err = NewMultiplier("session", a[i], b[i], len(a))
+		assert.NoError(t, err)
+		outgoing, err := multipliers[i].Start()
+		assert.NoError(t, err)
+		messages = append(messages, outgoing...)
+	}
+	for _, message := range messages {
+		if tamper != nil {
+			tamper(&message)
+		}
+		multipliers[message.To-1].Handle(message)
+	}
+	return multipliers
+}
+
+func TestMultiplier(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	a := ShareFiniteField(big.NewInt(12), fieldSize, 1, 5)
+	b := ShareFiniteField(big.NewInt(34), fieldSize, 1, 5)
+	c := ShareFiniteField(big.NewInt(56), fieldSize, 1, 5)
+
+	// The degree stays 1 across repeated multiplications
+	products := make([]Share, len(a))
+	for i, m := range multiply(t, a, b, nil) {
+		assert.True(m.Done())
+		var err error
+		products[i], err = m.Result()
+		assert.NoError(err)
+		assert.Equal(1, products[i].Degree)
+	}
+	for i, m := range multiply(t, products, c, nil) {
+		products[i], _ = m.Result()
+	}
+	secret, err := ShareCombine(products[3:])
+	assert.NoError(err)
+	assert.Equal(big.NewInt(12*34*56%7919), secret)
+
+	_, err = NewMultiplier("session", a[0], b[0], 2)
+	assert.Equal(ErrorTooFewParties, err)
+	m, _ := NewMultiplier("session", a[0], b[0], 5)
+	_, err = m.Handle(Message{Type: MessageSubshare, Session: "session", From: 2, To: 1, Share: &a[0]})
+	assert.Equal(ErrorUnexpectedMessage, err)
+	_, err = m.Result()
+	assert.Equal(ErrorTooFewShares, err)
+}
+
+func TestMultiplierMessages(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	a := ShareFiniteField(big.NewInt(3), fieldSize, 1, 3)
+	b := ShareFiniteField(big.NewInt(4), fieldSize, 1, 3)
+	m, err := NewMultiplier("session", a[0], b[0], 3)
+	assert.NoError(err)
+	other, _ := NewMultiplier("session", a[1], b[1], 3)
+	_, err = m.Handle(Message{Type: MessageSubshare, Session: "session", From: 2, To: 1, Share: &a[0]})
+	assert.Equal(ErrorUnexpectedMessage, err, "not started")
+	_, err = m.Start()
+	assert.NoError(err)
+	_, err = m.Start()
+	assert.Equal(ErrorUnexpectedMessage, err)
+
+	messages, _ := other.Start()
+	message := messages[0]
+	assert.Equal(MessageSubshare, message.Type)
+	assert.Equal("Subshare", message.Type.String())
+	assert.Equal(1, message.To)
+
+	wrongX := message
+	wrongX.Share = &Share{FieldSize: fieldSize, Degree: 1, X: 3, Y: big.NewInt(1)}
+	_, err = m.Handle(wrongX)
+	assert.Equal(ErrorUnexpectedMessage, err)
+	wrongDegree := message
+	wrongDegree.Share = &Share{FieldSize: fieldSize, Degree: 2, X: 1, Y: big.NewInt(1)}
+	_, err = m.Handle(wrongDegree)
+	assert.Equal(ErrorUnexpectedMessage, err)
+	wrongSession := message
+	wrongSession.Session = "other"
+	_, err = m.Handle(wrongSession)
+	assert.Equal(ErrorWrongSession, err)
+
+	_, err = m.Handle(message)
+	assert.NoError(err)
+	_, err = m.Handle(message)
+	assert.NoError(err)
+	assert.False(m.Done())
+	assert.Equal(ErrorTooFewShares, m.Finish([]int{1, 2, 3}))
+}
+
+func TestMultiplierFinish(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	a := ShareFiniteField(big.NewInt(5), fieldSize, 1, 4)
+	b := ShareFiniteField(big.NewInt(6), fieldSize, 1, 4)
+	// Party 4 never sends its sub-shares
+	multipliers := multiply(t, a, b, func(message *Message) {
+		if message.From == 4 {
+			message.Session = "dropped"
+		}
+	})
+	products := make([]Share, 3)
+	for i := range products {
+		assert.False(multipliers[i].Done())
+		assert.NoError(multipliers[i].Finish([]int{1, 2, 3}))
+		products[i], _ = multipliers[i].Result()
+	}
+	secret, err := ShareCombine(products[1:])
+	assert.NoError(err)
+	assert.Equal(big.NewInt(30), secret)
+}
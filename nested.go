@@ -0,0 +1,140 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// A ParentShare identifies a share that has been split into sub-shares.
+type ParentShare struct {
+	X      int
+	Degree int
+}
+
+// A NestedShare is a share of a share. Parents records the chain of shares it was split from,
+// outermost first, so the last element describes the share of which Share is a sub-share. A
+// NestedShare without parents is an ordinary share.
+//
+// NestedShares can be serialized with encoding/json, which records the nesting.
+type NestedShare struct {
+	Parents []ParentShare
+	Share
+}
+
+// SplitShare splits a share over a finite field into nShares sub-shares using a polynomial of given
+// degree, for instance to let a custodian distribute their share over an internal quorum.
+func SplitShare(share Share, degree int, nShares int) ([]NestedShare, error) {
+	return NestedShare{Share: share}.Split(degree, nShares)
+}
+
+// Split splits a nested share further into nShares sub-shares using a polynomial of given degree.
+func (s NestedShare) Split(degree int, nShares int) ([]NestedShare, error) {
+	if s.FieldSize == nil {
+		return nil, ErrorWrongShareType
+	}
+	parents := make([]ParentShare, len(s.Parents), len(s.Parents)+1)
+	copy(parents, s.Parents)
+	parents = append(parents, ParentShare{X: s.X, Degree: s.Degree})
+
+	shares := ShareFiniteField(s.Y, s.FieldSize, degree, nShares)
+	nested := make([]NestedShare, nShares)
+	for i := range shares {
+		nested[i] = NestedShare{Parents: parents, Share: shares[i]}
+	}
+	return nested, nil
+}
+
+// CombineNested recovers a secret from nested shares, which may have been split to different
+// depths. Working from the deepest level upwards, sub-shares of the same parent are combined into
+// the parent share, until ordinary shares remain that are combined using ShareCombine. Parents for
+// which too few sub-shares are given are ignored.
+func CombineNested(shares []NestedShare) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, ErrorNoShares
+	}
+	for _, share := range shares {
+		if share.FieldSize == nil {
+			return nil, ErrorWrongShareType
+		}
+	}
+
+	for {
+		depth := 0
+		for _, share := range shares {
+			if len(share.Parents) > depth {
+				depth = len(share.Parents)
+			}
+		}
+		if depth == 0 {
+			break
+		}
+
+		// Group the deepest shares by their chain of parents
+		children := make(map[string][]NestedShare)
+		remaining := make([]NestedShare, 0, len(shares))
+		for _, share := range shares {
+			if len(share.Parents) == depth {
+				key := fmt.Sprint(share.Parents)
+				children[key] = append(children[key], share)
+			} else {
+				remaining = append(remaining, share)
+			}
+		}
+		keys := make([]string, 0, len(children))
+		for key := range children {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			subShares := make([]Share, len(children[key]))
+			for i := range children[key] {
+				subShares[i] = children[key][i].Share
+			}
+			y, err := ShareCombine(subShares)
+			if errors.Is(err, ErrorTooFewShares) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			child := children[key][0]
+			parent := child.Parents[depth-1]
+			remaining = append(remaining, NestedShare{
+				Parents: child.Parents[:depth-1],
+				Share: Share{
+					FieldSize: child.FieldSize,
+					Degree:    parent.Degree,
+					X:         parent.X,
+					Y:         y,
+				},
+			})
+		}
+		shares = remaining
+	}
+
+	if len(shares) == 0 {
+		return nil, ErrorTooFewShares
+	}
+	plain := make([]Share, len(shares))
+	for i := range shares {
+		plain[i] = shares[i].Share
+	}
+	return ShareCombine(plain)
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNestedSecretSharing(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareFiniteField(big.NewInt(123), big.NewInt(7919), 2, 4)
+
+	// The custodian of share 0 splits it 2-out-of-3, and one of those sub-custodians splits
+	// their sub-share 2-out-of-2 again.
+	subShares, err := SplitShare(shares[0], 1, 3)
+	assert.NoError(err)
+	subSubShares, err := subShares[2].Split(1, 2)
+	assert.NoError(err)
+	assert.Equal([]ParentShare{{X: 1, Degree: 2}, {X: 3, Degree: 1}}, subSubShares[0].Parents)
+
+	var secret *big.Int
+
+	nested := []NestedShare{subShares[0], subSubShares[0], subSubShares[1], {Share: shares[1]}, {Share: shares[3]}}
+	secret, err = CombineNested(nested)
+	assert.NoError(err)
+	if assert.NotNil(secret) {
+		assert.Equal(int64(123), secret.Int64())
+	}
+
+	// Without the second sub-sub-share, the share of the first custodian can't be recovered
+	secret, err = CombineNested(append(nested[0:2], nested[3:5]...))
+	assert.Nil(secret)
+	assert.Equal(ErrorTooFewShares, err)
+
+	secret, err = CombineNested(nil)
+	assert.Nil(secret)
+	assert.Equal(ErrorNoShares, err)
+}
+
+func TestNestedShareSerialization(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareFiniteField(big.NewInt(123), big.NewInt(7919), 1, 2)
+	subShares, err := SplitShare(shares[0], 1, 2)
+	assert.NoError(err)
+
+	nested := []NestedShare{subShares[0], subShares[1], {Share: shares[1]}}
+	encoded, err := json.Marshal(nested)
+	assert.NoError(err)
+	var decoded []NestedShare
+	assert.NoError(json.Unmarshal(encoded, &decoded))
+	assert.Equal(nested[0].Parents, decoded[0].Parents)
+	assert.Nil(decoded[2].Parents)
+
+	secret, err := CombineNested(decoded)
+	assert.NoError(err)
+	if assert.NotNil(secret) {
+		assert.Equal(int64(123), secret.Int64())
+	}
+}
+
+func TestNestedSharingErrors(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareIntegers(big.NewInt(123), big.NewInt(10000), 100, 1, 2)
+
+	_, err := SplitShare(shares[0], 1, 2)
+	assert.Equal(ErrorWrongShareType, err)
+
+	_, err = CombineNested([]NestedShare{{Share: shares[0]}, {Share: shares[1]}})
+	assert.Equal(ErrorWrongShareType, err)
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+)
+
+// An Interpolator recovers a secret from shares that arrive one at a time, such as in CombineAsync.
+// It keeps the polynomial through the shares in Newton's form, whose coefficients are the divided
+// differences of the shares, so that adding a share takes O(t) operations for t shares so far,
+// instead of interpolating all shares again. The coefficients beyond the degree of the shares are
+// zero as long as all shares lie on a single polynomial of that degree, which makes Consistent
+// cheap.
+type Interpolator struct {
+	params       Share
+	xs           []int
+	coefficients []*big.Rat
+	consistent   bool
+}
+
+// NewInterpolator returns an Interpolator without shares.
+func NewInterpolator() *Interpolator {
+	return &Interpolator{consistent: true}
+}
+
+// Add adds a share. It returns ErrorIncompatibleShares if the share is incompatible with the first
+// share, ErrorDuplicateX if its X coordinate was added before and ErrorInvalidShare if it is
+// malformed, in which case the share is not added.
+func (p *Interpolator) Add(share Share) error {
+	if !wellFormed(share) {
+		return ErrorInvalidShare
+	}
+	if len(p.xs) > 0 && (!equalOrBothNil(p.params.FieldSize, share.FieldSize) || !equalOrBothNil(p.params.Factor, share.Factor) || p.params.Degree != share.Degree) {
+		return ErrorIncompatibleShares
+	}
+	if containsInt(p.xs, share.X) {
+		return ErrorDuplicateX
+	}
+	if len(p.xs) == 0 {
+		p.params = share
+		p.params.Y = nil
+	}
+
+	// The next coefficient is (y - f(x)) / prod_j (x - x_j), for the polynomial f through the
+	// previous shares
+	y := big.NewRat(0, 1).SetInt(share.Y)
+	difference := y.Sub(y, p.evaluate(share.X, len(p.coefficients)))
+	denominator := big.NewRat(1, 1)
+	for _, x := range p.xs {
+		denominator.Mul(denominator, big.NewRat(int64(share.X-x), 1))
+	}
+	coefficient, err := p.divide(difference, denominator)
+	if err != nil {
+		return err
+	}
+	if len(p.coefficients) > p.params.Degree && coefficient.Sign() != 0 {
+		p.consistent = false
+	}
+	p.xs = append(p.xs, share.X)
+	p.coefficients = append(p.coefficients, coefficient)
+	return nil
+}
+
+// Len returns the number of shares added.
+func (p *Interpolator) Len() int {
+	return len(p.xs)
+}
+
+// Consistent reports whether all shares added so far lie on a single polynomial of the degree of
+// the shares.
+func (p *Interpolator) Consistent() bool {
+	return p.consistent
+}
+
+// Evaluate evaluates the polynomial through the first degree+1 shares at x, like CombineAt.
+func (p *Interpolator) Evaluate(x int) (*big.Int, error) {
+	if len(p.xs) == 0 {
+		return nil, ErrorNoShares
+	}
+	if len(p.xs) <= p.params.Degree {
+		return nil, ErrorTooFewShares
+	}
+	y := p.evaluate(x, p.params.Degree+1)
+	if !y.IsInt() {
+		return nil, ErrorFractionalSecret
+	}
+	return big.NewInt(0).Set(y.Num()), nil
+}
+
+// Secret recovers the secret from the first degree+1 shares, like ShareCombine.
+func (p *Interpolator) Secret() (*big.Int, error) {
+	y, err := p.Evaluate(0)
+	if err != nil || p.params.FieldSize != nil {
+		return y, err
+	}
+	return integerSecret(y, p.params)
+}
+
+// evaluate evaluates the Newton form with the first n coefficients at x by Horner's rule.
+func (p *Interpolator) evaluate(x int, n int) *big.Rat {
+	y := big.NewRat(0, 1)
+	for k := n - 1; k >= 0; k-- {
+		y.Mul(y, big.NewRat(int64(x-p.xs[k]), 1))
+		y.Add(y, p.coefficients[k])
+		if p.params.FieldSize != nil {
+			y.SetInt(big.NewInt(0).Mod(y.Num(), p.params.FieldSize))
+		}
+	}
+	return y
+}
+
+// divide divides a by b, in the field of the shares for shares over a finite field.
+func (p *Interpolator) divide(a *big.Rat, b *big.Rat) (*big.Rat, error) {
+	if p.params.FieldSize == nil {
+		return a.Quo(a, b), nil
+	}
+	fieldSize := p.params.FieldSize
+	inverse := big.NewInt(0).Mod(b.Num(), fieldSize)
+	if inverse.ModInverse(inverse, fieldSize) == nil {
+		return nil, ErrorNotInvertible
+	}
+	quotient := inverse.Mul(inverse, a.Num())
+	return big.NewRat(0, 1).SetInt(quotient.Mod(quotient, fieldSize)), nil
+}
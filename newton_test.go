@@ -0,0 +1,87 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolator(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	shares := ShareFiniteField(big.NewInt(42), fieldSize, 2, 6)
+	p := NewInterpolator()
+	_, err := p.Secret()
+	assert.Equal(ErrorNoShares, err)
+	for i, share := range shares[:2] {
+		assert.NoError(p.Add(share))
+		assert.Equal(i+1, p.Len())
+	}
+	_, err = p.Secret()
+	assert.Equal(ErrorTooFewShares, err)
+	for _, share := range shares[2:] {
+		assert.NoError(p.Add(share))
+		secret, err := p.Secret()
+		assert.NoError(err)
+		assert.Equal(int64(42), secret.Int64())
+		assert.True(p.Consistent())
+	}
+	y, err := p.Evaluate(7)
+	assert.NoError(err)
+	expected, err := CombineAt(shares, 7)
+	assert.NoError(err)
+	assert.Equal(expected, y)
+
+	assert.Equal(ErrorDuplicateX, p.Add(shares[0]))
+	assert.Equal(ErrorIncompatibleShares, p.Add(ShareFiniteField(big.NewInt(42), big.NewInt(7907), 2, 7)[6]))
+	assert.Equal(ErrorInvalidShare, p.Add(Share{FieldSize: fieldSize, Degree: 2, X: 8}))
+	assert.Equal(6, p.Len())
+	wrong := ShareFiniteField(big.NewInt(42), fieldSize, 2, 7)[6]
+	assert.NoError(p.Add(wrong))
+	assert.False(p.Consistent())
+	// The secret is still taken from the first degree+1 shares
+	secret, err := p.Secret()
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+
+	// Shares over the integers
+	integers := ShareIntegers(big.NewInt(42), big.NewInt(100), 40, 2, 5)
+	p = NewInterpolator()
+	for _, share := range []Share{integers[4], integers[1], integers[3], integers[0]} {
+		assert.NoError(p.Add(share))
+	}
+	assert.True(p.Consistent())
+	secret, err = p.Secret()
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+	y, err = p.Evaluate(3)
+	assert.NoError(err)
+	assert.Equal(integers[2].Y, y)
+	wrong = integers[2]
+	wrong.Y = big.NewInt(0).Add(wrong.Y, big.NewInt(1))
+	assert.NoError(p.Add(wrong))
+	assert.False(p.Consistent())
+
+	p = NewInterpolator()
+	integers[0].Y = big.NewInt(0).Add(integers[0].Y, big.NewInt(1))
+	for _, share := range integers[:3] {
+		assert.NoError(p.Add(share))
+	}
+	_, err = p.Secret()
+	assert.Equal(ErrorFractionalSecret, err)
+}
@@ -0,0 +1,174 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorInvalidNoiseParameter = errors.New("Noise parameter must be positive")
+)
+
+// SampleDiscreteLaplace samples x from the discrete Laplace distribution with the given scale, for
+// which P(x) is proportional to exp(-|x|/scale). It uses the exact sampling algorithm of Canonne,
+// Kamath and Steinke, "The Discrete Gaussian for Differential Privacy" (2020), with randomness
+// from crypto/rand.
+func SampleDiscreteLaplace(scale *big.Rat) (*big.Int, error) {
+	if scale.Sign() <= 0 {
+		return nil, ErrorInvalidNoiseParameter
+	}
+	return sampleDiscreteLaplace(scale.Num(), scale.Denom())
+}
+
+// SampleDiscreteGaussian samples x from the discrete Gaussian distribution with parameter sigma2,
+// for which P(x) is proportional to exp(-x^2/(2 sigma2)), using the exact sampling algorithm of
+// Canonne, Kamath and Steinke.
+func SampleDiscreteGaussian(sigma2 *big.Rat) (*big.Int, error) {
+	if sigma2.Sign() <= 0 {
+		return nil, ErrorInvalidNoiseParameter
+	}
+	// t = floor(sigma) + 1
+	t := big.NewInt(0).Quo(sigma2.Num(), sigma2.Denom())
+	t.Sqrt(t).Add(t, big.NewInt(1))
+	for {
+		y, err := sampleDiscreteLaplace(t, big.NewInt(1))
+		if err != nil {
+			return nil, err
+		}
+		// gamma = (|y| - sigma2/t)^2 / (2 sigma2)
+		gamma := big.NewRat(0, 1).SetInt(big.NewInt(0).Abs(y))
+		gamma.Sub(gamma, big.NewRat(0, 1).Quo(sigma2, big.NewRat(0, 1).SetInt(t)))
+		gamma.Mul(gamma, gamma)
+		gamma.Quo(gamma, big.NewRat(0, 1).Mul(sigma2, big.NewRat(2, 1)))
+		c, err := bernoulliExp(gamma)
+		if err != nil {
+			return nil, err
+		}
+		if c {
+			return y, nil
+		}
+	}
+}
+
+// ShareDiscreteLaplace samples discrete Laplace noise with the given scale and shares it over a
+// finite field, see ShareFiniteField. The shares can be added to shares of an aggregate with
+// ShareAdd before opening it, to obtain a differentially private result.
+//
+// The party that calls this function learns the noise. To protect against any single party, every
+// party can deal noise in this way and all noise shares can be added, at the cost of more noise.
+func ShareDiscreteLaplace(scale *big.Rat, fieldSize *big.Int, degree int, nShares int) ([]Share, error) {
+	noise, err := SampleDiscreteLaplace(scale)
+	if err != nil {
+		return nil, err
+	}
+	return ShareFiniteField(noise, fieldSize, degree, nShares), nil
+}
+
+// ShareDiscreteGaussian samples discrete Gaussian noise with parameter sigma2 and shares it over a
+// finite field, see ShareDiscreteLaplace. Since the sum of discrete Gaussians is close to a discrete
+// Gaussian, k parties can each deal noise with parameter sigma2/k.
+func ShareDiscreteGaussian(sigma2 *big.Rat, fieldSize *big.Int, degree int, nShares int) ([]Share, error) {
+	noise, err := SampleDiscreteGaussian(sigma2)
+	if err != nil {
+		return nil, err
+	}
+	return ShareFiniteField(noise, fieldSize, degree, nShares), nil
+}
+
+// sampleDiscreteLaplace samples from the discrete Laplace distribution with scale t/s.
+func sampleDiscreteLaplace(t, s *big.Int) (*big.Int, error) {
+	for {
+		u, err := rand.Int(rand.Reader, t)
+		if err != nil {
+			return nil, err
+		}
+		d, err := bernoulliExp(big.NewRat(0, 1).SetFrac(u, t))
+		if err != nil {
+			return nil, err
+		}
+		if !d {
+			continue
+		}
+
+		v := big.NewInt(0)
+		for {
+			a, err := bernoulliExp(big.NewRat(1, 1))
+			if err != nil {
+				return nil, err
+			}
+			if !a {
+				break
+			}
+			v.Add(v, big.NewInt(1))
+		}
+
+		// y = floor((u + t*v) / s)
+		y := big.NewInt(0).Mul(t, v)
+		y.Add(y, u).Quo(y, s)
+		b, err := bernoulli(big.NewRat(1, 2))
+		if err != nil {
+			return nil, err
+		}
+		if b && y.Sign() == 0 {
+			continue
+		}
+		if b {
+			y.Neg(y)
+		}
+		return y, nil
+	}
+}
+
+// bernoulli returns true with probability p, which must lie in [0, 1].
+func bernoulli(p *big.Rat) (bool, error) {
+	r, err := rand.Int(rand.Reader, p.Denom())
+	if err != nil {
+		return false, err
+	}
+	return r.Cmp(p.Num()) < 0, nil
+}
+
+// bernoulliExp returns true with probability exp(-gamma), for gamma >= 0.
+func bernoulliExp(gamma *big.Rat) (bool, error) {
+	if gamma.Cmp(big.NewRat(1, 1)) <= 0 {
+		k := int64(1)
+		for {
+			a, err := bernoulli(big.NewRat(0, 1).Quo(gamma, big.NewRat(k, 1)))
+			if err != nil {
+				return false, err
+			}
+			if !a {
+				break
+			}
+			k++
+		}
+		return k%2 == 1, nil
+	}
+
+	whole := big.NewInt(0).Quo(gamma.Num(), gamma.Denom())
+	for i := big.NewInt(0); i.Cmp(whole) < 0; i.Add(i, big.NewInt(1)) {
+		b, err := bernoulliExp(big.NewRat(1, 1))
+		if err != nil {
+			return false, err
+		}
+		if !b {
+			return false, nil
+		}
+	}
+	return bernoulliExp(big.NewRat(0, 1).Sub(gamma, big.NewRat(0, 1).SetInt(whole)))
+}
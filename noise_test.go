@@ -0,0 +1,84 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sampleMoments returns the mean and variance of n samples.
+func sampleMoments(t *testing.T, n int, sample func() (*big.Int, error)) (float64, float64) {
+	var sum, sumSquares float64
+	for i := 0; i < n; i++ {
+		x, err := sample()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		f := float64(x.Int64())
+		sum += f
+		sumSquares += f * f
+	}
+	mean := sum / float64(n)
+	return mean, sumSquares/float64(n) - mean*mean
+}
+
+func TestDiscreteLaplace(t *testing.T) {
+	assert := assert.New(t)
+	mean, variance := sampleMoments(t, 2000, func() (*big.Int, error) {
+		return SampleDiscreteLaplace(big.NewRat(2, 1))
+	})
+	// The variance of the discrete Laplace distribution with scale 2 is about 7.83
+	assert.InDelta(0, mean, 0.5)
+	assert.InDelta(7.83, variance, 2)
+
+	_, err := SampleDiscreteLaplace(big.NewRat(0, 1))
+	assert.Equal(ErrorInvalidNoiseParameter, err)
+}
+
+func TestDiscreteGaussian(t *testing.T) {
+	assert := assert.New(t)
+	mean, variance := sampleMoments(t, 2000, func() (*big.Int, error) {
+		return SampleDiscreteGaussian(big.NewRat(9, 2))
+	})
+	assert.InDelta(0, mean, 0.5)
+	assert.InDelta(4.5, variance, 1)
+
+	_, err := SampleDiscreteGaussian(big.NewRat(-1, 1))
+	assert.Equal(ErrorInvalidNoiseParameter, err)
+}
+
+func TestNoiseSharing(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	shares := ShareFiniteField(big.NewInt(1000), fieldSize, 2, 5)
+	noise, err := ShareDiscreteGaussian(big.NewRat(1, 1), fieldSize, 2, 5)
+	assert.NoError(err)
+	for i := range shares {
+		shares[i], err = ShareAdd([]Share{shares[i], noise[i]})
+		assert.NoError(err)
+	}
+
+	noisy, err := ShareCombine(shares)
+	assert.NoError(err)
+	if assert.NotNil(noisy) {
+		assert.InDelta(1000, noisy.Int64(), 20)
+	}
+
+	_, err = ShareDiscreteLaplace(big.NewRat(-1, 1), fieldSize, 2, 5)
+	assert.Equal(ErrorInvalidNoiseParameter, err)
+}
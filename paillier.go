@@ -0,0 +1,279 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The threshold Paillier decryption in this file follows Damgard and Jurik, "A generalisation, a
+// simplification and some applications of Paillier's probabilistic public-key system" (PKC 2001),
+// with s = 1 and a trusted dealer. The modulus N = p * q is a product of safe primes p = 2p' + 1 and
+// q = 2q' + 1, and ciphertexts are ordinary Paillier ciphertexts (1 + N)^M * r^N mod N^2, so they
+// can be produced by any Paillier implementation with generator N + 1. The decryption exponent d,
+// with d = 0 mod p'q' and d = 1 mod N, is shared over the integers, so that the shares are
+// y_i = f(i) with f(0) = D * d for D = nShares!. A member decrypts a ciphertext c partially to
+// c^(2 * y_i) mod N^2, with a proof that it used the same y_i as in its verification key V^y_i. The
+// partial decryptions of degree+1 members combine to c^(4 * D^2 * d) = 1 + 4 * D^2 * M * N, which
+// reveals M.
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	ErrorInvalidPaillierKey = errors.New("Paillier key is not a product of two distinct safe primes")
+	ErrorInvalidCiphertext  = errors.New("Ciphertext is not invertible modulo N^2")
+	ErrorInvalidPlaintext   = errors.New("Plaintext is not in the range [0, N)")
+	ErrorInvalidPartial     = errors.New("Partial decryption does not verify")
+)
+
+// paillierChallengeBits is the size in bits of the challenges of the proofs of partial decryption,
+// and paillierHidingBits the statistical security with which their responses hide the shares.
+const (
+	paillierChallengeBits = 256
+	paillierHidingBits    = 128
+)
+
+// A PaillierCommittee holds the public values of a Paillier key shared with SharePaillierKey: the
+// modulus, the parameters of the sharing and the verification keys with which anyone can check
+// partial decryptions. It holds no secrets.
+type PaillierCommittee struct {
+	N      *big.Int
+	Degree int
+	Factor *big.Int
+	// ShareBits bounds the size in bits of the shares, to size the randomness of proofs
+	ShareBits int
+	// V is a random square modulo N^2, and VerificationKeys[i] is V^y_i for the member with X
+	// coordinate i+1
+	V                *big.Int
+	VerificationKeys []*big.Int
+}
+
+// A PaillierPartial is the partial decryption of a ciphertext by the member with X coordinate X,
+// with a Fiat-Shamir proof that it is correct.
+type PaillierPartial struct {
+	X         int
+	Value     *big.Int
+	Challenge *big.Int
+	Response  *big.Int
+}
+
+// SharePaillierKey shares the decryption key of the Paillier key with the safe primes p and q among
+// nShares members, such that any degree+1 of them can decrypt with DecryptShare and Combine. It
+// returns the public committee and the shares, with statSecParam bits of statistical security for
+// the sharing. The primes and the shares are all the dealer knows of the key; the primes should be
+// destroyed afterwards.
+func SharePaillierKey(p *big.Int, q *big.Int, statSecParam int, degree int, nShares int) (*PaillierCommittee, []Share, error) {
+	pPrime, qPrime := safePrimeHalf(p), safePrimeHalf(q)
+	if pPrime == nil || qPrime == nil || p.Cmp(q) == 0 {
+		return nil, nil, ErrorInvalidPaillierKey
+	}
+	n := big.NewInt(0).Mul(p, q)
+	factor := factorial(int64(nShares))
+	if big.NewInt(0).GCD(nil, nil, factor, n).Cmp(big.NewInt(1)) != 0 {
+		return nil, nil, ErrorInvalidPaillierKey
+	}
+	m := big.NewInt(0).Mul(pPrime, qPrime)
+	d := big.NewInt(0).ModInverse(m, n)
+	d.Mul(d, m)
+	nSquared := big.NewInt(0).Mul(n, n)
+	shares := ShareIntegers(d, nSquared, statSecParam, degree, nShares)
+
+	v, err := randomUnit(rand.Reader, nSquared)
+	if err != nil {
+		return nil, nil, err
+	}
+	v.Mul(v, v).Mod(v, nSquared)
+	committee := &PaillierCommittee{N: n, Degree: degree, Factor: factor, V: v, VerificationKeys: make([]*big.Int, nShares)}
+	for i, share := range shares {
+		committee.VerificationKeys[i] = big.NewInt(0).Exp(v, share.Y, nSquared)
+		if share.Y.BitLen() > committee.ShareBits {
+			committee.ShareBits = share.Y.BitLen()
+		}
+	}
+	return committee, shares, nil
+}
+
+// Encrypt encrypts a message in the range [0, N) to the committee, reading randomness from random.
+func (c *PaillierCommittee) Encrypt(random io.Reader, message *big.Int) (*big.Int, error) {
+	if message.Sign() < 0 || message.Cmp(c.N) >= 0 {
+		return nil, ErrorInvalidPlaintext
+	}
+	nSquared := c.nSquared()
+	r, err := randomUnit(random, c.N)
+	if err != nil {
+		return nil, err
+	}
+	// (1 + N)^M = 1 + M * N mod N^2
+	ciphertext := big.NewInt(0).Mul(message, c.N)
+	ciphertext.Add(ciphertext, big.NewInt(1))
+	return ciphertext.Mul(ciphertext, r.Exp(r, c.N, nSquared)).Mod(ciphertext, nSquared), nil
+}
+
+// DecryptShare returns the partial decryption of ciphertext by the member holding share, dealt by
+// SharePaillierKey, with a proof that it is correct.
+func (c *PaillierCommittee) DecryptShare(share Share, ciphertext *big.Int) (PaillierPartial, error) {
+	if share.Y == nil || share.FieldSize != nil || share.Factor == nil || share.Factor.Cmp(c.Factor) != 0 ||
+		share.X < 1 || share.X > len(c.VerificationKeys) {
+		return PaillierPartial{}, ErrorWrongShareType
+	}
+	base, err := c.proofBase(ciphertext)
+	if err != nil {
+		return PaillierPartial{}, err
+	}
+	nSquared := c.nSquared()
+	partial := PaillierPartial{X: share.X, Value: big.NewInt(0).Exp(ciphertext, big.NewInt(0).Lsh(share.Y, 1), nSquared)}
+
+	// Prove that log_(c^4) (c_i^2) = log_V V_i with a Schnorr-like proof over the integers, since
+	// the order of the group is secret
+	r, err := rand.Int(rand.Reader, big.NewInt(0).Lsh(big.NewInt(1), uint(c.ShareBits+paillierChallengeBits+paillierHidingBits)))
+	if err != nil {
+		return PaillierPartial{}, err
+	}
+	a := big.NewInt(0).Exp(base, r, nSquared)
+	b := big.NewInt(0).Exp(c.V, r, nSquared)
+	partial.Challenge = c.challenge(base, partial, a, b)
+	partial.Response = big.NewInt(0).Mul(partial.Challenge, share.Y)
+	partial.Response.Add(partial.Response, r)
+	return partial, nil
+}
+
+// VerifyPartial checks the proof that partial is the correct partial decryption of ciphertext by the
+// member with its X coordinate.
+func (c *PaillierCommittee) VerifyPartial(ciphertext *big.Int, partial PaillierPartial) bool {
+	if partial.X < 1 || partial.X > len(c.VerificationKeys) || partial.Value == nil || partial.Challenge == nil ||
+		partial.Response == nil {
+		return false
+	}
+	base, err := c.proofBase(ciphertext)
+	if err != nil {
+		return false
+	}
+	nSquared := c.nSquared()
+	// a = (c^4)^z * (c_i^2)^-e and b = V^z * V_i^-e
+	minusE := big.NewInt(0).Neg(partial.Challenge)
+	a := big.NewInt(0).Exp(big.NewInt(0).Mul(partial.Value, partial.Value), minusE, nSquared)
+	b := big.NewInt(0).Exp(c.VerificationKeys[partial.X-1], minusE, nSquared)
+	if a == nil || b == nil {
+		return false
+	}
+	a.Mul(a, big.NewInt(0).Exp(base, partial.Response, nSquared)).Mod(a, nSquared)
+	b.Mul(b, big.NewInt(0).Exp(c.V, partial.Response, nSquared)).Mod(b, nSquared)
+	return partial.Challenge.Cmp(c.challenge(base, partial, a, b)) == 0
+}
+
+// Combine decrypts ciphertext from the partial decryptions of its members. Partial decryptions that
+// do not verify are ignored; it returns ErrorTooFewShares if fewer than degree+1 members with
+// different X coordinates gave correct ones.
+func (c *PaillierCommittee) Combine(ciphertext *big.Int, partials []PaillierPartial) (*big.Int, error) {
+	var values []*big.Int
+	var xs []int
+	seen := make(map[int]bool)
+	for _, partial := range partials {
+		if len(xs) > c.Degree {
+			break
+		}
+		if !seen[partial.X] && c.VerifyPartial(ciphertext, partial) {
+			seen[partial.X] = true
+			values = append(values, partial.Value)
+			xs = append(xs, partial.X)
+		}
+	}
+	if len(xs) <= c.Degree {
+		return nil, ErrorTooFewShares
+	}
+
+	nSquared := c.nSquared()
+	combined := big.NewInt(1)
+	for i, value := range values {
+		// The integer 2 * D * lambda_i, where lambda_i is the Lagrange coefficient of xs[i] at 0
+		numerator := big.NewInt(0).Lsh(c.Factor, 1)
+		denominator := big.NewInt(1)
+		for j := range xs {
+			if i != j {
+				numerator.Mul(numerator, big.NewInt(int64(xs[j])))
+				denominator.Mul(denominator, big.NewInt(int64(xs[j]-xs[i])))
+			}
+		}
+		term := big.NewInt(0).Exp(value, numerator.Quo(numerator, denominator), nSquared)
+		if term == nil {
+			return nil, ErrorInvalidPartial
+		}
+		combined.Mul(combined, term).Mod(combined, nSquared)
+	}
+
+	// combined = 1 + 4 * D^2 * M * N, so M = L(combined) / (4 * D^2) mod N
+	message := combined.Sub(combined, big.NewInt(1)).Quo(combined, c.N)
+	scale := big.NewInt(0).Mul(c.Factor, c.Factor)
+	scale.Lsh(scale, 2).ModInverse(scale, c.N)
+	return message.Mul(message, scale).Mod(message, c.N), nil
+}
+
+// nSquared returns N^2.
+func (c *PaillierCommittee) nSquared() *big.Int {
+	return big.NewInt(0).Mul(c.N, c.N)
+}
+
+// proofBase checks that ciphertext is a unit modulo N^2, and returns c^4, the base of the proofs of
+// partial decryption.
+func (c *PaillierCommittee) proofBase(ciphertext *big.Int) (*big.Int, error) {
+	nSquared := c.nSquared()
+	if ciphertext == nil || ciphertext.Sign() <= 0 || ciphertext.Cmp(nSquared) >= 0 ||
+		big.NewInt(0).GCD(nil, nil, ciphertext, c.N).Cmp(big.NewInt(1)) != 0 {
+		return nil, ErrorInvalidCiphertext
+	}
+	return big.NewInt(0).Exp(ciphertext, big.NewInt(4), nSquared), nil
+}
+
+// challenge derives the Fiat-Shamir challenge of the proof of a partial decryption from the public
+// values and the commitments a and b.
+func (c *PaillierCommittee) challenge(base *big.Int, partial PaillierPartial, a *big.Int, b *big.Int) *big.Int {
+	h := sha256.New()
+	writeInt(h, c.N)
+	writeInt(h, c.V)
+	writeInt(h, c.VerificationKeys[partial.X-1])
+	writeUint64(h, uint64(partial.X))
+	writeInt(h, base)
+	writeInt(h, partial.Value)
+	writeInt(h, a)
+	writeInt(h, b)
+	return big.NewInt(0).SetBytes(h.Sum(nil))
+}
+
+// safePrimeHalf returns (p-1)/2 if p is a safe prime, and nil otherwise.
+func safePrimeHalf(p *big.Int) *big.Int {
+	if p == nil || !p.ProbablyPrime(20) {
+		return nil
+	}
+	half := big.NewInt(0).Rsh(p, 1)
+	if !half.ProbablyPrime(20) {
+		return nil
+	}
+	return half
+}
+
+// randomUnit returns a random unit modulo n, reading randomness from random.
+func randomUnit(random io.Reader, n *big.Int) (*big.Int, error) {
+	for {
+		r, err := rand.Int(random, n)
+		if err != nil {
+			return nil, err
+		}
+		if r.Sign() > 0 && big.NewInt(0).GCD(nil, nil, r, n).Cmp(big.NewInt(1)) == 0 {
+			return r, nil
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Safe primes of 256 bits, far too small for real keys
+var (
+	testSafePrimeP, _ = big.NewInt(0).SetString("106319839868699981195910254188762576092176524579546038640079702340804533516459", 10)
+	testSafePrimeQ, _ = big.NewInt(0).SetString("96718325112475993988951316115734988705842876939251455242353028823106083416743", 10)
+)
+
+func TestPaillierThresholdDecryption(t *testing.T) {
+	assert := assert.New(t)
+	committee, shares, err := SharePaillierKey(testSafePrimeP, testSafePrimeQ, 40, 2, 5)
+	assert.NoError(err)
+	assert.Len(shares, 5)
+
+	message := big.NewInt(123456789)
+	ciphertext, err := committee.Encrypt(rand.Reader, message)
+	assert.NoError(err)
+	partials := make([]PaillierPartial, len(shares))
+	for i, share := range shares {
+		partials[i], err = committee.DecryptShare(share, ciphertext)
+		assert.NoError(err)
+		assert.True(committee.VerifyPartial(ciphertext, partials[i]))
+	}
+	decrypted, err := committee.Combine(ciphertext, partials[2:])
+	assert.NoError(err)
+	assert.Equal(message, decrypted)
+
+	// Ciphertexts are additively homomorphic
+	other, _ := committee.Encrypt(rand.Reader, big.NewInt(1000))
+	nSquared := big.NewInt(0).Mul(committee.N, committee.N)
+	sum := big.NewInt(0).Mul(ciphertext, other)
+	sum.Mod(sum, nSquared)
+	for i, share := range shares {
+		partials[i], _ = committee.DecryptShare(share, sum)
+	}
+	decrypted, err = committee.Combine(sum, []PaillierPartial{partials[4], partials[0], partials[3]})
+	assert.NoError(err)
+	assert.Equal(big.NewInt(123457789), decrypted)
+}
+
+func TestPaillierWrongPartials(t *testing.T) {
+	assert := assert.New(t)
+	committee, shares, err := SharePaillierKey(testSafePrimeP, testSafePrimeQ, 40, 1, 4)
+	assert.NoError(err)
+	ciphertext, _ := committee.Encrypt(rand.Reader, big.NewInt(42))
+	partials := make([]PaillierPartial, len(shares))
+	for i, share := range shares {
+		partials[i], _ = committee.DecryptShare(share, ciphertext)
+	}
+
+	// A wrong partial decryption, or one for another ciphertext or member, does not verify and is
+	// skipped by Combine
+	wrong := partials[0]
+	wrong.Value = big.NewInt(0).Mul(wrong.Value, big.NewInt(2))
+	assert.False(committee.VerifyPartial(ciphertext, wrong))
+	moved := partials[1]
+	moved.X = 3
+	assert.False(committee.VerifyPartial(ciphertext, moved))
+	other, _ := committee.Encrypt(rand.Reader, big.NewInt(42))
+	assert.False(committee.VerifyPartial(other, partials[1]))
+
+	decrypted, err := committee.Combine(ciphertext, []PaillierPartial{wrong, moved, partials[1], partials[3]})
+	assert.NoError(err)
+	assert.Equal(big.NewInt(42), decrypted)
+	_, err = committee.Combine(ciphertext, []PaillierPartial{wrong, partials[1], partials[1]})
+	assert.Equal(ErrorTooFewShares, err)
+
+	_, err = committee.DecryptShare(shares[0], committee.N)
+	assert.Equal(ErrorInvalidCiphertext, err)
+	_, err = committee.DecryptShare(ShareIntegers(big.NewInt(1), big.NewInt(2), 40, 1, 3)[0], ciphertext)
+	assert.Equal(ErrorWrongShareType, err)
+	_, err = committee.Encrypt(rand.Reader, committee.N)
+	assert.Equal(ErrorInvalidPlaintext, err)
+}
+
+func TestSharePaillierKeyInvalid(t *testing.T) {
+	assert := assert.New(t)
+	_, _, err := SharePaillierKey(testSafePrimeP, testSafePrimeP, 40, 1, 3)
+	assert.Equal(ErrorInvalidPaillierKey, err)
+	// 13 is prime, but 6 is not
+	_, _, err = SharePaillierKey(testSafePrimeP, big.NewInt(13), 40, 1, 3)
+	assert.Equal(ErrorInvalidPaillierKey, err)
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// parallelism is the configured number of workers, or 0 for runtime.GOMAXPROCS(0).
+var parallelism int32
+
+// SetParallelism sets the number of goroutines that the batch operations on vectors and matrices,
+// such as ShareVectorFiniteField, CombineVector and ShareMatrix.Mul, use to process their elements.
+// Server operators can set it to 1 to cap the CPU usage of every call. A value below 1 restores the
+// default, which is runtime.GOMAXPROCS(0) and saturates all cores.
+func SetParallelism(n int) {
+	if n < 1 {
+		n = 0
+	}
+	atomic.StoreInt32(&parallelism, int32(n))
+}
+
+// Parallelism returns the number of goroutines used by batch operations, see SetParallelism.
+func Parallelism() int {
+	if n := atomic.LoadInt32(&parallelism); n > 0 {
+		return int(n)
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// parallelFor calls f for 0, ..., n-1 using a pool of Parallelism() workers. If some calls fail, it
+// returns the error of the call with the lowest index, so the result does not depend on scheduling.
+func parallelFor(n int, f func(j int) error) error {
+	workers := Parallelism()
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for j := 0; j < n; j++ {
+			if err := f(j); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make([]error, n)
+	next := int64(-1)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for j := int(atomic.AddInt64(&next, 1)); j < n; j = int(atomic.AddInt64(&next, 1)) {
+				errs[j] = f(j)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelism(t *testing.T) {
+	assert := assert.New(t)
+	defer SetParallelism(0)
+
+	assert.Equal(runtime.GOMAXPROCS(0), Parallelism())
+	SetParallelism(3)
+	assert.Equal(3, Parallelism())
+	SetParallelism(-1)
+	assert.Equal(runtime.GOMAXPROCS(0), Parallelism())
+
+	for _, n := range []int{1, 4} {
+		SetParallelism(n)
+		secrets := bigInts(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+		vectors := ShareVectorFiniteField(secrets, big.NewInt(7919), 2, 5)
+		combined, err := CombineVector(vectors[1:4])
+		assert.NoError(err)
+		assert.Equal(secrets, combined)
+	}
+}
+
+func TestParallelFor(t *testing.T) {
+	assert := assert.New(t)
+	defer SetParallelism(0)
+	SetParallelism(4)
+
+	var calls int64
+	assert.NoError(parallelFor(100, func(j int) error {
+		atomic.AddInt64(&calls, 1)
+		return nil
+	}))
+	assert.Equal(int64(100), calls)
+
+	// The error with the lowest index is returned
+	first, second := errors.New("first"), errors.New("second")
+	err := parallelFor(100, func(j int) error {
+		switch j {
+		case 30:
+			return first
+		case 70:
+			return second
+		}
+		return nil
+	})
+	assert.Equal(first, err)
+	assert.NoError(parallelFor(0, func(j int) error { return first }))
+}
@@ -0,0 +1,292 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorTooFewParties = errors.New("Too few parties for this operation")
+	ErrorNetwork       = errors.New("Unexpected message received from the network")
+)
+
+// A Network connects a party to all parties of a computation, including itself.
+type Network interface {
+	// Exchange performs a single round of communication. It sends outgoing[i] to the party with X
+	// coordinate i+1, and returns the messages received from all parties in the same order.
+	Exchange(outgoing [][]NestedShare) ([][]NestedShare, error)
+}
+
+// A Broadcaster connects a party to all parties of a computation for reliable broadcast: every
+// party delivers the same message from every sender, or none at all, even if the sender is
+// malicious. Protocols such as RandomShare depend on this, because over plain point-to-point links
+// a malicious dealer can send different commitments to different parties.
+type Broadcaster interface {
+	// Broadcast performs a single round of broadcast. It sends message to all parties, and returns
+	// the messages delivered from all parties in the order of their X coordinates, with nil for
+	// parties from which no message was delivered.
+	Broadcast(message []byte) ([][]byte, error)
+}
+
+// A Party performs interactive computations on shares over a finite field together with the other
+// parties connected by its Network. All parties must call the same methods with shares of the same
+// secrets in the same order. The protocols are secure against up to degree semi-honest parties.
+type Party struct {
+	x         int
+	nParties  int
+	fieldSize *big.Int
+	degree    int
+	network   Network
+	session   string
+}
+
+// NewParty returns the Party with X coordinate x among nParties parties that share secrets over the
+// finite field of integers modulo fieldSize with the given degree.
+func NewParty(x int, nParties int, fieldSize *big.Int, degree int, network Network) *Party {
+	return &Party{
+		x:         x,
+		nParties:  nParties,
+		fieldSize: fieldSize,
+		degree:    degree,
+		network:   network,
+	}
+}
+
+// SetSession sets the session of the party, such as the identifier of the computation, which is
+// included in the events of opening shares. If it is not empty, the errors of the network and of
+// opening shares are annotated with the session and the scheme of the party, see WithScheme, so
+// that failures of concurrent computations can be told apart.
+func (p *Party) SetSession(session string) {
+	p.session = session
+}
+
+// X returns the X coordinate of the party.
+func (p *Party) X() int {
+	return p.x
+}
+
+// Input shares the secrets of the party with the given X coordinate among all parties. Only that
+// party has to provide secrets, the others may pass nil, but all parties must pass the same count.
+func (p *Party) Input(owner int, secrets []*big.Int, count int) (ShareVector, error) {
+	outgoing := make([][]NestedShare, p.nParties)
+	if owner == p.x {
+		if len(secrets) != count {
+			return nil, ErrorVectorLength
+		}
+		for i, v := range ShareVectorFiniteField(secrets, p.fieldSize, p.degree, p.nParties) {
+			outgoing[i] = nest(v)
+		}
+	}
+	received, err := p.exchange(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	if owner < 1 || owner > len(received) || len(received[owner-1]) != count {
+		return nil, ErrorNetwork
+	}
+	return unnest(received[owner-1]), nil
+}
+
+// Open reveals the secrets shared by v to all parties.
+func (p *Party) Open(v ShareVector) ([]*big.Int, error) {
+	outgoing := make([][]NestedShare, p.nParties)
+	for i := range outgoing {
+		outgoing[i] = nest(v)
+	}
+	received, err := p.exchange(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([]ShareVector, len(received))
+	for i := range received {
+		vectors[i] = unnest(received[i])
+	}
+	return combineVector(p.session, vectors)
+}
+
+// Mul multiplies the secrets shared by a and b element-wise. Unlike ShareVectorMul, the resulting
+// shares have the same degree as the factors, which requires at least 2*degree+1 parties.
+func (p *Party) Mul(a ShareVector, b ShareVector) (ShareVector, error) {
+	if p.nParties <= 2*p.degree {
+		return nil, ErrorTooFewParties
+	}
+	products, err := ShareVectorMul([]ShareVector{a, b})
+	if err != nil {
+		return nil, err
+	}
+	return p.Reshare(products)
+}
+
+// Reshare converts the shares of v into fresh shares of the party's degree, see ShareRecombine.
+func (p *Party) Reshare(v ShareVector) (ShareVector, error) {
+	outgoing, err := v.Split(p.degree, p.nParties)
+	if err != nil {
+		return nil, err
+	}
+	received, err := p.exchange(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	return RecombineVector(received)
+}
+
+// Random returns shares of count uniformly random secrets that no party knows. Every party
+// contributes a random sharing and the results are added.
+func (p *Party) Random(count int) (ShareVector, error) {
+	return p.randomBelow(count, p.fieldSize)
+}
+
+// RandomBits returns shares of count uniformly random bits that no party knows.
+func (p *Party) RandomBits(count int) (ShareVector, error) {
+	bits := make(ShareVector, 0, count)
+	two := big.NewInt(2)
+	inverseTwo := big.NewInt(0).ModInverse(two, p.fieldSize)
+	for len(bits) < count {
+		// For a random a, a/sqrt(a^2) is a random sign, which is mapped to a bit
+		a, err := p.Random(count - len(bits))
+		if err != nil {
+			return nil, err
+		}
+		squares, err := p.Mul(a, a)
+		if err != nil {
+			return nil, err
+		}
+		opened, err := p.Open(squares)
+		if err != nil {
+			return nil, err
+		}
+		for j := range opened {
+			if opened[j].Sign() == 0 {
+				continue
+			}
+			root := big.NewInt(0).ModSqrt(opened[j], p.fieldSize)
+			root.ModInverse(root, p.fieldSize)
+			bit := ShareAddConstant(ShareMulConstant(a[j], root), big.NewInt(1))
+			bits = append(bits, ShareMulConstant(bit, inverseTwo))
+		}
+	}
+	return bits, nil
+}
+
+// Refresh re-randomizes the shares of v, so that shares from before and after refreshing can no
+// longer be combined. Every party contributes a random sharing of zero, which is added to v. See
+// PRSSKeys.Refresh to refresh without communication.
+func (p *Party) Refresh(v ShareVector) (ShareVector, error) {
+	zeros := make([]*big.Int, len(v))
+	for i := range zeros {
+		zeros[i] = big.NewInt(0)
+	}
+	contributions, err := p.contribute(zeros)
+	if err != nil {
+		return nil, err
+	}
+	return ShareVectorAdd([]ShareVector{v, contributions})
+}
+
+// ChangeDegree converts the shares of v into shares of the same secrets with a new degree, without
+// a dealer and without any party learning the secrets, so that the parties can raise or lower the
+// threshold of existing shares. It returns the converted shares together with a Party that uses the
+// new degree on the same Network, which the parties should use from then on. The old shares must
+// be discarded afterwards, since they still reveal the secrets to the old number of parties.
+func (p *Party) ChangeDegree(v ShareVector, degree int) (*Party, ShareVector, error) {
+	if degree < 0 || p.nParties <= degree {
+		return nil, nil, ErrorTooFewParties
+	}
+	q := NewParty(p.x, p.nParties, p.fieldSize, degree, p.network)
+	q.session = p.session
+	w, err := q.Reshare(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return q, w, nil
+}
+
+// randomBelow returns shares of count secrets that are the sum of a random value in [0, bound) from
+// every party.
+func (p *Party) randomBelow(count int, bound *big.Int) (ShareVector, error) {
+	secrets := make([]*big.Int, count)
+	for i := range secrets {
+		var err error
+		secrets[i], err = rand.Int(rand.Reader, bound)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p.contribute(secrets)
+}
+
+// contribute shares secrets among all parties, and returns shares of the sum of the secrets
+// contributed by all parties.
+func (p *Party) contribute(secrets []*big.Int) (ShareVector, error) {
+	outgoing := make([][]NestedShare, p.nParties)
+	for i, v := range ShareVectorFiniteField(secrets, p.fieldSize, p.degree, p.nParties) {
+		outgoing[i] = nest(v)
+	}
+	received, err := p.exchange(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	contributions := make([]ShareVector, len(received))
+	for i := range received {
+		if len(received[i]) != len(secrets) {
+			return nil, ErrorNetwork
+		}
+		contributions[i] = unnest(received[i])
+	}
+	return ShareVectorAdd(contributions)
+}
+
+// constant returns shares of count copies of a public constant.
+func (p *Party) constant(c *big.Int, count int) ShareVector {
+	v := make(ShareVector, count)
+	for i := range v {
+		v[i] = Share{
+			FieldSize: p.fieldSize,
+			Degree:    p.degree,
+			X:         p.x,
+			Y:         big.NewInt(0).Mod(c, p.fieldSize),
+		}
+	}
+	return v
+}
+
+func nest(v ShareVector) []NestedShare {
+	nested := make([]NestedShare, len(v))
+	for i := range v {
+		nested[i] = NestedShare{Share: v[i]}
+	}
+	return nested
+}
+
+func unnest(nested []NestedShare) ShareVector {
+	v := make(ShareVector, len(nested))
+	for i := range nested {
+		v[i] = nested[i].Share
+	}
+	return v
+}
+
+// exchange sends outgoing over the network of the party and returns the received shares, annotating
+// errors with the scheme of the party if it has a session.
+func (p *Party) exchange(outgoing [][]NestedShare) ([][]NestedShare, error) {
+	received, err := p.network.Exchange(outgoing)
+	if err != nil && p.session != "" {
+		return nil, WithScheme(err, p.session, Share{FieldSize: p.fieldSize, Degree: p.degree})
+	}
+	return received, err
+}
@@ -0,0 +1,207 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mersenne61 is the prime 2^61-1.
+var mersenne61 = big.NewInt(0).Sub(big.NewInt(0).Lsh(big.NewInt(1), 61), big.NewInt(1))
+
+// channelNetwork connects parties in the same process.
+type channelNetwork struct {
+	x        int
+	channels [][]chan []NestedShare
+}
+
+func (n *channelNetwork) Exchange(outgoing [][]NestedShare) ([][]NestedShare, error) {
+	for i := range outgoing {
+		n.channels[n.x-1][i] <- outgoing[i]
+	}
+	received := make([][]NestedShare, len(outgoing))
+	for i := range received {
+		received[i] = <-n.channels[i][n.x-1]
+	}
+	return received, nil
+}
+
+// runParties runs f for nParties parties concurrently and returns their results.
+func runParties(t *testing.T, nParties int, degree int, fieldSize *big.Int, f func(p *Party) ([]*big.Int, error)) [][]*big.Int {
+	channels := make([][]chan []NestedShare, nParties)
+	for i := range channels {
+		channels[i] = make([]chan []NestedShare, nParties)
+		for j := range channels[i] {
+			channels[i][j] = make(chan []NestedShare, 1)
+		}
+	}
+	results := make([][]*big.Int, nParties)
+	errs := make([]error, nParties)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := NewParty(i+1, nParties, fieldSize, degree, &channelNetwork{x: i + 1, channels: channels})
+			results[i], errs[i] = f(p)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	return results
+}
+
+func TestPartyMul(t *testing.T) {
+	assert := assert.New(t)
+	results := runParties(t, 5, 2, big.NewInt(7919), func(p *Party) ([]*big.Int, error) {
+		a, err := p.Input(1, bigInts(2, 3, 4), 3)
+		if err != nil {
+			return nil, err
+		}
+		b, err := p.Input(2, bigInts(5, 6, 7), 3)
+		if err != nil {
+			return nil, err
+		}
+		c, err := p.Mul(a, b)
+		if err != nil {
+			return nil, err
+		}
+		// Multiply again to check that the degree was reduced
+		c, err = p.Mul(c, a)
+		if err != nil {
+			return nil, err
+		}
+		return p.Open(c)
+	})
+	for _, result := range results {
+		assert.Equal(bigInts(20, 54, 112), result)
+	}
+}
+
+func TestShareMulCheckedReduces(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	a := ShareFiniteField(big.NewInt(12), fieldSize, 1, 3)
+	b := ShareFiniteField(big.NewInt(34), fieldSize, 1, 3)
+	c := ShareFiniteField(big.NewInt(56), fieldSize, 1, 3)
+
+	// Three shares of degree 1 only support a single multiplication, so the parties reduce the
+	// degree of a*b before multiplying by c
+	results := runParties(t, 3, 1, fieldSize, func(p *Party) ([]*big.Int, error) {
+		i := p.X() - 1
+		product, err := ShareMulChecked([]Share{a[i], b[i], c[i]}, 3, p)
+		if err != nil {
+			return nil, err
+		}
+		assert.Equal(2, product.Degree)
+		return []*big.Int{product.Y}, nil
+	})
+	products := make([]Share, len(results))
+	for i := range results {
+		products[i] = Share{FieldSize: fieldSize, Degree: 2, X: i + 1, Y: results[i][0]}
+	}
+	secret, err := ShareCombine(products)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(12*34*56%7919), secret)
+}
+
+func TestPartyRandom(t *testing.T) {
+	assert := assert.New(t)
+	results := runParties(t, 3, 1, big.NewInt(7919), func(p *Party) ([]*big.Int, error) {
+		bits, err := p.RandomBits(20)
+		if err != nil {
+			return nil, err
+		}
+		return p.Open(bits)
+	})
+	ones := 0
+	for _, bit := range results[0] {
+		assert.True(bit.Cmp(big.NewInt(0)) == 0 || bit.Cmp(big.NewInt(1)) == 0)
+		ones += int(bit.Int64())
+	}
+	assert.True(ones > 0 && ones < 20)
+	assert.Equal(results[0], results[1])
+}
+
+func TestPartyRefresh(t *testing.T) {
+	assert := assert.New(t)
+	shares := make([]ShareVector, 3)
+	results := runParties(t, 3, 1, big.NewInt(7919), func(p *Party) ([]*big.Int, error) {
+		v, err := p.Input(1, bigInts(42), 1)
+		if err != nil {
+			return nil, err
+		}
+		shares[p.X()-1], err = p.Refresh(v)
+		if err != nil {
+			return nil, err
+		}
+		return p.Open(shares[p.X()-1])
+	})
+	assert.Equal(bigInts(42), results[0])
+	assert.Equal(1, shares[0][0].Degree)
+}
+
+func TestPartyTooFewParties(t *testing.T) {
+	assert := assert.New(t)
+	p := NewParty(1, 4, big.NewInt(7919), 2, nil)
+	shares := ShareVectorFiniteField(bigInts(1), big.NewInt(7919), 2, 4)
+	_, err := p.Mul(shares[0], shares[0])
+	assert.Equal(ErrorTooFewParties, err)
+}
+
+func TestPartyChangeDegree(t *testing.T) {
+	assert := assert.New(t)
+	shares := make([]ShareVector, 5)
+	results := runParties(t, 5, 1, big.NewInt(7919), func(p *Party) ([]*big.Int, error) {
+		v, err := p.Input(1, bigInts(42, 43), 2)
+		if err != nil {
+			return nil, err
+		}
+		q, w, err := p.ChangeDegree(v, 3)
+		if err != nil {
+			return nil, err
+		}
+		// Lower the degree again, starting from the raised shares
+		r, u, err := q.ChangeDegree(w, 2)
+		if err != nil {
+			return nil, err
+		}
+		shares[p.X()-1] = w
+		return r.Open(u)
+	})
+	for _, result := range results {
+		assert.Equal(bigInts(42, 43), result)
+	}
+	assert.Equal(3, shares[0][0].Degree)
+
+	// Four shares of degree 3 recover the secrets, three do not
+	secret, err := ShareCombine([]Share{shares[0][0], shares[2][0], shares[3][0], shares[4][0]})
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+	_, err = ShareCombine([]Share{shares[0][0], shares[2][0], shares[3][0]})
+	assert.Equal(ErrorTooFewShares, err)
+
+	p := NewParty(1, 4, big.NewInt(7919), 1, nil)
+	_, _, err = p.ChangeDegree(shares[0], 4)
+	assert.Equal(ErrorTooFewParties, err)
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+)
+
+// A PayloadShare is the share of a single party of a byte payload that was split into chunks by
+// SharePayload. Every chunk is encoded as a vector of field elements, which are shared separately.
+type PayloadShare struct {
+	// Length is the length in bytes of the shared payload, which includes the 32 bytes added by
+	// the all-or-nothing transform if AONT is set.
+	Length    int
+	ChunkSize int
+	AONT      bool
+	Chunks    []ShareVector
+}
+
+// SharePayload splits a payload of arbitrary length into chunks of chunkSize bytes and shares every
+// chunk over a finite field, see ShareVectorFiniteField. It returns a PayloadShare for every party.
+// Every chunk is encoded into field elements of as many whole bytes as fit below the field size,
+// so the field size must exceed 256.
+//
+// If aont is set, the payload is first transformed with AONTTransform, so that all chunks are
+// needed to recover any part of the payload.
+func SharePayload(payload []byte, fieldSize *big.Int, degree int, nShares int, chunkSize int, aont bool) ([]PayloadShare, error) {
+	if elementSize(fieldSize) < 1 {
+		return nil, ErrorFieldTooSmall
+	}
+	if chunkSize < 1 {
+		return nil, ErrorInvalidPayload
+	}
+	if aont {
+		var err error
+		payload, err = AONTTransform(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	shares := make([]PayloadShare, nShares)
+	for i := range shares {
+		shares[i] = PayloadShare{Length: len(payload), ChunkSize: chunkSize, AONT: aont}
+	}
+	for offset := 0; offset < len(payload); offset += chunkSize {
+		end := offset + chunkSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+		vectors := ShareVectorFiniteField(encodeBytes(payload[offset:end], fieldSize), fieldSize, degree, nShares)
+		for i := range shares {
+			shares[i].Chunks = append(shares[i].Chunks, vectors[i])
+		}
+	}
+	return shares, nil
+}
+
+// CombinePayload combines the PayloadShares of several parties and recovers the payload.
+func CombinePayload(shares []PayloadShare) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrorNoShares
+	}
+	first := shares[0]
+	if first.ChunkSize < 1 || first.Length < 0 || len(first.Chunks) != (first.Length+first.ChunkSize-1)/first.ChunkSize {
+		return nil, ErrorInvalidPayload
+	}
+	for _, share := range shares[1:] {
+		if share.Length != first.Length || share.ChunkSize != first.ChunkSize || share.AONT != first.AONT || len(share.Chunks) != len(first.Chunks) {
+			return nil, ErrorIncompatibleShares
+		}
+	}
+	// Every chunk must hold field elements, whose field size determines how they are decoded
+	for _, share := range shares {
+		for _, chunk := range share.Chunks {
+			if len(chunk) == 0 || chunk[0].FieldSize == nil {
+				return nil, ErrorInvalidPayload
+			}
+		}
+	}
+
+	payload := make([]byte, 0, first.Length)
+	vectors := make([]ShareVector, len(shares))
+	for j := range first.Chunks {
+		for i := range shares {
+			vectors[i] = shares[i].Chunks[j]
+		}
+		elements, err := CombineVector(vectors)
+		if err != nil {
+			return nil, err
+		}
+		length := first.Length - len(payload)
+		if length > first.ChunkSize {
+			length = first.ChunkSize
+		}
+		chunk, err := decodeBytes(elements, vectors[0][0].FieldSize, length)
+		if err != nil {
+			return nil, err
+		}
+		payload = append(payload, chunk...)
+	}
+	if first.AONT {
+		return AONTInverse(payload)
+	}
+	return payload, nil
+}
+
+// ShareBytes shares a secret of arbitrary length, such as a key or a big integer that exceeds the
+// field size, over a finite field. Instead of wrapping around modulo fieldSize as ShareFiniteField
+// would, the secret is split into as many field elements as needed, preceded by an element holding
+// its length. It returns a ShareVector for every party. The field size must exceed 256.
+func ShareBytes(secret []byte, fieldSize *big.Int, degree int, nShares int) ([]ShareVector, error) {
+	if elementSize(fieldSize) < 1 {
+		return nil, ErrorFieldTooSmall
+	}
+	length := big.NewInt(int64(len(secret)))
+	if length.Cmp(fieldSize) >= 0 {
+		return nil, ErrorFieldTooSmall
+	}
+	elements := append([]*big.Int{length}, encodeBytes(secret, fieldSize)...)
+	return ShareVectorFiniteField(elements, fieldSize, degree, nShares), nil
+}
+
+// CombineBytes combines the ShareVectors produced by ShareBytes and recovers the secret.
+func CombineBytes(vectors []ShareVector) ([]byte, error) {
+	elements, err := CombineVector(vectors)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 || !elements[0].IsInt64() {
+		return nil, ErrorInvalidPayload
+	}
+	return decodeBytes(elements[1:], vectors[0][0].FieldSize, int(elements[0].Int64()))
+}
+
+// elementSize returns the number of whole bytes that are encoded in a single element of the finite
+// field of integers modulo fieldSize.
+func elementSize(fieldSize *big.Int) int {
+	return (fieldSize.BitLen() - 1) / 8
+}
+
+// fieldElementBytes returns y modulo fieldSize as a big-endian number of the byte length of
+// fieldSize, so that all elements of the field are encoded with the same length.
+func fieldElementBytes(y *big.Int, fieldSize *big.Int) []byte {
+	return big.NewInt(0).Mod(y, fieldSize).FillBytes(make([]byte, (fieldSize.BitLen()+7)/8))
+}
+
+// encodeBytes encodes data as big-endian field elements of elementSize(fieldSize) bytes each. The
+// last element may be shorter.
+func encodeBytes(data []byte, fieldSize *big.Int) []*big.Int {
+	size := elementSize(fieldSize)
+	elements := make([]*big.Int, 0, (len(data)+size-1)/size)
+	for offset := 0; offset < len(data); offset += size {
+		end := offset + size
+		if end > len(data) {
+			end = len(data)
+		}
+		elements = append(elements, big.NewInt(0).SetBytes(data[offset:end]))
+	}
+	return elements
+}
+
+// decodeBytes inverts encodeBytes for data of the given length.
+func decodeBytes(elements []*big.Int, fieldSize *big.Int, length int) ([]byte, error) {
+	size := elementSize(fieldSize)
+	if size < 1 || len(elements) != (length+size-1)/size {
+		return nil, ErrorInvalidPayload
+	}
+	data := make([]byte, length)
+	for i, element := range elements {
+		end := (i + 1) * size
+		if end > length {
+			end = length
+		}
+		if element.Sign() < 0 || (element.BitLen()+7)/8 > end-i*size {
+			return nil, ErrorInvalidPayload
+		}
+		element.FillBytes(data[i*size : end])
+	}
+	return data, nil
+}
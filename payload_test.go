@@ -0,0 +1,136 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharePayload(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	payload := []byte("The quick brown fox jumps over the lazy dog")
+
+	for _, aont := range []bool{false, true} {
+		for _, chunkSize := range []int{1, 5, 16, 1000} {
+			shares, err := SharePayload(payload, fieldSize, 2, 5, chunkSize, aont)
+			assert.NoError(err)
+			assert.Len(shares, 5)
+
+			recovered, err := CombinePayload(shares[2:])
+			assert.NoError(err)
+			assert.Equal(payload, recovered)
+		}
+	}
+
+	// A larger field packs several bytes into every element
+	shares, err := SharePayload(payload, mersenne61, 1, 3, 16, false)
+	assert.NoError(err)
+	assert.Len(shares[0].Chunks, 3)
+	assert.Len(shares[0].Chunks[0], 3)
+	recovered, err := CombinePayload(shares[:2])
+	assert.NoError(err)
+	assert.Equal(payload, recovered)
+
+	shares, err = SharePayload(nil, fieldSize, 1, 3, 16, false)
+	assert.NoError(err)
+	recovered, err = CombinePayload(shares)
+	assert.NoError(err)
+	assert.Empty(recovered)
+}
+
+func TestSharePayloadAONT(t *testing.T) {
+	assert := assert.New(t)
+	payload := bytes.Repeat([]byte("secret"), 20)
+	shares, err := SharePayload(payload, mersenne61, 1, 3, 16, true)
+	assert.NoError(err)
+	assert.Equal(len(payload)+32, shares[0].Length)
+
+	// Tampering with a single chunk garbles the whole payload
+	shares[1].Chunks[0][0].Y = big.NewInt(0).Add(shares[1].Chunks[0][0].Y, big.NewInt(1))
+	recovered, err := CombinePayload(shares[:2])
+	assert.NoError(err)
+	assert.NotEqual(payload[32:], recovered[32:])
+}
+
+func TestSharePayloadErrors(t *testing.T) {
+	assert := assert.New(t)
+	_, err := SharePayload([]byte("x"), big.NewInt(251), 1, 3, 16, false)
+	assert.Equal(ErrorFieldTooSmall, err)
+	_, err = SharePayload([]byte("x"), big.NewInt(7919), 1, 3, 0, false)
+	assert.Equal(ErrorInvalidPayload, err)
+
+	shares, err := SharePayload([]byte("some payload"), big.NewInt(7919), 1, 3, 4, false)
+	assert.NoError(err)
+	_, err = CombinePayload(nil)
+	assert.Equal(ErrorNoShares, err)
+	_, err = CombinePayload(shares[:1])
+	assert.Equal(ErrorTooFewShares, err)
+
+	shares[1].Length--
+	_, err = CombinePayload(shares)
+	assert.Equal(ErrorIncompatibleShares, err)
+	shares[1].Length++
+
+	// Elements must fit in the bytes they encode
+	shares[0].Chunks[0][0].Y = big.NewInt(7000)
+	shares[1].Chunks[0][0].Y = big.NewInt(7000)
+	_, err = CombinePayload(shares[:2])
+	assert.Equal(ErrorInvalidPayload, err)
+
+	// Chunks must not be empty
+	shares, err = SharePayload([]byte("some payload"), big.NewInt(7919), 1, 3, 4, false)
+	assert.NoError(err)
+	shares[0].Chunks[0] = ShareVector{}
+	shares[1].Chunks[0] = ShareVector{}
+	_, err = CombinePayload(shares[:2])
+	assert.Equal(ErrorInvalidPayload, err)
+}
+
+func TestShareBytes(t *testing.T) {
+	assert := assert.New(t)
+	large, _ := big.NewInt(0).SetString("123456789012345678901234567890123456789012345678901234567890", 10)
+	for _, secret := range [][]byte{{}, {0}, {0, 0, 1}, []byte("secret"), large.Bytes()} {
+		vectors, err := ShareBytes(secret, mersenne61, 2, 5)
+		assert.NoError(err)
+		assert.Len(vectors, 5)
+		recovered, err := CombineBytes(vectors[1:4])
+		assert.NoError(err)
+		assert.Equal(secret, recovered)
+	}
+
+	// A big integer larger than the field survives without wrapping around
+	vectors, err := ShareBytes(large.Bytes(), mersenne61, 1, 3)
+	assert.NoError(err)
+	recovered, err := CombineBytes(vectors[:2])
+	assert.NoError(err)
+	assert.Equal(large, big.NewInt(0).SetBytes(recovered))
+
+	_, err = ShareBytes([]byte("x"), big.NewInt(251), 1, 3)
+	assert.Equal(ErrorFieldTooSmall, err)
+	_, err = ShareBytes(make([]byte, 7919), big.NewInt(7919), 1, 3)
+	assert.Equal(ErrorFieldTooSmall, err)
+
+	// A wrong length is detected
+	vectors, err = ShareBytes([]byte("secret"), mersenne61, 0, 2)
+	assert.NoError(err)
+	vectors[0][0].Y = big.NewInt(100)
+	_, err = CombineBytes(vectors[:1])
+	assert.Equal(ErrorInvalidPayload, err)
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorOpeningMismatch = errors.New("Combined opening does not match the commitment")
+)
+
+// PedersenCommit returns the Pedersen commitment value * G + blinding * H to value with the given
+// blinding factor, for generators G and H of the same group. The commitment only binds the value if
+// nobody knows the discrete logarithm of H with respect to G.
+func PedersenCommit(generator GroupElement, blindingGenerator GroupElement, value *big.Int, blinding *big.Int) GroupElement {
+	return generator.ScalarMult(value).Add(blindingGenerator.ScalarMult(blinding))
+}
+
+// An OpeningShare is a share of the opening of a Pedersen commitment: a share of the value and a
+// share of the blinding factor, at the same X coordinate of sharings of the same degree.
+type OpeningShare struct {
+	Value    Share
+	Blinding Share
+}
+
+// ShareOpening shares the opening of the Pedersen commitment PedersenCommit(generator,
+// blindingGenerator, value, blinding) as a linked pair of sharings over the field of integers modulo
+// the group order. It returns Pedersen commitments to the pair of sharing polynomials: element j is
+// a_j * G + b_j * H for the coefficients a_j and b_j of X^j of the value and blinding polynomials,
+// so element 0 is the commitment to the opening. Unlike Feldman commitments, they reveal nothing
+// about the value. The shares carry the sharing fingerprint of the commitments.
+func ShareOpening(generator GroupElement, blindingGenerator GroupElement, value *big.Int, blinding *big.Int, degree int, nShares int) ([]OpeningShare, Commitments) {
+	fieldSize := generator.Order()
+	valueCoefficients := make([]*big.Int, degree)
+	blindingCoefficients := make([]*big.Int, degree)
+	commitments := make(Commitments, degree+1)
+	commitments[0] = PedersenCommit(generator, blindingGenerator, value, blinding)
+	for i := 0; i < degree; i++ {
+		valueCoefficients[i], _ = rand.Int(rand.Reader, fieldSize)
+		blindingCoefficients[i], _ = rand.Int(rand.Reader, fieldSize)
+		commitments[i+1] = PedersenCommit(generator, blindingGenerator, valueCoefficients[i], blindingCoefficients[i])
+	}
+	fingerprint := commitments.SharingFingerprint()
+	valueShares := WithSharingFingerprint(shareFiniteField(value, fieldSize, valueCoefficients, nShares), fingerprint)
+	blindingShares := WithSharingFingerprint(shareFiniteField(blinding, fieldSize, blindingCoefficients, nShares), fingerprint)
+	shares := make([]OpeningShare, nShares)
+	for i := range shares {
+		shares[i] = OpeningShare{Value: valueShares[i], Blinding: blindingShares[i]}
+	}
+	return shares, commitments
+}
+
+// VerifyOpeningShare checks that share lies on the pair of polynomials committed to by the Pedersen
+// commitments returned by ShareOpening, given the generators used when sharing.
+func VerifyOpeningShare(generator GroupElement, blindingGenerator GroupElement, commitments Commitments, share OpeningShare) bool {
+	order := generator.Order()
+	if !commitments.batchable(share.Value, order) || !commitments.batchable(share.Blinding, order) ||
+		share.Value.X != share.Blinding.X {
+		return false
+	}
+	commitment := PedersenCommit(generator, blindingGenerator, share.Value.Y, share.Blinding.Y)
+	return commitment.Equal(commitments.ShareCommitment(share.Value.X))
+}
+
+// CombineOpening recovers the value and blinding factor from shares of an opening like ShareCombine,
+// and checks that they open the public commitment. It returns ErrorOpeningMismatch if they do not,
+// for instance because a share was wrong; use VerifyOpeningShare to find the wrong shares.
+func CombineOpening(generator GroupElement, blindingGenerator GroupElement, commitment GroupElement, shares []OpeningShare) (*big.Int, *big.Int, error) {
+	valueShares := make([]Share, len(shares))
+	blindingShares := make([]Share, len(shares))
+	for i, share := range shares {
+		if share.Value.X != share.Blinding.X || share.Value.Degree != share.Blinding.Degree {
+			return nil, nil, ErrorIncompatibleShares
+		}
+		valueShares[i], blindingShares[i] = share.Value, share.Blinding
+	}
+	value, err := ShareCombine(valueShares)
+	if err != nil {
+		return nil, nil, err
+	}
+	blinding, err := ShareCombine(blindingShares)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !PedersenCommit(generator, blindingGenerator, value, blinding).Equal(commitment) {
+		return nil, nil, ErrorOpeningMismatch
+	}
+	return value, blinding, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareOpening(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	h := largeTestGroup.Element(big.NewInt(9))
+	value, blinding := big.NewInt(1000), big.NewInt(123456789)
+	commitment := PedersenCommit(g, h, value, blinding)
+
+	shares, commitments := ShareOpening(g, h, value, blinding, 2, 5)
+	assert.Len(shares, 5)
+	assert.Len(commitments, 3)
+	assert.True(commitment.Equal(commitments[0]))
+	for _, share := range shares {
+		assert.True(VerifyOpeningShare(g, h, commitments, share))
+	}
+
+	recoveredValue, recoveredBlinding, err := CombineOpening(g, h, commitment, shares[2:])
+	assert.NoError(err)
+	assert.Equal(value, recoveredValue)
+	assert.Equal(blinding, recoveredBlinding)
+
+	// A wrong blinding share is caught by the commitment, even though the value is unaffected
+	wrong := shares[1]
+	wrong.Blinding.Y = big.NewInt(0).Add(wrong.Blinding.Y, big.NewInt(1))
+	assert.False(VerifyOpeningShare(g, h, commitments, wrong))
+	_, _, err = CombineOpening(g, h, commitment, []OpeningShare{shares[0], wrong, shares[2]})
+	assert.Equal(ErrorOpeningMismatch, err)
+
+	// The halves of a share must belong together
+	mixed := OpeningShare{Value: shares[0].Value, Blinding: shares[1].Blinding}
+	assert.False(VerifyOpeningShare(g, h, commitments, mixed))
+	_, _, err = CombineOpening(g, h, commitment, []OpeningShare{mixed, shares[2], shares[3]})
+	assert.Equal(ErrorIncompatibleShares, err)
+
+	_, _, err = CombineOpening(g, h, commitment, shares[:2])
+	assert.True(errors.Is(err, ErrorTooFewShares))
+}
+
+func TestShareOpeningHomomorphic(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	h := largeTestGroup.Element(big.NewInt(9))
+	a, aCommitments := ShareOpening(g, h, big.NewInt(30), big.NewInt(1), 1, 3)
+	b, bCommitments := ShareOpening(g, h, big.NewInt(12), big.NewInt(2), 1, 3)
+
+	// Adding openings adds the commitments, as in confidential transactions
+	commitments, err := aCommitments.Add(bCommitments)
+	assert.NoError(err)
+	sums := make([]OpeningShare, 3)
+	for i := range sums {
+		sums[i].Value, err = ShareAdd([]Share{a[i].Value, b[i].Value})
+		assert.NoError(err)
+		sums[i].Blinding, err = ShareAdd([]Share{a[i].Blinding, b[i].Blinding})
+		assert.NoError(err)
+		assert.True(VerifyOpeningShare(g, h, commitments, sums[i]))
+	}
+	value, blinding, err := CombineOpening(g, h, commitments[0], sums)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(42), value)
+	assert.Equal(big.NewInt(3), blinding)
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// Parties jointly generate shares of a uniformly random permutation matrix as follows. Every
+// contributing party draws a RandomPermutation and shares it with SharePermutation. The parties
+// then multiply the shared matrices one by one with ShareMatrix.Mul, and after every product
+// reduce the degree back to the original degree by resharing (ShareMatrix.Vector, ShareVector.Split,
+// RecombineVector and ShareMatrixFromVector). The resulting permutation is uniformly random and
+// unknown to everyone, as long as at least one contributor is honest. Applying the shared
+// permutation to a shared vector with ShareMatrix.MulVector, followed by resharing, obliviously
+// shuffles the vector.
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorInvalidPermutation = errors.New("Invalid permutation given")
+)
+
+// RandomPermutation returns a uniformly random permutation of 0, ..., n-1, computed with the
+// Fisher-Yates shuffle using randomness from crypto/rand.
+func RandomPermutation(n int) ([]int, error) {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		perm[i], perm[j.Int64()] = perm[j.Int64()], perm[i]
+	}
+	return perm, nil
+}
+
+// SharePermutation shares the permutation matrix of perm over a finite field, see ShareFiniteField.
+// The permutation matrix P has P[perm[i]][i] = 1, so multiplying it with a vector moves element i
+// to position perm[i]. It returns a ShareMatrix for every party.
+func SharePermutation(perm []int, fieldSize *big.Int, degree int, nShares int) ([]ShareMatrix, error) {
+	seen := make([]bool, len(perm))
+	matrix := make([][]*big.Int, len(perm))
+	for r := range matrix {
+		matrix[r] = make([]*big.Int, len(perm))
+		for c := range matrix[r] {
+			matrix[r][c] = big.NewInt(0)
+		}
+	}
+	for i, j := range perm {
+		if j < 0 || j >= len(perm) || seen[j] {
+			return nil, ErrorInvalidPermutation
+		}
+		seen[j] = true
+		matrix[j][i].SetInt64(1)
+	}
+	return ShareMatrixFiniteField(matrix, fieldSize, degree, nShares), nil
+}
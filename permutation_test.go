@@ -0,0 +1,120 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reshareVectors runs the resharing protocol on the ShareVectors of all parties.
+func reshareVectors(t *testing.T, vectors []ShareVector, degree int) []ShareVector {
+	received := make([][][]NestedShare, len(vectors))
+	for _, v := range vectors {
+		subShares, err := v.Split(degree, len(vectors))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		for i := range received {
+			received[i] = append(received[i], subShares[i])
+		}
+	}
+	fresh := make([]ShareVector, len(vectors))
+	for i := range fresh {
+		var err error
+		fresh[i], err = RecombineVector(received[i])
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	return fresh
+}
+
+func TestRandomPermutation(t *testing.T) {
+	assert := assert.New(t)
+	perm, err := RandomPermutation(10)
+	assert.NoError(err)
+	sorted := append([]int{}, perm...)
+	sort.Ints(sorted)
+	assert.Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, sorted)
+}
+
+func TestJointPermutation(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	degree, nShares, size := 2, 5, 4
+
+	// Three parties contribute a random permutation
+	var perms [][]int
+	var shared []ShareMatrix
+	for contributor := 0; contributor < 3; contributor++ {
+		perm, err := RandomPermutation(size)
+		assert.NoError(err)
+		perms = append(perms, perm)
+		matrices, err := SharePermutation(perm, fieldSize, degree, nShares)
+		assert.NoError(err)
+		if shared == nil {
+			shared = matrices
+			continue
+		}
+		products := make([]ShareVector, nShares)
+		for i := range products {
+			product, err := shared[i].Mul(matrices[i])
+			assert.NoError(err)
+			products[i] = product.Vector()
+		}
+		for i, v := range reshareVectors(t, products, degree) {
+			shared[i], err = ShareMatrixFromVector(v, size)
+			assert.NoError(err)
+		}
+	}
+
+	// The result is the composition of the contributed permutations
+	matrix, err := CombineMatrix(shared)
+	assert.NoError(err)
+	for i := 0; i < size; i++ {
+		j := perms[0][perms[1][perms[2][i]]]
+		for r := 0; r < size; r++ {
+			if r == j {
+				assert.Equal(int64(1), matrix[r][i].Int64())
+			} else {
+				assert.Equal(int64(0), matrix[r][i].Int64())
+			}
+		}
+	}
+
+	// Shuffle a shared vector
+	vectors := ShareVectorFiniteField(bigInts(10, 20, 30, 40), fieldSize, degree, nShares)
+	for i := range vectors {
+		vectors[i], err = shared[i].MulVector(vectors[i])
+		assert.NoError(err)
+	}
+	shuffled, err := CombineVector(reshareVectors(t, vectors, degree))
+	assert.NoError(err)
+	for i := 0; i < size; i++ {
+		assert.Equal(int64(10*(i+1)), shuffled[perms[0][perms[1][perms[2][i]]]].Int64())
+	}
+}
+
+func TestSharePermutationErrors(t *testing.T) {
+	assert := assert.New(t)
+	_, err := SharePermutation([]int{0, 0}, big.NewInt(7919), 1, 3)
+	assert.Equal(ErrorInvalidPermutation, err)
+	_, err = SharePermutation([]int{0, 2}, big.NewInt(7919), 1, 3)
+	assert.Equal(ErrorInvalidPermutation, err)
+}
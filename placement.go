@@ -0,0 +1,129 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+var (
+	ErrorPlacementViolated = errors.New("Distribution of shares violates the placement policy")
+)
+
+// A Custodian describes the holder of a share: the X coordinate of its share, and attributes such
+// as its site, jurisdiction or role, by which a PlacementPolicy constrains the distribution of
+// shares.
+type Custodian struct {
+	X          int               `json:"x"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// A PlacementRule constrains how the custodians of a sharing are spread over the values of an
+// attribute. Zero fields impose no constraint.
+type PlacementRule struct {
+	// Attribute is the attribute that the rule applies to, such as "site". Custodians without the
+	// attribute violate the rule.
+	Attribute string `json:"attribute"`
+	// MaxPerValue is the maximum number of custodians with the same value of the attribute, for
+	// instance 1 for "no two shares in the same data center".
+	MaxPerValue int `json:"maxPerValue,omitempty"`
+	// MinValues is the minimum number of distinct values of the attribute among the custodians.
+	MinValues int `json:"minValues,omitempty"`
+	// BelowThreshold requires that the custodians with the same value of the attribute hold too few
+	// shares to recover the secret, for instance so that no single jurisdiction can compel a
+	// recovery.
+	BelowThreshold bool `json:"belowThreshold,omitempty"`
+}
+
+// A PlacementPolicy is a set of rules that a distribution of shares must satisfy. Policies can be
+// kept in configuration files as JSON.
+type PlacementPolicy struct {
+	Rules []PlacementRule `json:"rules"`
+}
+
+// A PlacementViolation describes a violation of rule Rule of a PlacementPolicy by the custodians
+// with X coordinates Xs. For rules on the number of custodians per value, Value is the value of the
+// attribute that the custodians share; it is empty for custodians without the attribute and for
+// violations of MinValues.
+type PlacementViolation struct {
+	Rule  int
+	Value string
+	Xs    []int
+}
+
+// Violations returns the violations of the policy by a distribution of shares of the given degree
+// among custodians, ordered by rule and value.
+func (p PlacementPolicy) Violations(custodians []Custodian, degree int) []PlacementViolation {
+	var violations []PlacementViolation
+	for i, rule := range p.Rules {
+		byValue := make(map[string][]int)
+		var missing []int
+		for _, custodian := range custodians {
+			value, ok := custodian.Attributes[rule.Attribute]
+			if !ok {
+				missing = append(missing, custodian.X)
+				continue
+			}
+			byValue[value] = append(byValue[value], custodian.X)
+		}
+		if missing != nil {
+			violations = append(violations, PlacementViolation{Rule: i, Xs: missing})
+		}
+		values := make([]string, 0, len(byValue))
+		for value := range byValue {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			xs := byValue[value]
+			if (rule.MaxPerValue > 0 && len(xs) > rule.MaxPerValue) || (rule.BelowThreshold && len(xs) > degree) {
+				violations = append(violations, PlacementViolation{Rule: i, Value: value, Xs: xs})
+			}
+		}
+		if len(values) < rule.MinValues {
+			xs := make([]int, len(custodians))
+			for j := range custodians {
+				xs[j] = custodians[j].X
+			}
+			violations = append(violations, PlacementViolation{Rule: i, Xs: xs})
+		}
+	}
+	return violations
+}
+
+// Check returns ErrorPlacementViolated if a distribution of shares of the given degree among
+// custodians violates the policy; use Violations to find out why.
+func (p PlacementPolicy) Check(custodians []Custodian, degree int) error {
+	if len(p.Violations(custodians, degree)) > 0 {
+		return ErrorPlacementViolated
+	}
+	return nil
+}
+
+// ShareForCustodians checks the distribution of shares of the given degree among custodians against
+// policy, and if it is satisfied, shares a secret over a finite field at the X coordinates of the
+// custodians with ShareFiniteFieldAt. The shares are in the order of custodians.
+func ShareForCustodians(secret *big.Int, fieldSize *big.Int, degree int, custodians []Custodian, policy PlacementPolicy) ([]Share, error) {
+	if err := policy.Check(custodians, degree); err != nil {
+		return nil, err
+	}
+	xs := make([]int, len(custodians))
+	for i := range custodians {
+		xs[i] = custodians[i].X
+	}
+	return ShareFiniteFieldAt(secret, fieldSize, degree, xs)
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlacementPolicy(t *testing.T) {
+	assert := assert.New(t)
+	var policy PlacementPolicy
+	assert.NoError(json.Unmarshal([]byte(`{"rules": [
+		{"attribute": "site", "maxPerValue": 1},
+		{"attribute": "jurisdiction", "minValues": 2, "belowThreshold": true}
+	]}`), &policy))
+	custodians := []Custodian{
+		{X: 1, Attributes: map[string]string{"site": "ams1", "jurisdiction": "NL"}},
+		{X: 2, Attributes: map[string]string{"site": "ams2", "jurisdiction": "NL"}},
+		{X: 3, Attributes: map[string]string{"site": "fra1", "jurisdiction": "DE"}},
+		{X: 4, Attributes: map[string]string{"site": "par1", "jurisdiction": "FR"}},
+	}
+	assert.Empty(policy.Violations(custodians, 2))
+	assert.NoError(policy.Check(custodians, 2))
+	shares, err := ShareForCustodians(big.NewInt(42), big.NewInt(7919), 2, custodians, policy)
+	assert.NoError(err)
+	assert.Len(shares, 4)
+	secret, err := ShareCombine(shares[1:])
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+
+	// With degree 1, the Dutch custodians could recover the secret together
+	assert.Equal([]PlacementViolation{{Rule: 1, Value: "NL", Xs: []int{1, 2}}}, policy.Violations(custodians, 1))
+	_, err = ShareForCustodians(big.NewInt(42), big.NewInt(7919), 1, custodians, policy)
+	assert.Equal(ErrorPlacementViolated, err)
+
+	// Two shares in one data center, a custodian without a site, and a single jurisdiction
+	custodians = []Custodian{
+		{X: 1, Attributes: map[string]string{"site": "ams1", "jurisdiction": "NL"}},
+		{X: 2, Attributes: map[string]string{"site": "ams1", "jurisdiction": "NL"}},
+		{X: 3, Attributes: map[string]string{"jurisdiction": "NL"}},
+	}
+	assert.Equal([]PlacementViolation{
+		{Rule: 0, Xs: []int{3}},
+		{Rule: 0, Value: "ams1", Xs: []int{1, 2}},
+		{Rule: 1, Value: "NL", Xs: []int{1, 2, 3}},
+		{Rule: 1, Xs: []int{1, 2, 3}},
+	}, policy.Violations(custodians, 2))
+	assert.Equal(ErrorPlacementViolated, policy.Check(custodians, 2))
+
+	// Without rules, only the X coordinates are checked
+	_, err = ShareForCustodians(big.NewInt(42), big.NewInt(7919), 1, []Custodian{{X: 1}, {X: 1}}, PlacementPolicy{})
+	assert.Equal(ErrorDuplicateX, err)
+}
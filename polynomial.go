@@ -0,0 +1,76 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+)
+
+// A SharedPolynomial holds the shares of a single party of the coefficients of a secret
+// polynomial, starting with the constant term. Since evaluating a polynomial at a public point is
+// linear in its coefficients, the parties can evaluate it locally with Evaluate and open only the
+// values, without learning the polynomial. This is the core of oblivious PRFs and verifiable
+// computations built on secret polynomials.
+type SharedPolynomial ShareVector
+
+// SharePolynomial shares every coefficient of a polynomial over a finite field, starting with the
+// constant term, see ShareVectorFiniteField. It returns a SharedPolynomial for every party.
+func SharePolynomial(coefficients []*big.Int, fieldSize *big.Int, degree int, nShares int) []SharedPolynomial {
+	vectors := ShareVectorFiniteField(coefficients, fieldSize, degree, nShares)
+	polynomials := make([]SharedPolynomial, len(vectors))
+	for i := range vectors {
+		polynomials[i] = SharedPolynomial(vectors[i])
+	}
+	return polynomials
+}
+
+// Evaluate returns shares of the values of the polynomial at the public points, see ApplyAffine.
+// Over a finite field, the powers of the points are reduced modulo the field size.
+func (p SharedPolynomial) Evaluate(points []*big.Int) (ShareVector, error) {
+	if len(p) == 0 {
+		return nil, ErrorNoShares
+	}
+	fieldSize := p[0].FieldSize
+	powers := make([][]*big.Int, len(points))
+	for i, point := range points {
+		powers[i] = make([]*big.Int, len(p))
+		power := big.NewInt(1)
+		for j := range p {
+			powers[i][j] = power
+			power = big.NewInt(0).Mul(power, point)
+			if fieldSize != nil {
+				power.Mod(power, fieldSize)
+			}
+		}
+	}
+	return ApplyAffine(powers, ShareVector(p), nil)
+}
+
+// Add returns shares of the sum of the polynomial and q, whose coefficients are shared with the
+// same parameters. The polynomials may have different lengths.
+func (p SharedPolynomial) Add(q SharedPolynomial) (SharedPolynomial, error) {
+	if len(p) < len(q) {
+		p, q = q, p
+	}
+	sum := make(SharedPolynomial, len(p))
+	copy(sum, p)
+	for j := range q {
+		var err error
+		if sum[j], err = ShareAdd([]Share{p[j], q[j]}); err != nil {
+			return nil, err
+		}
+	}
+	return sum, nil
+}
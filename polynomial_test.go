@@ -0,0 +1,72 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedPolynomial(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(2039)
+	// 3 + 2x + x^2
+	polynomials := SharePolynomial(bigInts(3, 2, 1), fieldSize, 1, 3)
+	assert.Len(polynomials, 3)
+
+	values := make([]ShareVector, len(polynomials))
+	for i := range polynomials {
+		var err error
+		values[i], err = polynomials[i].Evaluate(bigInts(0, 1, 10, 2038))
+		assert.NoError(err)
+	}
+	secrets, err := CombineVector(values[1:])
+	assert.NoError(err)
+	assert.Equal(bigInts(3, 6, 123, 2), secrets)
+
+	// Sums of polynomials evaluate to sums of values
+	others := SharePolynomial(bigInts(1, 1), fieldSize, 1, 3)
+	for i := range polynomials {
+		sum, err := polynomials[i].Add(others[i])
+		assert.NoError(err)
+		assert.Len(sum, 3)
+		values[i], err = sum.Evaluate(bigInts(10))
+		assert.NoError(err)
+	}
+	secrets, err = CombineVector(values)
+	assert.NoError(err)
+	assert.Equal(bigInts(134), secrets)
+
+	_, err = SharedPolynomial(nil).Evaluate(bigInts(1))
+	assert.Equal(ErrorNoShares, err)
+	_, err = polynomials[0].Add(others[1])
+	assert.Equal(ErrorIncompatibleShares, err)
+}
+
+func TestSharedPolynomialIntegers(t *testing.T) {
+	assert := assert.New(t)
+	vectors := ShareVectorIntegers(bigInts(-4, 0, 3), big.NewInt(10), 40, 2, 4)
+	values := make([]ShareVector, len(vectors))
+	for i := range vectors {
+		var err error
+		values[i], err = SharedPolynomial(vectors[i]).Evaluate(bigInts(-2, 5))
+		assert.NoError(err)
+	}
+	secrets, err := CombineVector(values[1:])
+	assert.NoError(err)
+	assert.Equal(bigInts(8, 71), secrets)
+}
@@ -0,0 +1,249 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+// BrachaBroadcaster implements Bracha's reliable broadcast ("Asynchronous Byzantine agreement
+// protocols", Information and Computation 75(2), 1987) among n parties, of which at most
+// f = (n-1)/3 may be malicious. Every round runs one instance per sender:
+//
+//  1. The sender sends its message to all parties (initial).
+//  2. A party that receives the initial message of the sender sends it to all parties (echo).
+//  3. A party that receives (n+f+1)/2 echoes or f+1 readies of the same message, rounded up, sends
+//     it to all parties (ready).
+//  4. A party that receives 2f+1 readies of the same message delivers it.
+//
+// If any honest party delivers a message, all honest parties deliver the same message.
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sync"
+	"time"
+)
+
+type brachaKind int
+
+const (
+	brachaInitial brachaKind = iota + 1
+	brachaEcho
+	brachaReady
+)
+
+// brachaMessage is a message of the instance of a sender in a round.
+type brachaMessage struct {
+	Round   int
+	Sender  int
+	Kind    brachaKind
+	Payload []byte
+}
+
+// brachaInstance is the state of the instance of a sender in a round.
+type brachaInstance struct {
+	echoed    bool
+	readied   bool
+	delivered bool
+	payload   []byte
+	// echoes and readies contain the parties that sent every payload, and voted those that sent
+	// any, since every party may only vote once for every kind
+	echoes  map[string][]int
+	readies map[string][]int
+	voted   map[brachaKind]map[int]bool
+}
+
+// A BrachaBroadcaster is a shamir.Broadcaster over the point-to-point links of a Pool, using
+// Bracha's reliable broadcast. It tolerates up to (nParties-1)/3 malicious parties. The links must
+// be authenticated, for instance by a TCPPool with TLS client certificates.
+type BrachaBroadcaster struct {
+	x        int
+	nParties int
+	faulty   int
+	pool     Pool
+	timeout  time.Duration
+	locks    []sync.Mutex
+
+	mutex     sync.Mutex
+	round     int
+	instances map[[2]int]*brachaInstance
+	// changed is closed and replaced whenever a message is delivered
+	changed chan struct{}
+}
+
+// NewBrachaBroadcaster returns the broadcaster for the party with X coordinate x among nParties
+// parties connected by pool. Broadcast waits at most timeout for the messages of a round, so the
+// timeout should be generous: a message that an honest party delivers after it may have been
+// delivered by others in time. The broadcaster receives from the pool in the background right away,
+// so the pool must not be used for anything else, and it keeps taking part in the previous round
+// for parties that are behind.
+func NewBrachaBroadcaster(x int, nParties int, pool Pool, timeout time.Duration) *BrachaBroadcaster {
+	b := &BrachaBroadcaster{
+		x:         x,
+		nParties:  nParties,
+		faulty:    (nParties - 1) / 3,
+		pool:      pool,
+		timeout:   timeout,
+		locks:     make([]sync.Mutex, nParties),
+		instances: make(map[[2]int]*brachaInstance),
+		changed:   make(chan struct{}),
+	}
+	for from := 1; from <= nParties; from++ {
+		if from != x {
+			go b.receive(from)
+		}
+	}
+	return b
+}
+
+// Broadcast performs a single round of reliable broadcast, see shamir.Broadcaster. It returns when
+// the messages of all parties are delivered, or when the timeout passes.
+func (b *BrachaBroadcaster) Broadcast(message []byte) ([][]byte, error) {
+	b.mutex.Lock()
+	b.round++
+	round := b.round
+	for key := range b.instances {
+		if key[0] < round-1 {
+			delete(b.instances, key)
+		}
+	}
+	b.mutex.Unlock()
+	// The message is handled asynchronously, so the caller must be free to reuse its buffer.
+	message = append([]byte{}, message...)
+	b.sendAll(brachaMessage{Round: round, Sender: b.x, Kind: brachaInitial, Payload: message})
+
+	deadline := time.NewTimer(b.timeout)
+	defer deadline.Stop()
+	for {
+		b.mutex.Lock()
+		delivered := make([][]byte, b.nParties)
+		complete := true
+		for sender := 1; sender <= b.nParties; sender++ {
+			if instance, ok := b.instances[[2]int{round, sender}]; ok && instance.delivered {
+				delivered[sender-1] = instance.payload
+			} else {
+				complete = false
+			}
+		}
+		changed := b.changed
+		b.mutex.Unlock()
+		if complete {
+			return delivered, nil
+		}
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return delivered, nil
+		}
+	}
+}
+
+// receive handles the messages from a party until receiving fails.
+func (b *BrachaBroadcaster) receive(from int) {
+	for {
+		encoded, err := b.pool.Receive(from)
+		if err != nil {
+			return
+		}
+		var m brachaMessage
+		if gob.NewDecoder(bytes.NewReader(encoded)).Decode(&m) == nil {
+			b.handle(from, m)
+		}
+	}
+}
+
+// handle processes a message from a party, and sends the resulting messages.
+func (b *BrachaBroadcaster) handle(from int, m brachaMessage) {
+	if m.Sender < 1 || m.Sender > b.nParties {
+		return
+	}
+	if m.Payload == nil {
+		// gob decodes empty messages as nil, which is reserved for messages not delivered
+		m.Payload = []byte{}
+	}
+	b.mutex.Lock()
+	// Only the previous, the current and the next round are kept
+	if m.Round < b.round-1 || m.Round > b.round+1 {
+		b.mutex.Unlock()
+		return
+	}
+	instance, ok := b.instances[[2]int{m.Round, m.Sender}]
+	if !ok {
+		instance = &brachaInstance{
+			echoes:  make(map[string][]int),
+			readies: make(map[string][]int),
+			voted:   map[brachaKind]map[int]bool{brachaEcho: {}, brachaReady: {}},
+		}
+		b.instances[[2]int{m.Round, m.Sender}] = instance
+	}
+	var send []brachaMessage
+	ready := func() {
+		if !instance.readied {
+			instance.readied = true
+			send = append(send, brachaMessage{Round: m.Round, Sender: m.Sender, Kind: brachaReady, Payload: m.Payload})
+		}
+	}
+	switch m.Kind {
+	case brachaInitial:
+		if from == m.Sender && !instance.echoed {
+			instance.echoed = true
+			send = append(send, brachaMessage{Round: m.Round, Sender: m.Sender, Kind: brachaEcho, Payload: m.Payload})
+		}
+	case brachaEcho, brachaReady:
+		if instance.voted[m.Kind][from] {
+			break
+		}
+		instance.voted[m.Kind][from] = true
+		key := string(m.Payload)
+		if m.Kind == brachaEcho {
+			instance.echoes[key] = append(instance.echoes[key], from)
+			if 2*len(instance.echoes[key]) >= b.nParties+b.faulty+1 {
+				ready()
+			}
+			break
+		}
+		instance.readies[key] = append(instance.readies[key], from)
+		if len(instance.readies[key]) >= b.faulty+1 {
+			ready()
+		}
+		if len(instance.readies[key]) >= 2*b.faulty+1 && !instance.delivered {
+			instance.delivered = true
+			instance.payload = m.Payload
+			close(b.changed)
+			b.changed = make(chan struct{})
+		}
+	}
+	b.mutex.Unlock()
+	for _, m := range send {
+		b.sendAll(m)
+	}
+}
+
+// sendAll sends a message to all parties, handling the copy for this party directly. Errors are
+// not reported, since reliable broadcast tolerates lost messages of up to f parties.
+func (b *BrachaBroadcaster) sendAll(m brachaMessage) {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(m); err != nil {
+		return
+	}
+	for to := 1; to <= b.nParties; to++ {
+		if to == b.x {
+			go b.handle(b.x, m)
+			continue
+		}
+		go func(to int) {
+			b.locks[to-1].Lock()
+			defer b.locks[to-1].Unlock()
+			b.pool.Send(to, buffer.Bytes())
+		}(to)
+	}
+}
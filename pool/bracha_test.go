@@ -0,0 +1,121 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+// broadcastAll broadcasts messages[i] with broadcasters[i] concurrently, and returns the messages
+// delivered to every broadcaster.
+func broadcastAll(broadcasters []*BrachaBroadcaster, messages [][]byte) [][][]byte {
+	delivered := make([][][]byte, len(broadcasters))
+	var wg sync.WaitGroup
+	for i := range broadcasters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			delivered[i], _ = broadcasters[i].Broadcast(messages[i])
+		}(i)
+	}
+	wg.Wait()
+	return delivered
+}
+
+func TestBrachaBroadcaster(t *testing.T) {
+	assert := assert.New(t)
+	pools := newChannelPools(4)
+	broadcasters := make([]*BrachaBroadcaster, len(pools))
+	for i := range pools {
+		broadcasters[i] = NewBrachaBroadcaster(i+1, len(pools), pools[i], time.Minute)
+	}
+	for round := 0; round < 3; round++ {
+		messages := [][]byte{[]byte("one"), []byte("two"), {}, []byte{byte(round)}}
+		for _, delivered := range broadcastAll(broadcasters, messages) {
+			assert.Equal(messages, delivered)
+		}
+	}
+}
+
+func TestBrachaBroadcasterFaulty(t *testing.T) {
+	assert := assert.New(t)
+	pools := newChannelPools(4)
+	broadcasters := make([]*BrachaBroadcaster, 3)
+	for i := range broadcasters {
+		broadcasters[i] = NewBrachaBroadcaster(i+1, 4, pools[i], 200*time.Millisecond)
+	}
+	send := func(to int, m brachaMessage) {
+		var buffer bytes.Buffer
+		gob.NewEncoder(&buffer).Encode(m)
+		pools[3].Send(to, buffer.Bytes())
+	}
+	messages := [][]byte{[]byte("one"), []byte("two"), []byte("three")}
+	expected := append(messages, nil)
+
+	// Party 4 is silent
+	for _, delivered := range broadcastAll(broadcasters, messages) {
+		assert.Equal(expected, delivered)
+	}
+
+	// Party 4 sends different messages to different parties, which no honest party delivers
+	send(1, brachaMessage{Round: 2, Sender: 4, Kind: brachaInitial, Payload: []byte("a")})
+	send(2, brachaMessage{Round: 2, Sender: 4, Kind: brachaInitial, Payload: []byte("a")})
+	send(3, brachaMessage{Round: 2, Sender: 4, Kind: brachaInitial, Payload: []byte("b")})
+	for _, delivered := range broadcastAll(broadcasters, messages) {
+		assert.Equal(expected, delivered)
+	}
+
+	// A message that reaches enough honest parties is delivered by all, even if party 4 does not
+	// take part in the echoes
+	send(1, brachaMessage{Round: 3, Sender: 4, Kind: brachaInitial, Payload: []byte("a")})
+	send(2, brachaMessage{Round: 3, Sender: 4, Kind: brachaInitial, Payload: []byte("a")})
+	send(3, brachaMessage{Round: 3, Sender: 4, Kind: brachaInitial, Payload: []byte("a")})
+	for _, delivered := range broadcastAll(broadcasters, messages) {
+		assert.Equal(append(messages, []byte("a")), delivered)
+	}
+}
+
+func TestRandomShareBracha(t *testing.T) {
+	assert := assert.New(t)
+	group := largeTestGroup
+	broadcastPools, networkPools := newChannelPools(4), newChannelPools(4)
+	shares := make([]shamir.Share, 4)
+	errs := make([]error, 4)
+	var wg sync.WaitGroup
+	for i := range shares {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			broadcaster := NewBrachaBroadcaster(i+1, 4, broadcastPools[i], time.Minute)
+			shares[i], _, errs[i] = shamir.RandomShare(i+1, 4, group.Generator(), group.Decode, 1, broadcaster, NewNetwork(i+1, 4, networkPools[i]))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(err)
+	}
+	a, err := shamir.ShareCombine(shares[:2])
+	assert.NoError(err)
+	b, err := shamir.ShareCombine(shares[2:])
+	assert.NoError(err)
+	assert.Equal(a, b)
+}
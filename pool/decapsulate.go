@@ -0,0 +1,58 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"github.com/TNO-MPC/shamir"
+)
+
+// RemoteDecapsulator returns a shamir.PartialDecapsulator that asks the committee member with X
+// coordinate x in the pool for its partial result, for use with shamir.NewCommitteeDecrypter. The
+// member must run ServeDecapsulation for the party using the pool. Decode parses the partial
+// results, for instance shamir.ModPGroup.Decode. Requests to different members may run
+// concurrently, but requests to the same member must not.
+func RemoteDecapsulator(pool Pool, decode func([]byte) (shamir.GroupElement, error)) shamir.PartialDecapsulator {
+	return func(x int, encapsulation shamir.GroupElement) (shamir.GroupElement, error) {
+		if err := pool.Send(x, encapsulation.Bytes()); err != nil {
+			return nil, err
+		}
+		response, err := pool.Receive(x)
+		if err != nil {
+			return nil, err
+		}
+		return decode(response)
+	}
+}
+
+// ServeDecapsulation answers the requests of the party with X coordinate client in the pool with the
+// partial results for share, until receiving fails, and returns that error. Invalid encapsulations
+// are answered with an empty message, so that the client fails to decode it.
+func ServeDecapsulation(pool Pool, client int, share shamir.Share, decode func([]byte) (shamir.GroupElement, error)) error {
+	for {
+		request, err := pool.Receive(client)
+		if err != nil {
+			return err
+		}
+		var response []byte
+		if encapsulation, err := decode(request); err == nil {
+			if partial, err := shamir.DecapsulateShare(share, encapsulation); err == nil {
+				response = partial.Bytes()
+			}
+		}
+		if err := pool.Send(client, response); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteDecapsulator(t *testing.T) {
+	assert := assert.New(t)
+	group := largeTestGroup
+	g := group.Generator()
+	shares, commitments := shamir.ShareFeldman(big.NewInt(123456), g, 1, 3)
+
+	// Party 4 decrypts with the help of the committee members 1 to 3
+	pools := newChannelPools(4)
+	for i, share := range shares {
+		go ServeDecapsulation(pools[i], 4, share, group.Decode)
+	}
+	decrypter := shamir.NewCommitteeDecrypter(commitments[0], group.Decode, []int{1, 2, 3}, 1, RemoteDecapsulator(pools[3], group.Decode))
+	message := []byte("Decrypted over the pool")
+	ciphertext, err := shamir.SealToCommittee(commitments[0], g, message)
+	assert.NoError(err)
+	for i := 0; i < 2; i++ {
+		decrypted, err := decrypter.Decrypt(nil, ciphertext, nil)
+		assert.NoError(err)
+		assert.Equal(message, decrypted)
+	}
+
+	// Requests that are not group elements are answered with an empty message
+	remote := RemoteDecapsulator(pools[3], group.Decode)
+	_, err = remote(1, group.Element(big.NewInt(-1)))
+	assert.Equal(shamir.ErrorInvalidElement, err)
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pool connects the interactive protocols of shamir.Party to a communication pool: a set of
+// parties that send byte messages to each other, as in the communication module of the TNO MPC Lab.
+// NewNetwork adapts any Pool to a shamir.Network, NewRoundNetwork adds deadlines, retries and
+// aborts to its rounds, and TCPPool is a reference implementation over TCP, optionally secured with
+// TLS.
+package pool
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"sync"
+
+	"github.com/TNO-MPC/shamir"
+)
+
+var (
+	ErrorUnknownParty = errors.New("No party with this X coordinate in the pool")
+)
+
+// A Pool sends messages between the parties of a computation, which are identified by their X
+// coordinates 1 to nParties. Messages between two parties must be delivered in order. Send and
+// Receive may be called concurrently for different parties.
+type Pool interface {
+	// Send sends a message to the party with X coordinate to.
+	Send(to int, message []byte) error
+	// Receive returns the next message from the party with X coordinate from.
+	Receive(from int) ([]byte, error)
+	// Broadcast sends a message to all other parties.
+	Broadcast(message []byte) error
+}
+
+// NewNetwork returns a shamir.Network for the party with X coordinate x among nParties parties that
+// exchanges the messages of every round through pool. Messages to the party itself do not pass
+// through the pool.
+func NewNetwork(x int, nParties int, pool Pool) shamir.Network {
+	return &network{x: x, nParties: nParties, pool: pool}
+}
+
+// message is the encoded form of the shares sent in a round. The slice is wrapped because gob
+// cannot encode nil values at the top level. The other fields are only used by NewRoundNetwork.
+type message struct {
+	Shares []shamir.NestedShare
+	// Round is the number of the round, starting at 1.
+	Round int
+	// Retry means that the sender has not received the message of the recipient for the round.
+	Retry bool
+	// Abort means that the sender aborted the round.
+	Abort bool
+}
+
+type network struct {
+	x        int
+	nParties int
+	pool     Pool
+}
+
+func (n *network) Exchange(outgoing [][]shamir.NestedShare) ([][]shamir.NestedShare, error) {
+	if len(outgoing) != n.nParties {
+		return nil, shamir.ErrorNetwork
+	}
+	// Send concurrently with receiving, so that large messages cannot block both sides
+	sendErrs := make([]error, n.nParties)
+	var wg sync.WaitGroup
+	for i := range outgoing {
+		if i+1 == n.x {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var buffer bytes.Buffer
+			if sendErrs[i] = gob.NewEncoder(&buffer).Encode(message{Shares: outgoing[i]}); sendErrs[i] == nil {
+				sendErrs[i] = n.pool.Send(i+1, buffer.Bytes())
+			}
+		}(i)
+	}
+
+	received := make([][]shamir.NestedShare, n.nParties)
+	var receiveErr error
+	for i := range received {
+		if i+1 == n.x {
+			received[i] = outgoing[i]
+			continue
+		}
+		encoded, err := n.pool.Receive(i + 1)
+		var decoded message
+		if err == nil {
+			err = gob.NewDecoder(bytes.NewReader(encoded)).Decode(&decoded)
+		}
+		received[i] = decoded.Shares
+		if err != nil {
+			receiveErr = err
+			break
+		}
+	}
+	wg.Wait()
+	for _, err := range sendErrs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	if receiveErr != nil {
+		return nil, receiveErr
+	}
+	return received, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+// largeTestGroup is a group of 127-bit order, in which forged shares and proofs are accepted with
+// negligible probability.
+var largeTestGroup = func() *shamir.ModPGroup {
+	p, _ := big.NewInt(0).SetString("340282366920938463463374607431768196007", 10)
+	q, _ := big.NewInt(0).SetString("170141183460469231731687303715884098003", 10)
+	return &shamir.ModPGroup{P: p, Q: q, G: big.NewInt(4)}
+}()
+
+// channelPool is a Pool of parties in the same process.
+type channelPool struct {
+	x        int
+	channels [][]chan []byte
+}
+
+func newChannelPools(nParties int) []Pool {
+	channels := make([][]chan []byte, nParties)
+	for i := range channels {
+		channels[i] = make([]chan []byte, nParties)
+		for j := range channels[i] {
+			channels[i][j] = make(chan []byte, 10)
+		}
+	}
+	pools := make([]Pool, nParties)
+	for i := range pools {
+		pools[i] = &channelPool{x: i + 1, channels: channels}
+	}
+	return pools
+}
+
+func (p *channelPool) Send(to int, message []byte) error {
+	p.channels[p.x-1][to-1] <- message
+	return nil
+}
+
+func (p *channelPool) Receive(from int) ([]byte, error) {
+	return <-p.channels[from-1][p.x-1], nil
+}
+
+func (p *channelPool) Broadcast(message []byte) error {
+	for i := range p.channels {
+		if i+1 != p.x {
+			p.Send(i+1, message)
+		}
+	}
+	return nil
+}
+
+// runProtocol multiplies two secrets among parties connected by pools and returns the opened
+// product of every party.
+func runProtocol(t *testing.T, pools []Pool) [][]*big.Int {
+	networks := make([]shamir.Network, len(pools))
+	for i := range pools {
+		networks[i] = NewNetwork(i+1, len(pools), pools[i])
+	}
+	results, errs := runParties(networks)
+	for _, err := range errs {
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	return results
+}
+
+// runParties runs the protocol of runProtocol on the given networks, and returns the results and
+// errors of every party.
+func runParties(networks []shamir.Network) ([][]*big.Int, []error) {
+	results := make([][]*big.Int, len(networks))
+	errs := make([]error, len(networks))
+	var wg sync.WaitGroup
+	for i := range networks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := shamir.NewParty(i+1, len(networks), big.NewInt(7919), 1, networks[i])
+			a, err := p.Input(1, []*big.Int{big.NewInt(6), big.NewInt(7)}, 2)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			b, err := p.Input(3, []*big.Int{big.NewInt(10), big.NewInt(20)}, 2)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			c, err := p.Mul(a, b)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i], errs[i] = p.Open(c)
+		}(i)
+	}
+	wg.Wait()
+	return results, errs
+}
+
+func TestNetwork(t *testing.T) {
+	assert := assert.New(t)
+	for _, result := range runProtocol(t, newChannelPools(3)) {
+		assert.Equal([]*big.Int{big.NewInt(60), big.NewInt(140)}, result)
+	}
+
+	network := NewNetwork(1, 3, newChannelPools(3)[0])
+	_, err := network.Exchange(nil)
+	assert.Equal(shamir.ErrorNetwork, err)
+}
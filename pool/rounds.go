@@ -0,0 +1,278 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TNO-MPC/shamir"
+)
+
+var (
+	ErrorInvalidPolicy = errors.New("Round policy needs a positive timeout and no negative retries")
+)
+
+// A RoundPolicy configures the rounds of a network returned by NewRoundNetwork.
+type RoundPolicy struct {
+	// Timeout is the time to wait for the messages of a round, and again after every retry. It must
+	// be positive.
+	Timeout time.Duration
+	// Retries is the number of times the messages of a round are sent again to the parties that
+	// have not been heard from, before the round is aborted.
+	Retries int
+}
+
+// An AbortError is returned by Exchange when a round is aborted, and identifies the parties to
+// blame where possible. After an abort, every further round fails with the same error.
+type AbortError struct {
+	// Round is the number of the aborted round, starting at 1.
+	Round int
+	// Missing contains the parties whose messages did not arrive in time, or whose connection
+	// failed.
+	Missing []int
+	// Malformed contains the parties that sent messages that could not be decoded.
+	Malformed []int
+	// AbortedBy is the party that aborted the round, or 0 if this party aborted it.
+	AbortedBy int
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (e *AbortError) Error() string {
+	switch {
+	case e.AbortedBy != 0:
+		return fmt.Sprintf("Round %d aborted by party %d", e.Round, e.AbortedBy)
+	case len(e.Malformed) > 0:
+		return fmt.Sprintf("Round %d aborted after malformed messages from parties %v", e.Round, e.Malformed)
+	}
+	return fmt.Sprintf("Round %d aborted without messages from parties %v", e.Round, e.Missing)
+}
+
+// Unwrap returns the underlying error.
+func (e *AbortError) Unwrap() error {
+	return e.Err
+}
+
+// NewRoundNetwork is like NewNetwork, but tolerates dropped and delayed messages. Messages carry
+// the number of their round, so that duplicates are ignored. A party that has not heard from some
+// parties within the timeout of the policy sends its message to them again, which also asks them to
+// send theirs again, since either message may have been lost. When the retries are exhausted, the
+// party aborts the round, tells the other parties, and returns an AbortError; the other parties
+// then return an AbortError as well. It returns ErrorInvalidPolicy if the timeout of the policy is
+// not positive or its number of retries is negative.
+//
+// The network receives from the pool in the background from its first round on, so the pool must
+// not be used for anything else afterwards. All parties must use a round network.
+func NewRoundNetwork(x int, nParties int, pool Pool, policy RoundPolicy) (shamir.Network, error) {
+	if policy.Timeout <= 0 || policy.Retries < 0 {
+		return nil, ErrorInvalidPolicy
+	}
+	return &roundNetwork{
+		x:        x,
+		nParties: nParties,
+		pool:     pool,
+		policy:   policy,
+		inbox:    make(chan inbound, 4*nParties),
+		done:     make(chan struct{}),
+		early:    make(map[int]message),
+		locks:    make([]sync.Mutex, nParties),
+	}, nil
+}
+
+// inbound is a message received from a party, or the error that occurred receiving it.
+type inbound struct {
+	from      int
+	message   message
+	err       error
+	malformed bool
+}
+
+type roundNetwork struct {
+	x        int
+	nParties int
+	pool     Pool
+	policy   RoundPolicy
+	start    sync.Once
+	inbox    chan inbound
+	// done is closed when the network aborts, to stop receiving
+	done chan struct{}
+	stop sync.Once
+	// early contains the messages of parties that are already in the next round
+	early map[int]message
+	// locks serialize the messages sent to every party
+	locks   []sync.Mutex
+	round   int
+	current [][]shamir.NestedShare
+	// previous are the messages of the previous round, for parties that still wait for them
+	previous [][]shamir.NestedShare
+	aborted  error
+}
+
+func (n *roundNetwork) Exchange(outgoing [][]shamir.NestedShare) ([][]shamir.NestedShare, error) {
+	if n.aborted != nil {
+		return nil, n.aborted
+	}
+	if len(outgoing) != n.nParties {
+		return nil, shamir.ErrorNetwork
+	}
+	n.start.Do(n.receive)
+	n.round++
+	n.previous, n.current = n.current, outgoing
+
+	var wg sync.WaitGroup
+	for i := range outgoing {
+		if i+1 != n.x {
+			wg.Add(1)
+			go func(to int) {
+				defer wg.Done()
+				n.send(to, message{Shares: n.current[to-1], Round: n.round})
+			}(i + 1)
+		}
+	}
+	wg.Wait()
+
+	received := make([][]shamir.NestedShare, n.nParties)
+	heard := make([]bool, n.nParties)
+	received[n.x-1], heard[n.x-1] = outgoing[n.x-1], true
+	remaining := n.nParties - 1
+	for from, m := range n.early {
+		received[from-1], heard[from-1] = m.Shares, true
+		remaining--
+		delete(n.early, from)
+	}
+	timer := time.NewTimer(n.policy.Timeout)
+	defer timer.Stop()
+	for retries := 0; remaining > 0; {
+		select {
+		case in := <-n.inbox:
+			switch {
+			case in.malformed:
+				return nil, n.abort(&AbortError{Malformed: []int{in.from}, Err: in.err})
+			case in.err != nil:
+				return nil, n.abort(&AbortError{Missing: []int{in.from}, Err: in.err})
+			case in.message.Abort:
+				n.aborted = &AbortError{Round: n.round, AbortedBy: in.from}
+				n.stopReceiving()
+				return nil, n.aborted
+			case in.message.Round == n.round+1:
+				n.early[in.from] = in.message
+			case in.message.Round == n.round:
+				// A retry means that the sender has not received our message either
+				if in.message.Retry {
+					go n.send(in.from, message{Shares: n.current[in.from-1], Round: n.round})
+				}
+				if !heard[in.from-1] {
+					received[in.from-1], heard[in.from-1] = in.message.Shares, true
+					remaining--
+				}
+			case in.message.Retry && in.message.Round == n.round-1:
+				go n.send(in.from, message{Shares: n.previous[in.from-1], Round: n.round - 1})
+			}
+		case <-timer.C:
+			var missing []int
+			for i := range heard {
+				if !heard[i] {
+					missing = append(missing, i+1)
+				}
+			}
+			if retries == n.policy.Retries {
+				return nil, n.abort(&AbortError{Missing: missing})
+			}
+			retries++
+			for _, to := range missing {
+				go n.send(to, message{Shares: n.current[to-1], Round: n.round, Retry: true})
+			}
+			timer.Reset(n.policy.Timeout)
+		}
+	}
+	return received, nil
+}
+
+// receive starts receiving the messages of every other party into the inbox.
+func (n *roundNetwork) receive() {
+	for from := 1; from <= n.nParties; from++ {
+		if from == n.x {
+			continue
+		}
+		go func(from int) {
+			for {
+				var in inbound
+				encoded, err := n.pool.Receive(from)
+				if err != nil {
+					in = inbound{from: from, err: err}
+				} else if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&in.message); err != nil {
+					in = inbound{from: from, err: err, malformed: true}
+				} else {
+					in.from = from
+				}
+				select {
+				case <-n.done:
+					return
+				default:
+				}
+				select {
+				case n.inbox <- in:
+				case <-n.done:
+					return
+				}
+				if in.err != nil && !in.malformed {
+					return
+				}
+			}
+		}(from)
+	}
+}
+
+// stopReceiving stops the goroutines started by receive once they received their next message, so
+// that they do not block on a full inbox after an abort.
+func (n *roundNetwork) stopReceiving() {
+	n.stop.Do(func() { close(n.done) })
+}
+
+// send sends a message to a party. Errors are not reported, since the recipient asks for the
+// message again, and aborts the round eventually.
+func (n *roundNetwork) send(to int, m message) {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(m); err != nil {
+		return
+	}
+	n.locks[to-1].Lock()
+	defer n.locks[to-1].Unlock()
+	n.pool.Send(to, buffer.Bytes())
+}
+
+// abort aborts the current round and tells the other parties.
+func (n *roundNetwork) abort(err *AbortError) error {
+	err.Round = n.round
+	n.aborted = err
+	n.stopReceiving()
+	var wg sync.WaitGroup
+	for to := 1; to <= n.nParties; to++ {
+		if to != n.x {
+			wg.Add(1)
+			go func(to int) {
+				defer wg.Done()
+				n.send(to, message{Round: n.round, Abort: true})
+			}(to)
+		}
+	}
+	wg.Wait()
+	return err
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+// lossyPool drops the first drops[to] messages sent to every party.
+type lossyPool struct {
+	Pool
+	mutex sync.Mutex
+	drops map[int]int
+}
+
+func (p *lossyPool) Send(to int, message []byte) error {
+	p.mutex.Lock()
+	drop := p.drops[to] > 0
+	p.drops[to]--
+	p.mutex.Unlock()
+	if drop {
+		return nil
+	}
+	return p.Pool.Send(to, message)
+}
+
+// newRoundNetwork returns a round network with a valid policy.
+func newRoundNetwork(t *testing.T, x int, nParties int, pool Pool, policy RoundPolicy) shamir.Network {
+	network, err := NewRoundNetwork(x, nParties, pool, policy)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return network
+}
+
+func TestRoundNetwork(t *testing.T) {
+	assert := assert.New(t)
+	pools := newChannelPools(3)
+	pools[0] = &lossyPool{Pool: pools[0], drops: map[int]int{2: 1, 3: 2}}
+	pools[2] = &lossyPool{Pool: pools[2], drops: map[int]int{1: 1}}
+	policy := RoundPolicy{Timeout: 20 * time.Millisecond, Retries: 5}
+	networks := make([]shamir.Network, len(pools))
+	for i := range pools {
+		networks[i] = newRoundNetwork(t, i+1, len(pools), pools[i], policy)
+	}
+	results, errs := runParties(networks)
+	for i := range results {
+		assert.NoError(errs[i])
+		assert.Equal([]*big.Int{big.NewInt(60), big.NewInt(140)}, results[i])
+	}
+
+	_, err := networks[0].Exchange(nil)
+	assert.Equal(shamir.ErrorNetwork, err)
+}
+
+func TestRoundNetworkAbort(t *testing.T) {
+	assert := assert.New(t)
+	outgoing := make([][]shamir.NestedShare, 3)
+
+	// Party 3 is silent, so party 1 aborts, and party 2 with a longer timeout learns about it
+	pools := newChannelPools(3)
+	networks := []shamir.Network{
+		newRoundNetwork(t, 1, 3, pools[0], RoundPolicy{Timeout: 10 * time.Millisecond, Retries: 2}),
+		newRoundNetwork(t, 2, 3, pools[1], RoundPolicy{Timeout: time.Minute}),
+	}
+	errs := make([]error, len(networks))
+	var wg sync.WaitGroup
+	for i := range networks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = networks[i].Exchange(outgoing)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(&AbortError{Round: 1, Missing: []int{3}}, errs[0])
+	assert.Equal(&AbortError{Round: 1, AbortedBy: 1}, errs[1])
+	assert.Equal("Round 1 aborted without messages from parties [3]", errs[0].Error())
+	assert.Equal("Round 1 aborted by party 1", errs[1].Error())
+	_, err := networks[0].Exchange(outgoing)
+	assert.Equal(errs[0], err)
+
+	// Malformed messages and failing connections are blamed on the sender
+	pools = newChannelPools(3)
+	network := newRoundNetwork(t, 1, 3, pools[0], RoundPolicy{Timeout: time.Minute})
+	pools[1].Send(1, []byte("garbage"))
+	_, err = network.Exchange(outgoing)
+	var abort *AbortError
+	assert.True(errors.As(err, &abort))
+	assert.Equal([]int{2}, abort.Malformed)
+	assert.Equal("Round 1 aborted after malformed messages from parties [2]", err.Error())
+
+	failure := errors.New("connection lost")
+	network = newRoundNetwork(t, 1, 2, failingPool{failure}, RoundPolicy{Timeout: time.Minute})
+	_, err = network.Exchange(make([][]shamir.NestedShare, 2))
+	assert.True(errors.Is(err, failure))
+	assert.True(errors.As(err, &abort))
+	assert.Equal([]int{2}, abort.Missing)
+}
+
+func TestRoundNetworkRetryFromUnheardParty(t *testing.T) {
+	assert := assert.New(t)
+	// Both first messages are lost. Party 2 never retries itself, so party 1 only completes the round
+	// if party 2 answers its retry with its own message.
+	pools := newChannelPools(2)
+	pools[0] = &lossyPool{Pool: pools[0], drops: map[int]int{2: 1}}
+	pools[1] = &lossyPool{Pool: pools[1], drops: map[int]int{1: 1}}
+	networks := []shamir.Network{
+		newRoundNetwork(t, 1, 2, pools[0], RoundPolicy{Timeout: 10 * time.Millisecond, Retries: 5}),
+		newRoundNetwork(t, 2, 2, pools[1], RoundPolicy{Timeout: time.Minute}),
+	}
+	share := func(x int) []shamir.NestedShare {
+		return []shamir.NestedShare{{Share: shamir.Share{X: x, Y: big.NewInt(int64(x))}}}
+	}
+	received := make([][][]shamir.NestedShare, len(networks))
+	errs := make([]error, len(networks))
+	var wg sync.WaitGroup
+	for i := range networks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			received[i], errs[i] = networks[i].Exchange([][]shamir.NestedShare{share(i + 1), share(i + 1)})
+		}(i)
+	}
+	wg.Wait()
+	for i := range networks {
+		if assert.NoError(errs[i]) {
+			assert.Equal(int64(2-i), received[i][1-i][0].Y.Int64())
+		}
+	}
+}
+
+func TestRoundNetworkPolicy(t *testing.T) {
+	assert := assert.New(t)
+	pools := newChannelPools(2)
+	_, err := NewRoundNetwork(1, 2, pools[0], RoundPolicy{})
+	assert.Equal(ErrorInvalidPolicy, err)
+	_, err = NewRoundNetwork(1, 2, pools[0], RoundPolicy{Timeout: time.Second, Retries: -1})
+	assert.Equal(ErrorInvalidPolicy, err)
+}
+
+func TestRoundNetworkStopsReceiving(t *testing.T) {
+	assert := assert.New(t)
+	pools := newChannelPools(2)
+	network := newRoundNetwork(t, 1, 2, pools[0], RoundPolicy{Timeout: 10 * time.Millisecond})
+	_, err := network.Exchange(make([][]shamir.NestedShare, 2))
+	assert.Equal(&AbortError{Round: 1, Missing: []int{2}}, err)
+
+	// After the abort, the network takes at most the message it was already waiting for
+	incoming := pools[1].(*channelPool).channels[1][0]
+	for i := 0; i < 3; i++ {
+		pools[1].Send(1, []byte("late"))
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(2, len(incoming))
+}
+
+// failingPool is a Pool whose connections have failed.
+type failingPool struct {
+	err error
+}
+
+func (p failingPool) Send(to int, message []byte) error {
+	return p.err
+}
+
+func (p failingPool) Receive(from int) ([]byte, error) {
+	return nil, p.err
+}
+
+func (p failingPool) Broadcast(message []byte) error {
+	return p.err
+}
@@ -0,0 +1,265 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	ErrorMessageTooLarge = errors.New("Message exceeds the maximum message size")
+)
+
+// MaxMessageSize is the maximum size of a message received by a TCPPool, in bytes.
+var MaxMessageSize = 1 << 30
+
+// dialInterval is the time between attempts to connect to a party that is not listening yet.
+const dialInterval = 100 * time.Millisecond
+
+// handshakeTimeout is the time in which a connecting party must complete the TLS handshake, if any,
+// and announce its X coordinate.
+var handshakeTimeout = 10 * time.Second
+
+// A TCPPool is a Pool in which every pair of parties is connected by a TCP connection. Messages are
+// framed with a 4-byte big-endian length.
+//
+// Every party listens for the parties with lower X coordinates and connects to the parties with
+// higher X coordinates, announcing its own X coordinate. Connections that do not announce an
+// expected X coordinate in time are dropped, so that stray connections to the listener cannot
+// prevent the parties from connecting. With a nil TLS configuration, these
+// announcements are not authenticated, so plain TCP should only be used on trusted networks. With
+// TLS, the configuration should require and verify client certificates, and the caller should check
+// that the certificates of the peers match their X coordinates, for instance via
+// tls.Config.VerifyConnection.
+type TCPPool struct {
+	x     int
+	conns []*connection
+}
+
+type connection struct {
+	conn      net.Conn
+	sendMutex sync.Mutex
+	recvMutex sync.Mutex
+}
+
+// NewTCPPool connects the party with X coordinate x to the other parties, where addresses[i] is the
+// address of the party with X coordinate i+1, and listener listens on the address of this party. It
+// keeps trying to connect to parties that are not listening yet until ctx is done. If tlsConfig is
+// not nil, all connections are secured with TLS. The listener is only used while connecting, and is
+// closed if connecting fails.
+func NewTCPPool(ctx context.Context, x int, listener net.Listener, addresses []string, tlsConfig *tls.Config) (*TCPPool, error) {
+	if x < 1 || x > len(addresses) {
+		return nil, ErrorUnknownParty
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p := &TCPPool{x: x, conns: make([]*connection, len(addresses))}
+	errs := make(chan error, 2)
+	go func() {
+		errs <- p.accept(listener, tlsConfig)
+	}()
+	go func() {
+		errs <- p.dial(ctx, addresses, tlsConfig)
+	}()
+
+	// On failure, stop dialing and unblock Accept by closing the listener
+	var err error
+	done := ctx.Done()
+	for pending := 2; pending > 0; {
+		select {
+		case e := <-errs:
+			pending--
+			if e != nil && err == nil {
+				err = e
+				cancel()
+			}
+		case <-done:
+			done = nil
+			listener.Close()
+		}
+	}
+	if err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// accept accepts the connections of the parties with lower X coordinates, dropping connections
+// with an invalid or late handshake.
+func (p *TCPPool) accept(listener net.Listener, tlsConfig *tls.Config) error {
+	for remaining := p.x - 1; remaining > 0; {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		if tlsConfig != nil {
+			conn = tls.Server(conn, tlsConfig)
+		}
+		from, ok := p.handshake(conn)
+		if !ok {
+			conn.Close()
+			continue
+		}
+		p.conns[from-1] = &connection{conn: conn}
+		remaining--
+	}
+	return nil
+}
+
+// handshake reads the X coordinate announced by a connecting party within handshakeTimeout, and
+// reports whether it is that of a party with a lower X coordinate that has not connected yet.
+func (p *TCPPool) handshake(conn net.Conn) (int, bool) {
+	if err := conn.SetReadDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return 0, false
+	}
+	var buffer [4]byte
+	if _, err := io.ReadFull(conn, buffer[:]); err != nil {
+		return 0, false
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return 0, false
+	}
+	from := int(binary.BigEndian.Uint32(buffer[:]))
+	if from < 1 || from >= p.x || p.conns[from-1] != nil {
+		return 0, false
+	}
+	return from, true
+}
+
+// dial connects to the parties with higher X coordinates.
+func (p *TCPPool) dial(ctx context.Context, addresses []string, tlsConfig *tls.Config) error {
+	var dialer net.Dialer
+	for to := p.x + 1; to <= len(addresses); to++ {
+		var conn net.Conn
+		for {
+			var err error
+			conn, err = dialer.DialContext(ctx, "tcp", addresses[to-1])
+			if err == nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(dialInterval):
+			}
+		}
+		if tlsConfig != nil {
+			// Like tls.Dial, verify the host name of the address unless a server name is configured
+			config := tlsConfig
+			if config.ServerName == "" {
+				host, _, err := net.SplitHostPort(addresses[to-1])
+				if err != nil {
+					conn.Close()
+					return err
+				}
+				config = config.Clone()
+				config.ServerName = host
+			}
+			conn = tls.Client(conn, config)
+		}
+		var buffer [4]byte
+		binary.BigEndian.PutUint32(buffer[:], uint32(p.x))
+		if _, err := conn.Write(buffer[:]); err != nil {
+			conn.Close()
+			return err
+		}
+		p.conns[to-1] = &connection{conn: conn}
+	}
+	return nil
+}
+
+// Send sends a message to the party with X coordinate to.
+func (p *TCPPool) Send(to int, message []byte) error {
+	c, err := p.connection(to)
+	if err != nil {
+		return err
+	}
+	if len(message) > MaxMessageSize {
+		return ErrorMessageTooLarge
+	}
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+	var buffer [4]byte
+	binary.BigEndian.PutUint32(buffer[:], uint32(len(message)))
+	if _, err := c.conn.Write(buffer[:]); err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	return err
+}
+
+// Receive returns the next message from the party with X coordinate from.
+func (p *TCPPool) Receive(from int) ([]byte, error) {
+	c, err := p.connection(from)
+	if err != nil {
+		return nil, err
+	}
+	c.recvMutex.Lock()
+	defer c.recvMutex.Unlock()
+	var buffer [4]byte
+	if _, err := io.ReadFull(c.conn, buffer[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(buffer[:])
+	if uint64(length) > uint64(MaxMessageSize) {
+		return nil, ErrorMessageTooLarge
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(c.conn, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// Broadcast sends a message to all other parties.
+func (p *TCPPool) Broadcast(message []byte) error {
+	for i := range p.conns {
+		if i+1 == p.x {
+			continue
+		}
+		if err := p.Send(i+1, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the connections to all parties.
+func (p *TCPPool) Close() error {
+	var err error
+	for _, c := range p.conns {
+		if c != nil {
+			if e := c.conn.Close(); err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+func (p *TCPPool) connection(x int) (*connection, error) {
+	if x < 1 || x > len(p.conns) || p.conns[x-1] == nil {
+		return nil, ErrorUnknownParty
+	}
+	return p.conns[x-1], nil
+}
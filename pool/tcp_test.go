@@ -0,0 +1,191 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// connectTCPPools connects nParties parties on the loopback interface.
+func connectTCPPools(t *testing.T, nParties int, tlsConfig *tls.Config) []*TCPPool {
+	listeners := make([]net.Listener, nParties)
+	addresses := make([]string, nParties)
+	for i := range listeners {
+		var err error
+		listeners[i], err = net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		defer listeners[i].Close()
+		addresses[i] = listeners[i].Addr().String()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	pools := make([]*TCPPool, nParties)
+	errs := make([]error, nParties)
+	var wg sync.WaitGroup
+	for i := range pools {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pools[i], errs[i] = NewTCPPool(ctx, i+1, listeners[i], addresses, tlsConfig)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	return pools
+}
+
+func TestTCPPool(t *testing.T) {
+	assert := assert.New(t)
+	pools := connectTCPPools(t, 3, nil)
+	defer func() {
+		for _, p := range pools {
+			p.Close()
+		}
+	}()
+
+	assert.NoError(pools[1].Broadcast([]byte("hello")))
+	for _, x := range []int{0, 2} {
+		message, err := pools[x].Receive(2)
+		assert.NoError(err)
+		assert.Equal([]byte("hello"), message)
+	}
+	assert.NoError(pools[0].Send(3, nil))
+	message, err := pools[2].Receive(1)
+	assert.NoError(err)
+	assert.Empty(message)
+
+	assert.Equal(ErrorUnknownParty, pools[0].Send(1, nil))
+	assert.Equal(ErrorUnknownParty, pools[0].Send(4, nil))
+
+	for _, result := range runProtocol(t, []Pool{pools[0], pools[1], pools[2]}) {
+		assert.Equal([]*big.Int{big.NewInt(60), big.NewInt(140)}, result)
+	}
+}
+
+func TestTCPPoolTLS(t *testing.T) {
+	assert := assert.New(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "party"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(err)
+	roots := x509.NewCertPool()
+	roots.AddCert(certificate)
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		RootCAs:      roots,
+		ClientCAs:    roots,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+
+	pools := connectTCPPools(t, 3, tlsConfig)
+	defer func() {
+		for _, p := range pools {
+			p.Close()
+		}
+	}()
+	for _, result := range runProtocol(t, []Pool{pools[0], pools[1], pools[2]}) {
+		assert.Equal([]*big.Int{big.NewInt(60), big.NewInt(140)}, result)
+	}
+}
+
+func TestTCPPoolStrayConnections(t *testing.T) {
+	assert := assert.New(t)
+	defer func(timeout time.Duration) { handshakeTimeout = timeout }(handshakeTimeout)
+	handshakeTimeout = 100 * time.Millisecond
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	// A connection that never announces itself, and one that announces an unexpected party
+	silent, err := net.Dial("tcp", listener.Addr().String())
+	assert.NoError(err)
+	defer silent.Close()
+	unexpected, err := net.Dial("tcp", listener.Addr().String())
+	assert.NoError(err)
+	defer unexpected.Close()
+	_, err = unexpected.Write([]byte{0, 0, 0, 7})
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	addresses := []string{"", listener.Addr().String()}
+	pools := make([]*TCPPool, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		pools[1], errs[1] = NewTCPPool(ctx, 2, listener, addresses, nil)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	first, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	defer first.Close()
+	pools[0], errs[0] = NewTCPPool(ctx, 1, first, addresses, nil)
+	wg.Wait()
+	if !assert.NoError(errs[0]) || !assert.NoError(errs[1]) {
+		return
+	}
+	defer pools[0].Close()
+	defer pools[1].Close()
+	assert.NoError(pools[0].Send(2, []byte("hello")))
+	message, err := pools[1].Receive(1)
+	assert.NoError(err)
+	assert.Equal([]byte("hello"), message)
+}
+
+func TestTCPPoolTimeout(t *testing.T) {
+	assert := assert.New(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	// Party 2 never connects
+	_, err = NewTCPPool(ctx, 1, listener, []string{listener.Addr().String(), "127.0.0.1:1"}, nil)
+	assert.Equal(context.DeadlineExceeded, err)
+	_, err = NewTCPPool(ctx, 3, listener, []string{"", ""}, nil)
+	assert.Equal(ErrorUnknownParty, err)
+}
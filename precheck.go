@@ -0,0 +1,149 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// n shares of degree t are consistent exactly when the syndromes
+//
+//	s_k = sum_i y_i x_i^k / prod_{j != i} (x_i - x_j),  k = 0, ..., n-t-2
+//
+// are all zero, since these are the inner products with a basis of the dual of the Reed-Solomon
+// code. If a single share i is wrong by e, then s_k = e x_i^k / prod_{j != i} (x_i - x_j), so the
+// ratio of consecutive syndromes is the X coordinate of the wrong share.
+
+import (
+	"math/big"
+)
+
+// CheckShares cheaply checks whether shares with more than degree+1 shares lie on a single
+// polynomial of their degree, before paying for a full reconstruction. It returns nil if they do,
+// or if there are no surplus shares to check against. Otherwise it returns ErrorInconsistentShares
+// and the X coordinates of the suspect shares: the single wrong share if the syndromes point at
+// one, and all shares otherwise. Locating a single wrong share requires at least two surplus
+// shares. Shares that cannot be combined at all give the error of ShareCombine and no suspects.
+func CheckShares(shares []Share) ([]int, error) {
+	if err := checkCombinable(shares); err != nil {
+		return nil, err
+	}
+	for i := range shares {
+		if containsX(shares[:i], shares[i].X) {
+			return nil, ErrorDuplicateX
+		}
+	}
+	nSyndromes := len(shares) - shares[0].Degree - 1
+	if nSyndromes == 0 {
+		return nil, nil
+	}
+
+	var zero func(k int, x int64) bool
+	if fieldSize := shares[0].FieldSize; fieldSize != nil {
+		syndromes, err := fieldSyndromes(shares, nSyndromes)
+		if err != nil {
+			return nil, err
+		}
+		// zero reports whether s_{k+1} - x s_k = 0, or s_k = 0 for x = 0
+		zero = func(k int, x int64) bool {
+			if x == 0 {
+				return syndromes[k].Sign() == 0
+			}
+			d := big.NewInt(x)
+			d.Mul(d, syndromes[k]).Sub(syndromes[k+1], d).Mod(d, fieldSize)
+			return d.Sign() == 0
+		}
+	} else {
+		syndromes := integerSyndromes(shares, nSyndromes)
+		zero = func(k int, x int64) bool {
+			if x == 0 {
+				return syndromes[k].Sign() == 0
+			}
+			d := big.NewRat(x, 1)
+			return d.Mul(d, syndromes[k]).Sub(syndromes[k+1], d).Sign() == 0
+		}
+	}
+
+	consistent := true
+	for k := 0; k < nSyndromes; k++ {
+		consistent = consistent && zero(k, 0)
+	}
+	if consistent {
+		return nil, nil
+	}
+	if nSyndromes >= 2 {
+		for _, share := range shares {
+			located := true
+			for k := 0; k < nSyndromes-1; k++ {
+				located = located && zero(k, int64(share.X))
+			}
+			if located {
+				return []int{share.X}, ErrorInconsistentShares
+			}
+		}
+	}
+	suspects := make([]int, len(shares))
+	for i := range shares {
+		suspects[i] = shares[i].X
+	}
+	return suspects, ErrorInconsistentShares
+}
+
+// fieldSyndromes returns the first count syndromes of shares over a finite field.
+func fieldSyndromes(shares []Share, count int) ([]*big.Int, error) {
+	fieldSize := shares[0].FieldSize
+	syndromes := make([]*big.Int, count)
+	for k := range syndromes {
+		syndromes[k] = big.NewInt(0)
+	}
+	for i := range shares {
+		weight := big.NewInt(1)
+		for j := range shares {
+			if j != i {
+				weight.Mul(weight, big.NewInt(int64(shares[i].X-shares[j].X)))
+			}
+		}
+		weight.Mod(weight, fieldSize)
+		if weight.ModInverse(weight, fieldSize) == nil {
+			return nil, ErrorNotInvertible
+		}
+		weight.Mul(weight, shares[i].Y).Mod(weight, fieldSize)
+		x := big.NewInt(int64(shares[i].X))
+		for k := range syndromes {
+			syndromes[k].Add(syndromes[k], weight).Mod(syndromes[k], fieldSize)
+			weight.Mul(weight, x).Mod(weight, fieldSize)
+		}
+	}
+	return syndromes, nil
+}
+
+// integerSyndromes returns the first count syndromes of shares over the integers.
+func integerSyndromes(shares []Share, count int) []*big.Rat {
+	syndromes := make([]*big.Rat, count)
+	for k := range syndromes {
+		syndromes[k] = big.NewRat(0, 1)
+	}
+	for i := range shares {
+		weight := big.NewInt(1)
+		for j := range shares {
+			if j != i {
+				weight.Mul(weight, big.NewInt(int64(shares[i].X-shares[j].X)))
+			}
+		}
+		term := big.NewRat(0, 1).SetFrac(shares[i].Y, weight)
+		x := big.NewRat(int64(shares[i].X), 1)
+		for k := range syndromes {
+			syndromes[k].Add(syndromes[k], term)
+			term.Mul(term, x)
+		}
+	}
+	return syndromes
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckShares(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareFiniteField(big.NewInt(42), big.NewInt(7919), 2, 6)
+	suspects, err := CheckShares(shares)
+	assert.NoError(err)
+	assert.Nil(suspects)
+	suspects, err = CheckShares(shares[:3])
+	assert.NoError(err)
+	assert.Nil(suspects)
+
+	shares[4].Y = big.NewInt(0).Add(shares[4].Y, big.NewInt(1))
+	suspects, err = CheckShares(shares)
+	assert.Equal(ErrorInconsistentShares, err)
+	assert.Equal([]int{5}, suspects)
+
+	// With a single surplus share, the wrong share cannot be located
+	suspects, err = CheckShares(shares[2:])
+	assert.Equal(ErrorInconsistentShares, err)
+	assert.Equal([]int{3, 4, 5, 6}, suspects)
+
+	shares[0].Y = big.NewInt(0).Add(shares[0].Y, big.NewInt(1))
+	suspects, err = CheckShares(shares)
+	assert.Equal(ErrorInconsistentShares, err)
+	assert.Len(suspects, 6)
+
+	_, err = CheckShares([]Share{shares[1], shares[1], shares[2], shares[3]})
+	assert.Equal(ErrorDuplicateX, err)
+	_, err = CheckShares(shares[:2])
+	assert.Equal(ErrorTooFewShares, err)
+}
+
+func TestIntegerCheckShares(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareIntegers(big.NewInt(42), big.NewInt(100), 40, 1, 5)
+	suspects, err := CheckShares(shares)
+	assert.NoError(err)
+	assert.Nil(suspects)
+
+	shares[1].Y = big.NewInt(0).Sub(shares[1].Y, big.NewInt(7))
+	suspects, err = CheckShares(shares)
+	assert.Equal(ErrorInconsistentShares, err)
+	assert.Equal([]int{2}, suspects)
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+)
+
+// A SecurityProfile is a consistent choice of security parameters, so that users do not have to pick
+// them individually. StatSecParam is the statistical security parameter for sharing over the
+// integers. FieldSize is a prime field size for sharing over a finite field that is large enough for
+// the protocols in this package that rely on hashing or masking (such as Intersect and LessThan) to
+// reach a comparable level of security.
+type SecurityProfile struct {
+	Name         string
+	StatSecParam int
+	FieldSize    *big.Int
+}
+
+// Conservative128 returns the profile that provides 128 bits of statistical security and uses the
+// prime field of size 2^255 - 19. Every call returns a new FieldSize, which callers may modify.
+func Conservative128() SecurityProfile {
+	return SecurityProfile{
+		Name:         "Conservative128",
+		StatSecParam: 128,
+		FieldSize:    big.NewInt(0).Sub(big.NewInt(0).Lsh(big.NewInt(1), 255), big.NewInt(19)),
+	}
+}
+
+// Fast80 returns the profile that provides 80 bits of statistical security and uses the prime field
+// of size 2^127 - 1, which keeps shares small and computations fast. Every call returns a new
+// FieldSize, which callers may modify.
+func Fast80() SecurityProfile {
+	return SecurityProfile{
+		Name:         "Fast80",
+		StatSecParam: 80,
+		FieldSize:    big.NewInt(0).Sub(big.NewInt(0).Lsh(big.NewInt(1), 127), big.NewInt(1)),
+	}
+}
+
+// ShareFiniteField shares a secret over the finite field of the profile, see ShareFiniteField.
+func (p SecurityProfile) ShareFiniteField(secret *big.Int, degree int, nShares int) []Share {
+	return ShareFiniteField(secret, p.FieldSize, degree, nShares)
+}
+
+// ShareIntegers shares a secret over the integers with the statistical security of the profile,
+// see ShareIntegers.
+func (p SecurityProfile) ShareIntegers(secret *big.Int, secretUpperBound *big.Int, degree int, nShares int) []Share {
+	return ShareIntegers(secret, secretUpperBound, p.StatSecParam, degree, nShares)
+}
+
+// CoefficientBound returns the exclusive upper bound on the random coefficients used when sharing
+// secrets up to secretUpperBound over the integers with the profile.
+func (p SecurityProfile) CoefficientBound(secretUpperBound *big.Int, nShares int) *big.Int {
+	return integerCoefficientBound(secretUpperBound, p.StatSecParam, nShares)
+}
+
+// ShareBitLength estimates the number of bits of the largest share that ShareIntegers can produce
+// for the given parameters, including the sign bit. This is useful for sizing storage or messages.
+func ShareBitLength(secretUpperBound *big.Int, statSecParam int, degree int, nShares int) int {
+	// |f(x)| <= nShares! * secretUpperBound + sum(j = 1..degree) coefficientUpperBound * nShares^j
+	coefficientUpperBound := integerCoefficientBound(secretUpperBound, statSecParam, nShares)
+	bound := big.NewInt(0).Mul(factorial(int64(nShares)), secretUpperBound)
+	power := big.NewInt(1)
+	for j := 1; j <= degree; j++ {
+		power.Mul(power, big.NewInt(int64(nShares)))
+		bound.Add(bound, big.NewInt(0).Mul(coefficientUpperBound, power))
+	}
+	return bound.BitLen() + 1
+}
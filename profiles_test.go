@@ -0,0 +1,62 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityProfiles(t *testing.T) {
+	assert := assert.New(t)
+	for _, profile := range []SecurityProfile{Conservative128(), Fast80()} {
+		assert.True(profile.FieldSize.ProbablyPrime(20), profile.Name)
+
+		secret, err := ShareCombine(profile.ShareFiniteField(big.NewInt(42), 2, 5))
+		assert.NoError(err)
+		assert.Equal(big.NewInt(42), secret)
+
+		shares := profile.ShareIntegers(big.NewInt(-42), big.NewInt(1000), 2, 5)
+		secret, err = ShareCombine(shares)
+		assert.NoError(err)
+		assert.Equal(big.NewInt(-42), secret)
+
+		bound := profile.CoefficientBound(big.NewInt(1000), 5)
+		assert.True(bound.BitLen() > profile.StatSecParam)
+	}
+	assert.Equal(128, Conservative128().StatSecParam)
+	assert.Equal(80, Fast80().StatSecParam)
+
+	// Profiles cannot be modified through their field sizes
+	Conservative128().FieldSize.SetInt64(7919)
+	assert.True(Conservative128().FieldSize.ProbablyPrime(20))
+	assert.Equal(255, Conservative128().FieldSize.BitLen())
+}
+
+func TestShareBitLength(t *testing.T) {
+	assert := assert.New(t)
+	upperBound := big.NewInt(1 << 20)
+	bits := ShareBitLength(upperBound, 40, 3, 7)
+	for i := 0; i < 10; i++ {
+		for _, share := range ShareIntegers(upperBound, upperBound, 40, 3, 7) {
+			assert.True(share.Y.BitLen()+1 <= bits)
+		}
+	}
+	// The estimate is not far off
+	assert.True(bits < 40+20+2*3+3*3+4)
+	assert.True(ShareBitLength(upperBound, 80, 3, 7) > bits)
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The zero sharings in this file are pseudo-random secret sharings after Cramer, Damgard and Ishai,
+// "Share conversion, pseudorandom secret-sharing and applications to secure computation" (TCC
+// 2005). For every coalition T of degree-1 parties, the parties outside T share a key r_T. The
+// polynomial g_T(X) = X * prod_(j in T) (X - j) has degree at most degree and vanishes at 0 and at
+// the X coordinates in T, so the parties outside T can evaluate PRF(r_T, nonce) * g_T, and the
+// parties in T, which do not know r_T, contribute nothing to it. The sum over all coalitions is a
+// sharing of zero of the given degree, which every party computes locally from its keys and a nonce.
+// Since the coalitions have degree-1 parties, the zero sharings are pseudo-random to coalitions of
+// up to degree-1 parties, one fewer than the interactive Party.Refresh tolerates.
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+)
+
+// prssSalt is the HKDF salt used for deriving pseudo-random field elements from PRSS keys.
+var prssSalt = []byte("github.com/TNO-MPC/shamir prss")
+
+// prssKeySize is the size in bytes of PRSS keys.
+const prssKeySize = 32
+
+// A PRSSKey is a key shared by all parties outside a coalition.
+type PRSSKey struct {
+	// Coalition contains the X coordinates of the parties that do not know the key.
+	Coalition []int
+	Key       []byte
+}
+
+// PRSSKeys are the keys of a single party for generating sharings of zero without communication,
+// for refreshing shares over a finite field of the given degree among nParties parties. They are
+// long-term secrets, and should be stored as securely as shares: a coalition that ever learns the
+// keys of degree parties can predict all zero sharings, and undo every refresh based on them. For
+// nParties parties, a party holds binomial(nParties-1, degree-1) keys, so this is practical for
+// large committees only with a small degree.
+type PRSSKeys struct {
+	X         int
+	NParties  int
+	Degree    int
+	FieldSize *big.Int
+	Keys      []PRSSKey
+}
+
+// DealPRSSKeys generates the PRSS keys of nParties parties for shares of the given degree over the
+// finite field of integers modulo fieldSize, for a trusted dealer. Use Party.SetupPRSS to generate
+// them without a dealer.
+func DealPRSSKeys(fieldSize *big.Int, degree int, nParties int) ([]*PRSSKeys, error) {
+	if degree < 1 || nParties <= degree {
+		return nil, ErrorTooFewParties
+	}
+	keys := make([]*PRSSKeys, nParties)
+	for i := range keys {
+		keys[i] = &PRSSKeys{X: i + 1, NParties: nParties, Degree: degree, FieldSize: fieldSize}
+	}
+	var err error
+	forEachCoalition(nParties, degree-1, func(coalition []int) {
+		key := make([]byte, prssKeySize)
+		if _, e := rand.Read(key); e != nil {
+			err = e
+			return
+		}
+		for _, k := range keys {
+			if !containsInt(coalition, k.X) {
+				k.Keys = append(k.Keys, PRSSKey{Coalition: coalition, Key: key})
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// SetupPRSS generates the PRSS keys of the party together with the other parties, in a single
+// round: the key of every coalition is chosen by the party with the lowest X coordinate outside it
+// and sent to the other parties outside it. The keys are only secret if the links of the Network
+// are confidential.
+func (p *Party) SetupPRSS() (*PRSSKeys, error) {
+	if p.degree < 1 || p.nParties <= p.degree {
+		return nil, ErrorTooFewParties
+	}
+	keys := &PRSSKeys{X: p.x, NParties: p.nParties, Degree: p.degree, FieldSize: p.fieldSize}
+	outgoing := make([][]NestedShare, p.nParties)
+	var err error
+	index := 0
+	forEachCoalition(p.nParties, p.degree-1, func(coalition []int) {
+		index++
+		if prssLeader(coalition) != p.x {
+			return
+		}
+		key := make([]byte, prssKeySize)
+		if _, e := rand.Read(key); e != nil {
+			err = e
+			return
+		}
+		keys.Keys = append(keys.Keys, PRSSKey{Coalition: coalition, Key: key})
+		for to := p.x + 1; to <= p.nParties; to++ {
+			if !containsInt(coalition, to) {
+				message := Share{X: index, Y: big.NewInt(0).SetBytes(key)}
+				outgoing[to-1] = append(outgoing[to-1], NestedShare{Share: message})
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	received, err := p.exchange(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	if len(received) != p.nParties {
+		return nil, ErrorNetwork
+	}
+
+	// Every party receives the keys of its coalitions from their leaders in the order of the
+	// coalitions
+	next := make([]int, p.nParties)
+	index = 0
+	forEachCoalition(p.nParties, p.degree-1, func(coalition []int) {
+		index++
+		leader := prssLeader(coalition)
+		if leader == p.x || containsInt(coalition, p.x) || err != nil {
+			return
+		}
+		messages := received[leader-1]
+		if next[leader-1] >= len(messages) || messages[next[leader-1]].X != index || messages[next[leader-1]].Y == nil ||
+			messages[next[leader-1]].Y.BitLen() > 8*prssKeySize {
+			err = ErrorNetwork
+			return
+		}
+		key := messages[next[leader-1]].Y.FillBytes(make([]byte, prssKeySize))
+		next[leader-1]++
+		keys.Keys = append(keys.Keys, PRSSKey{Coalition: coalition, Key: key})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ZeroShare returns the share of the party of a pseudo-random sharing of zero for nonce. All
+// parties must use the same nonce, which must never be reused, for instance a counter of refresh
+// epochs.
+func (k *PRSSKeys) ZeroShare(nonce []byte) Share {
+	x := big.NewInt(int64(k.X))
+	y := big.NewInt(0)
+	for _, key := range k.Keys {
+		// PRF(r_T, nonce) * g_T(x)
+		term := k.pseudoRandom(key.Key, nonce)
+		term.Mul(term, x)
+		for _, j := range key.Coalition {
+			term.Mul(term, big.NewInt(int64(k.X-j))).Mod(term, k.FieldSize)
+		}
+		y.Add(y, term)
+	}
+	return Share{FieldSize: k.FieldSize, Degree: k.Degree, X: k.X, Y: y.Mod(y, k.FieldSize)}
+}
+
+// Refresh re-randomizes the shares of v like Party.Refresh, but without communication, by adding a
+// pseudo-random sharing of zero for every element, derived from nonce and the position of the
+// element. All parties must call it with the same nonce, which must never be reused.
+func (k *PRSSKeys) Refresh(v ShareVector, nonce []byte) (ShareVector, error) {
+	zeros := make(ShareVector, len(v))
+	for j := range zeros {
+		elementNonce := make([]byte, len(nonce)+4)
+		copy(elementNonce, nonce)
+		binary.BigEndian.PutUint32(elementNonce[len(nonce):], uint32(j))
+		zeros[j] = k.ZeroShare(elementNonce)
+	}
+	return ShareVectorAdd([]ShareVector{v, zeros})
+}
+
+// pseudoRandom derives a pseudo-random field element from key and nonce with HKDF-SHA256, 16 bytes
+// longer than the field size to make the modulo bias negligible.
+func (k *PRSSKeys) pseudoRandom(key []byte, nonce []byte) *big.Int {
+	length := (k.FieldSize.BitLen()+7)/8 + 16
+	value := big.NewInt(0).SetBytes(hkdfExpand(hkdfExtract(prssSalt, key), nonce, length))
+	return value.Mod(value, k.FieldSize)
+}
+
+// forEachCoalition calls f for every set of size parties out of the X coordinates 1 to nParties, in
+// lexicographic order. The sets are fresh slices.
+func forEachCoalition(nParties int, size int, f func(coalition []int)) {
+	searchQuorums(nParties, size, func(quorum []int) bool {
+		coalition := make([]int, len(quorum))
+		for i, j := range quorum {
+			coalition[i] = j + 1
+		}
+		f(coalition)
+		return false
+	})
+}
+
+// prssLeader returns the lowest X coordinate outside coalition, whose party chooses its key.
+func prssLeader(coalition []int) int {
+	x := 1
+	for containsInt(coalition, x) {
+		x++
+	}
+	return x
+}
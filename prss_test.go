@@ -0,0 +1,106 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPRSSZeroShare(t *testing.T) {
+	assert := assert.New(t)
+	keys, err := DealPRSSKeys(mersenne61, 2, 5)
+	assert.NoError(err)
+	// Each party holds the keys of the coalitions of one party that it is not in
+	assert.Len(keys[0].Keys, 4)
+
+	zeros := make([]Share, len(keys))
+	for i := range keys {
+		zeros[i] = keys[i].ZeroShare([]byte("epoch 1"))
+		assert.Equal(2, zeros[i].Degree)
+		assert.NotEqual(int64(0), zeros[i].Y.Int64())
+	}
+	// Every quorum gives zero, so the shares lie on a single polynomial of the degree
+	for _, quorum := range [][]Share{zeros[:3], zeros[2:], {zeros[0], zeros[2], zeros[4]}} {
+		secret, err := ShareCombine(quorum)
+		assert.NoError(err)
+		assert.Equal(int64(0), secret.Int64())
+	}
+	assert.NotEqual(zeros[0].Y, keys[0].ZeroShare([]byte("epoch 2")).Y)
+
+	_, err = DealPRSSKeys(mersenne61, 2, 2)
+	assert.Equal(ErrorTooFewParties, err)
+}
+
+func TestPRSSRefresh(t *testing.T) {
+	assert := assert.New(t)
+	keys := make([]*PRSSKeys, 4)
+	results := runParties(t, 4, 1, mersenne61, func(p *Party) ([]*big.Int, error) {
+		k, err := p.SetupPRSS()
+		if err != nil {
+			return nil, err
+		}
+		keys[p.X()-1] = k
+		v, err := p.Input(1, bigInts(11, 22), 2)
+		if err != nil {
+			return nil, err
+		}
+		refreshed, err := k.Refresh(v, []byte("epoch 1"))
+		if err != nil {
+			return nil, err
+		}
+		if refreshed[0].Y.Cmp(v[0].Y) == 0 {
+			return nil, ErrorNetwork
+		}
+		return p.Open(refreshed)
+	})
+	assert.Equal(bigInts(11, 22), results[3])
+
+	// All parties hold the single key of the empty coalition
+	for _, k := range keys {
+		assert.Len(k.Keys, 1)
+		assert.Equal(keys[0].Keys[0].Key, k.Keys[0].Key)
+	}
+}
+
+func TestPRSSSetupDegreeTwo(t *testing.T) {
+	assert := assert.New(t)
+	keys := make([]*PRSSKeys, 5)
+	runParties(t, 5, 2, mersenne61, func(p *Party) ([]*big.Int, error) {
+		k, err := p.SetupPRSS()
+		keys[p.X()-1] = k
+		return nil, err
+	})
+	// The parties outside a coalition agree on its key
+	for _, k := range keys {
+		assert.Len(k.Keys, 4)
+		for _, key := range k.Keys {
+			for _, other := range keys {
+				if !containsInt(key.Coalition, other.X) {
+					assert.Contains(other.Keys, key)
+				}
+			}
+		}
+	}
+	zeros := make([]Share, len(keys))
+	for i := range keys {
+		zeros[i] = keys[i].ZeroShare([]byte("nonce"))
+	}
+	secret, err := ShareCombine(zeros[2:])
+	assert.NoError(err)
+	assert.Equal(int64(0), secret.Int64())
+}
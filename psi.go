@@ -0,0 +1,136 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+)
+
+// HashToField hashes an element to an element of the finite field of integers modulo fieldSize. It
+// expands the element with SHA-256 in counter mode to 128 bits more than the size of the field and
+// reduces the result modulo fieldSize.
+func HashToField(element []byte, fieldSize *big.Int) *big.Int {
+	length := (fieldSize.BitLen()+7)/8 + 16
+	digest := make([]byte, 0, length+sha256.Size)
+	var counter [4]byte
+	for i := uint32(0); len(digest) < length; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h := sha256.New()
+		h.Write(counter[:])
+		h.Write(element)
+		digest = h.Sum(digest)
+	}
+	hash := big.NewInt(0).SetBytes(digest[:length])
+	return hash.Mod(hash, fieldSize)
+}
+
+// SetPolynomial returns the coefficients, constant term first, of the monic polynomial over the
+// finite field whose roots are the hashes of the elements of set, see HashToField.
+func SetPolynomial(set [][]byte, fieldSize *big.Int) []*big.Int {
+	coefficients := []*big.Int{big.NewInt(1)}
+	for _, element := range set {
+		// Multiply by (z - root)
+		root := HashToField(element, fieldSize)
+		next := make([]*big.Int, len(coefficients)+1)
+		for i := range next {
+			next[i] = big.NewInt(0)
+			if i > 0 {
+				next[i].Add(next[i], coefficients[i-1])
+			}
+			if i < len(coefficients) {
+				next[i].Sub(next[i], big.NewInt(0).Mul(root, coefficients[i]))
+			}
+			next[i].Mod(next[i], fieldSize)
+		}
+		coefficients = next
+	}
+	return coefficients
+}
+
+// Intersect computes which elements of a query set are contained in another set, where both sets
+// are private to their owners, identified by their X coordinates. The set owner inputs the shared
+// coefficients of its SetPolynomial, and the query owner inputs, for every query, the powers of its
+// hash. The parties evaluate the polynomial at every query as an inner product of shares, multiply
+// the result by a shared random value and open it: it is zero for queries in the set and random
+// otherwise. Only the owners pass their sets, the others pass nil, but all parties must pass the
+// sizes of both sets.
+//
+// All parties learn the resulting flags and the sizes of the sets, but nothing about the elements.
+// This requires at least 2*degree+1 parties, and a field that is large enough for hash collisions
+// to be unlikely.
+func (p *Party) Intersect(setOwner int, set [][]byte, setSize int, queryOwner int, queries [][]byte, queryCount int) ([]bool, error) {
+	var coefficients []*big.Int
+	if p.x == setOwner {
+		if len(set) != setSize {
+			return nil, ErrorVectorLength
+		}
+		coefficients = SetPolynomial(set, p.fieldSize)
+	}
+	sharedCoefficients, err := p.Input(setOwner, coefficients, setSize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	var powers []*big.Int
+	if p.x == queryOwner {
+		if len(queries) != queryCount {
+			return nil, ErrorVectorLength
+		}
+		for _, query := range queries {
+			hash := HashToField(query, p.fieldSize)
+			power := big.NewInt(1)
+			for i := 0; i <= setSize; i++ {
+				powers = append(powers, big.NewInt(0).Set(power))
+				power.Mul(power, hash).Mod(power, p.fieldSize)
+			}
+		}
+	}
+	sharedPowers, err := p.Input(queryOwner, powers, queryCount*(setSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	evaluations := make(ShareVector, queryCount)
+	for j := range evaluations {
+		evaluations[j], err = sharedCoefficients.InnerProduct(sharedPowers[j*(setSize+1) : (j+1)*(setSize+1)])
+		if err != nil {
+			return nil, err
+		}
+	}
+	evaluations, err = p.Reshare(evaluations)
+	if err != nil {
+		return nil, err
+	}
+	masks, err := p.Random(queryCount)
+	if err != nil {
+		return nil, err
+	}
+	masked, err := p.Mul(evaluations, masks)
+	if err != nil {
+		return nil, err
+	}
+	opened, err := p.Open(masked)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make([]bool, queryCount)
+	for j := range flags {
+		flags[j] = opened[j].Sign() == 0
+	}
+	return flags, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetPolynomial(t *testing.T) {
+	assert := assert.New(t)
+	set := [][]byte{[]byte("alice"), []byte("bob"), []byte("carol")}
+	coefficients := SetPolynomial(set, mersenne61)
+	assert.Len(coefficients, 4)
+	assert.Equal(int64(1), coefficients[3].Int64())
+
+	for _, element := range append(set, []byte("dave")) {
+		// Evaluate with Horner's rule
+		z := HashToField(element, mersenne61)
+		y := big.NewInt(0)
+		for i := len(coefficients) - 1; i >= 0; i-- {
+			y.Mul(y, z).Add(y, coefficients[i]).Mod(y, mersenne61)
+		}
+		assert.Equal(string(element) == "dave", y.Sign() != 0)
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	assert := assert.New(t)
+	set := [][]byte{[]byte("alice"), []byte("bob"), []byte("carol")}
+	queries := [][]byte{[]byte("dave"), []byte("carol"), []byte("eve"), []byte("alice")}
+	results := runParties(t, 3, 1, mersenne61, func(p *Party) ([]*big.Int, error) {
+		var mySet, myQueries [][]byte
+		switch p.X() {
+		case 1:
+			mySet = set
+		case 2:
+			myQueries = queries
+		}
+		flags, err := p.Intersect(1, mySet, len(set), 2, myQueries, len(queries))
+		if err != nil {
+			return nil, err
+		}
+		result := make([]*big.Int, len(flags))
+		for i, flag := range flags {
+			result[i] = big.NewInt(0)
+			if flag {
+				result[i].SetInt64(1)
+			}
+		}
+		return result, nil
+	})
+	for _, result := range results {
+		assert.Equal(bigInts(0, 1, 0, 1), result)
+	}
+}
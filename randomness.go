@@ -0,0 +1,312 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrorDuplicateDigest     = errors.New("A digest was already received from this dealer")
+	ErrorMissingDigest       = errors.New("No digest was received from this dealer")
+	ErrorInvalidContribution = errors.New("Contribution does not match the commitments of the dealer")
+)
+
+// A RandomContribution is the contribution of one party to dealer-free shared randomness. The
+// protocol has two rounds:
+//
+//  1. Commit: every party creates a RandomContribution and broadcasts its Digest.
+//  2. Reveal: once all digests are in, every party broadcasts its Commitments and sends Share(x)
+//     privately to the party with X coordinate x.
+//
+// Every party feeds the messages it receives into its RandomCollector, which verifies them. The
+// parties then agree on the dealers to include using AgreeDealers, and compute their shares of the
+// random value with RandomCollector.Share. The value is uniformly random as long as one included
+// dealer is honest, because the digests prevent dealers from choosing their contribution based on
+// the contributions of others.
+type RandomContribution struct {
+	dealer      int
+	shares      []Share
+	commitments Commitments
+}
+
+// NewRandomContribution returns the contribution of the dealer with X coordinate dealer among
+// nParties parties, to shares of the given degree over the field of integers modulo the order of
+// generator.
+func NewRandomContribution(dealer int, generator GroupElement, degree int, nParties int) (*RandomContribution, error) {
+	secret, err := rand.Int(rand.Reader, generator.Order())
+	if err != nil {
+		return nil, err
+	}
+	shares, commitments := ShareFeldman(secret, generator, degree, nParties)
+	return &RandomContribution{dealer: dealer, shares: shares, commitments: commitments}, nil
+}
+
+// Digest returns the digest to broadcast in the commit round.
+func (c *RandomContribution) Digest() []byte {
+	return c.commitments.Digest()
+}
+
+// Commitments returns the commitments to broadcast in the reveal round.
+func (c *RandomContribution) Commitments() Commitments {
+	return c.commitments
+}
+
+// Share returns the share to send to the party with X coordinate x in the reveal round.
+func (c *RandomContribution) Share(x int) Share {
+	return c.shares[x-1]
+}
+
+// A RandomCollector collects and verifies the contributions to dealer-free shared randomness
+// received by a single party, see RandomContribution. It is safe for concurrent use.
+type RandomCollector struct {
+	x             int
+	generator     GroupElement
+	degree        int
+	mutex         sync.Mutex
+	digests       map[int][]byte
+	commitments   map[int]Commitments
+	contributions map[int]Share
+}
+
+// NewRandomCollector returns the RandomCollector for the party with X coordinate x.
+func NewRandomCollector(x int, generator GroupElement, degree int) *RandomCollector {
+	return &RandomCollector{
+		x:             x,
+		generator:     generator,
+		degree:        degree,
+		digests:       make(map[int][]byte),
+		commitments:   make(map[int]Commitments),
+		contributions: make(map[int]Share),
+	}
+}
+
+// ReceiveDigest stores the digest broadcast by a dealer in the commit round.
+func (r *RandomCollector) ReceiveDigest(dealer int, digest []byte) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.digests[dealer]; ok {
+		return ErrorDuplicateDigest
+	}
+	r.digests[dealer] = digest
+	return nil
+}
+
+// ReceiveContribution verifies and stores the commitments and share received from a dealer in the
+// reveal round. It returns ErrorInvalidContribution if the commitments do not match the digest of
+// the dealer or the share does not match the commitments, in which case the dealer should be
+// excluded. A dealer can only contribute once.
+func (r *RandomCollector) ReceiveContribution(dealer int, commitments Commitments, share Share) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	digest, ok := r.digests[dealer]
+	if !ok {
+		return ErrorMissingDigest
+	}
+	if _, ok := r.contributions[dealer]; ok {
+		return ErrorDuplicateContribution
+	}
+	if share.X != r.x || len(commitments) != r.degree+1 || !bytes.Equal(commitments.Digest(), digest) ||
+		!commitments.Verify(r.generator, share) {
+		return ErrorInvalidContribution
+	}
+	r.commitments[dealer] = commitments
+	r.contributions[dealer] = share
+	return nil
+}
+
+// Dealers returns the sorted X coordinates of the dealers whose contributions were verified.
+func (r *RandomCollector) Dealers() []int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	dealers := make([]int, 0, len(r.contributions))
+	for dealer := range r.contributions {
+		dealers = append(dealers, dealer)
+	}
+	sort.Ints(dealers)
+	return dealers
+}
+
+// Share returns the share of the random value that is the sum of the contributions of the given
+// dealers, together with the commitments to it. All parties must use the same dealers, see
+// AgreeDealers.
+func (r *RandomCollector) Share(dealers []int) (Share, Commitments, error) {
+	if len(dealers) == 0 {
+		return Share{}, nil, ErrorNoShares
+	}
+	fieldSize := r.generator.Order()
+	sum := Share{
+		FieldSize: fieldSize,
+		Degree:    r.degree,
+		X:         r.x,
+		Y:         big.NewInt(0),
+	}
+	var commitments Commitments
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, dealer := range dealers {
+		share, ok := r.contributions[dealer]
+		if !ok {
+			return Share{}, nil, ErrorMissingContribution
+		}
+		sum.Y.Add(sum.Y, share.Y)
+		if commitments == nil {
+			commitments = r.commitments[dealer]
+		} else {
+			commitments, _ = commitments.Add(r.commitments[dealer])
+		}
+	}
+	sum.Y.Mod(sum.Y, fieldSize)
+	return sum, commitments, nil
+}
+
+// AgreeDealers returns the sorted X coordinates of the dealers that appear in all given lists, as
+// returned by RandomCollector.Dealers of the parties. A dealer listed more than once in the same
+// list is counted once.
+func AgreeDealers(dealerLists ...[]int) []int {
+	if len(dealerLists) == 0 {
+		return nil
+	}
+	counts := make(map[int]int)
+	for _, dealers := range dealerLists {
+		listed := make(map[int]bool, len(dealers))
+		for _, dealer := range dealers {
+			if !listed[dealer] {
+				listed[dealer] = true
+				counts[dealer]++
+			}
+		}
+	}
+	agreed := make([]int, 0, len(counts))
+	for dealer, count := range counts {
+		if count == len(dealerLists) {
+			agreed = append(agreed, dealer)
+		}
+	}
+	sort.Ints(agreed)
+	return agreed
+}
+
+// RandomShare runs the protocol of RandomContribution for the party with X coordinate x among
+// nParties parties, and returns its share of the random value with the commitments to it. The
+// digests, the commitments and finally the lists of verified dealers are sent with broadcaster, and
+// the shares privately over network. Since all parties see the same broadcasts, they agree on the
+// dealers to include with AgreeDealers. The decode function parses the elements of the commitments,
+// for instance ModPGroup.Decode.
+//
+// A dealer that misbehaves towards any party is excluded, and malformed lists are ignored by all
+// parties alike. A party that excludes every dealer in its list makes the protocol fail with
+// ErrorNoShares at all parties, and can be identified from the lists.
+func RandomShare(x int, nParties int, generator GroupElement, decode func([]byte) (GroupElement, error), degree int, broadcaster Broadcaster, network Network) (Share, Commitments, error) {
+	contribution, err := NewRandomContribution(x, generator, degree, nParties)
+	if err != nil {
+		return Share{}, nil, err
+	}
+	collector := NewRandomCollector(x, generator, degree)
+	digests, err := broadcaster.Broadcast(contribution.Digest())
+	if err != nil {
+		return Share{}, nil, err
+	}
+	for i, digest := range digests {
+		if digest != nil {
+			collector.ReceiveDigest(i+1, digest)
+		}
+	}
+
+	var encodedCommitments bytes.Buffer
+	writeUint64(&encodedCommitments, uint64(len(contribution.Commitments())))
+	for _, element := range contribution.Commitments() {
+		writeBytes(&encodedCommitments, element.Bytes())
+	}
+	allCommitments, err := broadcaster.Broadcast(encodedCommitments.Bytes())
+	if err != nil {
+		return Share{}, nil, err
+	}
+	outgoing := make([][]NestedShare, nParties)
+	for i := range outgoing {
+		outgoing[i] = nest(ShareVector{contribution.Share(i + 1)})
+	}
+	received, err := network.Exchange(outgoing)
+	if err != nil {
+		return Share{}, nil, err
+	}
+	for i, encoded := range allCommitments {
+		commitments, err := readCommitments(bytes.NewReader(encoded), degree, decode)
+		if err == nil && i < len(received) && len(received[i]) == 1 {
+			collector.ReceiveContribution(i+1, commitments, received[i][0].Share)
+		}
+	}
+
+	var encodedDealers bytes.Buffer
+	dealers := collector.Dealers()
+	writeUint64(&encodedDealers, uint64(len(dealers)))
+	for _, dealer := range dealers {
+		writeUint64(&encodedDealers, uint64(dealer))
+	}
+	lists, err := broadcaster.Broadcast(encodedDealers.Bytes())
+	if err != nil {
+		return Share{}, nil, err
+	}
+	var dealerLists [][]int
+	for _, list := range lists {
+		if dealers, err := readDealers(bytes.NewReader(list), nParties); err == nil {
+			dealerLists = append(dealerLists, dealers)
+		}
+	}
+	return collector.Share(AgreeDealers(dealerLists...))
+}
+
+// readDealers reads a list of dealers as written by RandomShare. Lists with dealers outside 1 to
+// nParties or with duplicate dealers are rejected, as they would skew the count of AgreeDealers.
+func readDealers(r io.Reader, nParties int) ([]int, error) {
+	count, err := readUint64(r)
+	if err != nil || count > uint64(nParties) {
+		return nil, ErrorNetwork
+	}
+	dealers := make([]int, count)
+	for i := range dealers {
+		dealer, err := readUint64(r)
+		if err != nil || dealer == 0 || dealer > uint64(nParties) || containsInt(dealers[:i], int(dealer)) {
+			return nil, ErrorNetwork
+		}
+		dealers[i] = int(dealer)
+	}
+	return dealers, nil
+}
+
+// readCommitments reads commitments of the given degree as written by RandomShare.
+func readCommitments(r io.Reader, degree int, decode func([]byte) (GroupElement, error)) (Commitments, error) {
+	count, err := readUint64(r)
+	if err != nil || count != uint64(degree+1) {
+		return nil, ErrorNetwork
+	}
+	commitments := make(Commitments, count)
+	for i := range commitments {
+		b, err := readBytes(r, 1<<16)
+		if err != nil {
+			return nil, err
+		}
+		if commitments[i], err = decode(b); err != nil {
+			return nil, err
+		}
+	}
+	return commitments, nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedRandomness(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	nParties, degree := 4, 1
+	contributions := make([]*RandomContribution, nParties)
+	collectors := make([]*RandomCollector, nParties)
+	for i := range contributions {
+		var err error
+		contributions[i], err = NewRandomContribution(i+1, g, degree, nParties)
+		assert.NoError(err)
+		collectors[i] = NewRandomCollector(i+1, g, degree)
+	}
+
+	// Commit round
+	for _, collector := range collectors {
+		for dealer, contribution := range contributions {
+			assert.NoError(collector.ReceiveDigest(dealer+1, contribution.Digest()))
+		}
+	}
+	// Reveal round, in which dealer 3 sends a wrong share to party 2
+	for i, collector := range collectors {
+		for dealer, contribution := range contributions {
+			share := contribution.Share(i + 1)
+			if dealer == 2 && i == 1 {
+				share = contributions[3].Share(i + 1)
+			}
+			err := collector.ReceiveContribution(dealer+1, contribution.Commitments(), share)
+			if dealer == 2 && i == 1 {
+				assert.Equal(ErrorInvalidContribution, err)
+			} else {
+				assert.NoError(err)
+			}
+		}
+	}
+
+	lists := make([][]int, nParties)
+	for i := range collectors {
+		lists[i] = collectors[i].Dealers()
+	}
+	dealers := AgreeDealers(lists...)
+	assert.Equal([]int{1, 2, 4}, dealers)
+	assert.Equal([]int{1}, AgreeDealers([]int{1, 1}, []int{1, 2}))
+
+	shares := make([]Share, nParties)
+	var commitments Commitments
+	for i := range collectors {
+		var err error
+		shares[i], commitments, err = collectors[i].Share(dealers)
+		assert.NoError(err)
+		assert.True(commitments.Verify(g, shares[i]))
+	}
+	secret, err := ShareCombine(shares[2:])
+	assert.NoError(err)
+	expected, err := ShareCombine([]Share{
+		contributions[0].Share(1), contributions[0].Share(2),
+	})
+	assert.NoError(err)
+	for _, dealer := range []int{2, 4} {
+		s, err := ShareCombine([]Share{contributions[dealer-1].Share(1), contributions[dealer-1].Share(2)})
+		assert.NoError(err)
+		expected.Add(expected, s)
+	}
+	assert.Equal(expected.Mod(expected, largeTestGroup.Q), secret)
+	assert.True(commitments[0].Equal(g.ScalarMult(secret)))
+
+	_, _, err = collectors[0].Share([]int{3, 5})
+	assert.Equal(ErrorMissingContribution, err)
+	assert.Equal(ErrorDuplicateDigest, collectors[0].ReceiveDigest(1, contributions[0].Digest()))
+	assert.Equal(ErrorDuplicateContribution, collectors[0].ReceiveContribution(1, contributions[0].Commitments(), contributions[0].Share(1)))
+	assert.Equal(ErrorMissingDigest, collectors[0].ReceiveContribution(5, contributions[0].Commitments(), contributions[0].Share(1)))
+	// Commitments that differ from the digest are rejected
+	assert.Equal(ErrorInvalidContribution, collectors[1].ReceiveContribution(3, contributions[2].Commitments()[:1], contributions[2].Share(2)))
+}
+
+// channelBroadcaster broadcasts among parties in the same process.
+type channelBroadcaster struct {
+	x        int
+	channels [][]chan []byte
+}
+
+func (b *channelBroadcaster) Broadcast(message []byte) ([][]byte, error) {
+	for i := range b.channels {
+		b.channels[b.x-1][i] <- message
+	}
+	delivered := make([][]byte, len(b.channels))
+	for i := range delivered {
+		delivered[i] = <-b.channels[i][b.x-1]
+	}
+	return delivered, nil
+}
+
+func TestRandomShare(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	nParties, degree := 4, 1
+	broadcasts := make([][]chan []byte, nParties)
+	exchanges := make([][]chan []NestedShare, nParties)
+	for i := range broadcasts {
+		broadcasts[i] = make([]chan []byte, nParties)
+		exchanges[i] = make([]chan []NestedShare, nParties)
+		for j := range broadcasts[i] {
+			broadcasts[i][j] = make(chan []byte, 1)
+			exchanges[i][j] = make(chan []NestedShare, 1)
+		}
+	}
+	shares := make([]Share, nParties)
+	commitments := make([]Commitments, nParties)
+	errs := make([]error, nParties)
+	var wg sync.WaitGroup
+	for i := range shares {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			shares[i], commitments[i], errs[i] = RandomShare(i+1, nParties, g, largeTestGroup.Decode, degree,
+				&channelBroadcaster{x: i + 1, channels: broadcasts}, &channelNetwork{x: i + 1, channels: exchanges})
+		}(i)
+	}
+	wg.Wait()
+	for i := range shares {
+		assert.NoError(errs[i])
+		assert.Equal(commitments[0], commitments[i])
+		assert.True(commitments[i].Verify(g, shares[i]))
+	}
+	secret, err := ShareCombine(shares[1:3])
+	assert.NoError(err)
+	assert.True(g.ScalarMult(secret).Equal(commitments[0][0]))
+	assert.NotEqual(big.NewInt(0), secret)
+
+	_, err = readDealers(bytes.NewReader([]byte{0, 0, 0, 0, 0, 0, 0, 9}), nParties)
+	assert.Equal(ErrorNetwork, err)
+	_, err = readDealers(bytes.NewReader([]byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0}), nParties)
+	assert.Equal(ErrorNetwork, err)
+	_, err = readDealers(bytes.NewReader([]byte{0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1}), nParties)
+	assert.Equal(ErrorNetwork, err)
+	_, err = readCommitments(bytes.NewReader(nil), degree, largeTestGroup.Decode)
+	assert.Equal(ErrorNetwork, err)
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorShareNotCommitted     = errors.New("Share does not match the commitments")
+	ErrorInvalidReconstruction = errors.New("Reconstruction transcript does not verify")
+)
+
+// A ReconstructionTranscript records how a secret shared by ShareFeldman was reconstructed: the
+// shares used, the commitments they were checked against and the Lagrange weights with which they
+// were interpolated. An auditor that knows the generator can re-verify it with Verify, and the
+// Digest can be logged or signed to refer to it.
+type ReconstructionTranscript struct {
+	Shares      []Share
+	Commitments Commitments
+	Weights     []*big.Int
+	Secret      *big.Int
+}
+
+// CombineProved recovers a secret like ShareCombine from shares dealt by ShareFeldman with the given
+// generator, after checking every share against the commitments, and returns a transcript of the
+// reconstruction along with the secret. If a share does not match the commitments,
+// ErrorShareNotCommitted is returned. Like ShareCombine, it uses the first degree+1 shares.
+func CombineProved(shares []Share, generator GroupElement, commitments Commitments) (*big.Int, ReconstructionTranscript, error) {
+	var transcript ReconstructionTranscript
+	secret, err := emitCombine("", shares, func(shares []Share) (*big.Int, error) {
+		if err := checkCombinable(shares); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			if !commitments.Verify(generator, share) {
+				return nil, ErrorShareNotCommitted
+			}
+		}
+		shares = shares[:shares[0].Degree+1]
+		weights, secret, err := interpolateWeighted(shares, generator.Order())
+		if err != nil {
+			return nil, err
+		}
+		transcript = ReconstructionTranscript{Shares: shares, Commitments: commitments, Weights: weights, Secret: secret}
+		return secret, nil
+	})
+	if err != nil {
+		return nil, ReconstructionTranscript{}, err
+	}
+	return secret, transcript, nil
+}
+
+// Verify checks the transcript independently of the party that reconstructed the secret: that
+// every share matches the commitments, that the weights are the Lagrange coefficients for the X
+// coordinates of the shares, and that the secret is their weighted sum and matches the commitment
+// to the secret. It returns ErrorShareNotCommitted or ErrorInvalidReconstruction on failure.
+func (t ReconstructionTranscript) Verify(generator GroupElement) error {
+	if err := checkCombinable(t.Shares); err != nil {
+		return err
+	}
+	if len(t.Shares) != t.Shares[0].Degree+1 || len(t.Weights) != len(t.Shares) || t.Secret == nil {
+		return ErrorInvalidReconstruction
+	}
+	for _, share := range t.Shares {
+		if !t.Commitments.Verify(generator, share) {
+			return ErrorShareNotCommitted
+		}
+	}
+	weights, secret, err := interpolateWeighted(t.Shares, generator.Order())
+	if err != nil {
+		return err
+	}
+	for i := range weights {
+		if t.Weights[i] == nil || weights[i].Cmp(t.Weights[i]) != 0 {
+			return ErrorInvalidReconstruction
+		}
+	}
+	if secret.Cmp(t.Secret) != 0 || !generator.ScalarMult(t.Secret).Equal(t.Commitments[0]) {
+		return ErrorInvalidReconstruction
+	}
+	return nil
+}
+
+// Digest returns a SHA-256 hash of the transcript.
+func (t ReconstructionTranscript) Digest() []byte {
+	h := sha256.New()
+	writeUint64(h, uint64(len(t.Shares)))
+	for _, share := range t.Shares {
+		writeShare(h, share)
+	}
+	writeBytes(h, t.Commitments.Digest())
+	writeUint64(h, uint64(len(t.Weights)))
+	for _, weight := range t.Weights {
+		writeInt(h, weight)
+	}
+	writeInt(h, t.Secret)
+	return h.Sum(nil)
+}
+
+// interpolateWeighted returns the Lagrange weights of shares over the field of integers modulo
+// order, and the secret they interpolate.
+func interpolateWeighted(shares []Share, order *big.Int) ([]*big.Int, *big.Int, error) {
+	xs := make([]int, len(shares))
+	for i := range shares {
+		xs[i] = shares[i].X
+	}
+	weights, err := LagrangeCoefficients(xs, 0, order)
+	if err != nil {
+		return nil, nil, err
+	}
+	secret := big.NewInt(0)
+	for i := range shares {
+		secret.Add(secret, big.NewInt(0).Mul(weights[i], shares[i].Y))
+	}
+	return weights, secret.Mod(secret, order), nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineProved(t *testing.T) {
+	assert := assert.New(t)
+	g := testGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(777), g, 2, 5)
+
+	secret, transcript, err := CombineProved(shares[1:], g, commitments)
+	assert.NoError(err)
+	assert.Equal(int64(777), secret.Int64())
+	assert.Equal(shares[1:4], transcript.Shares)
+	assert.Len(transcript.Weights, 3)
+	assert.NoError(transcript.Verify(g))
+	assert.Len(transcript.Digest(), 32)
+
+	// Tampering with any part of the transcript is detected
+	tampered := transcript
+	tampered.Secret = big.NewInt(778)
+	assert.Equal(ErrorInvalidReconstruction, tampered.Verify(g))
+	assert.NotEqual(transcript.Digest(), tampered.Digest())
+	tampered = transcript
+	tampered.Weights = []*big.Int{big.NewInt(1), transcript.Weights[1], transcript.Weights[2]}
+	assert.Equal(ErrorInvalidReconstruction, tampered.Verify(g))
+	tampered = transcript
+	tampered.Shares = []Share{shares[0], shares[1], shares[2]}
+	assert.Equal(ErrorInvalidReconstruction, tampered.Verify(g))
+	tampered = transcript
+	tampered.Shares = shares[1:3]
+	assert.Equal(ErrorTooFewShares, tampered.Verify(g))
+
+	// A wrong share is rejected before the secret is computed
+	wrong := append([]Share{}, shares...)
+	wrong[4].Y = big.NewInt(0).Add(wrong[4].Y, big.NewInt(1))
+	_, _, err = CombineProved(wrong, g, commitments)
+	assert.True(errors.Is(err, ErrorShareNotCommitted))
+	tampered = transcript
+	tampered.Shares = []Share{shares[1], shares[2], wrong[4]}
+	assert.Equal(ErrorShareNotCommitted, tampered.Verify(g))
+
+	_, _, err = CombineProved(shares[:2], g, commitments)
+	assert.True(errors.Is(err, ErrorTooFewShares))
+}
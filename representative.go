@@ -0,0 +1,52 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+)
+
+// A Representative maps a secret reconstructed over the finite field of integers modulo fieldSize,
+// given in [0, fieldSize), to the form an application expects, or returns an error if the secret has
+// no such form. CanonicalRepresentative and CenteredRepresentative cover the common cases;
+// applications can write their own, for instance to check that a secret is a valid scalar or to
+// reject secrets that do not fit in a number of bits.
+type Representative func(secret *big.Int, fieldSize *big.Int) (*big.Int, error)
+
+// CanonicalRepresentative returns the secret in [0, fieldSize) unchanged, as ShareCombine does.
+func CanonicalRepresentative(secret *big.Int, fieldSize *big.Int) (*big.Int, error) {
+	return secret, nil
+}
+
+// CenteredRepresentative interprets the secret as a signed integer in (-fieldSize/2, fieldSize/2].
+func CenteredRepresentative(secret *big.Int, fieldSize *big.Int) (*big.Int, error) {
+	if secret.Cmp(big.NewInt(0).Rsh(fieldSize, 1)) > 0 {
+		return big.NewInt(0).Sub(secret, fieldSize), nil
+	}
+	return secret, nil
+}
+
+// CombineAs recovers a secret like ShareCombine and maps it with representative if the shares are
+// over a finite field. Secrets shared over the integers are returned unchanged.
+func CombineAs(shares []Share, representative Representative) (*big.Int, error) {
+	secret, err := ShareCombine(shares)
+	if err != nil {
+		return nil, err
+	}
+	if fieldSize := shares[0].FieldSize; fieldSize != nil {
+		return representative(secret, fieldSize)
+	}
+	return secret, nil
+}
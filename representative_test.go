@@ -0,0 +1,66 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineAs(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	for _, test := range []struct {
+		secret, canonical, centered int64
+	}{
+		{0, 0, 0},
+		{42, 42, 42},
+		{-42, 7877, -42},
+		{3959, 3959, 3959},
+		{3960, 3960, -3959},
+	} {
+		shares := ShareFiniteField(big.NewInt(test.secret), fieldSize, 1, 3)
+		secret, err := CombineAs(shares, CanonicalRepresentative)
+		assert.NoError(err)
+		assert.Equal(big.NewInt(test.canonical), secret)
+		secret, err = CombineAs(shares, CenteredRepresentative)
+		assert.NoError(err)
+		assert.Equal(big.NewInt(test.centered), secret)
+	}
+
+	// A decoder of the application that only accepts bytes
+	tooLarge := errors.New("too large")
+	toByte := func(secret *big.Int, fieldSize *big.Int) (*big.Int, error) {
+		if secret.BitLen() > 8 {
+			return nil, tooLarge
+		}
+		return secret, nil
+	}
+	secret, err := CombineAs(ShareFiniteField(big.NewInt(255), fieldSize, 1, 3), toByte)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(255), secret)
+	_, err = CombineAs(ShareFiniteField(big.NewInt(256), fieldSize, 1, 3), toByte)
+	assert.Equal(tooLarge, err)
+
+	// Errors of combining and secrets over the integers are passed through
+	_, err = CombineAs(ShareFiniteField(big.NewInt(1), fieldSize, 1, 3)[:1], CenteredRepresentative)
+	assert.Equal(ErrorTooFewShares, err)
+	secret, err = CombineAs(ShareIntegers(big.NewInt(5000), big.NewInt(10000), 40, 1, 3), CenteredRepresentative)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(5000), secret)
+}
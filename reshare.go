@@ -0,0 +1,116 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// Resharing lets a set of parties convert their shares of a secret into fresh shares of the same
+// secret, possibly of a different degree, without anyone learning the secret. Every party splits
+// its share with SplitShare and sends sub-share i to party i. Every party then recombines the
+// sub-shares it received with ShareRecombine. This is used for instance to reduce the degree of a
+// share of a product after ShareMul: a product of two degree-t sharings has degree 2t, and
+// resharing it with degree t requires 2t+1 parties.
+
+import (
+	"math/big"
+)
+
+// ShareRecombine combines the sub-shares that a party received from other parties, each of which
+// split its share of the same secret with SplitShare, into a fresh share of the secret. The result
+// has the X coordinate and degree of the sub-shares. Sub-shares of at least degree+1 different
+// parents are required, where degree is the degree of the parent shares.
+func ShareRecombine(subShares []NestedShare) (Share, error) {
+	if len(subShares) == 0 {
+		return Share{}, ErrorNoShares
+	}
+	first := subShares[0]
+	if first.FieldSize == nil || len(first.Parents) == 0 {
+		return Share{}, ErrorWrongShareType
+	}
+	parentDegree := first.Parents[len(first.Parents)-1].Degree
+	if len(subShares) <= parentDegree {
+		return Share{}, ErrorTooFewShares
+	}
+
+	xs := make([]int, parentDegree+1)
+	for i, share := range subShares {
+		if len(share.Parents) != len(first.Parents) || share.Parents[len(share.Parents)-1].Degree != parentDegree ||
+			!equalOrBothNil(first.FieldSize, share.FieldSize) || first.Degree != share.Degree || first.X != share.X {
+			return Share{}, ErrorIncompatibleShares
+		}
+		if i < len(xs) {
+			xs[i] = share.Parents[len(share.Parents)-1].X
+		}
+	}
+	coefficients, err := LagrangeCoefficients(xs, 0, first.FieldSize)
+	if err != nil {
+		return Share{}, err
+	}
+
+	share := Share{
+		FieldSize: first.FieldSize,
+		Degree:    first.Degree,
+		X:         first.X,
+		Y:         big.NewInt(0),
+	}
+	term := big.NewInt(0)
+	for i := range coefficients {
+		share.Y.Add(share.Y, term.Mul(coefficients[i], subShares[i].Y))
+	}
+	share.Y.Mod(share.Y, share.FieldSize)
+	return share, nil
+}
+
+// Split splits every share in the vector with SplitShare. The result contains, for every recipient,
+// the sub-shares of all elements of the vector.
+func (v ShareVector) Split(degree int, nShares int) ([][]NestedShare, error) {
+	subShares := make([][]NestedShare, nShares)
+	for i := range subShares {
+		subShares[i] = make([]NestedShare, len(v))
+	}
+	for j := range v {
+		shares, err := SplitShare(v[j], degree, nShares)
+		if err != nil {
+			return nil, err
+		}
+		for i := range subShares {
+			subShares[i][j] = shares[i]
+		}
+	}
+	return subShares, nil
+}
+
+// RecombineVector recombines the sub-shares of a vector that a party received from other parties,
+// as produced by ShareVector.Split, into a fresh ShareVector. subShares[i] holds the sub-shares
+// received from the i-th party.
+func RecombineVector(subShares [][]NestedShare) (ShareVector, error) {
+	if len(subShares) == 0 {
+		return nil, ErrorNoShares
+	}
+	v := make(ShareVector, len(subShares[0]))
+	elementShares := make([]NestedShare, len(subShares))
+	for j := range v {
+		for i := range subShares {
+			if len(subShares[i]) != len(v) {
+				return nil, ErrorVectorLength
+			}
+			elementShares[i] = subShares[i][j]
+		}
+		var err error
+		v[j], err = ShareRecombine(elementShares)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
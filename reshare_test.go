@@ -0,0 +1,90 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reshare runs the resharing protocol among the holders of shares, producing nShares fresh shares
+// of given degree.
+func reshare(t *testing.T, shares []Share, degree int, nShares int) []Share {
+	received := make([][]NestedShare, nShares)
+	for _, share := range shares {
+		subShares, err := SplitShare(share, degree, nShares)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		for i := range received {
+			received[i] = append(received[i], subShares[i])
+		}
+	}
+	fresh := make([]Share, nShares)
+	for i := range fresh {
+		var err error
+		fresh[i], err = ShareRecombine(received[i])
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	return fresh
+}
+
+func TestDegreeReduction(t *testing.T) {
+	assert := assert.New(t)
+	shares1 := ShareFiniteField(big.NewInt(12), big.NewInt(7919), 2, 5)
+	shares2 := ShareFiniteField(big.NewInt(34), big.NewInt(7919), 2, 5)
+
+	var err error
+	for i := range shares1 {
+		shares1[i], err = ShareMul([]Share{shares1[i], shares2[i]})
+		assert.NoError(err)
+	}
+	reduced := reshare(t, shares1, 2, 5)
+	assert.Equal(2, reduced[0].Degree)
+
+	secret, err := ShareCombine(reduced[2:5])
+	assert.NoError(err)
+	if assert.NotNil(secret) {
+		assert.Equal(int64(12*34), secret.Int64())
+	}
+}
+
+func TestShareRecombineErrors(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareFiniteField(big.NewInt(12), big.NewInt(7919), 2, 5)
+	subShares1, _ := SplitShare(shares[0], 1, 3)
+	subShares2, _ := SplitShare(shares[1], 1, 3)
+	subShares3, _ := SplitShare(shares[2], 1, 3)
+
+	var err error
+	_, err = ShareRecombine(nil)
+	assert.Equal(ErrorNoShares, err)
+
+	_, err = ShareRecombine([]NestedShare{subShares1[0], subShares2[0]})
+	assert.Equal(ErrorTooFewShares, err)
+
+	_, err = ShareRecombine([]NestedShare{subShares1[0], subShares2[0], subShares3[1]})
+	assert.Equal(ErrorIncompatibleShares, err)
+
+	_, err = ShareRecombine([]NestedShare{subShares1[0], subShares1[0], subShares3[0]})
+	assert.Equal(ErrorDuplicateX, err)
+
+	_, err = ShareRecombine([]NestedShare{{Share: shares[0]}})
+	assert.Equal(ErrorWrongShareType, err)
+}
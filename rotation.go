@@ -0,0 +1,47 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// Rotation replaces the secret shared among a set of custodians, for instance when a root key is
+// rotated, without a new distribution ceremony. The dealer shares only the difference between the
+// new and the old secret, and every custodian adds its share of the difference to its current share
+// with RotateShare. The custodians keep their X coordinates, and since the difference is shared with
+// a fresh random polynomial, the rotated shares cannot be combined with shares from before the
+// rotation. With Feldman commitments, the difference is dealt with ShareFeldman, and the commitments
+// to the rotated sharing are the old commitments added to those of the difference, see
+// Commitments.Add.
+
+import (
+	"math/big"
+)
+
+// ShareRotation shares the difference newSecret - oldSecret over a finite field among nShares
+// custodians, whose shares of oldSecret have the given degree and X coordinates 1 to nShares. The
+// secrets themselves are not needed by the custodians, and the difference reveals nothing about
+// either secret on its own.
+func ShareRotation(oldSecret *big.Int, newSecret *big.Int, fieldSize *big.Int, degree int, nShares int) []Share {
+	delta := big.NewInt(0).Sub(newSecret, oldSecret)
+	return ShareFiniteField(delta.Mod(delta, fieldSize), fieldSize, degree, nShares)
+}
+
+// RotateShare updates the share of a custodian with its share of the difference dealt by
+// ShareRotation or ShareFeldman. The share and the delta must be shares over the same finite field,
+// with the same degree and X coordinate.
+func RotateShare(share Share, delta Share) (Share, error) {
+	if share.FieldSize == nil || delta.FieldSize == nil {
+		return Share{}, ErrorWrongShareType
+	}
+	return ShareAdd([]Share{share, delta})
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateShare(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	shares := ShareFiniteField(big.NewInt(42), fieldSize, 2, 5)
+	deltas := ShareRotation(big.NewInt(42), big.NewInt(7), fieldSize, 2, 5)
+
+	rotated := make([]Share, len(shares))
+	for i := range shares {
+		var err error
+		rotated[i], err = RotateShare(shares[i], deltas[i])
+		assert.NoError(err)
+		assert.Equal(shares[i].X, rotated[i].X)
+	}
+	secret, err := ShareCombine(rotated[2:])
+	assert.NoError(err)
+	assert.Equal(int64(7), secret.Int64())
+	// Old and rotated shares do not combine to either secret, unless the deltas vanish at their X
+	mixed, err := ShareCombine([]Share{shares[0], rotated[1], rotated[2]})
+	assert.NoError(err)
+	if deltas[0].Y.Sign() != 0 {
+		assert.NotEqual(int64(7), mixed.Int64())
+	}
+
+	_, err = RotateShare(shares[0], deltas[1])
+	assert.Equal(ErrorIncompatibleShares, err)
+	_, err = RotateShare(ShareIntegers(big.NewInt(1), big.NewInt(10), 40, 1, 2)[0], deltas[0])
+	assert.Equal(ErrorWrongShareType, err)
+}
+
+func TestRotateShareFeldman(t *testing.T) {
+	assert := assert.New(t)
+	g := largeTestGroup.Generator()
+	shares, commitments := ShareFeldman(big.NewInt(100), g, 1, 3)
+	deltas, deltaCommitments := ShareFeldman(big.NewInt(23), g, 1, 3)
+	rotatedCommitments, err := commitments.Add(deltaCommitments)
+	assert.NoError(err)
+
+	rotated := make([]Share, len(shares))
+	for i := range shares {
+		rotated[i], err = RotateShare(shares[i], deltas[i])
+		assert.NoError(err)
+		assert.True(rotatedCommitments.Verify(g, rotated[i]))
+		assert.False(rotatedCommitments.Verify(g, shares[i]))
+	}
+	secret, err := ShareCombine(rotated[:2])
+	assert.NoError(err)
+	assert.Equal(int64(123), secret.Int64())
+	assert.True(rotatedCommitments[0].Equal(g.ScalarMult(big.NewInt(123))))
+}
@@ -28,20 +28,42 @@ var (
 	ErrorTooFewShares       = errors.New("Too few shares given")
 	ErrorIncompatibleShares = errors.New("Attempted to combine shares with different parameters")
 	ErrorFractionalSecret   = errors.New("Reconstruction of the secret failed")
+	ErrorIncompatibleFactor = errors.New("Factor is not a multiple of the factor of the share")
+	ErrorBoundExceeded      = errors.New("Reconstructed secret exceeds the bound of the shares")
+	ErrorSecretOutOfRange   = errors.New("Secret is outside the given range")
+	ErrorInvalidShare       = errors.New("Share is malformed")
+	ErrorDegreeTooHigh      = errors.New("Degree of the product is too high to combine from the shares")
 )
 
 // A Share is a share of a secret. If FieldSize == nil, it is a share over the integers, otherwise
 // it is a Shamir secret share over a finite field.
+//
+// For shares over the integers, the shares lie on a polynomial whose constant term is the secret
+// multiplied by Factor. ShareIntegers uses Factor nShares!, and ShareMul multiplies the factors of
+// the shares, so a product of k shares has Factor (nShares!)^k. ShareCombine divides by Factor
+// after interpolating. Shares can only be added and combined if their factors are equal; use
+// Normalize to bring shares to a common factor first. Shares over a finite field have no Factor.
+//
+// Shares over the integers also track Bound, an upper bound on the absolute value of the secret.
+// ShareIntegers sets it to the upper bound on the secret, and the operations on shares update it.
+// A nil Bound is not tracked.
+//
+// SharingFingerprint optionally identifies the sharing that the share belongs to, see
+// WithSharingFingerprint. Shares with different fingerprints are not combined. The operations on
+// shares produce shares of a new sharing, without a fingerprint.
 type Share struct {
-	FieldSize *big.Int
-	Factor    *big.Int
-	Degree    int
-	X         int
-	Y         *big.Int
+	FieldSize          *big.Int
+	Factor             *big.Int
+	Degree             int
+	X                  int
+	Y                  *big.Int
+	Bound              *big.Int
+	SharingFingerprint []byte `json:",omitempty"`
 }
 
 // ShareFiniteField shares a secret over a finite field of integers modulo fieldSize.
-// The caller must ensure that fieldSize is prime.
+// The caller must ensure that fieldSize is prime. The secret is reduced modulo fieldSize; use
+// ShareBytes for secrets that do not fit in the field.
 // It produces a configurable number of shares using a polynomial of given degree. Note that
 // degree+1 shares are required for reconstruction of the secret.
 func ShareFiniteField(secret *big.Int, fieldSize *big.Int, degree int, nShares int) []Share {
@@ -49,81 +71,95 @@ func ShareFiniteField(secret *big.Int, fieldSize *big.Int, degree int, nShares i
 	for i := range coefficients {
 		coefficients[i], _ = rand.Int(rand.Reader, fieldSize)
 	}
-	shares := make([]Share, nShares)
-	for i := range shares {
-		shares[i].FieldSize = fieldSize
-		shares[i].Degree = degree
-		shares[i].X = i + 1
-		shares[i].Y = big.NewInt(0).Set(secret)
-		// compute f(i) == secret + sum(j) coeff[j] i^(j+1)
-		for j := range coefficients {
-			term := big.NewInt(int64(i + 1))
-			term.Exp(term, big.NewInt(int64(j+1)), nil)
-			term.Mul(term, coefficients[j])
-			shares[i].Y.Add(shares[i].Y, term)
-		}
-		shares[i].Y.Mod(shares[i].Y, fieldSize)
-	}
-	return shares
+	return shareFiniteField(secret, fieldSize, coefficients, nShares)
 }
 
 // ShareIntegers shares a secret over the integers. It requires a known upper bound on the secret
-// and will provide statSecParam bits of statistical security.
+// and will provide statSecParam bits of statistical security. The secret is not checked against
+// the bound, but the shares of a secret beyond it do not track a Bound; use ShareSignedIntegers
+// for secrets that may be negative.
 // It produces a configurable number of shares using a polynomial of given degree. Note that
 // degree+1 shares are required for reconstruction of the secret.
 func ShareIntegers(secret *big.Int, secretUpperBound *big.Int, statSecParam int, degree int, nShares int) []Share {
-	coefficientUpperBound := big.NewInt(2)
-	coefficientUpperBound.
-		Exp(coefficientUpperBound, big.NewInt(int64(statSecParam)), nil).
-		Mul(coefficientUpperBound, big.NewInt(int64(nShares*nShares))).
-		Mul(coefficientUpperBound, secretUpperBound)
-
+	coefficientUpperBound := integerCoefficientBound(secretUpperBound, statSecParam, nShares)
 	coefficients := make([]*big.Int, degree)
 	for i := range coefficients {
 		coefficients[i], _ = rand.Int(rand.Reader, coefficientUpperBound)
 	}
+	return shareIntegers(secret, secretUpperBound, coefficients, nShares)
+}
 
-	shares := make([]Share, nShares)
-	nFactorial := factorial(int64(nShares))
-	secret = big.NewInt(0).Mul(secret, nFactorial)
-	for i := range shares {
-		shares[i].Degree = degree
-		shares[i].Factor = nFactorial
-		shares[i].X = i + 1
-		shares[i].Y = big.NewInt(0).Set(secret)
-		// compute f(i) == secret + sum(j) coeff[j] i^(j+1)
-		for j := range coefficients {
-			term := big.NewInt(int64(i + 1))
-			term.Exp(term, big.NewInt(int64(j+1)), nil)
-			term.Mul(term, coefficients[j])
-			shares[i].Y.Add(shares[i].Y, term)
+// ShareSignedIntegers shares a signed secret with |secret| <= bound over the integers, and returns
+// ErrorSecretOutOfRange for a secret outside that range. Unlike ShareIntegers, the masks are sized
+// for the whole range [-bound, bound], so the shares of any two secrets in this range are
+// statistically indistinguishable with statSecParam bits of security. ShareCombine then returns the
+// secret with its sign, and fails with ErrorBoundExceeded rather than returning a secret outside the
+// range.
+func ShareSignedIntegers(secret *big.Int, bound *big.Int, statSecParam int, degree int, nShares int) ([]Share, error) {
+	if big.NewInt(0).Abs(secret).Cmp(bound) > 0 {
+		return nil, ErrorSecretOutOfRange
+	}
+	width := big.NewInt(0).Lsh(bound, 1)
+	coefficientUpperBound := integerCoefficientBound(width, statSecParam, nShares)
+	coefficients := make([]*big.Int, degree)
+	for i := range coefficients {
+		var err error
+		coefficients[i], err = rand.Int(rand.Reader, coefficientUpperBound)
+		if err != nil {
+			return nil, err
 		}
 	}
-	return shares
+	return shareIntegers(secret, bound, coefficients, nShares), nil
 }
 
 // ShareCombine combines a set of shares of the same secret and recovers the secret.
 // If too few shares are given, or the shares are incompatible, an error is returned instead.
+// For shares over the integers with a Bound, ErrorBoundExceeded is returned if the secret exceeds it.
+// If the shares have a sharing fingerprint, the error is annotated with their scheme, see
+// WithScheme, so compare it with errors.Is.
 func ShareCombine(shares []Share) (*big.Int, error) {
-	// Check that we have enough shares and that they're compatible
-	if len(shares) == 0 {
-		return nil, ErrorNoShares
-	}
-	if len(shares) <= shares[0].Degree {
-		return nil, ErrorTooFewShares
+	return emitCombine("", shares, shareCombine)
+}
+
+func shareCombine(shares []Share) (*big.Int, error) {
+	if err := checkCombinable(shares); err != nil {
+		return nil, err
 	}
-	for i := 1; i != len(shares); i++ {
-		if !equalOrBothNil(shares[0].FieldSize, shares[i].FieldSize) || shares[0].Degree != shares[i].Degree {
-			return nil, ErrorIncompatibleShares
+	shares = shares[:shares[0].Degree+1]
+
+	if shares[0].FieldSize != nil {
+		if secret := combineField(shares); secret != nil {
+			return secret, nil
 		}
+		// The field size is not prime. Rationals auto-normalize, but can't take into account the
+		// inversion rules in a finite field. We have to do this manually.
+		secret := combineRational(shares)
+		inverse := big.NewInt(0).ModInverse(secret.Denom(), shares[0].FieldSize)
+		if inverse == nil {
+			return nil, ErrorNotInvertible
+		}
+		return big.NewInt(0).Mod(secret.Num().Mul(secret.Num(), inverse), shares[0].FieldSize), nil
+	} else {
+		// If incompatible shares were used, this will result in a non-integer, or in an integer
+		// that is not a multiple of the factor
+		secret := combineRational(shares)
+		if !secret.IsInt() {
+			return nil, ErrorFractionalSecret
+		}
+		// Rationals auto-normalize, so if it's integer, we can just use the numerator
+		return integerSecret(secret.Num(), shares[0])
 	}
 
+}
+
+// combineRational interpolates the polynomial through shares at zero over the rationals.
+func combineRational(shares []Share) *big.Rat {
 	// Reconstruct the secret using en.wikipedia.org/wiki/Shamir's_Secret_Sharing#Computationally_efficient_approach
 	secret := big.NewRat(0, 1)
 	term := big.NewRat(0, 1)
-	for i := 0; i <= shares[0].Degree; i++ {
+	for i := range shares {
 		term.SetInt(shares[i].Y)
-		for j := 0; j <= shares[0].Degree; j++ {
+		for j := range shares {
 			if i == j {
 				continue
 			}
@@ -131,28 +167,83 @@ func ShareCombine(shares []Share) (*big.Int, error) {
 		}
 		secret.Add(secret, term)
 	}
+	return secret
+}
 
-	if shares[0].FieldSize != nil {
-		// Rationals auto-normalize, but can't take into account the inversion rules in
-		// a finite field. We have to do this manually.
-
-		return big.NewInt(0).Mod(secret.Num().Mul(
-			secret.Num(),
-			secret.Denom().ModInverse(secret.Denom(), shares[0].FieldSize),
-		), shares[0].FieldSize), nil
-	} else {
-		// If incompatible shares were used, this will result in a non-integer
-		if !secret.IsInt() {
-			return nil, ErrorFractionalSecret
+// combineField interpolates the polynomial through shares over their finite field at zero, like
+// combineRational but with a single modular inversion: the Lagrange coefficient of x_i is the
+// fraction of the integers prod x_j and prod (x_j - x_i), and the fractions are summed over the
+// product of all denominators. It returns nil if that product is not invertible, which can only
+// happen for fields whose size is not prime.
+func combineField(shares []Share) *big.Int {
+	p := shares[0].FieldSize
+	n := len(shares)
+	numerators := make([]*big.Int, n)
+	denominators := make([]*big.Int, n)
+	// prefix[i] is the product of the first i denominators
+	prefix := make([]*big.Int, n+1)
+	prefix[0] = big.NewInt(1)
+	tmp := big.NewInt(0)
+	for i := range shares {
+		numerators[i] = big.NewInt(0).Set(shares[i].Y)
+		denominators[i] = big.NewInt(1)
+		numerator, denominator := int64(1), int64(1)
+		for j := range shares {
+			if i != j {
+				numerator = mulSmall(numerators[i], numerator, int64(shares[j].X), p, tmp)
+				denominator = mulSmall(denominators[i], denominator, int64(shares[j].X-shares[i].X), p, tmp)
+			}
 		}
-		// Rationals auto-normalize, so if it's integer, we can just use the numerator
-		return big.NewInt(0).Div(secret.Num(), shares[0].Factor), nil
+		numerators[i].Mul(numerators[i], tmp.SetInt64(numerator)).Mod(numerators[i], p)
+		denominators[i].Mul(denominators[i], tmp.SetInt64(denominator)).Mod(denominators[i], p)
+		prefix[i+1] = big.NewInt(0).Mul(prefix[i], denominators[i])
+		prefix[i+1].Mod(prefix[i+1], p)
+	}
+	inverse := big.NewInt(0).ModInverse(prefix[n], p)
+	if inverse == nil {
+		return nil
+	}
+
+	// Going backwards, suffix is the product of the denominators after i, so that every numerator
+	// is multiplied by all denominators but its own
+	secret := big.NewInt(0)
+	suffix := big.NewInt(1)
+	term := big.NewInt(0)
+	for i := n - 1; i >= 0; i-- {
+		term.Mul(numerators[i], prefix[i]).Mod(term, p)
+		secret.Add(secret, term.Mul(term, suffix))
+		suffix.Mul(suffix, denominators[i]).Mod(suffix, p)
+	}
+	secret.Mod(secret, p)
+	return big.NewInt(0).Mod(secret.Mul(secret, inverse), p)
+}
+
+// mulSmall returns the product of a and b if it surely fits in an int64, and otherwise multiplies z
+// by a modulo p and returns b. It is used to collect small factors before multiplying a big.Int by
+// them, which saves most of the big.Int multiplications.
+func mulSmall(z *big.Int, a int64, b int64, p *big.Int, tmp *big.Int) int64 {
+	if a > -1<<31 && a < 1<<31 && b > -1<<31 && b < 1<<31 {
+		return a * b
 	}
+	z.Mul(z, tmp.SetInt64(a)).Mod(z, p)
+	return b
+}
 
+// integerSecret divides f(0), interpolated from shares over the integers with the parameters of
+// share, by their Factor, and checks the result against their Bound.
+func integerSecret(y *big.Int, share Share) (*big.Int, error) {
+	quotient, remainder := big.NewInt(0).QuoRem(y, share.Factor, big.NewInt(0))
+	if remainder.Sign() != 0 {
+		return nil, ErrorFractionalSecret
+	}
+	if share.Bound != nil && big.NewInt(0).Abs(quotient).Cmp(share.Bound) > 0 {
+		return nil, ErrorBoundExceeded
+	}
+	return quotient, nil
 }
 
 // ShareAdd adds shares of two secrets to produce a share of the sum of the secrets.
-// It requires a set of shares with equal X values, degrees, and field sizes.
+// It requires a set of shares with equal X values, degrees, field sizes and factors.
 func ShareAdd(shares []Share) (Share, error) {
 	if len(shares) == 0 {
 		return Share{}, ErrorNoShares
@@ -163,15 +254,17 @@ func ShareAdd(shares []Share) (Share, error) {
 		Factor:    shares[0].Factor,
 		X:         shares[0].X,
 		Y:         big.NewInt(0).Set(shares[0].Y),
+		Bound:     shares[0].Bound,
 	}
 	for i := 1; i != len(shares); i++ {
-		if !equalOrBothNil(shares[0].FieldSize, shares[i].FieldSize) || shares[0].Degree != shares[i].Degree || shares[0].X != shares[i].X {
+		if !equalOrBothNil(shares[0].FieldSize, shares[i].FieldSize) || !equalOrBothNil(shares[0].Factor, shares[i].Factor) || shares[0].Degree != shares[i].Degree || shares[0].X != shares[i].X {
 			return Share{}, ErrorIncompatibleShares
 		}
 		sum.Y.Add(sum.Y, shares[i].Y)
 		if sum.FieldSize != nil {
 			sum.Y.Mod(sum.Y, sum.FieldSize)
 		}
+		sum.Bound = addBounds(sum.Bound, shares[i].Bound)
 	}
 	return sum, nil
 }
@@ -188,6 +281,7 @@ func ShareMul(shares []Share) (Share, error) {
 		Degree:    shares[0].Degree,
 		X:         shares[0].X,
 		Y:         big.NewInt(0).Set(shares[0].Y),
+		Bound:     shares[0].Bound,
 	}
 	if shares[0].Factor != nil {
 		sum.Factor = big.NewInt(0).Set(shares[0].Factor)
@@ -204,10 +298,275 @@ func ShareMul(shares []Share) (Share, error) {
 		if sum.Factor != nil {
 			sum.Factor.Mul(sum.Factor, shares[i].Factor)
 		}
+		sum.Bound = mulBounds(sum.Bound, shares[i].Bound)
 	}
 	return sum, nil
 }
 
+// A DegreeReducer reduces the degree of shares by communicating with the other shareholders, such
+// as a Party with its Reshare method.
+type DegreeReducer interface {
+	Reshare(v ShareVector) (ShareVector, error)
+}
+
+// ShareMulChecked multiplies shares like ShareMul for a sharing with nShares shares, and returns
+// ErrorDegreeTooHigh instead of a product of degree nShares or more, which could never be combined.
+// If reducer is not nil, it multiplies the factors one by one and reduces the degree of the
+// intermediate product with reducer whenever the next multiplication would exceed the limit, so all
+// shareholders must call it with the same factors. It only returns ErrorDegreeTooHigh if the
+// product of two factors of reduced degree already exceeds the limit.
+func ShareMulChecked(shares []Share, nShares int, reducer DegreeReducer) (Share, error) {
+	if len(shares) == 0 {
+		return Share{}, ErrorNoShares
+	}
+	product, err := ShareMul(shares[:1])
+	if err != nil {
+		return Share{}, err
+	}
+	for _, share := range shares[1:] {
+		if product.Degree+share.Degree >= nShares && reducer != nil {
+			reduced, err := reducer.Reshare(ShareVector{product})
+			if err != nil {
+				return Share{}, err
+			}
+			product = reduced[0]
+		}
+		if product.Degree+share.Degree >= nShares {
+			return Share{}, ErrorDegreeTooHigh
+		}
+		if product, err = mulPair(product, share); err != nil {
+			return Share{}, err
+		}
+	}
+	return product, nil
+}
+
+// mulPair multiplies two shares like ShareMul, but allows their degrees to differ.
+func mulPair(a Share, b Share) (Share, error) {
+	if !equalOrBothNil(a.FieldSize, b.FieldSize) || a.X != b.X || (a.Factor == nil) != (b.Factor == nil) {
+		return Share{}, ErrorIncompatibleShares
+	}
+	product := Share{
+		FieldSize: a.FieldSize,
+		Degree:    a.Degree + b.Degree,
+		X:         a.X,
+		Y:         big.NewInt(0).Mul(a.Y, b.Y),
+		Bound:     mulBounds(a.Bound, b.Bound),
+	}
+	if product.FieldSize != nil {
+		product.Y.Mod(product.Y, product.FieldSize)
+	}
+	if a.Factor != nil {
+		product.Factor = big.NewInt(0).Mul(a.Factor, b.Factor)
+	}
+	return product, nil
+}
+
+// ShareAddConstant adds a public constant to the secret shared by share and returns the resulting share.
+func ShareAddConstant(share Share, constant *big.Int) Share {
+	result := Share{
+		FieldSize: share.FieldSize,
+		Degree:    share.Degree,
+		Factor:    share.Factor,
+		X:         share.X,
+		Y:         big.NewInt(0).Set(constant),
+	}
+	if result.Factor != nil {
+		result.Y.Mul(result.Y, result.Factor)
+	}
+	result.Y.Add(result.Y, share.Y)
+	if result.FieldSize != nil {
+		result.Y.Mod(result.Y, result.FieldSize)
+	}
+	result.Bound = addBounds(share.Bound, big.NewInt(0).Abs(constant))
+	return result
+}
+
+// ShareMulConstant multiplies the secret shared by share by a public constant and returns the
+// resulting share. Unlike ShareMul, this does not increase the degree.
+func ShareMulConstant(share Share, constant *big.Int) Share {
+	result := Share{
+		FieldSize: share.FieldSize,
+		Degree:    share.Degree,
+		Factor:    share.Factor,
+		X:         share.X,
+		Y:         big.NewInt(0).Mul(share.Y, constant),
+		Bound:     mulBounds(share.Bound, big.NewInt(0).Abs(constant)),
+	}
+	if result.FieldSize != nil {
+		result.Y.Mod(result.Y, result.FieldSize)
+	}
+	return result
+}
+
+// Normalize rescales a share over the integers to the given factor, which must be a multiple of the
+// factor of the share, and returns the resulting share of the same secret. For example, to add a
+// share with Factor n! to a product share with Factor (n!)^2, normalize the former to (n!)^2 first.
+// Note that the factor can only be increased locally.
+func Normalize(share Share, factor *big.Int) (Share, error) {
+	if share.FieldSize != nil || share.Factor == nil {
+		return Share{}, ErrorWrongShareType
+	}
+	scale, remainder := big.NewInt(0).DivMod(factor, share.Factor, big.NewInt(0))
+	if remainder.Sign() != 0 || scale.Sign() <= 0 {
+		return Share{}, ErrorIncompatibleFactor
+	}
+	return Share{
+		Degree: share.Degree,
+		Factor: big.NewInt(0).Set(factor),
+		X:      share.X,
+		Y:      big.NewInt(0).Mul(share.Y, scale),
+		Bound:  share.Bound,
+	}, nil
+}
+
+// shareFiniteField evaluates the polynomial with constant term secret and the given higher-order
+// coefficients at 1, ..., nShares modulo fieldSize.
+func shareFiniteField(secret *big.Int, fieldSize *big.Int, coefficients []*big.Int, nShares int) []Share {
+	xs := make([]int, nShares)
+	for i := range xs {
+		xs[i] = i + 1
+	}
+	return shareFiniteFieldAt(secret, fieldSize, coefficients, xs)
+}
+
+// shareFiniteFieldAt evaluates the polynomial with constant term secret and the given higher-order
+// coefficients at the given X coordinates modulo fieldSize.
+func shareFiniteFieldAt(secret *big.Int, fieldSize *big.Int, coefficients []*big.Int, xs []int) []Share {
+	event := Event{Kind: EventDealingStarted, FieldSize: fieldSize, Degree: len(coefficients), NShares: len(xs)}
+	emit(event)
+	event.Kind = EventShareIssued
+	shares := make([]Share, len(xs))
+	for i, x := range xs {
+		shares[i].FieldSize = fieldSize
+		shares[i].Degree = len(coefficients)
+		shares[i].X = x
+		shares[i].Y = evaluatePolynomial(secret, coefficients, x)
+		shares[i].Y.Mod(shares[i].Y, fieldSize)
+		event.X = x
+		emit(event)
+	}
+	return shares
+}
+
+// shareIntegers evaluates the polynomial with constant term secret*nShares! and the given
+// higher-order coefficients at 1, ..., nShares over the integers. The shares get the given bound.
+func shareIntegers(secret *big.Int, bound *big.Int, coefficients []*big.Int, nShares int) []Share {
+	if bound != nil && big.NewInt(0).Abs(secret).Cmp(bound) > 0 {
+		// A Bound that the secret already exceeds would make ShareCombine fail.
+		bound = nil
+	}
+	event := Event{Kind: EventDealingStarted, Degree: len(coefficients), NShares: nShares}
+	emit(event)
+	event.Kind = EventShareIssued
+	shares := make([]Share, nShares)
+	nFactorial := factorial(int64(nShares))
+	secret = big.NewInt(0).Mul(secret, nFactorial)
+	for i := range shares {
+		shares[i].Degree = len(coefficients)
+		shares[i].Factor = nFactorial
+		shares[i].Bound = bound
+		shares[i].X = i + 1
+		shares[i].Y = evaluatePolynomial(secret, coefficients, i+1)
+		event.X = i + 1
+		emit(event)
+	}
+	return shares
+}
+
+// integerCoefficientBound returns the exclusive upper bound on the coefficients used when sharing
+// over the integers, i.e. 2^statSecParam * nShares^2 * secretUpperBound.
+func integerCoefficientBound(secretUpperBound *big.Int, statSecParam int, nShares int) *big.Int {
+	coefficientUpperBound := big.NewInt(2)
+	return coefficientUpperBound.
+		Exp(coefficientUpperBound, big.NewInt(int64(statSecParam)), nil).
+		Mul(coefficientUpperBound, big.NewInt(int64(nShares*nShares))).
+		Mul(coefficientUpperBound, secretUpperBound)
+}
+
+// evaluatePolynomial computes f(x) == constant + sum(j) coeff[j] x^(j+1) over the integers.
+func evaluatePolynomial(constant *big.Int, coefficients []*big.Int, x int) *big.Int {
+	y := big.NewInt(0).Set(constant)
+	for j := range coefficients {
+		term := big.NewInt(int64(x))
+		term.Exp(term, big.NewInt(int64(j+1)), nil)
+		term.Mul(term, coefficients[j])
+		y.Add(y, term)
+	}
+	return y
+}
+
+// checkCombinable checks that enough shares are given, that they're well-formed and compatible, and
+// that their X coordinates are distinct.
+func checkCombinable(shares []Share) error {
+	if len(shares) == 0 {
+		return ErrorNoShares
+	}
+	if len(shares) <= shares[0].Degree {
+		return ErrorTooFewShares
+	}
+	for i := 1; i != len(shares); i++ {
+		if !equalOrBothNil(shares[0].FieldSize, shares[i].FieldSize) || !equalOrBothNil(shares[0].Factor, shares[i].Factor) || shares[0].Degree != shares[i].Degree ||
+			!sameFingerprint(shares[0], shares[i]) {
+			return ErrorIncompatibleShares
+		}
+	}
+	xs := make(map[int]bool, len(shares))
+	for i := range shares {
+		if !wellFormed(shares[i]) {
+			return ErrorInvalidShare
+		}
+		if xs[shares[i].X] {
+			return ErrorDuplicateX
+		}
+		xs[shares[i].X] = true
+	}
+	return nil
+}
+
+// wellFormed reports whether share can be combined with other shares without dividing by zero: it
+// has a value, a non-negative degree, and a positive field size or a non-zero factor.
+func wellFormed(share Share) bool {
+	if share.Y == nil || share.Degree < 0 {
+		return false
+	}
+	if share.FieldSize != nil {
+		return share.FieldSize.Sign() > 0
+	}
+	return share.Factor != nil && share.Factor.Sign() != 0
+}
+
+// SecurityLoss estimates by how many bits the statistical security of a share over the integers,
+// dealt by ShareIntegers with the given upper bound on the secret, has decreased by the operations
+// on it, i.e. the number of bits by which its Bound exceeds secretUpperBound. The masks of shares
+// are sized for secrets up to secretUpperBound, so a result that can be larger is hidden less well.
+// It returns 0 for shares without a Bound. The estimate is for linear operations; the shares of
+// products (see ShareMul) should additionally be re-randomized before they are revealed.
+func SecurityLoss(share Share, secretUpperBound *big.Int) int {
+	if share.Bound == nil || share.Bound.Cmp(secretUpperBound) <= 0 {
+		return 0
+	}
+	ratio := big.NewInt(0).Sub(share.Bound, big.NewInt(1))
+	ratio.Quo(ratio, secretUpperBound)
+	return ratio.BitLen()
+}
+
+// addBounds returns a+b, or nil if either bound is not tracked.
+func addBounds(a, b *big.Int) *big.Int {
+	if a == nil || b == nil {
+		return nil
+	}
+	return big.NewInt(0).Add(a, b)
+}
+
+// mulBounds returns a*b, or nil if either bound is not tracked.
+func mulBounds(a, b *big.Int) *big.Int {
+	if a == nil || b == nil {
+		return nil
+	}
+	return big.NewInt(0).Mul(a, b)
+}
+
 func equalOrBothNil(a, b *big.Int) bool {
 	if a == nil && b == nil {
 		return true
@@ -15,6 +15,7 @@
 package shamir
 
 import (
+	"fmt"
 	"math/big"
 	"testing"
 
@@ -53,6 +54,59 @@ func TestShamirSecretSharing(t *testing.T) {
 	}
 }
 
+func TestShamirSecretSharingFastPath(t *testing.T) {
+	assert := assert.New(t)
+	secret := big.NewInt(0).Sub(mersenne61, big.NewInt(5))
+	shares := ShareFiniteField(secret, mersenne61, 15, 40)
+	for _, quorum := range [][]Share{shares[:16], shares[24:], append(shares[30:], shares[:6]...)} {
+		recovered := combineField(quorum)
+		assert.Equal(secret, recovered)
+		rational := combineRational(quorum)
+		inverse := big.NewInt(0).ModInverse(rational.Denom(), mersenne61)
+		assert.Equal(recovered, inverse.Mul(inverse, rational.Num()).Mod(inverse, mersenne61))
+	}
+
+	// Over a ring, the common denominator may not be invertible while the reduced fraction is, and
+	// the result is that of the rational interpolation as before
+	shares = ShareFiniteField(big.NewInt(5), big.NewInt(1<<16), 1, 3)
+	quorum := []Share{shares[0], shares[2]}
+	assert.Nil(combineField(quorum))
+	recovered, err := ShareCombine(quorum)
+	assert.NoError(err)
+	rational := combineRational(quorum)
+	assert.True(rational.IsInt())
+	assert.Equal(rational.Num().Mod(rational.Num(), big.NewInt(1<<16)), recovered)
+}
+
+// benchmarkCombine benchmarks combining a minimal quorum of shares of the given degree.
+func benchmarkCombine(b *testing.B, combine func([]Share)) {
+	for _, degree := range []int{2, 10, 50} {
+		shares := ShareFiniteField(big.NewInt(42), mersenne61, degree, degree+1)
+		b.Run(fmt.Sprintf("Degree%d", degree), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				combine(shares)
+			}
+		})
+	}
+}
+
+func BenchmarkShareCombine(b *testing.B) {
+	benchmarkCombine(b, func(shares []Share) { ShareCombine(shares) })
+}
+
+func BenchmarkCombineField(b *testing.B) {
+	benchmarkCombine(b, func(shares []Share) { combineField(shares) })
+}
+
+// BenchmarkCombineRational benchmarks the interpolation over the rationals that ShareCombine used
+// for all shares before combineField, for comparison.
+func BenchmarkCombineRational(b *testing.B) {
+	benchmarkCombine(b, func(shares []Share) {
+		secret := combineRational(shares)
+		secret.Num().Mul(secret.Num(), big.NewInt(0).ModInverse(secret.Denom(), mersenne61))
+	})
+}
+
 func TestShamirSecretAddition(t *testing.T) {
 	assert := assert.New(t)
 	shares1 := ShareFiniteField(big.NewInt(123), big.NewInt(7919), 3, 4)
@@ -91,6 +145,58 @@ func TestShamirSecretMultiplication(t *testing.T) {
 	}
 }
 
+func TestShamirSecretMultiplicationChecked(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	a := ShareFiniteField(big.NewInt(12), fieldSize, 1, 5)
+	b := ShareFiniteField(big.NewInt(34), fieldSize, 1, 5)
+	c := ShareFiniteField(big.NewInt(56), fieldSize, 1, 5)
+
+	products := make([]Share, len(a))
+	for i := range a {
+		var err error
+		products[i], err = ShareMulChecked([]Share{a[i], b[i], c[i]}, 5, nil)
+		assert.NoError(err)
+		assert.Equal(3, products[i].Degree)
+	}
+	secret, err := ShareCombine(products[1:])
+	assert.NoError(err)
+	assert.Equal(big.NewInt(12*34*56%7919), secret)
+
+	// With four shares, the product of degree 4 could never be combined
+	_, err = ShareMulChecked([]Share{a[0], b[0], c[0], a[0]}, 4, nil)
+	assert.Equal(ErrorDegreeTooHigh, err)
+	_, err = ShareMulChecked([]Share{a[0], b[1]}, 5, nil)
+	assert.Equal(ErrorIncompatibleShares, err)
+	_, err = ShareMulChecked(nil, 5, nil)
+	assert.Equal(ErrorNoShares, err)
+
+	// Integer shares keep their factors
+	x := ShareIntegers(big.NewInt(6), big.NewInt(10), 40, 1, 3)
+	y := ShareIntegers(big.NewInt(7), big.NewInt(10), 40, 1, 3)
+	for i := range x {
+		x[i], err = ShareMulChecked([]Share{x[i], y[i]}, 3, nil)
+		assert.NoError(err)
+	}
+	secret, err = ShareCombine(x)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(42), secret)
+}
+
+func TestShamirSecretConstants(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareFiniteField(big.NewInt(123), big.NewInt(7919), 2, 3)
+	for i := range shares {
+		shares[i] = ShareMulConstant(ShareAddConstant(shares[i], big.NewInt(-23)), big.NewInt(-3))
+	}
+
+	secret, err := ShareCombine(shares)
+	assert.NoError(err)
+	if assert.NotNil(secret) {
+		assert.Equal(int64(7919-300), secret.Int64())
+	}
+}
+
 func TestIntegerSecretSharing(t *testing.T) {
 	assert := assert.New(t)
 	shares := ShareIntegers(big.NewInt(123), big.NewInt(10000), 100, 3, 5)
@@ -160,6 +266,124 @@ func TestIntegerSecretMultiplication(t *testing.T) {
 	}
 }
 
+func TestIntegerSecretConstants(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareIntegers(big.NewInt(123), big.NewInt(10000), 100, 2, 3)
+	for i := range shares {
+		shares[i] = ShareMulConstant(ShareAddConstant(shares[i], big.NewInt(-23)), big.NewInt(-3))
+	}
+
+	secret, err := ShareCombine(shares)
+	assert.NoError(err)
+	if assert.NotNil(secret) {
+		assert.Equal(int64(-300), secret.Int64())
+	}
+}
+
+func TestIntegerSecretNormalization(t *testing.T) {
+	assert := assert.New(t)
+	a := ShareIntegers(big.NewInt(12), big.NewInt(10000), 40, 1, 4)
+	b := ShareIntegers(big.NewInt(-5), big.NewInt(10000), 40, 1, 4)
+	c := ShareIntegers(big.NewInt(7), big.NewInt(10000), 40, 2, 4)
+
+	// a*b + c, where c is first brought to the factor and degree of the product
+	sums := make([]Share, len(a))
+	for i := range a {
+		product, err := ShareMul([]Share{a[i], b[i]})
+		assert.NoError(err)
+		normalized, err := Normalize(c[i], product.Factor)
+		assert.NoError(err)
+		assert.Equal(product.Factor, normalized.Factor)
+
+		_, err = ShareAdd([]Share{product, c[i]})
+		assert.Equal(ErrorIncompatibleShares, err)
+		sums[i], err = ShareAdd([]Share{product, normalized})
+		assert.NoError(err)
+	}
+	secret, err := ShareCombine(sums[:3])
+	assert.NoError(err)
+	assert.Equal(big.NewInt(-53), secret)
+
+	// Mixed factors are rejected instead of silently producing a wrong secret
+	normalized, err := Normalize(a[2], big.NewInt(48))
+	assert.NoError(err)
+	_, err = ShareCombine([]Share{a[0], a[1], normalized})
+	assert.Equal(ErrorIncompatibleShares, err)
+
+	_, err = Normalize(a[0], big.NewInt(36))
+	assert.Equal(ErrorIncompatibleFactor, err)
+	_, err = Normalize(ShareFiniteField(big.NewInt(1), big.NewInt(7919), 1, 2)[0], big.NewInt(2))
+	assert.Equal(ErrorWrongShareType, err)
+}
+
+func TestIntegerSecretBounds(t *testing.T) {
+	assert := assert.New(t)
+	upperBound := big.NewInt(1000)
+	a := ShareIntegers(big.NewInt(-12), upperBound, 40, 1, 3)
+	b := ShareIntegers(big.NewInt(34), upperBound, 40, 1, 3)
+	assert.Equal(upperBound, a[0].Bound)
+	assert.Equal(0, SecurityLoss(a[0], upperBound))
+
+	sum, err := ShareAdd([]Share{a[0], b[0]})
+	assert.NoError(err)
+	assert.Equal(big.NewInt(2000), sum.Bound)
+	assert.Equal(1, SecurityLoss(sum, upperBound))
+
+	product, err := ShareMul([]Share{a[0], b[0]})
+	assert.NoError(err)
+	assert.Equal(big.NewInt(1000000), product.Bound)
+	assert.Equal(10, SecurityLoss(product, upperBound))
+
+	shifted := ShareMulConstant(ShareAddConstant(a[0], big.NewInt(-24)), big.NewInt(-4))
+	assert.Equal(big.NewInt(4096), shifted.Bound)
+	assert.Equal(3, SecurityLoss(shifted, upperBound))
+
+	// A secret beyond the bound indicates that the shares are inconsistent
+	for i := range b {
+		b[i].Bound = big.NewInt(10)
+	}
+	_, err = ShareCombine(b)
+	assert.Equal(ErrorBoundExceeded, err)
+
+	// Secrets dealt beyond the bound do not track it
+	tooLarge := ShareIntegers(big.NewInt(20000), upperBound, 40, 1, 3)
+	assert.Nil(tooLarge[0].Bound)
+	secret, err := ShareCombine(tooLarge)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(20000), secret)
+	assert.Equal(0, SecurityLoss(tooLarge[0], upperBound))
+}
+
+func TestSignedIntegerSecretSharing(t *testing.T) {
+	assert := assert.New(t)
+	bound := big.NewInt(1000)
+	for _, secret := range []int64{-1000, -999, -1, 0, 1, 1000} {
+		shares, err := ShareSignedIntegers(big.NewInt(secret), bound, 40, 2, 5)
+		assert.NoError(err)
+		combined, err := ShareCombine(shares[2:])
+		assert.NoError(err)
+		assert.Equal(big.NewInt(secret), combined)
+	}
+
+	a, err := ShareSignedIntegers(big.NewInt(-600), bound, 40, 1, 3)
+	assert.NoError(err)
+	b, err := ShareSignedIntegers(big.NewInt(-700), bound, 40, 1, 3)
+	assert.NoError(err)
+	sums := make([]Share, len(a))
+	for i := range a {
+		sums[i], err = ShareAdd([]Share{a[i], b[i]})
+		assert.NoError(err)
+	}
+	combined, err := ShareCombine(sums)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(-1300), combined)
+
+	_, err = ShareSignedIntegers(big.NewInt(-1001), bound, 40, 2, 5)
+	assert.Equal(ErrorSecretOutOfRange, err)
+	_, err = ShareSignedIntegers(big.NewInt(1001), bound, 40, 2, 5)
+	assert.Equal(ErrorSecretOutOfRange, err)
+}
+
 func TestErrors(t *testing.T) {
 	assert := assert.New(t)
 
@@ -192,4 +416,26 @@ func TestErrors(t *testing.T) {
 	shares3[0].X = 500
 	_, err = ShareCombine(shares3)
 	assert.Equal(ErrorFractionalSecret, err)
+
+	// Malformed shares are rejected instead of causing a division by zero
+	shares3[0].X = shares3[1].X
+	_, err = ShareCombine(shares3)
+	assert.Equal(ErrorDuplicateX, err)
+	shares4 := ShareIntegers(big.NewInt(456), big.NewInt(7919), 100, 2, 5)
+	for i := range shares4 {
+		shares4[i].Factor = big.NewInt(0)
+	}
+	_, err = ShareCombine(shares4)
+	assert.Equal(ErrorInvalidShare, err)
+	shares5 := ShareFiniteField(big.NewInt(456), big.NewInt(7919), 2, 5)
+	shares5[2].Y = nil
+	_, err = ShareCombine(shares5)
+	assert.Equal(ErrorInvalidShare, err)
+	for i := range shares5 {
+		shares5[i].FieldSize = big.NewInt(12)
+		shares5[i].X = 2*i + 1
+		shares5[i].Y = big.NewInt(int64(i + 1))
+	}
+	_, err = ShareCombine(shares5)
+	assert.Equal(ErrorNotInvertible, err)
 }
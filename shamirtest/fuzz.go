@@ -0,0 +1,111 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamirtest
+
+// The fuzzing targets in this file follow the conventions of go-fuzz: they take the fuzzed input,
+// return 1 if it was interesting and 0 otherwise, and panic when an invariant is violated. With
+// native fuzzing, call them from a fuzz test:
+//
+//	func FuzzCombine(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, data []byte) { shamirtest.FuzzCombine(data) })
+//	}
+
+import (
+	"math/big"
+
+	"github.com/TNO-MPC/shamir"
+)
+
+// fuzzFieldSizes are the field sizes that the fuzzing targets choose from. FuzzSplitCombine skips the
+// first, which has too few X coordinates to share a secret.
+var fuzzFieldSizes = []*big.Int{
+	big.NewInt(2),
+	big.NewInt(7919),
+	shamir.Fast80().FieldSize,
+	shamir.Conservative128().FieldSize,
+}
+
+// FuzzCombine decodes shares with arbitrary parameters and values from data and combines them, to
+// check that ShareCombine returns an error instead of panicking on malformed shares.
+func FuzzCombine(data []byte) int {
+	shares := decodeShares(data)
+	if len(shares) == 0 {
+		return 0
+	}
+	shamir.ShareCombine(shares)
+	return 1
+}
+
+// FuzzSplitCombine shares a secret decoded from data with parameters decoded from data, and panics
+// if CheckCombine, CheckAdd, CheckMul or CheckConstant fails.
+func FuzzSplitCombine(data []byte) int {
+	if len(data) < 4 {
+		return 0
+	}
+	degree := int(data[1] % 4)
+	nShares := 2*degree + 1 + int(data[2]%4)
+	secret := big.NewInt(0).SetBytes(data[4:])
+	constant := big.NewInt(int64(int8(data[3])))
+
+	var shares []shamir.Share
+	if data[0]&1 == 0 {
+		fieldSize := fuzzFieldSizes[1+int(data[0]>>1)%(len(fuzzFieldSizes)-1)]
+		shares = shamir.ShareFiniteField(secret, fieldSize, degree, nShares)
+	} else {
+		bound := big.NewInt(0).Lsh(big.NewInt(1), uint(8*len(data[4:])))
+		shares = shamir.ShareIntegers(secret, bound, 40, degree, nShares)
+	}
+	if err := CheckCombine(shares, secret); err != nil {
+		panic(err)
+	}
+	if err := CheckAdd(shares, shares, secret, secret); err != nil {
+		panic(err)
+	}
+	if err := CheckMul(shares, shares, secret, secret); err != nil {
+		panic(err)
+	}
+	if err := CheckConstant(shares, secret, constant); err != nil {
+		panic(err)
+	}
+	return 1
+}
+
+// decodeShares decodes shares from data: a byte with the number of shares, a byte selecting a field
+// size or the integers, a byte with the degree and a byte with the factor or an arbitrary field
+// size, followed by an X and a Y byte for every share. Every value is taken as it is, so that the
+// shares can be malformed in every way that a decoded share can be.
+func decodeShares(data []byte) []shamir.Share {
+	if len(data) < 4 {
+		return nil
+	}
+	n, kind, degree, factor := int(data[0]%16), int(data[1]), int(int8(data[2])), int64(int8(data[3]))
+	data = data[4:]
+	if len(data) < 2*n {
+		return nil
+	}
+	shares := make([]shamir.Share, n)
+	for i := range shares {
+		shares[i] = shamir.Share{Degree: degree, X: int(int8(data[2*i])), Y: big.NewInt(int64(data[2*i+1]))}
+		switch {
+		case kind < len(fuzzFieldSizes):
+			shares[i].FieldSize = fuzzFieldSizes[kind]
+		case kind < 2*len(fuzzFieldSizes):
+			shares[i].Factor = big.NewInt(factor)
+		default:
+			shares[i].FieldSize = big.NewInt(factor)
+		}
+	}
+	return shares
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shamirtest checks invariants of secret sharing, for tests of code that extends or wraps
+// the shares of package shamir: that combining any sufficient subset of shares gives the secret,
+// that combining never panics, and that the homomorphic operations on shares are compatible with
+// the operations on the secrets. FuzzCombine and FuzzSplitCombine are fuzzing targets built on
+// these checks.
+package shamirtest
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/TNO-MPC/shamir"
+)
+
+var (
+	ErrorPanic           = errors.New("Function panicked")
+	ErrorCombineMismatch = errors.New("Combined shares do not give the expected secret")
+)
+
+// CheckNoPanic calls f and returns ErrorPanic if it panics.
+func CheckNoPanic(f func()) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = ErrorPanic
+		}
+	}()
+	f()
+	return nil
+}
+
+// CheckCombine checks that the shares combine to secret, modulo the field size for shares over a
+// finite field. It combines all shares, and every run of degree+1 consecutive shares, wrapping
+// around, so that every share is used at every position of a combination. Errors of ShareCombine
+// are returned as they are, and a panic as ErrorPanic.
+func CheckCombine(shares []shamir.Share, secret *big.Int) error {
+	if len(shares) == 0 {
+		return shamir.ErrorNoShares
+	}
+	expected := reduce(secret, shares[0])
+	if err := checkCombined(shares, expected); err != nil {
+		return err
+	}
+	threshold := shares[0].Degree + 1
+	if threshold > len(shares) {
+		return shamir.ErrorTooFewShares
+	}
+	subset := make([]shamir.Share, threshold)
+	for start := range shares {
+		for i := range subset {
+			subset[i] = shares[(start+i)%len(shares)]
+		}
+		if err := checkCombined(subset, expected); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckAdd checks that adding the shares of a and b share by share gives shares of secretA+secretB.
+// The shares must be ordered alike, so that a[i] and b[i] have the same X coordinate.
+func CheckAdd(a []shamir.Share, b []shamir.Share, secretA *big.Int, secretB *big.Int) error {
+	if len(a) != len(b) {
+		return shamir.ErrorVectorLength
+	}
+	sums := make([]shamir.Share, len(a))
+	for i := range a {
+		var err error
+		if panicked := CheckNoPanic(func() { sums[i], err = shamir.ShareAdd([]shamir.Share{a[i], b[i]}) }); panicked != nil {
+			return panicked
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return CheckCombine(sums, big.NewInt(0).Add(secretA, secretB))
+}
+
+// CheckMul checks that multiplying the shares of a and b share by share gives shares of
+// secretA*secretB, of the sum of their degrees. At least degree+1 of these shares are needed.
+func CheckMul(a []shamir.Share, b []shamir.Share, secretA *big.Int, secretB *big.Int) error {
+	if len(a) != len(b) {
+		return shamir.ErrorVectorLength
+	}
+	products := make([]shamir.Share, len(a))
+	for i := range a {
+		var err error
+		if panicked := CheckNoPanic(func() { products[i], err = shamir.ShareMul([]shamir.Share{a[i], b[i]}) }); panicked != nil {
+			return panicked
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return CheckCombine(products, big.NewInt(0).Mul(secretA, secretB))
+}
+
+// CheckConstant checks that adding a constant to, and multiplying by a constant, the shares of a
+// secret gives shares of secret+constant and secret*constant.
+func CheckConstant(shares []shamir.Share, secret *big.Int, constant *big.Int) error {
+	sums := make([]shamir.Share, len(shares))
+	products := make([]shamir.Share, len(shares))
+	if err := CheckNoPanic(func() {
+		for i, share := range shares {
+			sums[i] = shamir.ShareAddConstant(share, constant)
+			products[i] = shamir.ShareMulConstant(share, constant)
+		}
+	}); err != nil {
+		return err
+	}
+	if err := CheckCombine(sums, big.NewInt(0).Add(secret, constant)); err != nil {
+		return err
+	}
+	return CheckCombine(products, big.NewInt(0).Mul(secret, constant))
+}
+
+// checkCombined checks that ShareCombine recovers the expected secret from shares.
+func checkCombined(shares []shamir.Share, expected *big.Int) error {
+	var combined *big.Int
+	var err error
+	if panicked := CheckNoPanic(func() { combined, err = shamir.ShareCombine(shares) }); panicked != nil {
+		return panicked
+	}
+	if err != nil {
+		return err
+	}
+	if combined.Cmp(expected) != 0 {
+		return ErrorCombineMismatch
+	}
+	return nil
+}
+
+// reduce returns secret modulo the field size of share, or secret itself for shares over the
+// integers.
+func reduce(secret *big.Int, share shamir.Share) *big.Int {
+	if share.FieldSize == nil {
+		return secret
+	}
+	return big.NewInt(0).Mod(secret, share.FieldSize)
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamirtest
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckNoPanic(t *testing.T) {
+	assert := assert.New(t)
+	assert.NoError(CheckNoPanic(func() {}))
+	assert.Equal(ErrorPanic, CheckNoPanic(func() { panic("panic") }))
+}
+
+func TestCheckCombine(t *testing.T) {
+	assert := assert.New(t)
+	secret := big.NewInt(-42)
+	fieldSize := big.NewInt(7919)
+	shares := shamir.ShareFiniteField(secret, fieldSize, 2, 5)
+	assert.NoError(CheckCombine(shares, secret))
+	assert.NoError(CheckAdd(shares, shares, secret, secret))
+	assert.NoError(CheckMul(shares, shares, secret, secret))
+	assert.NoError(CheckConstant(shares, secret, big.NewInt(-3)))
+
+	integers := shamir.ShareIntegers(big.NewInt(42), big.NewInt(100), 40, 1, 4)
+	assert.NoError(CheckCombine(integers, big.NewInt(42)))
+	assert.NoError(CheckMul(integers, integers, big.NewInt(42), big.NewInt(42)))
+	assert.NoError(CheckConstant(integers, big.NewInt(42), big.NewInt(5)))
+
+	assert.Equal(ErrorCombineMismatch, CheckCombine(shares, big.NewInt(43)))
+	wrong := append([]shamir.Share{}, shares...)
+	wrong[3].Y = big.NewInt(0).Add(wrong[3].Y, big.NewInt(1))
+	assert.Equal(ErrorCombineMismatch, CheckCombine(wrong, secret))
+	assert.Equal(shamir.ErrorTooFewShares, CheckMul(shares[:4], shares[:4], secret, secret))
+	assert.Equal(shamir.ErrorVectorLength, CheckAdd(shares, shares[1:], secret, secret))
+	assert.Equal(shamir.ErrorIncompatibleShares, CheckAdd(shares[:4], integers, secret, secret))
+	assert.Equal(shamir.ErrorNoShares, CheckCombine(nil, secret))
+	wrong[3].Y = nil
+	assert.Equal(ErrorPanic, CheckAdd(wrong, shares, secret, secret))
+}
+
+func TestFuzz(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(0, FuzzCombine(nil))
+	assert.Equal(1, FuzzCombine([]byte{3, 1, 1, 0, 1, 10, 2, 20, 3, 30}))
+	assert.Equal(0, FuzzSplitCombine(nil))
+	assert.Equal(1, FuzzSplitCombine([]byte{0, 1, 0, 7, 42}))
+
+	// The targets must not panic on any input
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		data := make([]byte, random.Intn(40))
+		random.Read(data)
+		assert.NoError(CheckNoPanic(func() { FuzzCombine(data) }), "%x", data)
+		if i%10 == 0 {
+			assert.NoError(CheckNoPanic(func() { FuzzSplitCombine(data) }), "%x", data)
+		}
+	}
+}
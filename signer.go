@@ -0,0 +1,273 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The threshold signatures in this file are RSA signatures after Shoup, "Practical threshold
+// signatures" (EUROCRYPT 2000), with a trusted dealer. The private exponent d is shared over the
+// integers, so that the shares are y_i = f(i) with f(0) = D * d for D = nShares!. A member signs an
+// encoded message x with the partial signature x^y_i mod N. Since D times a Lagrange coefficient is
+// an integer, the partial signatures of degree+1 members combine to w = x^(D^2 * d), and with
+// a * D^2 + b * e = 1, the signature is w^a * x^b. The result is an ordinary RSA signature.
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+)
+
+var (
+	ErrorInvalidExponent = errors.New("Public exponent is not coprime to the factor of the shares")
+	ErrorInvalidDigest   = errors.New("Digest does not match the hash function or the key size")
+	ErrorSigning         = errors.New("No quorum of partial signatures gives a valid signature")
+)
+
+// pkcs1Prefixes are the DER encodings of the DigestInfo of PKCS #1 v1.5 signatures, up to the
+// digest itself.
+var pkcs1Prefixes = map[crypto.Hash][]byte{
+	crypto.SHA1:   {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
+	crypto.SHA224: {0x30, 0x2d, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x04, 0x05, 0x00, 0x04, 0x1c},
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// ShareRSAKey shares the private exponent of key over the integers among nShares members, such
+// that any degree+1 of them can sign with CommitteeSigner. The public exponent must be coprime to
+// nShares!, which holds for the usual exponent 65537 and fewer than 65537 members. The shares and
+// key.Public() are all that the members and signers need; key should be destroyed afterwards.
+func ShareRSAKey(key *rsa.PrivateKey, statSecParam int, degree int, nShares int) ([]Share, error) {
+	if big.NewInt(0).GCD(nil, nil, factorial(int64(nShares)), big.NewInt(int64(key.E))).Cmp(big.NewInt(1)) != 0 {
+		return nil, ErrorInvalidExponent
+	}
+	return ShareIntegers(key.D, key.N, statSecParam, degree, nShares), nil
+}
+
+// SignShare returns the partial signature of a member holding share, dealt by ShareRSAKey, on an
+// encoded message. Like the shares themselves, partial signatures should only be sent to parties
+// entitled to sign.
+func SignShare(share Share, publicKey *rsa.PublicKey, message *big.Int) (*big.Int, error) {
+	if share.Y == nil || share.FieldSize != nil || share.Factor == nil {
+		return nil, ErrorWrongShareType
+	}
+	if message.Sign() < 0 || message.Cmp(publicKey.N) >= 0 {
+		return nil, ErrorInvalidDigest
+	}
+	partial := big.NewInt(0).Exp(message, share.Y, publicKey.N)
+	if partial == nil {
+		return nil, ErrorInvalidDigest
+	}
+	return partial, nil
+}
+
+// A PartialSigner returns the partial signature of the member with X coordinate x on an encoded
+// message, see SignShare. Implementations usually ask the member over the network.
+type PartialSigner func(x int, message *big.Int) (*big.Int, error)
+
+// A CommitteeSigner is a crypto.Signer for an RSA key shared with ShareRSAKey, so that the key can
+// be used with crypto/tls, crypto/x509 and other libraries without them knowing that it is split.
+// Sign gathers the partial signatures of the members and combines them; no single party, including
+// the caller, ever holds the private key.
+type CommitteeSigner struct {
+	publicKey *rsa.PublicKey
+	members   []int
+	degree    int
+	factor    *big.Int
+	partial   PartialSigner
+}
+
+// NewCommitteeSigner returns a signer for the committee with the given public key, whose nShares
+// members hold shares of the given degree. Partial obtains the partial signatures of the members
+// with X coordinates members.
+func NewCommitteeSigner(publicKey *rsa.PublicKey, members []int, degree int, nShares int, partial PartialSigner) *CommitteeSigner {
+	return &CommitteeSigner{publicKey: publicKey, members: members, degree: degree, factor: factorial(int64(nShares)), partial: partial}
+}
+
+// Public returns the public key of the committee, an *rsa.PublicKey.
+func (s *CommitteeSigner) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign signs a digest like rsa.PrivateKey.Sign: with RSASSA-PSS if opts is an *rsa.PSSOptions,
+// drawing the salt from random, and with PKCS #1 v1.5 otherwise. All members are asked
+// concurrently, and members that fail or return wrong partial signatures are tolerated as long as
+// degree+1 members answer correctly. It returns ErrorSigning if no quorum of partial signatures
+// gives a valid signature, and ErrorTooFewShares if fewer than degree+1 members answer.
+func (s *CommitteeSigner) Sign(random io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	var encoded []byte
+	var err error
+	if pss, ok := opts.(*rsa.PSSOptions); ok {
+		encoded, err = encodePSS(random, digest, pss.HashFunc(), pss.SaltLength, s.publicKey.N.BitLen()-1)
+	} else {
+		encoded, err = encodePKCS1v15(digest, opts.HashFunc(), (s.publicKey.N.BitLen()+7)/8)
+	}
+	if err != nil {
+		return nil, err
+	}
+	message := big.NewInt(0).SetBytes(encoded)
+
+	results := make([]*big.Int, len(s.members))
+	errs := make([]error, len(s.members))
+	var wg sync.WaitGroup
+	for i, x := range s.members {
+		wg.Add(1)
+		go func(i int, x int) {
+			defer wg.Done()
+			results[i], errs[i] = s.partial(x, message)
+		}(i, x)
+	}
+	wg.Wait()
+	var partials []*big.Int
+	var xs []int
+	var firstErr error
+	for i, result := range results {
+		if errs[i] != nil || result == nil {
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		partials = append(partials, result)
+		xs = append(xs, s.members[i])
+	}
+	if len(partials) < s.degree+1 {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, ErrorTooFewShares
+	}
+
+	// Wrong partial signatures give an invalid signature, so try quorums until one verifies; with
+	// honest members the first one does.
+	var signature *big.Int
+	found := searchQuorums(len(partials), s.degree+1, func(quorum []int) bool {
+		selected := make([]*big.Int, len(quorum))
+		selectedXs := make([]int, len(quorum))
+		for i, j := range quorum {
+			selected[i], selectedXs[i] = partials[j], xs[j]
+		}
+		signature = s.combine(message, selected, selectedXs)
+		return signature != nil && big.NewInt(0).Exp(signature, big.NewInt(int64(s.publicKey.E)), s.publicKey.N).Cmp(message) == 0
+	})
+	if !found {
+		return nil, ErrorSigning
+	}
+	return signature.FillBytes(make([]byte, (s.publicKey.N.BitLen()+7)/8)), nil
+}
+
+// combine combines the partial signatures of the members with X coordinates xs on message, or
+// returns nil if a partial signature is not invertible modulo N.
+func (s *CommitteeSigner) combine(message *big.Int, partials []*big.Int, xs []int) *big.Int {
+	n := s.publicKey.N
+	w := big.NewInt(1)
+	for i, partial := range partials {
+		// The integer D * lambda_i, where lambda_i is the Lagrange coefficient of xs[i] at 0
+		numerator := big.NewInt(0).Set(s.factor)
+		denominator := big.NewInt(1)
+		for j := range xs {
+			if i != j {
+				numerator.Mul(numerator, big.NewInt(int64(xs[j])))
+				denominator.Mul(denominator, big.NewInt(int64(xs[j]-xs[i])))
+			}
+		}
+		term := big.NewInt(0).Exp(partial, numerator.Quo(numerator, denominator), n)
+		if term == nil {
+			return nil
+		}
+		w.Mul(w, term).Mod(w, n)
+	}
+	a, b := big.NewInt(0), big.NewInt(0)
+	big.NewInt(0).GCD(a, b, big.NewInt(0).Mul(s.factor, s.factor), big.NewInt(int64(s.publicKey.E)))
+	xb := big.NewInt(0).Exp(message, b, n)
+	if w.Exp(w, a, n) == nil || xb == nil {
+		return nil
+	}
+	return w.Mul(w, xb).Mod(w, n)
+}
+
+// encodePKCS1v15 returns the EMSA-PKCS1-v1_5 encoding of a digest of size bytes. A zero hash signs
+// the digest as is.
+func encodePKCS1v15(digest []byte, hash crypto.Hash, size int) ([]byte, error) {
+	var prefix []byte
+	if hash != 0 {
+		var ok bool
+		if prefix, ok = pkcs1Prefixes[hash]; !ok || len(digest) != hash.Size() {
+			return nil, ErrorInvalidDigest
+		}
+	}
+	if size < len(prefix)+len(digest)+11 {
+		return nil, ErrorInvalidDigest
+	}
+	encoded := make([]byte, size)
+	encoded[1] = 1
+	for i := 2; i < size-len(prefix)-len(digest)-1; i++ {
+		encoded[i] = 0xff
+	}
+	copy(encoded[size-len(prefix)-len(digest):], prefix)
+	copy(encoded[size-len(digest):], digest)
+	return encoded, nil
+}
+
+// encodePSS returns the EMSA-PSS encoding of a digest in bits bits, with MGF1 over the same hash
+// and a salt of the length given as in rsa.PSSOptions.
+func encodePSS(random io.Reader, digest []byte, hash crypto.Hash, saltLength int, bits int) ([]byte, error) {
+	if !hash.Available() || len(digest) != hash.Size() {
+		return nil, ErrorInvalidDigest
+	}
+	size := (bits + 7) / 8
+	switch saltLength {
+	case rsa.PSSSaltLengthAuto:
+		saltLength = size - 2 - hash.Size()
+	case rsa.PSSSaltLengthEqualsHash:
+		saltLength = hash.Size()
+	}
+	if saltLength < 0 || size < hash.Size()+saltLength+2 {
+		return nil, ErrorInvalidDigest
+	}
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return nil, err
+	}
+
+	h := hash.New()
+	h.Write(make([]byte, 8))
+	h.Write(digest)
+	h.Write(salt)
+	mHash := h.Sum(nil)
+
+	encoded := make([]byte, size)
+	db := encoded[:size-hash.Size()-1]
+	db[len(db)-saltLength-1] = 1
+	copy(db[len(db)-saltLength:], salt)
+	// MGF1: xor db with the hashes of mHash and a counter
+	for counter, done := uint32(0), 0; done < len(db); counter++ {
+		h.Reset()
+		h.Write(mHash)
+		h.Write([]byte{byte(counter >> 24), byte(counter >> 16), byte(counter >> 8), byte(counter)})
+		for _, b := range h.Sum(nil) {
+			if done == len(db) {
+				break
+			}
+			db[done] ^= b
+			done++
+		}
+	}
+	db[0] &= 0xff >> (8*size - bits)
+	copy(encoded[len(db):], mHash)
+	encoded[size-1] = 0xbc
+	return encoded, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitteeSigner(t *testing.T) {
+	assert := assert.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(err)
+	shares, err := ShareRSAKey(key, 40, 2, 5)
+	assert.NoError(err)
+
+	offline := errors.New("offline")
+	var wrong, down map[int]bool
+	partial := func(x int, message *big.Int) (*big.Int, error) {
+		if down[x] {
+			return nil, offline
+		}
+		result, err := SignShare(shares[x-1], &key.PublicKey, message)
+		if wrong[x] {
+			result.Add(result, big.NewInt(1))
+		}
+		return result, err
+	}
+	var signer crypto.Signer = NewCommitteeSigner(&key.PublicKey, []int{1, 2, 3, 4, 5}, 2, 5, partial)
+	assert.Equal(&key.PublicKey, signer.Public())
+
+	digest := sha256.Sum256([]byte("Signed by three of five"))
+	signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	assert.NoError(err)
+	assert.NoError(rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+	expected, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, digest[:])
+	assert.NoError(err)
+	assert.Equal(expected, signature)
+	for _, saltLength := range []int{rsa.PSSSaltLengthAuto, rsa.PSSSaltLengthEqualsHash, 10} {
+		opts := &rsa.PSSOptions{SaltLength: saltLength, Hash: crypto.SHA256}
+		signature, err = signer.Sign(rand.Reader, digest[:], opts)
+		assert.NoError(err)
+		assert.NoError(rsa.VerifyPSS(&key.PublicKey, crypto.SHA256, digest[:], signature, opts))
+	}
+
+	// Failing and cheating members are tolerated as long as a quorum is honest
+	wrong, down = map[int]bool{1: true}, map[int]bool{4: true}
+	signature, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	assert.NoError(err)
+	assert.Equal(expected, signature)
+	wrong, down = map[int]bool{1: true, 2: true}, map[int]bool{4: true}
+	_, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	assert.Equal(ErrorSigning, err)
+	wrong, down = nil, map[int]bool{1: true, 2: true, 3: true}
+	_, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	assert.Equal(offline, err)
+	down = nil
+
+	// The signer can be used wherever a crypto.Signer is expected
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "committee"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	assert.NoError(err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(err)
+	assert.NoError(certificate.CheckSignature(certificate.SignatureAlgorithm, certificate.RawTBSCertificate, certificate.Signature))
+
+	_, err = signer.Sign(rand.Reader, digest[:20], crypto.SHA256)
+	assert.Equal(ErrorInvalidDigest, err)
+	_, err = SignShare(ShareFiniteField(big.NewInt(1), big.NewInt(7919), 1, 2)[0], &key.PublicKey, big.NewInt(2))
+	assert.Equal(ErrorWrongShareType, err)
+	key.E = 3
+	_, err = ShareRSAKey(key, 40, 2, 5)
+	assert.Equal(ErrorInvalidExponent, err)
+}
@@ -0,0 +1,206 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simulation runs multiparty protocols built on shamir.Party among parties in the same
+// process, connected by channels, with hooks to inject faults. It is intended for testing and
+// developing protocols.
+package simulation
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/TNO-MPC/shamir"
+)
+
+var (
+	ErrorAborted = errors.New("Another party aborted the simulation")
+)
+
+// A Message is a message sent from one party to another in a round of the simulation.
+type Message struct {
+	Round  int
+	From   int
+	To     int
+	Shares []shamir.NestedShare
+}
+
+// A Simulation runs parties in the same process, connected by channels.
+type Simulation struct {
+	NParties  int
+	FieldSize *big.Int
+	Degree    int
+
+	// Fault, if set, is called for every message before it is delivered. It may modify the message,
+	// for instance to corrupt shares, or replace its shares by nil to drop it. The parties run
+	// concurrently, but the calls are serialized, so Fault may keep state without locking. The order
+	// of the calls within a round is not defined.
+	Fault func(message *Message)
+
+	faultMutex sync.Mutex
+}
+
+// New returns a Simulation of nParties parties that share secrets over the finite field of integers
+// modulo fieldSize with the given degree.
+func New(nParties int, fieldSize *big.Int, degree int) *Simulation {
+	return &Simulation{
+		NParties:  nParties,
+		FieldSize: fieldSize,
+		Degree:    degree,
+	}
+}
+
+// Run runs protocol for every party concurrently and returns the results, ordered by X coordinate.
+// If the protocol of any party fails, the other parties are aborted and the first error is returned.
+func (s *Simulation) Run(protocol func(p *shamir.Party) (interface{}, error)) ([]interface{}, error) {
+	channels := make([][]chan []shamir.NestedShare, s.NParties)
+	for i := range channels {
+		channels[i] = make([]chan []shamir.NestedShare, s.NParties)
+		for j := range channels[i] {
+			channels[i][j] = make(chan []shamir.NestedShare, 1)
+		}
+	}
+	aborted := make(chan struct{})
+	var abortOnce sync.Once
+	var firstErr error
+
+	results := make([]interface{}, s.NParties)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			network := &network{
+				simulation: s,
+				x:          i + 1,
+				channels:   channels,
+				aborted:    aborted,
+			}
+			var err error
+			results[i], err = protocol(shamir.NewParty(i+1, s.NParties, s.FieldSize, s.Degree, network))
+			if err != nil {
+				abortOnce.Do(func() {
+					firstErr = err
+					close(aborted)
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// Deal lets the party with X coordinate dealer share secrets among all parties, and returns the
+// ShareVectors of all parties.
+func (s *Simulation) Deal(dealer int, secrets []*big.Int) ([]shamir.ShareVector, error) {
+	return s.runVectors(func(p *shamir.Party) (shamir.ShareVector, error) {
+		if p.X() == dealer {
+			return p.Input(dealer, secrets, len(secrets))
+		}
+		return p.Input(dealer, nil, len(secrets))
+	})
+}
+
+// DKG lets the parties jointly generate shares of count random secrets that no party knows, and
+// returns the ShareVectors of all parties.
+func (s *Simulation) DKG(count int) ([]shamir.ShareVector, error) {
+	return s.runVectors(func(p *shamir.Party) (shamir.ShareVector, error) {
+		return p.Random(count)
+	})
+}
+
+// Refresh lets the parties refresh their ShareVectors, and returns the refreshed ShareVectors.
+func (s *Simulation) Refresh(vectors []shamir.ShareVector) ([]shamir.ShareVector, error) {
+	return s.runVectors(func(p *shamir.Party) (shamir.ShareVector, error) {
+		return p.Refresh(vectors[p.X()-1])
+	})
+}
+
+// Multiply lets the parties multiply the secrets shared by a and b element-wise, and returns the
+// ShareVectors of the products.
+func (s *Simulation) Multiply(a []shamir.ShareVector, b []shamir.ShareVector) ([]shamir.ShareVector, error) {
+	return s.runVectors(func(p *shamir.Party) (shamir.ShareVector, error) {
+		return p.Mul(a[p.X()-1], b[p.X()-1])
+	})
+}
+
+// Open lets the parties open their ShareVectors, and returns the secrets as opened by every party.
+func (s *Simulation) Open(vectors []shamir.ShareVector) ([][]*big.Int, error) {
+	results, err := s.Run(func(p *shamir.Party) (interface{}, error) {
+		return p.Open(vectors[p.X()-1])
+	})
+	if err != nil {
+		return nil, err
+	}
+	opened := make([][]*big.Int, len(results))
+	for i := range results {
+		opened[i] = results[i].([]*big.Int)
+	}
+	return opened, nil
+}
+
+func (s *Simulation) runVectors(protocol func(p *shamir.Party) (shamir.ShareVector, error)) ([]shamir.ShareVector, error) {
+	results, err := s.Run(func(p *shamir.Party) (interface{}, error) {
+		return protocol(p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([]shamir.ShareVector, len(results))
+	for i := range results {
+		vectors[i] = results[i].(shamir.ShareVector)
+	}
+	return vectors, nil
+}
+
+// network implements shamir.Network for a single party of a simulation.
+type network struct {
+	simulation *Simulation
+	x          int
+	round      int
+	channels   [][]chan []shamir.NestedShare
+	aborted    chan struct{}
+}
+
+func (n *network) Exchange(outgoing [][]shamir.NestedShare) ([][]shamir.NestedShare, error) {
+	n.round++
+	for i := range outgoing {
+		shares := outgoing[i]
+		if n.simulation.Fault != nil {
+			message := &Message{Round: n.round, From: n.x, To: i + 1, Shares: shares}
+			n.simulation.faultMutex.Lock()
+			n.simulation.Fault(message)
+			n.simulation.faultMutex.Unlock()
+			shares = message.Shares
+		}
+		select {
+		case n.channels[n.x-1][i] <- shares:
+		case <-n.aborted:
+			return nil, ErrorAborted
+		}
+	}
+	received := make([][]shamir.NestedShare, len(outgoing))
+	for i := range received {
+		select {
+		case received[i] = <-n.channels[i][n.x-1]:
+		case <-n.aborted:
+			return nil, ErrorAborted
+		}
+	}
+	return received, nil
+}
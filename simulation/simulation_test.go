@@ -0,0 +1,90 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulation
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulation(t *testing.T) {
+	assert := assert.New(t)
+	s := New(5, big.NewInt(7919), 2)
+
+	a, err := s.Deal(1, []*big.Int{big.NewInt(6), big.NewInt(7)})
+	assert.NoError(err)
+	b, err := s.Deal(4, []*big.Int{big.NewInt(10), big.NewInt(20)})
+	assert.NoError(err)
+	products, err := s.Multiply(a, b)
+	assert.NoError(err)
+	refreshed, err := s.Refresh(products)
+	assert.NoError(err)
+	assert.NotEqual(products[0][0].Y, refreshed[0][0].Y)
+
+	opened, err := s.Open(refreshed)
+	assert.NoError(err)
+	for _, secrets := range opened {
+		assert.Equal([]*big.Int{big.NewInt(60), big.NewInt(140)}, secrets)
+	}
+
+	random, err := s.DKG(3)
+	assert.NoError(err)
+	assert.Len(random, 5)
+	assert.Len(random[0], 3)
+}
+
+func TestSimulationFaults(t *testing.T) {
+	assert := assert.New(t)
+	s := New(3, big.NewInt(7919), 1)
+	shares, err := s.Deal(1, []*big.Int{big.NewInt(42)})
+	assert.NoError(err)
+
+	// Corrupt the share that party 1 sends to party 2 when opening
+	s.Fault = func(message *Message) {
+		if message.From == 1 && message.To == 2 {
+			corrupted := message.Shares[0]
+			corrupted.Y = big.NewInt(0).Add(corrupted.Y, big.NewInt(1))
+			message.Shares = []shamir.NestedShare{corrupted}
+		}
+	}
+	opened, err := s.Open(shares)
+	assert.NoError(err)
+	assert.Equal(int64(42), opened[0][0].Int64())
+	assert.NotEqual(int64(42), opened[1][0].Int64())
+
+	// Drop the message that party 3 sends to party 1, which aborts the simulation
+	s.Fault = func(message *Message) {
+		if message.From == 3 && message.To == 1 {
+			message.Shares = nil
+		}
+	}
+	_, err = s.Deal(3, []*big.Int{big.NewInt(42)})
+	assert.Equal(shamir.ErrorNetwork, err)
+}
+
+func TestSimulationFaultsSerialized(t *testing.T) {
+	assert := assert.New(t)
+	s := New(5, big.NewInt(7919), 2)
+	messages := 0
+	s.Fault = func(message *Message) {
+		messages++
+	}
+	_, err := s.Deal(1, []*big.Int{big.NewInt(42)})
+	assert.NoError(err)
+	assert.Equal(25, messages)
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+)
+
+// Sort obliviously sorts the secrets shared by v in ascending order and returns fresh shares of the
+// sorted vector. The secrets must lie in [0, 2^bits), see LessThan. It evaluates Batcher's odd-even
+// merge sort network, performing all compare-exchange operations of a layer in parallel, so neither
+// the secrets nor their original positions are revealed. Once sorted, the shares of the minimum,
+// maximum, median or any other order statistic can be picked directly.
+func (p *Party) Sort(v ShareVector, bits int) (ShareVector, error) {
+	sorted := append(ShareVector{}, v...)
+	for _, layer := range sortingNetwork(len(v)) {
+		low := make(ShareVector, len(layer))
+		high := make(ShareVector, len(layer))
+		for i, comparator := range layer {
+			low[i], high[i] = sorted[comparator[0]], sorted[comparator[1]]
+		}
+		swap, err := p.LessThan(high, low, bits)
+		if err != nil {
+			return nil, err
+		}
+
+		// min = low + swap (high - low), max = high - swap (high - low)
+		differences := make(ShareVector, len(layer))
+		for i := range layer {
+			differences[i], err = ShareAdd([]Share{high[i], ShareMulConstant(low[i], big.NewInt(-1))})
+			if err != nil {
+				return nil, err
+			}
+		}
+		deltas, err := p.Mul(swap, differences)
+		if err != nil {
+			return nil, err
+		}
+		for i, comparator := range layer {
+			sorted[comparator[0]], err = ShareAdd([]Share{low[i], deltas[i]})
+			if err != nil {
+				return nil, err
+			}
+			sorted[comparator[1]], err = ShareAdd([]Share{high[i], ShareMulConstant(deltas[i], big.NewInt(-1))})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sorted, nil
+}
+
+// sortingNetwork returns the layers of comparators of Batcher's odd-even merge sort network for n
+// elements. The comparators within a layer are disjoint and every comparator (i, j) has i < j. For
+// n that is not a power of two, the network for the next power of two is used and comparators on
+// the missing elements are dropped, which is equivalent to padding with maximal elements.
+func sortingNetwork(n int) [][][2]int {
+	size := 1
+	for size < n {
+		size *= 2
+	}
+	var layers [][][2]int
+	for p := 1; p < size; p *= 2 {
+		for k := p; k >= 1; k /= 2 {
+			var layer [][2]int
+			for j := k % p; j+k < size; j += 2 * k {
+				for i := 0; i < k && i+j+k < size; i++ {
+					if (i+j)/(2*p) == (i+j+k)/(2*p) && i+j+k < n {
+						layer = append(layer, [2]int{i + j, i + j + k})
+					}
+				}
+			}
+			if len(layer) > 0 {
+				layers = append(layers, layer)
+			}
+		}
+	}
+	return layers
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortingNetwork(t *testing.T) {
+	assert := assert.New(t)
+	// By the 0-1 principle, a network that sorts all sequences of zeros and ones sorts everything
+	for n := 1; n <= 10; n++ {
+		network := sortingNetwork(n)
+		for input := 0; input < 1<<n; input++ {
+			values := make([]int, n)
+			for i := range values {
+				values[i] = (input >> i) & 1
+			}
+			for _, layer := range network {
+				for _, comparator := range layer {
+					if values[comparator[0]] > values[comparator[1]] {
+						values[comparator[0]], values[comparator[1]] = values[comparator[1]], values[comparator[0]]
+					}
+				}
+			}
+			for i := 1; i < n; i++ {
+				assert.LessOrEqual(values[i-1], values[i])
+			}
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	assert := assert.New(t)
+	values := bigInts(42, 7, 255, 0, 7, 100)
+	results := runParties(t, 3, 1, mersenne61, func(p *Party) ([]*big.Int, error) {
+		v, err := p.Input(3, values, len(values))
+		if err != nil {
+			return nil, err
+		}
+		sorted, err := p.Sort(v, 8)
+		if err != nil {
+			return nil, err
+		}
+		return p.Open(sorted)
+	})
+	assert.Equal(bigInts(0, 7, 7, 42, 100, 255), results[0])
+}
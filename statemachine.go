@@ -0,0 +1,242 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The state machines in this file implement dealing and reconstruction without assuming a
+// transport. Every step consumes a Message and returns the Messages to send, which the caller
+// delivers to the participant given by Message.To in any way it likes. Messages can be serialized
+// with encoding/json. Participants are identified by integers: shareholders by the X coordinate of
+// their share, and dealers and reconstructors by any identifier that does not clash with these.
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorUnexpectedMessage = errors.New("Message is not expected in the current state")
+	ErrorWrongSession      = errors.New("Message belongs to a different session")
+)
+
+// A MessageType is the type of a Message.
+type MessageType int
+
+const (
+	// MessageDeal carries a share from the dealer to a shareholder.
+	MessageDeal MessageType = iota + 1
+	// MessageRequest asks a shareholder to reveal its share to the sender.
+	MessageRequest
+	// MessageReveal carries a share from a shareholder to a reconstructor.
+	MessageReveal
+	// MessageDeliver carries a recovered secret to its recipient, see Ceremony.
+	MessageDeliver
+	// MessageSubshare carries a sub-share of a local product to another party, see Multiplier.
+	MessageSubshare
+)
+
+// String returns the name of the message type.
+func (t MessageType) String() string {
+	switch t {
+	case MessageDeal:
+		return "Deal"
+	case MessageRequest:
+		return "Request"
+	case MessageReveal:
+		return "Reveal"
+	case MessageDeliver:
+		return "Deliver"
+	case MessageSubshare:
+		return "Subshare"
+	}
+	return "Unknown"
+}
+
+// A Message is sent between the participants of a session. Share is set for MessageDeal,
+// MessageReveal and MessageSubshare, and Secret for MessageDeliver.
+type Message struct {
+	Type    MessageType `json:"type"`
+	Session string      `json:"session"`
+	From    int         `json:"from"`
+	To      int         `json:"to"`
+	Share   *Share      `json:"share,omitempty"`
+	Secret  *big.Int    `json:"secret,omitempty"`
+}
+
+// A Dealer shares a secret over a finite field among shareholders 1 to nShares.
+type Dealer struct {
+	session string
+	id      int
+	shares  []Share
+}
+
+// NewDealer returns a Dealer with the given identifier that shares secret in the given session,
+// see ShareFiniteField.
+func NewDealer(session string, id int, secret *big.Int, fieldSize *big.Int, degree int, nShares int) *Dealer {
+	return &Dealer{
+		session: session,
+		id:      id,
+		shares:  ShareFiniteField(secret, fieldSize, degree, nShares),
+	}
+}
+
+// Deal returns a MessageDeal for every shareholder. The shares are forgotten afterwards, so Deal
+// returns ErrorUnexpectedMessage when called again.
+func (d *Dealer) Deal() ([]Message, error) {
+	if d.shares == nil {
+		return nil, ErrorUnexpectedMessage
+	}
+	messages := make([]Message, len(d.shares))
+	for i := range d.shares {
+		messages[i] = Message{
+			Type:    MessageDeal,
+			Session: d.session,
+			From:    d.id,
+			To:      d.shares[i].X,
+			Share:   &d.shares[i],
+		}
+	}
+	d.shares = nil
+	return messages, nil
+}
+
+// A Shareholder holds a share dealt by a Dealer, and reveals it to Reconstructors on request.
+type Shareholder struct {
+	session    string
+	x          int
+	share      *Share
+	authorized func(requester int) bool
+}
+
+// NewShareholder returns the Shareholder with X coordinate x in the given session. Requests from
+// requesters for which authorized returns false are ignored; a nil authorized allows all requests.
+func NewShareholder(session string, x int, authorized func(requester int) bool) *Shareholder {
+	return &Shareholder{session: session, x: x, authorized: authorized}
+}
+
+// Share returns the share held by the shareholder, and false if it has not been dealt yet.
+func (s *Shareholder) Share() (Share, bool) {
+	if s.share == nil {
+		return Share{}, false
+	}
+	return *s.share, true
+}
+
+// Handle processes a MessageDeal or MessageRequest and returns the messages to send in response.
+// A share is accepted only once, and requests are only answered after a share was dealt.
+func (s *Shareholder) Handle(message Message) ([]Message, error) {
+	if message.Session != s.session {
+		return nil, ErrorWrongSession
+	}
+	switch {
+	case message.Type == MessageDeal && s.share == nil && message.Share != nil && message.Share.X == s.x:
+		share := *message.Share
+		s.share = &share
+		return nil, nil
+	case message.Type == MessageRequest && s.share != nil:
+		if s.authorized != nil && !s.authorized(message.From) {
+			return nil, nil
+		}
+		share := *s.share
+		return []Message{{
+			Type:    MessageReveal,
+			Session: s.session,
+			From:    s.x,
+			To:      message.From,
+			Share:   &share,
+		}}, nil
+	}
+	return nil, ErrorUnexpectedMessage
+}
+
+// A Reconstructor recovers a secret from the shares revealed by shareholders.
+type Reconstructor struct {
+	session      string
+	id           int
+	shareholders []int
+	requested    bool
+	shares       []Share
+	secret       *big.Int
+}
+
+// NewReconstructor returns a Reconstructor with the given identifier that requests the shares of
+// the given shareholders in the given session.
+func NewReconstructor(session string, id int, shareholders []int) *Reconstructor {
+	return &Reconstructor{session: session, id: id, shareholders: shareholders}
+}
+
+// Request returns a MessageRequest for every shareholder. It can only be called once.
+func (r *Reconstructor) Request() ([]Message, error) {
+	if r.requested {
+		return nil, ErrorUnexpectedMessage
+	}
+	r.requested = true
+	messages := make([]Message, len(r.shareholders))
+	for i, x := range r.shareholders {
+		messages[i] = Message{
+			Type:    MessageRequest,
+			Session: r.session,
+			From:    r.id,
+			To:      x,
+		}
+	}
+	return messages, nil
+}
+
+// Handle processes a MessageReveal. Once degree+1 compatible shares from distinct shareholders have
+// been revealed, the secret is recovered and Done returns true; later reveals are ignored.
+func (r *Reconstructor) Handle(message Message) ([]Message, error) {
+	if message.Session != r.session {
+		return nil, ErrorWrongSession
+	}
+	if message.Type != MessageReveal || !r.requested || message.Share == nil || message.Share.X != message.From ||
+		!containsInt(r.shareholders, message.From) {
+		return nil, ErrorUnexpectedMessage
+	}
+	if r.secret != nil || containsX(r.shares, message.From) {
+		return nil, nil
+	}
+	r.shares = append(r.shares, *message.Share)
+	if len(r.shares) > r.shares[0].Degree {
+		secret, err := ShareCombine(r.shares)
+		if err != nil {
+			r.shares = r.shares[:len(r.shares)-1]
+			return nil, err
+		}
+		r.secret = secret
+	}
+	return nil, nil
+}
+
+// Done reports whether the secret has been recovered.
+func (r *Reconstructor) Done() bool {
+	return r.secret != nil
+}
+
+// Secret returns the recovered secret, or ErrorTooFewShares if the reconstructor is not done.
+func (r *Reconstructor) Secret() (*big.Int, error) {
+	if r.secret == nil {
+		return nil, ErrorTooFewShares
+	}
+	return r.secret, nil
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateMachines(t *testing.T) {
+	assert := assert.New(t)
+	const dealerID, reconstructorID = 100, 101
+	dealer := NewDealer("s", dealerID, big.NewInt(42), big.NewInt(7919), 2, 5)
+	shareholders := make([]*Shareholder, 5)
+	for i := range shareholders {
+		shareholders[i] = NewShareholder("s", i+1, func(requester int) bool {
+			return requester == reconstructorID
+		})
+	}
+	reconstructor := NewReconstructor("s", reconstructorID, []int{2, 3, 4, 5})
+
+	// deliver routes messages through JSON until no messages are left
+	var deliver func(messages []Message)
+	deliver = func(messages []Message) {
+		for _, message := range messages {
+			encoded, err := json.Marshal(message)
+			assert.NoError(err)
+			var decoded Message
+			assert.NoError(json.Unmarshal(encoded, &decoded))
+			var replies []Message
+			if decoded.To == reconstructorID {
+				replies, err = reconstructor.Handle(decoded)
+			} else {
+				replies, err = shareholders[decoded.To-1].Handle(decoded)
+			}
+			assert.NoError(err)
+			deliver(replies)
+		}
+	}
+
+	messages, err := dealer.Deal()
+	assert.NoError(err)
+	deliver(messages)
+	_, err = dealer.Deal()
+	assert.Equal(ErrorUnexpectedMessage, err)
+	share, ok := shareholders[0].Share()
+	assert.True(ok)
+	assert.Equal(1, share.X)
+
+	assert.False(reconstructor.Done())
+	_, err = reconstructor.Secret()
+	assert.Equal(ErrorTooFewShares, err)
+	messages, err = reconstructor.Request()
+	assert.NoError(err)
+	assert.Len(messages, 4)
+	deliver(messages)
+	assert.True(reconstructor.Done())
+	secret, err := reconstructor.Secret()
+	assert.NoError(err)
+	assert.Equal(big.NewInt(42), secret)
+}
+
+func TestStateMachineErrors(t *testing.T) {
+	assert := assert.New(t)
+	shareholder := NewShareholder("s", 1, func(requester int) bool { return requester == 7 })
+	request := Message{Type: MessageRequest, Session: "s", From: 7, To: 1}
+	_, err := shareholder.Handle(request)
+	assert.Equal(ErrorUnexpectedMessage, err)
+
+	shares := ShareFiniteField(big.NewInt(42), big.NewInt(7919), 1, 3)
+	_, err = shareholder.Handle(Message{Type: MessageDeal, Session: "other", To: 1, Share: &shares[0]})
+	assert.Equal(ErrorWrongSession, err)
+	_, err = shareholder.Handle(Message{Type: MessageDeal, Session: "s", To: 1, Share: &shares[1]})
+	assert.Equal(ErrorUnexpectedMessage, err)
+	_, err = shareholder.Handle(Message{Type: MessageDeal, Session: "s", To: 1, Share: &shares[0]})
+	assert.NoError(err)
+	_, err = shareholder.Handle(Message{Type: MessageDeal, Session: "s", To: 1, Share: &shares[0]})
+	assert.Equal(ErrorUnexpectedMessage, err)
+
+	// Unauthorized requests are ignored
+	replies, err := shareholder.Handle(Message{Type: MessageRequest, Session: "s", From: 8, To: 1})
+	assert.NoError(err)
+	assert.Empty(replies)
+	replies, err = shareholder.Handle(request)
+	assert.NoError(err)
+	assert.Equal([]Message{{Type: MessageReveal, Session: "s", From: 1, To: 7, Share: &shares[0]}}, replies)
+
+	reconstructor := NewReconstructor("s", 7, []int{1, 2})
+	_, err = reconstructor.Handle(replies[0])
+	assert.Equal(ErrorUnexpectedMessage, err)
+	_, err = reconstructor.Request()
+	assert.NoError(err)
+	_, err = reconstructor.Request()
+	assert.Equal(ErrorUnexpectedMessage, err)
+	_, err = reconstructor.Handle(Message{Type: MessageReveal, Session: "s", From: 3, To: 7, Share: &shares[2]})
+	assert.Equal(ErrorUnexpectedMessage, err)
+	_, err = reconstructor.Handle(replies[0])
+	assert.NoError(err)
+	_, err = reconstructor.Handle(replies[0])
+	assert.NoError(err)
+	assert.False(reconstructor.Done())
+	assert.Equal("Reveal", MessageReveal.String())
+	assert.Equal("Deliver", MessageDeliver.String())
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The statistics in this file are computed by letting every party locally compute shares of the
+// sums and sums of products of the shared data, and opening only those aggregates. The mean,
+// variance and covariance are then computed in the clear, so no interactive protocols are required.
+// Histograms of shared values are the exception: assigning a value to a bin requires the comparison
+// protocol of Party.
+// Sums of products have twice the degree of the data, so 2*degree+1 parties are required to open
+// them. Secrets over a finite field are interpreted as signed integers in (-fieldSize/2, fieldSize/2].
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorInvalidCount = errors.New("Count must be positive")
+	ErrorInvalidBin   = errors.New("Bin out of range")
+	ErrorInvalidEdges = errors.New("Bin edges must be given in increasing order")
+)
+
+// Mean recovers the mean of count shared values from the parties' shares of their sum, as computed
+// by ShareVector.Sum.
+func Mean(sums []Share, count int) (*big.Rat, error) {
+	if count <= 0 {
+		return nil, ErrorInvalidCount
+	}
+	sum, err := CombineAs(sums, CenteredRepresentative)
+	if err != nil {
+		return nil, err
+	}
+	return big.NewRat(0, 1).SetFrac(sum, big.NewInt(int64(count))), nil
+}
+
+// Variance recovers the population variance of count shared values from the parties' shares of
+// their sum and of their sum of squares, as computed by ShareVector.Sum and ShareVector.InnerProduct
+// of the vector with itself.
+func Variance(sums []Share, sumsOfSquares []Share, count int) (*big.Rat, error) {
+	return Covariance(sums, sums, sumsOfSquares, count)
+}
+
+// Covariance recovers the population covariance of two vectors of count shared values, from the
+// parties' shares of the sums of both vectors and of their inner product.
+func Covariance(sumsX []Share, sumsY []Share, sumsOfProducts []Share, count int) (*big.Rat, error) {
+	meanX, err := Mean(sumsX, count)
+	if err != nil {
+		return nil, err
+	}
+	meanY, err := Mean(sumsY, count)
+	if err != nil {
+		return nil, err
+	}
+	meanXY, err := Mean(sumsOfProducts, count)
+	if err != nil {
+		return nil, err
+	}
+	// cov(x, y) = E[xy] - E[x]E[y]
+	return meanXY.Sub(meanXY, meanX.Mul(meanX, meanY)), nil
+}
+
+// ShareOneHot shares the one-hot encoding of bin among nBins bins over a finite field, to contribute
+// a value to a shared histogram. The parties add the ShareVectors of all contributions with
+// ShareVectorAdd, after which CombineVector recovers the count of every bin. The dealer must know
+// the bin in the clear; use Party.Histogram to count values that are already shared.
+func ShareOneHot(bin int, nBins int, fieldSize *big.Int, degree int, nShares int) ([]ShareVector, error) {
+	if bin < 0 || bin >= nBins {
+		return nil, ErrorInvalidBin
+	}
+	secrets := make([]*big.Int, nBins)
+	for i := range secrets {
+		secrets[i] = big.NewInt(0)
+	}
+	secrets[bin].SetInt64(1)
+	return ShareVectorFiniteField(secrets, fieldSize, degree, nShares), nil
+}
+
+// Histogram returns shares of the number of values in each of the len(edges)+1 bins delimited by
+// the public edges, which must be increasing: the first bin counts the values below edges[0], bin i
+// the values in [edges[i-1], edges[i]), and the last bin the values of at least edges[len(edges)-1].
+// The values and edges must be in [0, 2^bits), with the field size required by LessThanConstant.
+// Only the counts are revealed when they are opened, not which value fell in which bin.
+func (p *Party) Histogram(values ShareVector, edges []*big.Int, bits int) (ShareVector, error) {
+	if len(values) == 0 {
+		return nil, ErrorInvalidCount
+	}
+	if len(edges) == 0 {
+		return nil, ErrorInvalidEdges
+	}
+	for i := 1; i < len(edges); i++ {
+		if edges[i].Cmp(edges[i-1]) <= 0 {
+			return nil, ErrorInvalidEdges
+		}
+	}
+
+	// Compare every value to every edge in a single run of the comparison protocol
+	a := make(ShareVector, 0, len(values)*len(edges))
+	c := make([]*big.Int, 0, len(values)*len(edges))
+	for _, value := range values {
+		for _, edge := range edges {
+			a = append(a, value)
+			c = append(c, edge)
+		}
+	}
+	below, err := p.LessThanConstant(a, c, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	// The count of bin i is the number of values below edges[i] minus those below edges[i-1]
+	m := make([][]*big.Int, len(edges)+1)
+	for i := range m {
+		m[i] = make([]*big.Int, len(below))
+		for j := range m[i] {
+			switch edge := j % len(edges); edge {
+			case i:
+				m[i][j] = big.NewInt(1)
+			case i - 1:
+				m[i][j] = big.NewInt(-1)
+			default:
+				m[i][j] = big.NewInt(0)
+			}
+		}
+	}
+	b := make([]*big.Int, len(m))
+	for i := range b {
+		b[i] = big.NewInt(0)
+	}
+	b[len(edges)].SetInt64(int64(len(values)))
+	return ApplyAffine(m, below, b)
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatistics(t *testing.T) {
+	assert := assert.New(t)
+	x := ShareVectorFiniteField(bigInts(-2, 4, 4, 6), big.NewInt(7919), 1, 3)
+	y := ShareVectorFiniteField(bigInts(1, 2, 3, 6), big.NewInt(7919), 1, 3)
+
+	sumsX := make([]Share, 3)
+	sumsY := make([]Share, 3)
+	sumsXX := make([]Share, 3)
+	sumsXY := make([]Share, 3)
+	var err error
+	for i := range x {
+		sumsX[i], err = x[i].Sum()
+		assert.NoError(err)
+		sumsY[i], err = y[i].Sum()
+		assert.NoError(err)
+		sumsXX[i], err = x[i].InnerProduct(x[i])
+		assert.NoError(err)
+		sumsXY[i], err = x[i].InnerProduct(y[i])
+		assert.NoError(err)
+	}
+
+	mean, err := Mean(sumsX, 4)
+	assert.NoError(err)
+	assert.Equal("3", mean.RatString())
+
+	variance, err := Variance(sumsX, sumsXX, 4)
+	assert.NoError(err)
+	assert.Equal("9", variance.RatString())
+
+	covariance, err := Covariance(sumsX, sumsY, sumsXY, 4)
+	assert.NoError(err)
+	assert.Equal("9/2", covariance.RatString())
+
+	_, err = Mean(sumsX, 0)
+	assert.Equal(ErrorInvalidCount, err)
+	_, err = Variance(sumsX, sumsXX[0:2], 4)
+	assert.Equal(ErrorTooFewShares, err)
+}
+
+func TestHistogram(t *testing.T) {
+	assert := assert.New(t)
+	counts := make([]ShareVector, 3)
+	for _, bin := range []int{0, 2, 2, 3, 2} {
+		contribution, err := ShareOneHot(bin, 4, big.NewInt(7919), 1, 3)
+		assert.NoError(err)
+		for i := range counts {
+			if counts[i] == nil {
+				counts[i] = contribution[i]
+				continue
+			}
+			counts[i], err = ShareVectorAdd([]ShareVector{counts[i], contribution[i]})
+			assert.NoError(err)
+		}
+	}
+
+	histogram, err := CombineVector(counts)
+	assert.NoError(err)
+	assert.Equal(bigInts(1, 0, 3, 1), histogram)
+
+	_, err = ShareOneHot(4, 4, big.NewInt(7919), 1, 3)
+	assert.Equal(ErrorInvalidBin, err)
+}
+
+func TestPartyHistogram(t *testing.T) {
+	assert := assert.New(t)
+	values := bigInts(0, 9, 10, 17, 200, 255, 10, 3)
+	edges := bigInts(10, 20, 255)
+	results := runParties(t, 3, 1, mersenne61, func(p *Party) ([]*big.Int, error) {
+		x, err := p.Input(1, values, len(values))
+		if err != nil {
+			return nil, err
+		}
+		counts, err := p.Histogram(x, edges, 8)
+		if err != nil {
+			return nil, err
+		}
+		return p.Open(counts)
+	})
+	assert.Equal(bigInts(3, 3, 1, 1), results[0])
+
+	p := NewParty(1, 3, mersenne61, 1, nil)
+	shares := ShareVectorFiniteField(bigInts(1), mersenne61, 1, 3)
+	_, err := p.Histogram(shares[0], bigInts(2, 2), 8)
+	assert.Equal(ErrorInvalidEdges, err)
+	_, err = p.Histogram(shares[0], nil, 8)
+	assert.Equal(ErrorInvalidEdges, err)
+	_, err = p.Histogram(nil, edges, 8)
+	assert.Equal(ErrorInvalidCount, err)
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TNO-MPC/shamir"
+)
+
+// shareFileExtension is the extension of the files in which a FileStore stores shares.
+const shareFileExtension = ".share"
+
+// A FileStore stores every share in a file in a directory, readable by the owner only.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore for the directory dir, which is created if it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Put stores a share under id. The share is written to a temporary file first and then renamed, so
+// that a crash never leaves a partially written share behind.
+func (s *FileStore) Put(ctx context.Context, id string, share shamir.Share) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	data, err := encodeShare(share)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path(id))
+}
+
+// Get returns the share stored under id.
+func (s *FileStore) Get(ctx context.Context, id string) (shamir.Share, error) {
+	if err := checkID(id); err != nil {
+		return shamir.Share{}, err
+	}
+	data, err := ioutil.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return shamir.Share{}, ErrorNotFound
+	}
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	return decodeShare(data)
+}
+
+// List returns the IDs of all stored shares that start with prefix.
+func (s *FileStore) List(ctx context.Context, prefix string) ([]string, error) {
+	if err := checkPrefix(prefix); err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Mode().IsRegular() || !strings.HasSuffix(name, shareFileExtension) {
+			continue
+		}
+		id := strings.TrimSuffix(name, shareFileExtension)
+		if strings.HasPrefix(id, prefix) && checkID(id) == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete removes the share stored under id.
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+shareFileExtension)
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStore(t *testing.T) {
+	assert := assert.New(t)
+	dir := filepath.Join(t.TempDir(), "shares")
+	store, err := NewFileStore(dir)
+	assert.NoError(err)
+	testShareStore(t, store)
+
+	info, err := os.Stat(filepath.Join(dir, "key-2.share"))
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	// Other files in the directory are ignored, and corrupted shares are detected
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0600))
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "broken.share"), []byte("broken"), 0600))
+	ids, err := store.List(context.Background(), "")
+	assert.NoError(err)
+	assert.Equal([]string{"broken", "key-2", "other"}, ids)
+	_, err = store.Get(context.Background(), "broken")
+	assert.Equal(shamir.ErrorInvalidBundle, err)
+
+	share := shamir.ShareFiniteField(big.NewInt(42), big.NewInt(7919), 1, 3)[0]
+	assert.NoError(store.Put(context.Background(), "broken", share))
+	restored, err := store.Get(context.Background(), "broken")
+	assert.NoError(err)
+	assert.Equal(share, restored)
+}
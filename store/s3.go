@@ -0,0 +1,296 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TNO-MPC/shamir"
+)
+
+// S3Credentials are the credentials with which an S3Store signs its requests. SessionToken is only
+// needed for temporary credentials.
+type S3Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// An S3Store stores every share as an object in a bucket of an S3-compatible object store, such as
+// Amazon S3 or MinIO. It addresses buckets by path, as in https://endpoint/bucket/key, and signs its
+// requests with AWS Signature Version 4.
+type S3Store struct {
+	// Client is used for all requests; nil means http.DefaultClient.
+	Client *http.Client
+
+	endpoint    string
+	region      string
+	bucket      string
+	prefix      string
+	credentials S3Credentials
+	now         func() time.Time
+}
+
+// NewS3Store returns an S3Store for the bucket at endpoint, such as "https://s3.eu-west-1.amazonaws.com",
+// in the given region. The shares are stored under keys that start with prefix, such as "shares/".
+func NewS3Store(endpoint string, region string, bucket string, prefix string, credentials S3Credentials) *S3Store {
+	return &S3Store{
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		region:      region,
+		bucket:      bucket,
+		prefix:      prefix,
+		credentials: credentials,
+		now:         time.Now,
+	}
+}
+
+// Put stores a share under id.
+func (s *S3Store) Put(ctx context.Context, id string, share shamir.Share) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	data, err := encodeShare(share)
+	if err != nil {
+		return err
+	}
+	response, err := s.do(ctx, http.MethodPut, s.prefix+id, nil, data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return ErrorStorage
+	}
+	return nil
+}
+
+// Get returns the share stored under id.
+func (s *S3Store) Get(ctx context.Context, id string) (shamir.Share, error) {
+	if err := checkID(id); err != nil {
+		return shamir.Share{}, err
+	}
+	response, err := s.do(ctx, http.MethodGet, s.prefix+id, nil, nil)
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return shamir.Share{}, ErrorNotFound
+	}
+	if response.StatusCode != http.StatusOK {
+		return shamir.Share{}, ErrorStorage
+	}
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	return decodeShare(data)
+}
+
+// List returns the IDs of all stored shares that start with prefix.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	if err := checkPrefix(prefix); err != nil {
+		return nil, err
+	}
+	var ids []string
+	query := url.Values{"list-type": {"2"}, "prefix": {s.prefix + prefix}}
+	for {
+		response, err := s.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		if response.StatusCode == http.StatusOK {
+			err = xml.NewDecoder(response.Body).Decode(&result)
+		} else {
+			err = ErrorStorage
+		}
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range result.Contents {
+			id := strings.TrimPrefix(object.Key, s.prefix)
+			if checkID(id) == nil {
+				ids = append(ids, id)
+			}
+		}
+		if !result.IsTruncated {
+			break
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete removes the share stored under id.
+func (s *S3Store) Delete(ctx context.Context, id string) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	response, err := s.do(ctx, http.MethodDelete, s.prefix+id, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		return ErrorStorage
+	}
+	return nil
+}
+
+// do sends a signed request for the object with the given key, or for the bucket if key is empty.
+func (s *S3Store) do(ctx context.Context, method string, key string, query url.Values, body []byte) (*http.Response, error) {
+	path := "/" + s.bucket
+	if key != "" {
+		path += "/" + key
+	}
+	request, err := http.NewRequestWithContext(ctx, method, s.endpoint+escapePath(path), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.URL.RawQuery = escapeQuery(query)
+	if s.credentials.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.credentials.SessionToken)
+	}
+	signS3(request, body, s.credentials, s.region, s.now())
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= 300 {
+		// Drain the body so that the connection can be reused
+		io.Copy(ioutil.Discard, response.Body)
+	}
+	return response, nil
+}
+
+// signS3 signs a request to S3 with AWS Signature Version 4. It signs the host and all headers of
+// the request, so these must not change after signing.
+func signS3(request *http.Request, body []byte, credentials S3Credentials, region string, now time.Time) {
+	timestamp := now.UTC().Format("20060102T150405Z")
+	date := timestamp[:8]
+	payloadHash := sha256.Sum256(body)
+	request.Header.Set("X-Amz-Date", timestamp)
+	request.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := request.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		path,
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + timestamp + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+credentials.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath escapes a path as required by Signature Version 4, which leaves only unreserved
+// characters and slashes unescaped.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		segments[i] = escapeS3(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
+
+// escapeQuery encodes a query sorted by key, as required by Signature Version 4.
+func escapeQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, escapeS3(key)+"="+escapeS3(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapeS3 percent-encodes all bytes of s except unreserved characters.
+func escapeS3(s string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			escaped.WriteByte(c)
+		} else {
+			escaped.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package store persists shares in storage backends behind a common ShareStore interface, so that
+// applications do not need their own glue for every backend. FileStore keeps shares in a directory,
+// S3Store in a bucket of an S3-compatible object store and VaultStore in a KV version 2 secrets
+// engine of HashiCorp Vault. WithAuthorizer adds per-share access control and auditing to any of
+// them.
+//
+// Shares are stored in the bundle format of the shamir package, see shamir.WriteBundle, which
+// includes a checksum.
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/TNO-MPC/shamir"
+)
+
+var (
+	ErrorNotFound     = errors.New("No share stored under this ID")
+	ErrorInvalidID    = errors.New("Share IDs must consist of letters, digits, '.', '-' and '_'")
+	ErrorAccessDenied = errors.New("Access to the share denied")
+	ErrorStorage      = errors.New("Storage backend returned an unexpected response")
+)
+
+// A ShareStore stores shares under string IDs. IDs are non-empty and consist of ASCII letters,
+// digits, '.', '-' and '_', but are not "." or "..", so that they can be used as file names and
+// object keys without escaping. All methods are safe for concurrent use.
+type ShareStore interface {
+	// Put stores a share under id, replacing any share stored under it before.
+	Put(ctx context.Context, id string, share shamir.Share) error
+	// Get returns the share stored under id, or ErrorNotFound.
+	Get(ctx context.Context, id string) (shamir.Share, error)
+	// List returns the IDs of all stored shares that start with prefix, in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Delete removes the share stored under id. Deleting a share that does not exist is not an
+	// error.
+	Delete(ctx context.Context, id string) error
+}
+
+// An Operation is an operation on a ShareStore.
+type Operation int
+
+const (
+	OperationPut Operation = iota
+	OperationGet
+	OperationList
+	OperationDelete
+)
+
+func (o Operation) String() string {
+	switch o {
+	case OperationPut:
+		return "put"
+	case OperationGet:
+		return "get"
+	case OperationList:
+		return "list"
+	case OperationDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// An Authorizer decides whether the caller identified by ctx may perform operation on the share
+// with the given ID, and returns a non-nil error, such as ErrorAccessDenied, if not. It is also the
+// place to record an audit log of all accesses.
+type Authorizer func(ctx context.Context, operation Operation, id string) error
+
+// WithAuthorizer returns a ShareStore that calls authorize before every operation on store. For
+// List, authorize is called for every ID found, and only the IDs for which it returns nil are
+// returned.
+func WithAuthorizer(store ShareStore, authorize Authorizer) ShareStore {
+	return &authorizedStore{store: store, authorize: authorize}
+}
+
+type authorizedStore struct {
+	store     ShareStore
+	authorize Authorizer
+}
+
+func (s *authorizedStore) Put(ctx context.Context, id string, share shamir.Share) error {
+	if err := s.authorize(ctx, OperationPut, id); err != nil {
+		return err
+	}
+	return s.store.Put(ctx, id, share)
+}
+
+func (s *authorizedStore) Get(ctx context.Context, id string) (shamir.Share, error) {
+	if err := s.authorize(ctx, OperationGet, id); err != nil {
+		return shamir.Share{}, err
+	}
+	return s.store.Get(ctx, id)
+}
+
+func (s *authorizedStore) List(ctx context.Context, prefix string) ([]string, error) {
+	ids, err := s.store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	allowed := ids[:0]
+	for _, id := range ids {
+		if s.authorize(ctx, OperationList, id) == nil {
+			allowed = append(allowed, id)
+		}
+	}
+	return allowed, nil
+}
+
+func (s *authorizedStore) Delete(ctx context.Context, id string) error {
+	if err := s.authorize(ctx, OperationDelete, id); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, id)
+}
+
+// checkID returns ErrorInvalidID if id is not a valid share ID.
+func checkID(id string) error {
+	if id == "" || id == "." || id == ".." || !validIDCharacters(id) {
+		return ErrorInvalidID
+	}
+	return nil
+}
+
+// checkPrefix returns ErrorInvalidID if prefix cannot start a valid share ID.
+func checkPrefix(prefix string) error {
+	if !validIDCharacters(prefix) {
+		return ErrorInvalidID
+	}
+	return nil
+}
+
+func validIDCharacters(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_')
+	}) < 0
+}
+
+// encodeShare encodes a share as a bundle.
+func encodeShare(share shamir.Share) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := shamir.WriteBundle(&buffer, []shamir.Share{share}, false); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// decodeShare decodes a share encoded by encodeShare.
+func decodeShare(data []byte) (shamir.Share, error) {
+	shares, err := shamir.ReadBundle(bytes.NewReader(data))
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	if len(shares) != 1 {
+		return shamir.Share{}, shamir.ErrorInvalidBundle
+	}
+	return shares[0], nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/TNO-MPC/shamir"
+	"github.com/stretchr/testify/assert"
+)
+
+// testShareStore checks the behaviour that all ShareStores have in common.
+func testShareStore(t *testing.T, store ShareStore) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	shares := shamir.ShareFiniteField(big.NewInt(42), big.NewInt(7919), 1, 3)
+
+	ids, err := store.List(ctx, "")
+	assert.NoError(err)
+	assert.Empty(ids)
+	_, err = store.Get(ctx, "key-1")
+	assert.Equal(ErrorNotFound, err)
+
+	assert.NoError(store.Put(ctx, "key-2", shares[1]))
+	assert.NoError(store.Put(ctx, "key-1", shares[2]))
+	assert.NoError(store.Put(ctx, "other", shares[0]))
+	assert.NoError(store.Put(ctx, "key-1", shares[0]))
+	share, err := store.Get(ctx, "key-1")
+	assert.NoError(err)
+	assert.Equal(shares[0], share)
+
+	ids, err = store.List(ctx, "key")
+	assert.NoError(err)
+	assert.Equal([]string{"key-1", "key-2"}, ids)
+	ids, err = store.List(ctx, "")
+	assert.NoError(err)
+	assert.Equal([]string{"key-1", "key-2", "other"}, ids)
+
+	assert.NoError(store.Delete(ctx, "key-1"))
+	assert.NoError(store.Delete(ctx, "key-1"))
+	_, err = store.Get(ctx, "key-1")
+	assert.Equal(ErrorNotFound, err)
+	ids, err = store.List(ctx, "key")
+	assert.NoError(err)
+	assert.Equal([]string{"key-2"}, ids)
+
+	for _, id := range []string{"", ".", "..", "a/b", "../a", "a b"} {
+		assert.Equal(ErrorInvalidID, store.Put(ctx, id, shares[0]), id)
+		_, err = store.Get(ctx, id)
+		assert.Equal(ErrorInvalidID, err, id)
+		assert.Equal(ErrorInvalidID, store.Delete(ctx, id), id)
+	}
+	_, err = store.List(ctx, "a/")
+	assert.Equal(ErrorInvalidID, err)
+}
+
+type userKey struct{}
+
+func TestWithAuthorizer(t *testing.T) {
+	assert := assert.New(t)
+	files, err := NewFileStore(t.TempDir())
+	assert.NoError(err)
+	var audit []string
+	// Users may only access the shares whose IDs start with their name, and the admin everything
+	store := WithAuthorizer(files, func(ctx context.Context, operation Operation, id string) error {
+		user, _ := ctx.Value(userKey{}).(string)
+		audit = append(audit, user+" "+operation.String()+" "+id)
+		if user == "admin" || (len(id) > len(user) && id[:len(user)+1] == user+"-") {
+			return nil
+		}
+		return ErrorAccessDenied
+	})
+	testShareStore(t, WithAuthorizer(files, func(context.Context, Operation, string) error { return nil }))
+
+	share := shamir.ShareFiniteField(big.NewInt(42), big.NewInt(7919), 1, 3)[0]
+	alice := context.WithValue(context.Background(), userKey{}, "alice")
+	admin := context.WithValue(context.Background(), userKey{}, "admin")
+	assert.NoError(store.Put(alice, "alice-1", share))
+	assert.Equal(ErrorAccessDenied, store.Put(alice, "bob-1", share))
+	assert.NoError(store.Put(admin, "bob-1", share))
+	_, err = store.Get(alice, "bob-1")
+	assert.Equal(ErrorAccessDenied, err)
+	assert.Equal(ErrorAccessDenied, store.Delete(alice, "bob-1"))
+
+	ids, err := store.List(alice, "")
+	assert.NoError(err)
+	assert.Equal([]string{"alice-1"}, ids)
+	ids, err = store.List(admin, "")
+	assert.NoError(err)
+	assert.Equal([]string{"alice-1", "bob-1", "key-2", "other"}, ids)
+	assert.Contains(audit, "alice get bob-1")
+	assert.Equal("unknown", Operation(-1).String())
+}
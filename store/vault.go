@@ -0,0 +1,200 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/TNO-MPC/shamir"
+)
+
+// A VaultStore stores every share as a secret in a KV version 2 secrets engine of HashiCorp Vault,
+// using its HTTP API. The share is stored base64-encoded under the key "share" of the secret.
+// Deleting a share deletes all its versions and metadata.
+type VaultStore struct {
+	// Client is used for all requests; nil means http.DefaultClient.
+	Client *http.Client
+
+	address string
+	token   string
+	mount   string
+	prefix  string
+}
+
+// NewVaultStore returns a VaultStore for the Vault server at address, such as
+// "https://vault.example.com:8200", authenticating with token. The shares are stored in the secrets
+// engine mounted at mount, such as "secret", under the path prefix, such as "shares/".
+func NewVaultStore(address string, token string, mount string, prefix string) *VaultStore {
+	return &VaultStore{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		mount:   strings.Trim(mount, "/"),
+		prefix:  prefix,
+	}
+}
+
+type vaultSecret struct {
+	Share string `json:"share"`
+}
+
+// Put stores a share under id.
+func (s *VaultStore) Put(ctx context.Context, id string, share shamir.Share) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	data, err := encodeShare(share)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(struct {
+		Data vaultSecret `json:"data"`
+	}{vaultSecret{Share: base64.StdEncoding.EncodeToString(data)}})
+	if err != nil {
+		return err
+	}
+	response, err := s.do(ctx, http.MethodPost, "data", id, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		return ErrorStorage
+	}
+	return nil
+}
+
+// Get returns the share stored under id.
+func (s *VaultStore) Get(ctx context.Context, id string) (shamir.Share, error) {
+	if err := checkID(id); err != nil {
+		return shamir.Share{}, err
+	}
+	response, err := s.do(ctx, http.MethodGet, "data", id, nil)
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return shamir.Share{}, ErrorNotFound
+	}
+	if response.StatusCode != http.StatusOK {
+		return shamir.Share{}, ErrorStorage
+	}
+	var secret struct {
+		Data struct {
+			Data *vaultSecret `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&secret); err != nil {
+		return shamir.Share{}, err
+	}
+	// The latest version of a secret that was deleted but not destroyed has no data
+	if secret.Data.Data == nil {
+		return shamir.Share{}, ErrorNotFound
+	}
+	data, err := base64.StdEncoding.DecodeString(secret.Data.Data.Share)
+	if err != nil {
+		return shamir.Share{}, ErrorStorage
+	}
+	return decodeShare(data)
+}
+
+// List returns the IDs of all stored shares that start with prefix.
+func (s *VaultStore) List(ctx context.Context, prefix string) ([]string, error) {
+	if err := checkPrefix(prefix); err != nil {
+		return nil, err
+	}
+	// Vault lists the keys below a path, so the prefix is filtered here
+	response, err := s.do(ctx, "LIST", "metadata", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, ErrorStorage
+	}
+	var list struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, key := range list.Data.Keys {
+		if strings.HasPrefix(key, prefix) && checkID(key) == nil {
+			ids = append(ids, key)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete removes all versions of the share stored under id.
+func (s *VaultStore) Delete(ctx context.Context, id string) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	response, err := s.do(ctx, http.MethodDelete, "metadata", id, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusNotFound {
+		return ErrorStorage
+	}
+	return nil
+}
+
+// do sends a request to the API endpoint kind ("data" or "metadata") for the secret with the given
+// ID, or for the prefix if id is empty.
+func (s *VaultStore) do(ctx context.Context, method string, kind string, id string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, s.address+"/v1/"+s.mount+"/"+kind+"/"+s.prefix+id, reader)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("X-Vault-Token", s.token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= 300 {
+		// Drain the body so that the connection can be reused
+		io.Copy(ioutil.Discard, response.Body)
+	}
+	return response, nil
+}
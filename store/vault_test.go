@@ -0,0 +1,108 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVault implements the parts of the KV version 2 API of Vault used by VaultStore.
+type fakeVault struct {
+	mutex   sync.Mutex
+	token   string
+	secrets map[string]json.RawMessage
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if r.Header.Get("X-Vault-Token") != v.token {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		path := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+		switch r.Method {
+		case http.MethodPost:
+			var request struct {
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			v.secrets[path] = request.Data
+			w.Write([]byte(`{"data": {"version": 1}}`))
+		case http.MethodGet:
+			secret, ok := v.secrets[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": secret}})
+		}
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/"):
+		path := strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/")
+		switch r.Method {
+		case "LIST":
+			keys := []string{}
+			for key := range v.secrets {
+				if strings.HasPrefix(key, path) {
+					keys = append(keys, strings.TrimPrefix(key, path))
+				}
+			}
+			if len(keys) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": keys}})
+		case http.MethodDelete:
+			delete(v.secrets, path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestVaultStore(t *testing.T) {
+	assert := assert.New(t)
+	vault := &fakeVault{token: "s.token", secrets: make(map[string]json.RawMessage)}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	store := NewVaultStore(server.URL+"/", "s.token", "/secret/", "shares/")
+	testShareStore(t, store)
+	assert.Contains(vault.secrets, "shares/key-2")
+
+	// Subfolders below the prefix are not shares
+	vault.secrets["shares/folder/key-3"] = json.RawMessage(`{}`)
+	ids, err := store.List(context.Background(), "")
+	assert.NoError(err)
+	assert.Equal([]string{"key-2", "other"}, ids)
+
+	store = NewVaultStore(server.URL, "wrong", "secret", "shares/")
+	_, err = store.Get(context.Background(), "key-2")
+	assert.Equal(ErrorStorage, err)
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	ErrorInvalidTestVector  = errors.New("Test vector is malformed")
+	ErrorTestVectorMismatch = errors.New("Shares differ from the expected shares of the test vector")
+)
+
+// A TestVector records the shares that deterministic sharing (see ShareFiniteFieldSeeded and
+// ShareIntegersSeeded) produces for given parameters, secret and seed. Other implementations can
+// use test vectors to verify that they are byte-exact compatible with this package.
+//
+// In the JSON encoding, all big integers are decimal strings and the seed is a hex string. For
+// sharing over a finite field, fieldSize is set, which may also be the identifier of a registered
+// field (see RegisterField); for sharing over the integers, secretUpperBound and statSecParam are
+// set instead, and every share has the factor nShares!.
+type TestVector struct {
+	Name             string            `json:"name"`
+	FieldSize        string            `json:"fieldSize,omitempty"`
+	SecretUpperBound string            `json:"secretUpperBound,omitempty"`
+	StatSecParam     int               `json:"statSecParam,omitempty"`
+	Degree           int               `json:"degree"`
+	NShares          int               `json:"nShares"`
+	Secret           string            `json:"secret"`
+	Seed             string            `json:"seed"`
+	Shares           []TestVectorShare `json:"shares"`
+}
+
+// A TestVectorShare is an expected share of a TestVector.
+type TestVectorShare struct {
+	X int    `json:"x"`
+	Y string `json:"y"`
+}
+
+// NewTestVector deals a secret over a finite field with ShareFiniteFieldSeeded and records the
+// result as a test vector.
+func NewTestVector(name string, secret *big.Int, fieldSize *big.Int, degree int, nShares int, seed []byte) TestVector {
+	vector := TestVector{
+		Name:      name,
+		FieldSize: fieldSize.String(),
+		Degree:    degree,
+		NShares:   nShares,
+		Secret:    secret.String(),
+		Seed:      hex.EncodeToString(seed),
+	}
+	vector.setShares(ShareFiniteFieldSeeded(secret, fieldSize, degree, nShares, seed))
+	return vector
+}
+
+// NewIntegerTestVector deals a secret over the integers with ShareIntegersSeeded and records the
+// result as a test vector.
+func NewIntegerTestVector(name string, secret *big.Int, secretUpperBound *big.Int, statSecParam int, degree int, nShares int, seed []byte) TestVector {
+	vector := TestVector{
+		Name:             name,
+		SecretUpperBound: secretUpperBound.String(),
+		StatSecParam:     statSecParam,
+		Degree:           degree,
+		NShares:          nShares,
+		Secret:           secret.String(),
+		Seed:             hex.EncodeToString(seed),
+	}
+	vector.setShares(ShareIntegersSeeded(secret, secretUpperBound, statSecParam, degree, nShares, seed))
+	return vector
+}
+
+// ReadTestVectors reads a JSON array of test vectors.
+func ReadTestVectors(r io.Reader) ([]TestVector, error) {
+	var vectors []TestVector
+	if err := json.NewDecoder(r).Decode(&vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// WriteTestVectors writes test vectors as an indented JSON array.
+func WriteTestVectors(w io.Writer, vectors []TestVector) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(vectors)
+}
+
+// Deal reproduces the dealing described by the test vector with this package.
+func (v TestVector) Deal() ([]Share, error) {
+	secret, ok := big.NewInt(0).SetString(v.Secret, 10)
+	if !ok {
+		return nil, ErrorInvalidTestVector
+	}
+	seed, err := hex.DecodeString(v.Seed)
+	if err != nil || v.Degree < 0 || v.NShares < 1 {
+		return nil, ErrorInvalidTestVector
+	}
+	if v.FieldSize != "" {
+		fieldSize, err := ParseFieldSize(v.FieldSize)
+		if err != nil {
+			return nil, ErrorInvalidTestVector
+		}
+		return ShareFiniteFieldSeeded(secret, fieldSize, v.Degree, v.NShares, seed), nil
+	}
+	secretUpperBound, ok := big.NewInt(0).SetString(v.SecretUpperBound, 10)
+	if !ok || secretUpperBound.Sign() <= 0 {
+		return nil, ErrorInvalidTestVector
+	}
+	return ShareIntegersSeeded(secret, secretUpperBound, v.StatSecParam, v.Degree, v.NShares, seed), nil
+}
+
+// Check reproduces the dealing described by the test vector and compares the result to the
+// expected shares. It returns ErrorTestVectorMismatch if they differ.
+func (v TestVector) Check() error {
+	shares, err := v.Deal()
+	if err != nil {
+		return err
+	}
+	if len(shares) != len(v.Shares) {
+		return ErrorTestVectorMismatch
+	}
+	for i := range shares {
+		if shares[i].X != v.Shares[i].X || shares[i].Y.String() != v.Shares[i].Y {
+			return ErrorTestVectorMismatch
+		}
+	}
+	return nil
+}
+
+func (v *TestVector) setShares(shares []Share) {
+	v.Shares = make([]TestVectorShare, len(shares))
+	for i, share := range shares {
+		v.Shares[i] = TestVectorShare{X: share.X, Y: share.Y.String()}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestVectors(t *testing.T) {
+	assert := assert.New(t)
+	f, err := os.Open("testdata/vectors.json")
+	if !assert.NoError(err) {
+		return
+	}
+	defer f.Close()
+	vectors, err := ReadTestVectors(f)
+	assert.NoError(err)
+	assert.NotEmpty(vectors)
+
+	for _, vector := range vectors {
+		assert.NoError(vector.Check(), vector.Name)
+
+		shares, err := vector.Deal()
+		assert.NoError(err)
+		secret, err := ShareCombine(shares)
+		assert.NoError(err)
+		assert.Equal(vector.Secret, secret.String(), vector.Name)
+	}
+}
+
+func TestTestVectorRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	vectors := []TestVector{
+		NewTestVector("field", big.NewInt(1234), big.NewInt(7919), 2, 4, []byte("seed")),
+		NewIntegerTestVector("integers", big.NewInt(-7), big.NewInt(100), 40, 1, 3, []byte("seed")),
+	}
+	var buf bytes.Buffer
+	assert.NoError(WriteTestVectors(&buf, vectors))
+	decoded, err := ReadTestVectors(&buf)
+	assert.NoError(err)
+	assert.Equal(vectors, decoded)
+	for _, vector := range decoded {
+		assert.NoError(vector.Check())
+	}
+
+	tampered := NewTestVector("field", big.NewInt(1234), big.NewInt(7919), 2, 4, []byte("seed"))
+	tampered.Shares[1].Y = "0"
+	assert.Equal(ErrorTestVectorMismatch, tampered.Check())
+	tampered.Shares = tampered.Shares[:3]
+	assert.Equal(ErrorTestVectorMismatch, tampered.Check())
+
+	malformed := vectors[0]
+	malformed.Seed = "not hex"
+	assert.Equal(ErrorInvalidTestVector, malformed.Check())
+	malformed = vectors[1]
+	malformed.SecretUpperBound = ""
+	assert.Equal(ErrorInvalidTestVector, malformed.Check())
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorInvalidTiers = errors.New("Degree of the metadata must be between 0 and the degree of the secret")
+)
+
+// A TieredShare is the share of a single party of a secret and its metadata, dealt by ShareTiered.
+type TieredShare struct {
+	Metadata ShareVector
+	Secret   ShareVector
+}
+
+// ShareTiered shares a secret together with non-sensitive metadata, such as a label, the owner and
+// the creation date of the secret, with a lower threshold for the metadata: any metadataDegree+1
+// shares reveal the metadata, while degree+1 shares are needed for the secret. Recovery
+// coordinators can thus identify a set of shares with CombineMetadata before enough custodians
+// come together to recover the secret. Both are shared with ShareBytes, and all shares carry the
+// same random sharing fingerprint, so that the shares of different secrets are not mixed up.
+func ShareTiered(secret []byte, metadata []byte, fieldSize *big.Int, metadataDegree int, degree int, nShares int) ([]TieredShare, error) {
+	if metadataDegree < 0 || metadataDegree > degree {
+		return nil, ErrorInvalidTiers
+	}
+	fingerprint := make([]byte, sharingFingerprintSize)
+	if _, err := rand.Read(fingerprint); err != nil {
+		return nil, err
+	}
+	metadataVectors, err := ShareBytes(metadata, fieldSize, metadataDegree, nShares)
+	if err != nil {
+		return nil, err
+	}
+	secretVectors, err := ShareBytes(secret, fieldSize, degree, nShares)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]TieredShare, nShares)
+	for i := range shares {
+		shares[i] = TieredShare{
+			Metadata: WithSharingFingerprint(metadataVectors[i], fingerprint),
+			Secret:   WithSharingFingerprint(secretVectors[i], fingerprint),
+		}
+	}
+	return shares, nil
+}
+
+// CombineMetadata recovers the metadata from at least metadataDegree+1 shares dealt by ShareTiered.
+func CombineMetadata(shares []TieredShare) ([]byte, error) {
+	vectors := make([]ShareVector, len(shares))
+	for i := range shares {
+		vectors[i] = shares[i].Metadata
+	}
+	return CombineBytes(vectors)
+}
+
+// CombineTiered recovers the secret and the metadata from at least degree+1 shares dealt by
+// ShareTiered.
+func CombineTiered(shares []TieredShare) (secret []byte, metadata []byte, err error) {
+	if metadata, err = CombineMetadata(shares); err != nil {
+		return nil, nil, err
+	}
+	vectors := make([]ShareVector, len(shares))
+	for i := range shares {
+		vectors[i] = shares[i].Secret
+	}
+	if secret, err = CombineBytes(vectors); err != nil {
+		return nil, nil, err
+	}
+	return secret, metadata, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareTiered(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := Conservative128().FieldSize
+	secret := []byte("correct horse battery staple")
+	metadata := []byte(`{"label": "backup key", "owner": "operations", "created": "2021-06-01"}`)
+	shares, err := ShareTiered(secret, metadata, fieldSize, 1, 3, 5)
+	assert.NoError(err)
+	assert.Len(shares, 5)
+
+	// Two shares reveal the metadata, but not the secret
+	recovered, err := CombineMetadata(shares[3:])
+	assert.NoError(err)
+	assert.Equal(metadata, recovered)
+	_, _, err = CombineTiered(shares[3:])
+	assert.True(errors.Is(err, ErrorTooFewShares))
+
+	recoveredSecret, recoveredMetadata, err := CombineTiered(shares[1:])
+	assert.NoError(err)
+	assert.Equal(secret, recoveredSecret)
+	assert.Equal(metadata, recoveredMetadata)
+
+	// Shares of another secret are not mixed in
+	other, err := ShareTiered(secret, metadata, fieldSize, 1, 3, 5)
+	assert.NoError(err)
+	_, err = CombineMetadata([]TieredShare{shares[0], other[1]})
+	assert.True(errors.Is(err, ErrorIncompatibleShares))
+
+	// Equal thresholds are allowed
+	shares, err = ShareTiered(secret, nil, fieldSize, 2, 2, 3)
+	assert.NoError(err)
+	recovered, err = CombineMetadata(shares)
+	assert.NoError(err)
+	assert.Empty(recovered)
+
+	_, err = ShareTiered(secret, metadata, fieldSize, 3, 2, 5)
+	assert.Equal(ErrorInvalidTiers, err)
+	_, err = ShareTiered(secret, metadata, fieldSize, -1, 2, 5)
+	assert.Equal(ErrorInvalidTiers, err)
+}
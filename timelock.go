@@ -0,0 +1,199 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The time-lock puzzles in this file are those of Rivest, Shamir and Wagner, "Time-lock puzzles and
+// timed-release crypto" (1996). The dealer, who knows the factorization of N = pq, computes
+// a^(2^T) mod N with a single exponentiation modulo phi(N), and encrypts a share under a key
+// derived from it. Anyone else has to compute it with T squarings modulo N, one after the other.
+//
+// Together with a sharing of degree t, this gives escrow with a threshold override: any t+1
+// shareholders recover the secret immediately, while t of them also need the locked helper share,
+// and hence the time to solve its puzzle. For a dead-man's switch, the owner hands the puzzle to
+// the shareholders and keeps renewing the sharing, for instance with a Lifecycle; the delay starts
+// when somebody starts solving, not when the puzzle is made.
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"time"
+)
+
+var (
+	ErrorInvalidTimeLock = errors.New("Time-locked share is malformed")
+)
+
+// timeLockPrimeBits is the size in bits of the primes of the modulus of a time-lock puzzle.
+const timeLockPrimeBits = 1024
+
+// timeLockCheckInterval is the number of squarings between checks of the context in SolveTimeLock.
+const timeLockCheckInterval = 1 << 14
+
+var timeLockSalt = []byte("github.com/TNO-MPC/shamir timelock")
+
+// A TimeLockedShare is a finite field share encrypted under the solution of a time-lock puzzle:
+// the key is derived from Base^(2^Squarings) mod N.
+type TimeLockedShare struct {
+	FieldSize          *big.Int
+	Degree             int
+	X                  int
+	SharingFingerprint []byte `json:",omitempty"`
+	N                  *big.Int
+	Base               *big.Int
+	Squarings          uint64
+	Ciphertext         []byte
+}
+
+// ShareTimeLocked shares a secret over a finite field with a polynomial of given degree, like
+// ShareFiniteField, and locks an additional helper share with X coordinate nShares+1 in a time-lock
+// puzzle of the given number of squarings. degree+1 of the shares recover the secret immediately,
+// degree of them together with the solved helper share after solving the puzzle.
+func ShareTimeLocked(secret *big.Int, fieldSize *big.Int, degree int, nShares int, squarings uint64) ([]Share, TimeLockedShare, error) {
+	shares := ShareFiniteField(secret, fieldSize, degree, nShares+1)
+	locked, err := LockShare(shares[nShares], squarings)
+	if err != nil {
+		return nil, TimeLockedShare{}, err
+	}
+	return shares[:nShares], locked, nil
+}
+
+// LockShare encrypts a finite field share under a fresh time-lock puzzle of the given number of
+// squarings. Use TimeLockSquarings to choose the number of squarings for a delay.
+func LockShare(share Share, squarings uint64) (TimeLockedShare, error) {
+	return lockShare(share, squarings, timeLockPrimeBits)
+}
+
+func lockShare(share Share, squarings uint64, primeBits int) (TimeLockedShare, error) {
+	if share.FieldSize == nil || share.Y == nil {
+		return TimeLockedShare{}, ErrorWrongShareType
+	}
+	p, err := rand.Prime(rand.Reader, primeBits)
+	if err != nil {
+		return TimeLockedShare{}, err
+	}
+	q, err := rand.Prime(rand.Reader, primeBits)
+	if err != nil {
+		return TimeLockedShare{}, err
+	}
+	if p.Cmp(q) == 0 {
+		return lockShare(share, squarings, primeBits)
+	}
+	n := big.NewInt(0).Mul(p, q)
+	base, err := randomUnit(rand.Reader, n)
+	if err != nil {
+		return TimeLockedShare{}, err
+	}
+	// The dealer shortcuts the squarings by reducing the exponent 2^squarings modulo phi(N)
+	phi := big.NewInt(0).Mul(p.Sub(p, big.NewInt(1)), q.Sub(q, big.NewInt(1)))
+	exponent := big.NewInt(0).Exp(big.NewInt(2), big.NewInt(0).SetUint64(squarings), phi)
+	solution := big.NewInt(0).Exp(base, exponent, n)
+
+	locked := TimeLockedShare{
+		FieldSize:          share.FieldSize,
+		Degree:             share.Degree,
+		X:                  share.X,
+		SharingFingerprint: share.SharingFingerprint,
+		N:                  n,
+		Base:               base,
+		Squarings:          squarings,
+	}
+	aead, err := newGCM(locked.key(solution))
+	if err != nil {
+		return TimeLockedShare{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return TimeLockedShare{}, err
+	}
+	locked.Ciphertext = aead.Seal(nonce, nonce, fieldElementBytes(share.Y, share.FieldSize), locked.header())
+	return locked, nil
+}
+
+// SolveTimeLock solves the puzzle of a time-locked share by sequential squaring and returns the
+// share. It returns ctx.Err() if ctx is done first, and ErrorDecryption if the locked share was
+// modified.
+func SolveTimeLock(ctx context.Context, locked TimeLockedShare) (Share, error) {
+	if locked.FieldSize == nil || locked.N == nil || locked.Base == nil || locked.N.Sign() <= 0 {
+		return Share{}, ErrorInvalidTimeLock
+	}
+	solution := big.NewInt(0).Mod(locked.Base, locked.N)
+	for i := uint64(0); i < locked.Squarings; i++ {
+		if i%timeLockCheckInterval == 0 {
+			select {
+			case <-ctx.Done():
+				return Share{}, ctx.Err()
+			default:
+			}
+		}
+		solution.Mul(solution, solution).Mod(solution, locked.N)
+	}
+	aead, err := newGCM(locked.key(solution))
+	if err != nil {
+		return Share{}, err
+	}
+	if len(locked.Ciphertext) < aead.NonceSize() {
+		return Share{}, ErrorDecryption
+	}
+	y, err := aead.Open(nil, locked.Ciphertext[:aead.NonceSize()], locked.Ciphertext[aead.NonceSize():], locked.header())
+	if err != nil {
+		return Share{}, ErrorDecryption
+	}
+	return Share{
+		FieldSize:          locked.FieldSize,
+		Degree:             locked.Degree,
+		X:                  locked.X,
+		Y:                  big.NewInt(0).SetBytes(y),
+		SharingFingerprint: locked.SharingFingerprint,
+	}, nil
+}
+
+// TimeLockSquarings measures how many squarings modulo a modulus of the size used by LockShare this
+// machine does in the given delay. A well-equipped attacker may be several times faster, so choose
+// the delay with a margin. The measurement itself takes up to a tenth of a second.
+func TimeLockSquarings(delay time.Duration) uint64 {
+	n, _ := rand.Int(rand.Reader, big.NewInt(0).Lsh(big.NewInt(1), 2*timeLockPrimeBits))
+	n.SetBit(n, 2*timeLockPrimeBits-1, 1)
+	x := big.NewInt(3)
+	count := uint64(0)
+	start := time.Now()
+	for time.Since(start) < 100*time.Millisecond {
+		for i := 0; i < 1000; i++ {
+			x.Mul(x, x).Mod(x, n)
+		}
+		count += 1000
+	}
+	return uint64(float64(count) * delay.Seconds() / time.Since(start).Seconds())
+}
+
+// header encodes the public parameters of the locked share, which are authenticated along with it.
+func (l TimeLockedShare) header() []byte {
+	var header bytes.Buffer
+	writeInt(&header, l.FieldSize)
+	writeUint64(&header, uint64(l.Degree))
+	writeUint64(&header, uint64(l.X))
+	writeBytes(&header, l.SharingFingerprint)
+	writeInt(&header, l.N)
+	writeInt(&header, l.Base)
+	writeUint64(&header, l.Squarings)
+	return header.Bytes()
+}
+
+// key derives the encryption key of the locked share from the solution of its puzzle.
+func (l TimeLockedShare) key(solution *big.Int) []byte {
+	return hkdfExpand(hkdfExtract(timeLockSalt, solution.Bytes()), l.header(), encryptionKeySize)
+}
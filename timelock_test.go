@@ -0,0 +1,82 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeLock(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	shares := ShareFiniteField(big.NewInt(1234), fieldSize, 2, 6)
+	locked, err := lockShare(shares[5], 1000, 256)
+	assert.NoError(err)
+
+	// A full quorum recovers immediately, a reduced quorum with the solved helper share
+	secret, err := ShareCombine(shares[:3])
+	assert.NoError(err)
+	assert.Equal(big.NewInt(1234), secret)
+	helper, err := SolveTimeLock(context.Background(), locked)
+	assert.NoError(err)
+	assert.Equal(shares[5], helper)
+	secret, err = ShareCombine([]Share{shares[0], shares[3], helper})
+	assert.NoError(err)
+	assert.Equal(big.NewInt(1234), secret)
+
+	// The puzzle cannot be shortcut by changing its parameters
+	tampered := locked
+	tampered.Squarings = 10
+	_, err = SolveTimeLock(context.Background(), tampered)
+	assert.Equal(ErrorDecryption, err)
+	tampered = locked
+	tampered.X = 1
+	_, err = SolveTimeLock(context.Background(), tampered)
+	assert.Equal(ErrorDecryption, err)
+	tampered = locked
+	tampered.N = nil
+	_, err = SolveTimeLock(context.Background(), tampered)
+	assert.Equal(ErrorInvalidTimeLock, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = SolveTimeLock(ctx, locked)
+	assert.Equal(context.Canceled, err)
+
+	_, err = lockShare(Share{Degree: 1, X: 1, Y: big.NewInt(1)}, 10, 256)
+	assert.Equal(ErrorWrongShareType, err)
+}
+
+func TestShareTimeLocked(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(7919)
+	shares, locked, err := ShareTimeLocked(big.NewInt(42), fieldSize, 1, 3, 100)
+	assert.NoError(err)
+	assert.Equal(3, len(shares))
+	assert.Equal(4, locked.X)
+	assert.Equal(2*timeLockPrimeBits, locked.N.BitLen())
+	helper, err := SolveTimeLock(context.Background(), locked)
+	assert.NoError(err)
+	secret, err := ShareCombine([]Share{shares[2], helper})
+	assert.NoError(err)
+	assert.Equal(big.NewInt(42), secret)
+
+	assert.True(TimeLockSquarings(time.Second) > TimeLockSquarings(time.Millisecond))
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"io"
+	"math/big"
+)
+
+// A Transcript maintains a running SHA-256 hash of everything that happened in a session, such as
+// the parameters, commitments, share fingerprints and the messages of every round. Parties that
+// append the same public data in the same order obtain the same Digest, so comparing digests
+// detects desynchronization or tampering. Every entry is labelled and length-prefixed, so different
+// sequences of entries never result in the same digest.
+type Transcript struct {
+	h hash.Hash
+}
+
+// NewTranscript starts a transcript for the session with the given identifier.
+func NewTranscript(sessionID []byte) *Transcript {
+	t := &Transcript{h: sha256.New()}
+	t.Append("session", sessionID)
+	return t
+}
+
+// Append appends labelled data to the transcript.
+func (t *Transcript) Append(label string, data []byte) {
+	writeBytes(t.h, []byte(label))
+	writeBytes(t.h, data)
+}
+
+// AppendParameters appends the parameters of a dealing to the transcript.
+func (t *Transcript) AppendParameters(fieldSize *big.Int, degree int, nShares int) {
+	writeBytes(t.h, []byte("parameters"))
+	writeInt(t.h, fieldSize)
+	writeUint64(t.h, uint64(degree))
+	writeUint64(t.h, uint64(nShares))
+}
+
+// AppendBeacon appends the round and randomness of a public beacon to the transcript, see
+// ShareFiniteFieldWithBeacon.
+func (t *Transcript) AppendBeacon(round uint64, randomness []byte) {
+	writeBytes(t.h, []byte("beacon"))
+	writeUint64(t.h, round)
+	writeBytes(t.h, randomness)
+}
+
+// AppendShareFingerprint appends the fingerprint of a share to the transcript, see ShareFingerprint.
+func (t *Transcript) AppendShareFingerprint(fingerprint []byte) {
+	t.Append("share fingerprint", fingerprint)
+}
+
+// AppendMessage appends a message of the given round from one party to another to the transcript.
+func (t *Transcript) AppendMessage(round int, from int, to int, shares []NestedShare) {
+	writeBytes(t.h, []byte("message"))
+	writeUint64(t.h, uint64(round))
+	writeUint64(t.h, uint64(from))
+	writeUint64(t.h, uint64(to))
+	writeUint64(t.h, uint64(len(shares)))
+	for _, share := range shares {
+		writeUint64(t.h, uint64(len(share.Parents)))
+		for _, parent := range share.Parents {
+			writeUint64(t.h, uint64(parent.X))
+			writeUint64(t.h, uint64(parent.Degree))
+		}
+		writeShare(t.h, share.Share)
+	}
+}
+
+// Digest returns the digest of the transcript so far. Appending to the transcript afterwards is
+// allowed.
+func (t *Transcript) Digest() []byte {
+	return t.h.Sum(nil)
+}
+
+// ShareFingerprint returns the SHA-256 hash of an unambiguous encoding of share. A dealer can publish
+// the fingerprints of all shares, so that shareholders can check that they received the share that
+// everyone else expects. Note that for small fields, the share can be found from its fingerprint by
+// trying all values.
+func ShareFingerprint(share Share) []byte {
+	h := sha256.New()
+	writeShare(h, share)
+	return h.Sum(nil)
+}
+
+// writeShare writes an unambiguous encoding of share to w.
+func writeShare(w io.Writer, share Share) {
+	writeInt(w, share.FieldSize)
+	writeInt(w, share.Factor)
+	writeUint64(w, uint64(share.Degree))
+	writeUint64(w, uint64(share.X))
+	writeInt(w, share.Y)
+}
+
+// writeInt writes a length-prefixed encoding of the sign and magnitude of n to w. The nil pointer is
+// encoded differently from zero.
+func writeInt(w io.Writer, n *big.Int) {
+	if n == nil {
+		w.Write([]byte{0xff})
+		return
+	}
+	w.Write([]byte{byte(n.Sign() + 1)})
+	writeBytes(w, n.Bytes())
+}
+
+// writeBytes writes b to w, prefixed with its length.
+func writeBytes(w io.Writer, b []byte) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(len(b)))
+	w.Write(buf[:])
+	w.Write(b)
+}
+
+func writeUint64(w io.Writer, n uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], n)
+	w.Write(buf[:])
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscript(t *testing.T) {
+	assert := assert.New(t)
+	shares := ShareFiniteField(big.NewInt(123), big.NewInt(7919), 1, 3)
+
+	record := func(t *Transcript, shares []Share) []byte {
+		t.AppendParameters(big.NewInt(7919), 1, 3)
+		for _, share := range shares {
+			t.AppendShareFingerprint(ShareFingerprint(share))
+		}
+		t.AppendMessage(1, 2, 3, []NestedShare{{Share: shares[1]}})
+		t.Append("done", nil)
+		return t.Digest()
+	}
+
+	digest := record(NewTranscript([]byte("session 1")), shares)
+	assert.Len(digest, 32)
+	assert.Equal(digest, record(NewTranscript([]byte("session 1")), shares))
+	assert.NotEqual(digest, record(NewTranscript([]byte("session 2")), shares))
+
+	tampered := append([]Share{}, shares...)
+	tampered[2].Y = big.NewInt(0).Add(shares[2].Y, big.NewInt(1))
+	assert.NotEqual(digest, record(NewTranscript([]byte("session 1")), tampered))
+
+	// Labels and data are separated unambiguously
+	t1 := NewTranscript(nil)
+	t1.Append("ab", []byte("c"))
+	t2 := NewTranscript(nil)
+	t2.Append("a", []byte("bc"))
+	assert.NotEqual(t1.Digest(), t2.Digest())
+}
+
+func TestShareFingerprint(t *testing.T) {
+	assert := assert.New(t)
+	share := Share{FieldSize: big.NewInt(7919), Degree: 1, X: 1, Y: big.NewInt(0)}
+	integerShare := Share{Factor: big.NewInt(7919), Degree: 1, X: 1, Y: big.NewInt(0)}
+	assert.NotEqual(ShareFingerprint(share), ShareFingerprint(integerShare))
+	assert.Equal(ShareFingerprint(share), ShareFingerprint(Share{FieldSize: big.NewInt(7919), Degree: 1, X: 1, Y: big.NewInt(0)}))
+}
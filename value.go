@@ -0,0 +1,78 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorValueEncoding = errors.New("Value was encoded differently than it is decoded")
+)
+
+// The first byte of a shared value identifies its encoding.
+const (
+	valueBinary byte = iota + 1
+	valueGob
+)
+
+// ShareValue shares an arbitrary Go value over a finite field, see ShareBytes. Values implementing
+// encoding.BinaryMarshaler are encoded with MarshalBinary, and other values with encoding/gob. This
+// allows applications to split structured objects, such as configurations or credentials, directly.
+// It returns a ShareVector for every party.
+func ShareValue(value interface{}, fieldSize *big.Int, degree int, nShares int) ([]ShareVector, error) {
+	var encoded []byte
+	if marshaler, ok := value.(encoding.BinaryMarshaler); ok {
+		data, err := marshaler.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		encoded = append([]byte{valueBinary}, data...)
+	} else {
+		buf := bytes.NewBuffer([]byte{valueGob})
+		if err := gob.NewEncoder(buf).Encode(value); err != nil {
+			return nil, err
+		}
+		encoded = buf.Bytes()
+	}
+	return ShareBytes(encoded, fieldSize, degree, nShares)
+}
+
+// CombineValue combines the ShareVectors produced by ShareValue and decodes the value into target,
+// which must be a pointer. If target implements encoding.BinaryUnmarshaler, the value must have
+// been encoded with MarshalBinary, and otherwise with encoding/gob.
+func CombineValue(vectors []ShareVector, target interface{}) error {
+	encoded, err := CombineBytes(vectors)
+	if err != nil {
+		return err
+	}
+	if len(encoded) == 0 {
+		return ErrorInvalidPayload
+	}
+	if unmarshaler, ok := target.(encoding.BinaryUnmarshaler); ok {
+		if encoded[0] != valueBinary {
+			return ErrorValueEncoding
+		}
+		return unmarshaler.UnmarshalBinary(encoded[1:])
+	}
+	if encoded[0] != valueGob {
+		return ErrorValueEncoding
+	}
+	return gob.NewDecoder(bytes.NewReader(encoded[1:])).Decode(target)
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type credentials struct {
+	User     string
+	Password string
+	Scopes   []string
+}
+
+func TestShareValue(t *testing.T) {
+	assert := assert.New(t)
+	value := credentials{User: "admin", Password: "correct horse battery staple", Scopes: []string{"read", "write"}}
+	vectors, err := ShareValue(value, mersenne61, 2, 5)
+	assert.NoError(err)
+
+	var recovered credentials
+	assert.NoError(CombineValue(vectors[2:], &recovered))
+	assert.Equal(value, recovered)
+
+	// Values implementing encoding.BinaryMarshaler use it
+	timestamp := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	vectors, err = ShareValue(timestamp, mersenne61, 1, 3)
+	assert.NoError(err)
+	var recoveredTimestamp time.Time
+	assert.NoError(CombineValue(vectors[:2], &recoveredTimestamp))
+	assert.True(timestamp.Equal(recoveredTimestamp))
+
+	// Decoding with the other encoding fails
+	assert.Equal(ErrorValueEncoding, CombineValue(vectors, &recovered))
+	vectors, err = ShareValue(url.Values{"a": {"b"}}, mersenne61, 1, 3)
+	assert.NoError(err)
+	assert.Equal(ErrorValueEncoding, CombineValue(vectors, &recoveredTimestamp))
+
+	_, err = ShareValue(func() {}, mersenne61, 1, 3)
+	assert.Error(err)
+	_, err = ShareValue(value, big.NewInt(251), 1, 3)
+	assert.Equal(ErrorFieldTooSmall, err)
+}
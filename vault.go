@@ -0,0 +1,105 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorWrongShareType = errors.New("Share is of the wrong type for this operation")
+)
+
+var vaultSalt = []byte("github.com/TNO-MPC/shamir vault")
+
+// A Vault deals secrets deterministically from a master seed. Every secret is identified by an ID,
+// from which a separate seed is derived, so that the share set of any secret can be regenerated on
+// demand and only the master seed (and optionally the commitments) has to be backed up.
+type Vault struct {
+	prk []byte
+}
+
+// NewVault returns a Vault for the given master seed. The master seed should contain at least 32
+// bytes of entropy and must be protected at least as well as all secrets dealt from it.
+func NewVault(masterSeed []byte) *Vault {
+	return &Vault{prk: hkdfExtract(vaultSalt, masterSeed)}
+}
+
+// ShareFiniteField shares the secret with the given ID over a finite field, see ShareFiniteFieldSeeded.
+// The same ID must not be used for two different secrets.
+func (v *Vault) ShareFiniteField(id string, secret *big.Int, fieldSize *big.Int, degree int, nShares int) []Share {
+	return ShareFiniteFieldSeeded(secret, fieldSize, degree, nShares, v.derive("seed", id))
+}
+
+// ShareIntegers shares the secret with the given ID over the integers, see ShareIntegersSeeded.
+// The same ID must not be used for two different secrets.
+func (v *Vault) ShareIntegers(id string, secret *big.Int, secretUpperBound *big.Int, statSecParam int, degree int, nShares int) []Share {
+	return ShareIntegersSeeded(secret, secretUpperBound, statSecParam, degree, nShares, v.derive("seed", id))
+}
+
+// RegenerateFiniteField regenerates all nShares shares of the secret with the given ID from a
+// single share of it, without requiring the secret itself.
+func (v *Vault) RegenerateFiniteField(id string, share Share, nShares int) ([]Share, error) {
+	if share.FieldSize == nil {
+		return nil, ErrorWrongShareType
+	}
+	coefficients := seededCoefficients(v.derive("seed", id), share.FieldSize, share.Degree)
+	secret := big.NewInt(0).Sub(share.Y, evaluatePolynomial(big.NewInt(0), coefficients, share.X))
+	secret.Mod(secret, share.FieldSize)
+	return shareFiniteField(secret, share.FieldSize, coefficients, nShares), nil
+}
+
+// RegenerateIntegers regenerates all shares of the integer-shared secret with the given ID from a
+// single share of it, without requiring the secret itself. The parameters must be the ones used
+// when dealing the secret.
+func (v *Vault) RegenerateIntegers(id string, share Share, secretUpperBound *big.Int, statSecParam int, nShares int) ([]Share, error) {
+	if share.FieldSize != nil || share.Factor == nil || share.Factor.Cmp(factorial(int64(nShares))) != 0 {
+		return nil, ErrorWrongShareType
+	}
+	coefficientUpperBound := integerCoefficientBound(secretUpperBound, statSecParam, nShares)
+	coefficients := seededCoefficients(v.derive("seed", id), coefficientUpperBound, share.Degree)
+	secret := big.NewInt(0).Sub(share.Y, evaluatePolynomial(big.NewInt(0), coefficients, share.X))
+	secret.Div(secret, share.Factor)
+	return shareIntegers(secret, secretUpperBound, coefficients, nShares), nil
+}
+
+// Commitment computes a commitment to a share set of the secret with the given ID, keyed with a
+// key derived from the master seed. It can be stored alongside the backup of the master seed and
+// allows the Vault to check regenerated or returned shares with Verify. The commitment reveals
+// nothing about the shares to anyone without the master seed.
+func (v *Vault) Commitment(id string, shares []Share) []byte {
+	mac := hmac.New(sha256.New, v.derive("commitment", id))
+	for _, share := range shares {
+		writeShare(mac, share)
+	}
+	return mac.Sum(nil)
+}
+
+// Verify checks that shares matches a commitment produced by Commitment.
+func (v *Vault) Verify(id string, shares []Share, commitment []byte) bool {
+	return hmac.Equal(v.Commitment(id, shares), commitment)
+}
+
+// derive derives a 32-byte key for the given purpose and secret ID from the master seed.
+func (v *Vault) derive(purpose string, id string) []byte {
+	info := make([]byte, 0, len(purpose)+1+len(id))
+	info = append(info, purpose...)
+	info = append(info, 0)
+	info = append(info, id...)
+	return hkdfExpand(v.prk, info, 32)
+}
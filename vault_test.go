@@ -0,0 +1,87 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultFiniteField(t *testing.T) {
+	assert := assert.New(t)
+	vault := NewVault([]byte("a master seed that is used for testing only"))
+
+	shares := vault.ShareFiniteField("key-1", big.NewInt(123), big.NewInt(7919), 3, 5)
+	assert.Equal(shares, vault.ShareFiniteField("key-1", big.NewInt(123), big.NewInt(7919), 3, 5))
+	assert.NotEqual(shares, vault.ShareFiniteField("key-2", big.NewInt(123), big.NewInt(7919), 3, 5))
+
+	regenerated, err := vault.RegenerateFiniteField("key-1", shares[2], 5)
+	assert.NoError(err)
+	assert.Equal(shares, regenerated)
+
+	commitment := vault.Commitment("key-1", shares)
+	assert.True(vault.Verify("key-1", regenerated, commitment))
+	assert.False(vault.Verify("key-2", regenerated, commitment))
+	regenerated[0].Y.Add(regenerated[0].Y, big.NewInt(1))
+	assert.False(vault.Verify("key-1", regenerated, commitment))
+
+	regenerated, err = NewVault([]byte("another master seed")).RegenerateFiniteField("key-1", shares[2], 5)
+	assert.NoError(err)
+	assert.False(vault.Verify("key-1", regenerated, commitment))
+}
+
+func TestVaultIntegers(t *testing.T) {
+	assert := assert.New(t)
+	vault := NewVault([]byte("a master seed that is used for testing only"))
+
+	shares := vault.ShareIntegers("key-1", big.NewInt(-123), big.NewInt(10000), 100, 3, 5)
+	regenerated, err := vault.RegenerateIntegers("key-1", shares[4], big.NewInt(10000), 100, 5)
+	assert.NoError(err)
+	assert.Equal(shares, regenerated)
+
+	secret, err := ShareCombine(regenerated[0:4])
+	assert.NoError(err)
+	if assert.NotNil(secret) {
+		assert.Equal(int64(-123), secret.Int64())
+	}
+
+	_, err = vault.RegenerateIntegers("key-1", shares[4], big.NewInt(10000), 100, 6)
+	assert.Equal(ErrorWrongShareType, err)
+	_, err = vault.RegenerateFiniteField("key-1", shares[4], 5)
+	assert.Equal(ErrorWrongShareType, err)
+}
+
+func TestVaultConcurrency(t *testing.T) {
+	assert := assert.New(t)
+	vault := NewVault([]byte("a master seed of at least 32 bytes"))
+	expected := vault.ShareFiniteField("secret", big.NewInt(42), big.NewInt(7919), 2, 5)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			shares := vault.ShareFiniteField("secret", big.NewInt(42), big.NewInt(7919), 2, 5)
+			assert.Equal(expected, shares)
+			regenerated, err := vault.RegenerateFiniteField("secret", shares[i%5], 5)
+			assert.NoError(err)
+			assert.True(vault.Verify("secret", regenerated, vault.Commitment("secret", expected)))
+		}(i)
+	}
+	wg.Wait()
+}
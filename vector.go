@@ -0,0 +1,193 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorVectorLength = errors.New("Vectors of different lengths given")
+)
+
+// A ShareVector holds the shares of a single party of a vector of secrets, so all of its shares
+// have the same X coordinate.
+type ShareVector []Share
+
+// ShareVectorFiniteField shares every secret in secrets over a finite field, see ShareFiniteField.
+// It returns a ShareVector for every party.
+func ShareVectorFiniteField(secrets []*big.Int, fieldSize *big.Int, degree int, nShares int) []ShareVector {
+	vectors := make([]ShareVector, nShares)
+	for i := range vectors {
+		vectors[i] = make(ShareVector, len(secrets))
+	}
+	parallelFor(len(secrets), func(j int) error {
+		shares := ShareFiniteField(secrets[j], fieldSize, degree, nShares)
+		for i := range vectors {
+			vectors[i][j] = shares[i]
+		}
+		return nil
+	})
+	return vectors
+}
+
+// ShareVectorIntegers shares every secret in secrets over the integers, see ShareIntegers. It
+// returns a ShareVector for every party.
+func ShareVectorIntegers(secrets []*big.Int, secretUpperBound *big.Int, statSecParam int, degree int, nShares int) []ShareVector {
+	vectors := make([]ShareVector, nShares)
+	for i := range vectors {
+		vectors[i] = make(ShareVector, len(secrets))
+	}
+	parallelFor(len(secrets), func(j int) error {
+		shares := ShareIntegers(secrets[j], secretUpperBound, statSecParam, degree, nShares)
+		for i := range vectors {
+			vectors[i][j] = shares[i]
+		}
+		return nil
+	})
+	return vectors
+}
+
+// CombineVector combines the ShareVectors of several parties and recovers the vector of secrets.
+func CombineVector(vectors []ShareVector) ([]*big.Int, error) {
+	return combineVector("", vectors)
+}
+
+// combineVector combines the ShareVectors of several parties in the given session, which may be
+// empty, see emitCombine.
+func combineVector(session string, vectors []ShareVector) ([]*big.Int, error) {
+	if len(vectors) == 0 {
+		return nil, ErrorNoShares
+	}
+	secrets := make([]*big.Int, len(vectors[0]))
+	for i := range vectors {
+		if len(vectors[i]) != len(secrets) {
+			return nil, ErrorVectorLength
+		}
+	}
+	err := parallelFor(len(secrets), func(j int) error {
+		shares := make([]Share, len(vectors))
+		for i := range vectors {
+			shares[i] = vectors[i][j]
+		}
+		var err error
+		secrets[j], err = emitCombine(session, shares, shareCombine)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// ShareVectorAdd adds ShareVectors of the same party element-wise, see ShareAdd.
+func ShareVectorAdd(vectors []ShareVector) (ShareVector, error) {
+	return shareVectorApply(vectors, ShareAdd)
+}
+
+// ShareVectorMul multiplies ShareVectors of the same party element-wise, see ShareMul.
+func ShareVectorMul(vectors []ShareVector) (ShareVector, error) {
+	return shareVectorApply(vectors, ShareMul)
+}
+
+// Sum returns a share of the sum of the elements of the vector.
+func (v ShareVector) Sum() (Share, error) {
+	return ShareAdd(v)
+}
+
+// InnerProduct returns a share of the inner product of the vectors shared by v and w. Its degree is
+// the sum of the degrees of v and w.
+func (v ShareVector) InnerProduct(w ShareVector) (Share, error) {
+	products, err := ShareVectorMul([]ShareVector{v, w})
+	if err != nil {
+		return Share{}, err
+	}
+	return products.Sum()
+}
+
+// ApplyAffine returns shares of M v + b for the vector shared by v, a public matrix m with a row per
+// element of the result and a column per element of v, and a public vector b, or nil for M v. It is
+// computed locally, without communication or a change of degree, and reduces every element of the
+// result only once, so it is much faster than a loop of ShareMulConstant and ShareAdd. The shares
+// of v must have the same parameters and X coordinate.
+func ApplyAffine(m [][]*big.Int, v ShareVector, b []*big.Int) (ShareVector, error) {
+	if len(v) == 0 {
+		return nil, ErrorNoShares
+	}
+	if b != nil && len(b) != len(m) {
+		return nil, ErrorVectorLength
+	}
+	for _, share := range v[1:] {
+		if !compatible(v[0], share) || share.X != v[0].X {
+			return nil, ErrorIncompatibleShares
+		}
+	}
+	result := make(ShareVector, len(m))
+	for i, row := range m {
+		if len(row) != len(v) {
+			return nil, ErrorVectorLength
+		}
+		y := big.NewInt(0)
+		bound := big.NewInt(0)
+		term := big.NewInt(0)
+		for j, share := range v {
+			y.Add(y, term.Mul(row[j], share.Y))
+			bound = addBounds(bound, mulBounds(share.Bound, term.Abs(row[j])))
+		}
+		if b != nil {
+			if v[0].Factor != nil {
+				y.Add(y, term.Mul(b[i], v[0].Factor))
+			} else {
+				y.Add(y, b[i])
+			}
+			bound = addBounds(bound, term.Abs(b[i]))
+		}
+		if v[0].FieldSize != nil {
+			y.Mod(y, v[0].FieldSize)
+		}
+		result[i] = Share{
+			FieldSize: v[0].FieldSize,
+			Degree:    v[0].Degree,
+			Factor:    v[0].Factor,
+			X:         v[0].X,
+			Y:         y,
+			Bound:     bound,
+		}
+	}
+	return result, nil
+}
+
+func shareVectorApply(vectors []ShareVector, op func([]Share) (Share, error)) (ShareVector, error) {
+	if len(vectors) == 0 {
+		return nil, ErrorNoShares
+	}
+	result := make(ShareVector, len(vectors[0]))
+	shares := make([]Share, len(vectors))
+	for j := range result {
+		for i := range vectors {
+			if len(vectors[i]) != len(result) {
+				return nil, ErrorVectorLength
+			}
+			shares[i] = vectors[i][j]
+		}
+		var err error
+		result[j], err = op(shares)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
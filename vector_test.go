@@ -0,0 +1,116 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bigInts(values ...int64) []*big.Int {
+	ints := make([]*big.Int, len(values))
+	for i, value := range values {
+		ints[i] = big.NewInt(value)
+	}
+	return ints
+}
+
+func TestShareVector(t *testing.T) {
+	assert := assert.New(t)
+	x := ShareVectorFiniteField(bigInts(1, 2, 3), big.NewInt(7919), 1, 3)
+	y := ShareVectorFiniteField(bigInts(4, 5, 6), big.NewInt(7919), 1, 3)
+
+	sums := make([]ShareVector, 3)
+	innerProducts := make([]Share, 3)
+	var err error
+	for i := range x {
+		sums[i], err = ShareVectorAdd([]ShareVector{x[i], y[i]})
+		assert.NoError(err)
+		innerProducts[i], err = x[i].InnerProduct(y[i])
+		assert.NoError(err)
+	}
+
+	secrets, err := CombineVector(sums)
+	assert.NoError(err)
+	assert.Equal(bigInts(5, 7, 9), secrets)
+
+	innerProduct, err := ShareCombine(innerProducts)
+	assert.NoError(err)
+	if assert.NotNil(innerProduct) {
+		assert.Equal(int64(32), innerProduct.Int64())
+	}
+
+	_, err = ShareVectorAdd([]ShareVector{x[0], y[0][0:2]})
+	assert.Equal(ErrorVectorLength, err)
+	_, err = CombineVector([]ShareVector{x[0], x[1][0:2]})
+	assert.Equal(ErrorVectorLength, err)
+	_, err = CombineVector(nil)
+	assert.Equal(ErrorNoShares, err)
+}
+
+func TestShareVectorIntegers(t *testing.T) {
+	assert := assert.New(t)
+	vectors := ShareVectorIntegers(bigInts(-1, 0, 1), big.NewInt(10), 40, 2, 3)
+
+	secrets, err := CombineVector(vectors)
+	assert.NoError(err)
+	assert.Equal(bigInts(-1, 0, 1), secrets)
+}
+
+func TestApplyAffine(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := big.NewInt(2039)
+	m := [][]*big.Int{bigInts(1, 2, 3), bigInts(-1, 0, 4)}
+	b := bigInts(10, -20)
+	vectors := ShareVectorFiniteField(bigInts(5, 6, 7), fieldSize, 1, 3)
+	results := make([]ShareVector, len(vectors))
+	for i := range vectors {
+		var err error
+		results[i], err = ApplyAffine(m, vectors[i], b)
+		assert.NoError(err)
+		assert.Len(results[i], 2)
+		assert.Equal(1, results[i][0].Degree)
+	}
+	secrets, err := CombineVector(results)
+	assert.NoError(err)
+	assert.Equal(bigInts(5+12+21+10, -5+28-20), secrets)
+
+	// Over the integers, constants are scaled by the factor and bounds are tracked
+	integerVectors := ShareVectorIntegers(bigInts(5, 6, 7), big.NewInt(10), 40, 1, 3)
+	for i := range integerVectors {
+		results[i], err = ApplyAffine(m, integerVectors[i], b)
+		assert.NoError(err)
+	}
+	assert.Equal(big.NewInt(10*6+10), results[0][0].Bound)
+	secrets, err = CombineVector(results)
+	assert.NoError(err)
+	assert.Equal(bigInts(48, -5+28-20), secrets)
+
+	linear, err := ApplyAffine(m, vectors[0], nil)
+	assert.NoError(err)
+	expected, _ := ShareAdd([]Share{vectors[0][0], ShareMulConstant(vectors[0][1], big.NewInt(2)), ShareMulConstant(vectors[0][2], big.NewInt(3))})
+	assert.Equal(expected.Y, linear[0].Y)
+
+	_, err = ApplyAffine([][]*big.Int{bigInts(1, 2)}, vectors[0], nil)
+	assert.Equal(ErrorVectorLength, err)
+	_, err = ApplyAffine(m, vectors[0], bigInts(1))
+	assert.Equal(ErrorVectorLength, err)
+	_, err = ApplyAffine(m, ShareVector{vectors[0][0], vectors[1][1], vectors[0][2]}, nil)
+	assert.Equal(ErrorIncompatibleShares, err)
+	_, err = ApplyAffine(m, nil, nil)
+	assert.Equal(ErrorNoShares, err)
+}
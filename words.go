@@ -0,0 +1,138 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrorUnknownWord   = errors.New("Word is not in the word lists")
+	ErrorWordOrder     = errors.New("Word is from the wrong word list, a word may be missing or transposed")
+	ErrorWordChecksum  = errors.New("Checksum of the words does not match")
+	ErrorWordsTooShort = errors.New("Too few words given")
+	ErrorInvalidX      = errors.New("X coordinate out of range")
+)
+
+// wordChecksumSize is the size in bytes of the checksum appended by ShareToWords.
+const wordChecksumSize = 2
+
+var evenIndex, oddIndex = indexWords(evenWords), indexWords(oddWords)
+
+// EncodeWords encodes data as words for reading out loud, one word per byte. Bytes at even
+// positions are encoded with a list of two-syllable words and bytes at odd positions with a list
+// of three-syllable words, so that a missing, repeated or transposed word is detected by
+// DecodeWords at the position where it occurs.
+func EncodeWords(data []byte) []string {
+	words := make([]string, len(data))
+	for i, b := range data {
+		if i%2 == 0 {
+			words[i] = evenWords[b]
+		} else {
+			words[i] = oddWords[b]
+		}
+	}
+	return words
+}
+
+// DecodeWords decodes words produced by EncodeWords, ignoring case. On failure, it returns the
+// position of the first word that could not be decoded with ErrorUnknownWord or ErrorWordOrder.
+// Words that are replaced by other words from the same list are not detected; use ShareToWords,
+// which adds a checksum, for shares.
+func DecodeWords(words []string) ([]byte, int, error) {
+	data := make([]byte, len(words))
+	for i, word := range words {
+		word = strings.ToLower(word)
+		index, other := evenIndex, oddIndex
+		if i%2 == 1 {
+			index, other = oddIndex, evenIndex
+		}
+		b, ok := index[word]
+		if !ok {
+			if _, ok := other[word]; ok {
+				return nil, i, ErrorWordOrder
+			}
+			return nil, i, ErrorUnknownWord
+		}
+		data[i] = b
+	}
+	return data, -1, nil
+}
+
+// ShareToWords encodes a share over a finite field as words, for instance to read it out over the
+// phone during an emergency recovery. Only the X and Y coordinates are encoded, followed by a
+// checksum over the share and its parameters, so the recipient must know the field size and degree.
+// X coordinates must be below 65536.
+func ShareToWords(share Share) ([]string, error) {
+	if share.FieldSize == nil {
+		return nil, ErrorWrongShareType
+	}
+	if share.X < 0 || share.X > 0xffff {
+		return nil, ErrorInvalidX
+	}
+	data := append([]byte{byte(share.X >> 8), byte(share.X)}, fieldElementBytes(share.Y, share.FieldSize)...)
+	return EncodeWords(append(data, wordChecksum(data, share.FieldSize, share.Degree)...)), nil
+}
+
+// ShareFromWords decodes a share encoded by ShareToWords, given the field size and degree of the
+// share. On failure, it returns the position of the first wrong word if it is known, or -1 if only
+// the checksum failed.
+func ShareFromWords(words []string, fieldSize *big.Int, degree int) (Share, int, error) {
+	size := 2 + (fieldSize.BitLen()+7)/8
+	data, position, err := DecodeWords(words)
+	if err != nil {
+		return Share{}, position, err
+	}
+	if len(data) < size+wordChecksumSize {
+		return Share{}, len(data), ErrorWordsTooShort
+	}
+	if len(data) > size+wordChecksumSize {
+		return Share{}, size + wordChecksumSize, ErrorWordChecksum
+	}
+	if !bytes.Equal(data[size:], wordChecksum(data[:size], fieldSize, degree)) {
+		return Share{}, -1, ErrorWordChecksum
+	}
+	return Share{
+		FieldSize: fieldSize,
+		Degree:    degree,
+		X:         int(data[0])<<8 | int(data[1]),
+		Y:         big.NewInt(0).SetBytes(data[2:size]),
+	}, -1, nil
+}
+
+// wordChecksum returns the first bytes of a SHA-256 hash of the encoded share and its parameters.
+func wordChecksum(data []byte, fieldSize *big.Int, degree int) []byte {
+	h := sha256.New()
+	writeInt(h, fieldSize)
+	writeUint64(h, uint64(degree))
+	writeBytes(h, data)
+	return h.Sum(nil)[:wordChecksumSize]
+}
+
+func parseWords(list string) []string {
+	return strings.Fields(list)
+}
+
+func indexWords(words []string) map[string]byte {
+	index := make(map[string]byte, len(words))
+	for i, word := range words {
+		index[word] = byte(i)
+	}
+	return index
+}
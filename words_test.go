@@ -0,0 +1,91 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordLists(t *testing.T) {
+	assert := assert.New(t)
+	assert.Len(evenWords, 256)
+	assert.Len(oddWords, 256)
+	assert.Len(evenIndex, 256)
+	assert.Len(oddIndex, 256)
+	for word := range evenIndex {
+		_, ok := oddIndex[word]
+		assert.False(ok, word)
+	}
+}
+
+func TestEncodeWords(t *testing.T) {
+	assert := assert.New(t)
+	data := []byte{0x00, 0x00, 0xff, 0xff, 0x42}
+	words := EncodeWords(data)
+	assert.Equal([]string{"aardvark", "adroitness", "zulu", "yucatan", evenWords[0x42]}, words)
+
+	decoded, position, err := DecodeWords([]string{"Aardvark", "ADROITNESS", "zulu", "yucatan", evenWords[0x42]})
+	assert.NoError(err)
+	assert.Equal(-1, position)
+	assert.Equal(data, decoded)
+
+	// A missing word shifts the following words to the other list
+	_, position, err = DecodeWords(append(words[:1:1], words[2:]...))
+	assert.Equal(ErrorWordOrder, err)
+	assert.Equal(1, position)
+	// Transposed words
+	_, position, err = DecodeWords([]string{words[0], words[2], words[1]})
+	assert.Equal(ErrorWordOrder, err)
+	assert.Equal(1, position)
+	_, position, err = DecodeWords([]string{words[0], "aadvark"})
+	assert.Equal(ErrorUnknownWord, err)
+	assert.Equal(1, position)
+}
+
+func TestShareWords(t *testing.T) {
+	assert := assert.New(t)
+	fieldSize := Conservative128().FieldSize
+	shares := ShareFiniteField(big.NewInt(42), fieldSize, 1, 3)
+	words, err := ShareToWords(shares[2])
+	assert.NoError(err)
+	assert.Len(words, 36)
+
+	share, position, err := ShareFromWords(words, fieldSize, 1)
+	assert.NoError(err)
+	assert.Equal(-1, position)
+	secret, err := ShareCombine([]Share{shares[0], share})
+	assert.NoError(err)
+	assert.Equal(int64(42), secret.Int64())
+
+	// A word replaced by another word of the same list is caught by the checksum
+	wrong := append([]string{}, words...)
+	wrong[10] = EncodeWords([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, byte(evenIndex[words[10]] + 1)})[10]
+	_, position, err = ShareFromWords(wrong, fieldSize, 1)
+	assert.Equal(ErrorWordChecksum, err)
+	assert.Equal(-1, position)
+	_, _, err = ShareFromWords(words, fieldSize, 2)
+	assert.Equal(ErrorWordChecksum, err)
+	_, position, err = ShareFromWords(words[:34], fieldSize, 1)
+	assert.Equal(ErrorWordsTooShort, err)
+	assert.Equal(34, position)
+
+	_, err = ShareToWords(Share{Factor: big.NewInt(1), Y: big.NewInt(1)})
+	assert.Equal(ErrorWrongShareType, err)
+	_, err = ShareToWords(Share{FieldSize: fieldSize, X: 70000, Y: big.NewInt(1)})
+	assert.Equal(ErrorInvalidX, err)
+}
@@ -0,0 +1,253 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+// The conversions in this file connect Shamir shares to engines that work on XOR (boolean) shares,
+// such as GMW or garbled circuits, in which every party holds a bit string and the value is the XOR
+// of the bit strings of all parties. ToXOR decomposes the shared values into shared bits, which are
+// masked with random bits of all parties but the first and opened to the first party only. FromXOR
+// lets every party share the bits of its XOR share, and computes the XOR of these with a + b - 2ab.
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// BitDecompose returns shares of the bits of the secrets of a, for secrets in [0, 2^bits), with bit
+// j of a[i] at index i*bits+j. The field size must exceed (nParties+2) * 2^(bits+41). The secrets
+// are statistically hidden with 40 bits of security.
+func (p *Party) BitDecompose(a ShareVector, bits int) (ShareVector, error) {
+	bound := big.NewInt(int64(p.nParties + 2))
+	bound.Lsh(bound, uint(bits+1+comparisonSecurity))
+	if p.fieldSize.Cmp(bound) <= 0 {
+		return nil, ErrorFieldTooSmall
+	}
+	maskBits, err := p.RandomBits(len(a) * bits)
+	if err != nil {
+		return nil, err
+	}
+	highBound := big.NewInt(1)
+	highBound.Lsh(highBound, uint(1+comparisonSecurity))
+	highMasks, err := p.randomBelow(len(a), highBound)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open c = a + 2^bits r'' + r', where r' are the low bits of the mask, then a = c - r' mod 2^bits
+	twoM := big.NewInt(1)
+	twoM.Lsh(twoM, uint(bits))
+	masked := make(ShareVector, len(a))
+	for i := range a {
+		terms := []Share{a[i], ShareMulConstant(highMasks[i], twoM)}
+		for j := 0; j < bits; j++ {
+			terms = append(terms, ShareMulConstant(maskBits[i*bits+j], big.NewInt(0).Lsh(big.NewInt(1), uint(j))))
+		}
+		masked[i], err = ShareAdd(terms)
+		if err != nil {
+			return nil, err
+		}
+	}
+	opened, err := p.Open(masked)
+	if err != nil {
+		return nil, err
+	}
+	return p.bitSubtract(opened, maskBits, bits)
+}
+
+// bitSubtract returns shares of the bits of c[i] - r[i] mod 2^m, where c[i] are public integers and
+// r[i*m+j] is bit j of the shared r[i], in the same layout.
+func (p *Party) bitSubtract(c []*big.Int, r ShareVector, m int) (ShareVector, error) {
+	// With borrow b, bit j of the difference is c_j xor r_j xor b. The next borrow is r_j or b if
+	// c_j = 0, and r_j and b if c_j = 1.
+	differences := make(ShareVector, len(r))
+	borrows := p.constant(big.NewInt(0), len(c))
+	one := big.NewInt(1)
+	for j := 0; j < m; j++ {
+		bits := make(ShareVector, len(c))
+		for i := range c {
+			bits[i] = r[i*m+j]
+		}
+		products, err := p.Mul(bits, borrows)
+		if err != nil {
+			return nil, err
+		}
+		for i := range c {
+			sum, err := ShareAdd([]Share{bits[i], borrows[i]})
+			if err != nil {
+				return nil, err
+			}
+			xor, err := ShareAdd([]Share{sum, ShareMulConstant(products[i], big.NewInt(-2))})
+			if err != nil {
+				return nil, err
+			}
+			if c[i].Bit(j) == 0 {
+				differences[i*m+j] = xor
+				borrows[i], err = ShareAdd([]Share{sum, ShareMulConstant(products[i], big.NewInt(-1))})
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				differences[i*m+j] = ShareAddConstant(ShareMulConstant(xor, big.NewInt(-1)), one)
+				borrows[i] = products[i]
+			}
+		}
+	}
+	return differences, nil
+}
+
+// ToXOR converts shares of secrets in [0, 2^bits) into XOR shares: it returns the bit string of this
+// party for every secret, such that the XOR of the bit strings of all parties equals the secret. The
+// requirements on the field size are those of BitDecompose.
+func (p *Party) ToXOR(a ShareVector, bits int) ([]*big.Int, error) {
+	decomposed, err := p.BitDecompose(a, bits)
+	if err != nil {
+		return nil, err
+	}
+	// Every party but the first chooses its XOR shares at random
+	own := make([]*big.Int, len(decomposed))
+	for i := range own {
+		own[i] = big.NewInt(0)
+	}
+	if p.x != 1 {
+		for i := range own {
+			own[i], err = rand.Int(rand.Reader, big.NewInt(2))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	masks, err := p.inputAll(own)
+	if err != nil {
+		return nil, err
+	}
+	masked := decomposed
+	for _, mask := range masks[1:] {
+		masked, err = p.xor(masked, mask)
+		if err != nil {
+			return nil, err
+		}
+	}
+	opened, err := p.openTo(1, masked)
+	if err != nil {
+		return nil, err
+	}
+	if p.x == 1 {
+		own = opened
+	}
+	return composeBits(own, bits), nil
+}
+
+// FromXOR converts XOR shares into Shamir shares: values holds the bit string of this party for
+// every secret, of which only the lowest bits are used. All parties must pass the same number of
+// values.
+func (p *Party) FromXOR(values []*big.Int, bits int) (ShareVector, error) {
+	own := make([]*big.Int, len(values)*bits)
+	for i := range values {
+		for j := 0; j < bits; j++ {
+			own[i*bits+j] = big.NewInt(int64(values[i].Bit(j)))
+		}
+	}
+	contributions, err := p.inputAll(own)
+	if err != nil {
+		return nil, err
+	}
+	xored := contributions[0]
+	for _, contribution := range contributions[1:] {
+		xored, err = p.xor(xored, contribution)
+		if err != nil {
+			return nil, err
+		}
+	}
+	secrets := make(ShareVector, len(values))
+	for i := range secrets {
+		terms := make([]Share, bits)
+		for j := range terms {
+			terms[j] = ShareMulConstant(xored[i*bits+j], big.NewInt(0).Lsh(big.NewInt(1), uint(j)))
+		}
+		secrets[i], err = ShareAdd(terms)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return secrets, nil
+}
+
+// xor returns shares of the XOR of the bits shared by a and b.
+func (p *Party) xor(a ShareVector, b ShareVector) (ShareVector, error) {
+	products, err := p.Mul(a, b)
+	if err != nil {
+		return nil, err
+	}
+	result := make(ShareVector, len(a))
+	for i := range a {
+		result[i], err = ShareAdd([]Share{a[i], b[i], ShareMulConstant(products[i], big.NewInt(-2))})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// inputAll shares secrets of every party among all parties, and returns the shares of the secrets
+// of every party, ordered by X coordinate. All parties must pass the same number of secrets.
+func (p *Party) inputAll(secrets []*big.Int) ([]ShareVector, error) {
+	outgoing := make([][]NestedShare, p.nParties)
+	for i, v := range ShareVectorFiniteField(secrets, p.fieldSize, p.degree, p.nParties) {
+		outgoing[i] = nest(v)
+	}
+	received, err := p.exchange(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([]ShareVector, len(received))
+	for i := range received {
+		if len(received[i]) != len(secrets) {
+			return nil, ErrorNetwork
+		}
+		vectors[i] = unnest(received[i])
+	}
+	return vectors, nil
+}
+
+// openTo reveals the secrets shared by v to the party with X coordinate owner only. The other
+// parties receive nil.
+func (p *Party) openTo(owner int, v ShareVector) ([]*big.Int, error) {
+	outgoing := make([][]NestedShare, p.nParties)
+	outgoing[owner-1] = nest(v)
+	received, err := p.exchange(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	if p.x != owner {
+		return nil, nil
+	}
+	vectors := make([]ShareVector, len(received))
+	for i := range received {
+		vectors[i] = unnest(received[i])
+	}
+	return CombineVector(vectors)
+}
+
+// composeBits returns the integers whose bits are given by bits[i*m+j], for bit j of integer i.
+func composeBits(bits []*big.Int, m int) []*big.Int {
+	values := make([]*big.Int, len(bits)/m)
+	for i := range values {
+		values[i] = big.NewInt(0)
+		for j := m - 1; j >= 0; j-- {
+			values[i].Lsh(values[i], 1).Or(values[i], bits[i*m+j])
+		}
+	}
+	return values
+}
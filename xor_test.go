@@ -0,0 +1,87 @@
+// Copyright 2021 TNO
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitDecompose(t *testing.T) {
+	assert := assert.New(t)
+	results := runParties(t, 3, 1, mersenne61, func(p *Party) ([]*big.Int, error) {
+		a, err := p.Input(1, bigInts(0, 5, 255, 128), 4)
+		if err != nil {
+			return nil, err
+		}
+		bits, err := p.BitDecompose(a, 8)
+		if err != nil {
+			return nil, err
+		}
+		return p.Open(bits)
+	})
+	for i, value := range []int64{0, 5, 255, 128} {
+		for j := 0; j < 8; j++ {
+			assert.Equal(big.NewInt(value>>uint(j)&1), results[0][i*8+j])
+		}
+	}
+}
+
+func TestXORConversion(t *testing.T) {
+	assert := assert.New(t)
+	xorShares := make([][]*big.Int, 3)
+	results := runParties(t, 3, 1, mersenne61, func(p *Party) ([]*big.Int, error) {
+		a, err := p.Input(2, bigInts(1000, 0, 65535), 3)
+		if err != nil {
+			return nil, err
+		}
+		xorShares[p.X()-1], err = p.ToXOR(a, 16)
+		if err != nil {
+			return nil, err
+		}
+		// Flip all bits in the XOR domain and convert back
+		flipped := make([]*big.Int, len(a))
+		for i := range flipped {
+			flipped[i] = big.NewInt(0).Set(xorShares[p.X()-1][i])
+			if p.X() == 1 {
+				flipped[i].Xor(flipped[i], big.NewInt(0xffff))
+			}
+		}
+		b, err := p.FromXOR(flipped, 16)
+		if err != nil {
+			return nil, err
+		}
+		return p.Open(b)
+	})
+	for i, value := range []int64{1000, 0, 65535} {
+		xored := big.NewInt(0)
+		for _, shares := range xorShares {
+			xored.Xor(xored, shares[i])
+		}
+		assert.Equal(value, xored.Int64())
+		assert.Equal(big.NewInt(value^0xffff), results[0][i])
+	}
+	// The XOR shares of the other parties are random
+	assert.NotEqual(xorShares[1], xorShares[2])
+}
+
+func TestBitDecomposeFieldTooSmall(t *testing.T) {
+	assert := assert.New(t)
+	p := NewParty(1, 3, big.NewInt(7919), 1, nil)
+	_, err := p.BitDecompose(nil, 8)
+	assert.Equal(ErrorFieldTooSmall, err)
+}